@@ -0,0 +1,138 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	flags "github.com/jessevdk/go-flags"
+	"github.com/nzin/taxsi2/internal/config"
+	"github.com/nzin/taxsi2/internal/db"
+	"github.com/nzin/taxsi2/internal/policy"
+)
+
+/*
+ConfigExportCommand dumps the policy stored in the database as YAML
+*/
+type ConfigExportCommand struct {
+	Output string `short:"o" long:"output" description:"file to write the policy to (default stdout)"`
+
+	ds  func() (db.DbService, error)
+	out io.Writer
+}
+
+/*
+ConfigApplyCommand diffs a YAML policy against the database and applies it
+*/
+type ConfigApplyCommand struct {
+	DryRun  bool   `long:"dry-run" description:"only print the changes"`
+	Author  string `long:"author" env:"USER" default:"taxsi2" description:"author recorded in the config history"`
+	Comment string `long:"comment" description:"comment recorded in the config history"`
+	Args    struct {
+		File string `positional-arg-name:"file" required:"yes"`
+	} `positional-args:"yes"`
+
+	ds  func() (db.DbService, error)
+	out io.Writer
+}
+
+func (c *ConfigExportCommand) Execute(args []string) error {
+	ds, err := c.ds()
+	if err != nil {
+		return err
+	}
+
+	doc, err := policy.Export(ds)
+	if err != nil {
+		return err
+	}
+	data, err := doc.Marshal()
+	if err != nil {
+		return err
+	}
+
+	if c.Output == "" {
+		_, err = c.out.Write(data)
+		return err
+	}
+	return os.WriteFile(c.Output, data, 0644)
+}
+
+func (c *ConfigApplyCommand) Execute(args []string) error {
+	data, err := os.ReadFile(c.Args.File)
+	if err != nil {
+		return err
+	}
+	doc, err := policy.Unmarshal(data)
+	if err != nil {
+		return err
+	}
+
+	ds, err := c.ds()
+	if err != nil {
+		return err
+	}
+
+	var changes []policy.Change
+	if c.DryRun {
+		changes, err = policy.Diff(ds, doc)
+	} else {
+		changes, err = policy.Apply(ds, doc, c.Author, c.Comment)
+	}
+	if err != nil {
+		return err
+	}
+
+	for _, change := range changes {
+		fmt.Fprintln(c.out, change)
+	}
+	if len(changes) == 0 {
+		fmt.Fprintln(c.out, "no change")
+	} else if c.DryRun {
+		fmt.Fprintf(c.out, "%d change(s) to apply (dry run)\n", len(changes))
+	} else {
+		fmt.Fprintf(c.out, "%d change(s) applied\n", len(changes))
+	}
+	return nil
+}
+
+func newDbService() (db.DbService, error) {
+	return db.NewDbService(
+		config.Config.DBDriver,
+		config.Config.DBConnectionStr,
+		config.Config.DBConnectionRetryAttempts,
+		config.Config.DBConnectionRetryDelay,
+	)
+}
+
+/*
+RunConfig runs the 'taxsi2 config <export|apply>' sub commands and returns the exit code
+*/
+func RunConfig(args []string) int {
+	return runConfig(args, newDbService, os.Stdout)
+}
+
+func runConfig(args []string, ds func() (db.DbService, error), out io.Writer) int {
+	parser := flags.NewNamedParser("taxsi2 config", flags.Default)
+	parser.ShortDescription = "manage the taxsi2 policy as a YAML document"
+
+	_, err := parser.AddCommand("export", "export the policy", "Dump the GlobalConfig, allow/deny lists, geoip countries, rate limits, rules, JWT policies and modsec rulesets as YAML", &ConfigExportCommand{ds: ds, out: out})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	_, err = parser.AddCommand("apply", "apply a policy", "Diff a YAML policy against the database and apply it in one transaction", &ConfigApplyCommand{ds: ds, out: out})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	// errors (including the commands ones) are printed by the parser
+	if _, err := parser.ParseArgs(args); err != nil {
+		if fe, ok := err.(*flags.Error); ok && fe.Type == flags.ErrHelp {
+			return 0
+		}
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,69 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/nzin/taxsi2/internal/db"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunConfig(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "taxsi.cli*")
+	assert.Nil(t, err)
+	defer os.RemoveAll(tmpDir)
+
+	dbs, err := db.NewDbService("sqlite3", filepath.Join(tmpDir, "taxsi.db"), 1, 1*time.Second)
+	assert.Nil(t, err)
+	newDs := func() (db.DbService, error) { return dbs, nil }
+
+	err = dbs.SetConfigValueForKey("mode", "enabled")
+	assert.Nil(t, err)
+
+	t.Run("happy path: export", func(t *testing.T) {
+		var out bytes.Buffer
+		code := runConfig([]string{"export"}, newDs, &out)
+		assert.Equal(t, 0, code)
+		assert.Contains(t, out.String(), "mode: enabled")
+	})
+
+	t.Run("happy path: apply with dry run", func(t *testing.T) {
+		file := filepath.Join(tmpDir, "policy.yaml")
+		err := os.WriteFile(file, []byte("config:\n  mode: dryrun\n"), 0644)
+		assert.Nil(t, err)
+
+		var out bytes.Buffer
+		code := runConfig([]string{"apply", "--dry-run", file}, newDs, &out)
+		assert.Equal(t, 0, code)
+		assert.Equal(t, "~ config.mode: enabled -> dryrun\n1 change(s) to apply (dry run)\n", out.String())
+
+		value, err := dbs.GetConfigValueForKey("mode")
+		assert.Nil(t, err)
+		assert.Equal(t, "enabled", value)
+
+		out.Reset()
+		code = runConfig([]string{"apply", "--author", "bob", file}, newDs, &out)
+		assert.Equal(t, 0, code)
+		assert.Equal(t, "~ config.mode: enabled -> dryrun\n1 change(s) applied\n", out.String())
+
+		value, err = dbs.GetConfigValueForKey("mode")
+		assert.Nil(t, err)
+		assert.Equal(t, "dryrun", value)
+	})
+
+	t.Run("not happy path: bad policy", func(t *testing.T) {
+		file := filepath.Join(tmpDir, "bad.yaml")
+		err := os.WriteFile(file, []byte("config:\n  mode: foo\n"), 0644)
+		assert.Nil(t, err)
+
+		var out bytes.Buffer
+		code := runConfig([]string{"apply", file}, newDs, &out)
+		assert.Equal(t, 1, code)
+
+		code = runConfig([]string{"unknown"}, newDs, &out)
+		assert.Equal(t, 1, code)
+	})
+}
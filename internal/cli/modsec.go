@@ -0,0 +1,90 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	flags "github.com/jessevdk/go-flags"
+	"github.com/nzin/taxsi2/internal/db"
+	"github.com/nzin/taxsi2/internal/engine/plugins/modsec"
+)
+
+/*
+ModsecImportCommand loads SecLang files (like the OWASP CRS rules) into the
+database, each file is a ruleset named after the file
+*/
+type ModsecImportCommand struct {
+	DryRun bool `long:"dry-run" description:"only parse the files"`
+	Args   struct {
+		Files []string `positional-arg-name:"file.conf" required:"yes"`
+	} `positional-args:"yes"`
+
+	ds  func() (db.DbService, error)
+	out io.Writer
+}
+
+func (c *ModsecImportCommand) Execute(args []string) error {
+	var ds db.DbService
+	if !c.DryRun {
+		var err error
+		if ds, err = c.ds(); err != nil {
+			return err
+		}
+	}
+
+	for _, file := range c.Args.Files {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		ruleset := db.ModsecRuleset{Name: filepath.Base(file), Content: string(content)}
+
+		var rs *modsec.Ruleset
+		if c.DryRun {
+			rs = modsec.Parse(ruleset.Name, ruleset.Content)
+		} else if rs, err = modsec.SaveRuleset(ds, &ruleset); err != nil {
+			return err
+		}
+
+		for _, w := range rs.Warnings {
+			fmt.Fprintln(c.out, w)
+		}
+		rules := 0
+		for _, r := range rs.Rules {
+			if r.Marker == "" {
+				rules++
+			}
+		}
+		fmt.Fprintf(c.out, "%s: %d rule(s), %d response rule(s), %d warning(s)\n", ruleset.Name, rules, rs.ResponseRules, len(rs.Warnings))
+	}
+	return nil
+}
+
+/*
+RunModsec runs the 'taxsi2 modsec import' sub command and returns the exit code
+*/
+func RunModsec(args []string) int {
+	return runModsec(args, newDbService, os.Stdout)
+}
+
+func runModsec(args []string, ds func() (db.DbService, error), out io.Writer) int {
+	parser := flags.NewNamedParser("taxsi2 modsec", flags.Default)
+	parser.ShortDescription = "manage the SecLang rulesets of the modsec plugin"
+
+	_, err := parser.AddCommand("import", "import SecLang files", "Load .conf files (like the OWASP CRS rules) into the database, the unsupported rules are reported and skipped", &ModsecImportCommand{ds: ds, out: out})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	// errors (including the commands ones) are printed by the parser
+	if _, err := parser.ParseArgs(args); err != nil {
+		if fe, ok := err.(*flags.Error); ok && fe.Type == flags.ErrHelp {
+			return 0
+		}
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,59 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/nzin/taxsi2/internal/db"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunModsec(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "taxsi.cli*")
+	assert.Nil(t, err)
+	defer os.RemoveAll(tmpDir)
+
+	dbs, err := db.NewDbService("sqlite3", filepath.Join(tmpDir, "taxsi.db"), 1, 1*time.Second)
+	assert.Nil(t, err)
+	newDs := func() (db.DbService, error) { return dbs, nil }
+
+	file := filepath.Join(tmpDir, "REQUEST-942-APPLICATION-ATTACK-SQLI.conf")
+	err = os.WriteFile(file, []byte(`
+SecRule ARGS "@detectSQLi" "id:942100,phase:2,block,severity:'CRITICAL'"
+SecRule ARGS "@pmFromFile sqli.data" "id:942101,phase:2,block"
+`), 0644)
+	assert.Nil(t, err)
+
+	t.Run("happy path: import with dry run", func(t *testing.T) {
+		var out bytes.Buffer
+		code := runModsec([]string{"import", "--dry-run", file}, newDs, &out)
+		assert.Equal(t, 0, code)
+		assert.Equal(t, "REQUEST-942-APPLICATION-ATTACK-SQLI.conf:3: rule 942101 skipped: @pmf is not supported\n"+
+			"REQUEST-942-APPLICATION-ATTACK-SQLI.conf: 1 rule(s), 0 response rule(s), 1 warning(s)\n", out.String())
+
+		rulesets, err := dbs.GetModsecRulesets()
+		assert.Nil(t, err)
+		assert.Equal(t, 0, len(rulesets))
+
+		out.Reset()
+		code = runModsec([]string{"import", file}, newDs, &out)
+		assert.Equal(t, 0, code)
+
+		rulesets, err = dbs.GetModsecRulesets()
+		assert.Nil(t, err)
+		assert.Equal(t, 1, len(rulesets))
+		assert.Equal(t, "REQUEST-942-APPLICATION-ATTACK-SQLI.conf", rulesets[0].Name)
+	})
+
+	t.Run("not happy path: missing file", func(t *testing.T) {
+		var out bytes.Buffer
+		code := runModsec([]string{"import", filepath.Join(tmpDir, "missing.conf")}, newDs, &out)
+		assert.Equal(t, 1, code)
+
+		code = runModsec([]string{"import"}, newDs, &out)
+		assert.Equal(t, 1, code)
+	})
+}
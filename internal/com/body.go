@@ -0,0 +1,245 @@
+package com
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+const (
+	// the bodies bigger than that are not parsed (and reported as malformed)
+	MAX_BODY_SIZE = 1024 * 1024
+	// nesting limit of the JSON and XML documents
+	MAX_BODY_DEPTH = 32
+	// how many fields a body can have
+	MAX_BODY_FIELDS = 1024
+)
+
+/*
+File is an uploaded file (multipart/form-data)
+*/
+type File struct {
+	// the form field name
+	Name        string
+	Filename    string
+	ContentType string
+	Content     []byte
+}
+
+/*
+bodyParser flattens a body into named fields, like user.address[0].street
+*/
+type bodyParser struct {
+	p      *ParsedRequest
+	fields int
+}
+
+func (b *bodyParser) add(source string, name string, value string) error {
+	b.fields++
+	if b.fields > MAX_BODY_FIELDS {
+		return fmt.Errorf("more than %d fields", MAX_BODY_FIELDS)
+	}
+	b.p.Fields = append(b.p.Fields, newField(source, name, value))
+	return nil
+}
+
+/*
+parseBody parses the body according to its content type.
+The fields parsed before an error are kept
+*/
+func parseBody(p *ParsedRequest, body []byte, params map[string]string) error {
+	if len(body) == 0 {
+		return nil
+	}
+	b := &bodyParser{p: p}
+	ct := p.ContentType
+
+	var parse func() error
+	switch {
+	case ct == "application/x-www-form-urlencoded":
+		parse = func() error { return b.parseUrlencoded(body) }
+	case ct == "multipart/form-data":
+		parse = func() error { return b.parseMultipart(body, params["boundary"]) }
+	case ct == "application/json" || strings.HasSuffix(ct, "+json"):
+		parse = func() error { return b.parseJson(body) }
+	case ct == "application/xml" || ct == "text/xml" || strings.HasSuffix(ct, "+xml"):
+		parse = func() error { return b.parseXml(body) }
+	default:
+		// opaque body
+		return nil
+	}
+
+	if len(body) > MAX_BODY_SIZE {
+		return fmt.Errorf("body bigger than %d bytes", MAX_BODY_SIZE)
+	}
+	return parse()
+}
+
+func (b *bodyParser) parseUrlencoded(body []byte) error {
+	// the values that can be parsed are kept even on error
+	form, err := url.ParseQuery(string(body))
+	for name, values := range form {
+		for _, v := range values {
+			if err := b.add(SOURCE_FORM, name, v); err != nil {
+				return err
+			}
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("invalid urlencoded body: %v", err)
+	}
+	return nil
+}
+
+func (b *bodyParser) parseMultipart(body []byte, boundary string) error {
+	if boundary == "" {
+		return fmt.Errorf("multipart body without boundary")
+	}
+	reader := multipart.NewReader(bytes.NewReader(body), boundary)
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("invalid multipart body: %v", err)
+		}
+		content, err := io.ReadAll(part)
+		if err != nil {
+			return fmt.Errorf("invalid multipart body: %v", err)
+		}
+
+		if part.FileName() == "" {
+			if err := b.add(SOURCE_FORM, part.FormName(), string(content)); err != nil {
+				return err
+			}
+			continue
+		}
+		// the file names are user controlled too
+		if err := b.add(SOURCE_FILE, part.FormName(), rawFileName(part)); err != nil {
+			return err
+		}
+		b.p.Files = append(b.p.Files, File{
+			Name:        part.FormName(),
+			Filename:    part.FileName(),
+			ContentType: part.Header.Get("Content-Type"),
+			Content:     content,
+		})
+	}
+}
+
+/*
+rawFileName returns the file name of a part as sent by the client
+(part.FileName() strips the directories, like a ../ traversal)
+*/
+func rawFileName(part *multipart.Part) string {
+	_, params, err := mime.ParseMediaType(part.Header.Get("Content-Disposition"))
+	if err != nil || params["filename"] == "" {
+		return part.FileName()
+	}
+	return params["filename"]
+}
+
+func (b *bodyParser) parseJson(body []byte) error {
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	var doc interface{}
+	if err := decoder.Decode(&doc); err != nil {
+		return fmt.Errorf("invalid JSON body: %v", err)
+	}
+	if decoder.More() {
+		return fmt.Errorf("invalid JSON body: trailing data")
+	}
+	return b.walkJson("", doc, 0)
+}
+
+func (b *bodyParser) walkJson(name string, doc interface{}, depth int) error {
+	if depth > MAX_BODY_DEPTH {
+		return fmt.Errorf("JSON body deeper than %d", MAX_BODY_DEPTH)
+	}
+	switch v := doc.(type) {
+	case map[string]interface{}:
+		for k, child := range v {
+			childName := k
+			if name != "" {
+				childName = name + "." + k
+			}
+			if err := b.walkJson(childName, child, depth+1); err != nil {
+				return err
+			}
+		}
+		return nil
+	case []interface{}:
+		for i, child := range v {
+			if err := b.walkJson(name+"["+strconv.Itoa(i)+"]", child, depth+1); err != nil {
+				return err
+			}
+		}
+		return nil
+	case string:
+		return b.add(SOURCE_JSON, name, v)
+	case json.Number:
+		return b.add(SOURCE_JSON, name, v.String())
+	case bool:
+		return b.add(SOURCE_JSON, name, strconv.FormatBool(v))
+	}
+	// null
+	return nil
+}
+
+/*
+parseXml flattens the text and the attributes of the elements
+(like user.address.street and user.address.street@id)
+*/
+func (b *bodyParser) parseXml(body []byte) error {
+	decoder := xml.NewDecoder(bytes.NewReader(body))
+	path := []string{}
+	text := []*strings.Builder{}
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			if len(path) > 0 {
+				return fmt.Errorf("invalid XML body: unclosed element %s", path[len(path)-1])
+			}
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("invalid XML body: %v", err)
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			if len(path) >= MAX_BODY_DEPTH {
+				return fmt.Errorf("XML body deeper than %d", MAX_BODY_DEPTH)
+			}
+			path = append(path, t.Name.Local)
+			text = append(text, &strings.Builder{})
+			name := strings.Join(path, ".")
+			for _, a := range t.Attr {
+				if err := b.add(SOURCE_XML, name+"@"+a.Name.Local, a.Value); err != nil {
+					return err
+				}
+			}
+		case xml.CharData:
+			if len(text) > 0 {
+				text[len(text)-1].Write(t)
+			}
+		case xml.EndElement:
+			// the decoder checks that the end element matches
+			if value := strings.TrimSpace(text[len(text)-1].String()); value != "" {
+				if err := b.add(SOURCE_XML, strings.Join(path, "."), value); err != nil {
+					return err
+				}
+			}
+			path = path[:len(path)-1]
+			text = text[:len(text)-1]
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package com
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func parseBodyRequest(contentType string, body string) *ParsedRequest {
+	u, _ := url.Parse("http://www.example.com/api")
+	payload := &TaxsiCom{
+		Method:  "POST",
+		Url:     u,
+		Headers: map[string][]string{"Content-Type": {contentType}},
+		Body:    []byte(body),
+	}
+	return payload.Parse()
+}
+
+func bodyFields(p *ParsedRequest, source string) map[string]string {
+	fields := map[string]string{}
+	for _, f := range p.FieldsFrom(source) {
+		fields[f.Name] = f.Value
+	}
+	return fields
+}
+
+func TestParseBody(t *testing.T) {
+	t.Run("happy path: JSON", func(t *testing.T) {
+		p := parseBodyRequest("application/json; charset=utf-8", `{
+			"user": {"name": "Bob", "admin": false, "age": 42, "nick": null,
+			         "address": [{"street": "1 main st"}, {"street": "2 main st"}]},
+			"tags": ["a", "b"]
+		}`)
+		assert.Nil(t, p.BodyError)
+		assert.Equal(t, map[string]string{
+			"user.name":              "Bob",
+			"user.admin":             "false",
+			"user.age":               "42",
+			"user.address[0].street": "1 main st",
+			"user.address[1].street": "2 main st",
+			"tags[0]":                "a",
+			"tags[1]":                "b",
+		}, bodyFields(p, SOURCE_JSON))
+
+		p = parseBodyRequest("application/vnd.api+json", `["x"]`)
+		assert.Nil(t, p.BodyError)
+		assert.Equal(t, map[string]string{"[0]": "x"}, bodyFields(p, SOURCE_JSON))
+	})
+
+	t.Run("happy path: XML", func(t *testing.T) {
+		p := parseBodyRequest("text/xml", `<?xml version="1.0"?>
+			<user id="1"><name>Bob</name><address><street>1 main st</street></address></user>`)
+		assert.Nil(t, p.BodyError)
+		assert.Equal(t, map[string]string{
+			"user@id":             "1",
+			"user.name":           "Bob",
+			"user.address.street": "1 main st",
+		}, bodyFields(p, SOURCE_XML))
+	})
+
+	t.Run("happy path: urlencoded", func(t *testing.T) {
+		p := parseBodyRequest("application/x-www-form-urlencoded", "user=bob&comment=hello+world")
+		assert.Nil(t, p.BodyError)
+		assert.Equal(t, map[string]string{"user": "bob", "comment": "hello world"}, bodyFields(p, SOURCE_FORM))
+	})
+
+	t.Run("happy path: multipart", func(t *testing.T) {
+		body := strings.ReplaceAll(`--XXX
+Content-Disposition: form-data; name="title"
+
+my picture
+--XXX
+Content-Disposition: form-data; name="picture"; filename="cat.png"
+Content-Type: image/png
+
+PNGDATA
+--XXX--
+`, "\n", "\r\n")
+		p := parseBodyRequest("multipart/form-data; boundary=XXX", body)
+		assert.Nil(t, p.BodyError)
+		assert.Equal(t, map[string]string{"title": "my picture"}, bodyFields(p, SOURCE_FORM))
+		assert.Equal(t, map[string]string{"picture": "cat.png"}, bodyFields(p, SOURCE_FILE))
+		assert.Equal(t, 1, len(p.Files))
+		assert.Equal(t, "picture", p.Files[0].Name)
+		assert.Equal(t, "cat.png", p.Files[0].Filename)
+		assert.Equal(t, "image/png", p.Files[0].ContentType)
+		assert.Equal(t, "PNGDATA", string(p.Files[0].Content))
+	})
+
+	t.Run("happy path: opaque bodies are not parsed", func(t *testing.T) {
+		p := parseBodyRequest("application/octet-stream", "{not json")
+		assert.Nil(t, p.BodyError)
+		assert.Equal(t, 0, len(p.FieldsFrom(SOURCE_FORM, SOURCE_JSON, SOURCE_XML)))
+	})
+
+	t.Run("not happy path: malformed bodies", func(t *testing.T) {
+		for ct, body := range map[string]string{
+			"application/json":                  `{"user": "bob"`,
+			"text/xml":                          `<user><name>bob</user>`,
+			"application/xml":                   `<user>`,
+			"application/x-www-form-urlencoded": "a=%zz",
+			"multipart/form-data":               "--XXX\r\n",
+			"multipart/form-data; boundary=XXX": "--YYY\r\nfoo\r\n",
+		} {
+			p := parseBodyRequest(ct, body)
+			assert.NotNil(t, p.BodyError, "%s: %s", ct, body)
+		}
+		// the fields parsed before the error are kept
+		p := parseBodyRequest("application/x-www-form-urlencoded", "a=1&b=%zz")
+		assert.Equal(t, map[string]string{"a": "1"}, bodyFields(p, SOURCE_FORM))
+	})
+
+	t.Run("not happy path: limits", func(t *testing.T) {
+		deep := strings.Repeat(`{"a":`, MAX_BODY_DEPTH+2) + "1" + strings.Repeat("}", MAX_BODY_DEPTH+2)
+		assert.NotNil(t, parseBodyRequest("application/json", deep).BodyError)
+
+		deep = strings.Repeat("<a>", MAX_BODY_DEPTH+1) + strings.Repeat("</a>", MAX_BODY_DEPTH+1)
+		assert.NotNil(t, parseBodyRequest("text/xml", deep).BodyError)
+
+		fields := []string{}
+		for i := 0; i <= MAX_BODY_FIELDS; i++ {
+			fields = append(fields, fmt.Sprintf("f%d=1", i))
+		}
+		assert.NotNil(t, parseBodyRequest("application/x-www-form-urlencoded", strings.Join(fields, "&")).BodyError)
+
+		big := `{"a": "` + strings.Repeat("x", MAX_BODY_SIZE) + `"}`
+		assert.NotNil(t, parseBodyRequest("application/json", big).BodyError)
+	})
+}
@@ -0,0 +1,216 @@
+package com
+
+import (
+	"encoding/base64"
+	"html"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+const (
+	// how many decoding layers are applied (a value url-encoded 3 times...)
+	MAX_DECODING_DEPTH = 3
+	// how many decoded variants are kept per value
+	MAX_DECODED_VARIANTS = 8
+	// the values bigger than that are not decoded (only normalized)
+	MAX_DECODED_SIZE = 64 * 1024
+	// the smallest base64 string worth decoding
+	MIN_BASE64_SIZE = 8
+)
+
+type decoder func(string) (string, bool)
+
+// the order matters: the first decoded variants are the most likely
+var decoders = []decoder{urlDecode, htmlDecode, unicodeDecode, base64Decode}
+
+/*
+Decode returns the normalized value, followed by its normalized decoded
+variants (url, html entities, unicode escapes, base64), applied recursively
+*/
+func Decode(value string) []string {
+	layers := []string{value}
+	if len(value) <= MAX_DECODED_SIZE {
+		seen := map[string]bool{value: true}
+		current := []string{value}
+		for depth := 0; depth < MAX_DECODING_DEPTH && len(current) > 0; depth++ {
+			next := []string{}
+			for _, v := range current {
+				for _, d := range decoders {
+					decoded, ok := d(v)
+					if !ok || seen[decoded] || len(layers) >= MAX_DECODED_VARIANTS {
+						continue
+					}
+					seen[decoded] = true
+					layers = append(layers, decoded)
+					next = append(next, decoded)
+				}
+			}
+			current = next
+		}
+	}
+
+	result := []string{}
+	seen := map[string]bool{}
+	for _, l := range layers {
+		n := Normalize(l)
+		if !seen[n] {
+			seen[n] = true
+			result = append(result, n)
+		}
+	}
+	return result
+}
+
+/*
+Normalize strips the null bytes, folds the unicode compatibility
+characters (like the fullwidth ＜) and lowercases a value
+*/
+func Normalize(value string) string {
+	value = strings.ReplaceAll(value, "\x00", "")
+	if utf8.ValidString(value) {
+		value = norm.NFKC.String(value)
+	}
+	return strings.ToLower(value)
+}
+
+func urlDecode(s string) (string, bool) {
+	if !strings.Contains(s, "%") {
+		return "", false
+	}
+	// IIS %uXXXX
+	d := replaceEscapes(s, "%u", 4)
+	d = replaceEscapes(d, "%U", 4)
+	// the invalid escapes are kept as is
+	d, err := url.PathUnescape(fixPercents(d))
+	if err != nil {
+		return "", false
+	}
+	return d, d != s
+}
+
+/*
+fixPercents escapes the '%' that don't start a valid escape
+*/
+func fixPercents(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '%' && !(i+2 < len(s) && isHex(s[i+1]) && isHex(s[i+2])) {
+			b.WriteString("%25")
+			continue
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+func isHex(c byte) bool {
+	return (c >= '0' && c <= '9') || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F')
+}
+
+func htmlDecode(s string) (string, bool) {
+	if !strings.Contains(s, "&") {
+		return "", false
+	}
+	d := html.UnescapeString(s)
+	return d, d != s
+}
+
+/*
+unicodeDecode decodes the javascript/css/json escapes (\uXXXX, \xXX)
+*/
+func unicodeDecode(s string) (string, bool) {
+	if !strings.Contains(s, "\\") {
+		return "", false
+	}
+	d := replaceEscapes(s, "\\u", 4)
+	d = replaceEscapes(d, "\\x", 2)
+	return d, d != s
+}
+
+func replaceEscapes(s string, prefix string, size int) string {
+	var b strings.Builder
+	for {
+		i := strings.Index(s, prefix)
+		if i < 0 {
+			b.WriteString(s)
+			return b.String()
+		}
+		b.WriteString(s[:i])
+		s = s[i:]
+		if len(s) >= len(prefix)+size {
+			if r, err := strconv.ParseUint(s[len(prefix):len(prefix)+size], 16, 32); err == nil {
+				b.WriteRune(rune(r))
+				s = s[len(prefix)+size:]
+				continue
+			}
+		}
+		b.WriteString(prefix)
+		s = s[len(prefix):]
+	}
+}
+
+/*
+base64Decode decodes the values that look like base64,
+and are text once decoded
+*/
+func base64Decode(s string) (string, bool) {
+	if len(s) < MIN_BASE64_SIZE {
+		return "", false
+	}
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if !((c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9') ||
+			c == '+' || c == '/' || c == '-' || c == '_' || c == '=') {
+			return "", false
+		}
+	}
+
+	var d []byte
+	var err error
+	for _, enc := range []*base64.Encoding{base64.StdEncoding, base64.URLEncoding, base64.RawStdEncoding, base64.RawURLEncoding} {
+		if d, err = enc.DecodeString(s); err == nil {
+			break
+		}
+	}
+	if err != nil || !utf8.Valid(d) {
+		return "", false
+	}
+	for _, r := range string(d) {
+		if r < ' ' && r != '\t' && r != '\n' && r != '\r' {
+			return "", false
+		}
+	}
+	return string(d), true
+}
+
+/*
+NormalizePath decodes a path (recursively), and resolves its dot-segments
+(the trailing slash is kept)
+*/
+func NormalizePath(p string) string {
+	for depth := 0; depth < MAX_DECODING_DEPTH; depth++ {
+		d, err := url.PathUnescape(fixPercents(p))
+		if err != nil || d == p {
+			break
+		}
+		p = d
+	}
+	p = strings.ReplaceAll(p, "\x00", "")
+	// windows separators
+	p = strings.ReplaceAll(p, "\\", "/")
+	if p == "" {
+		return "/"
+	}
+
+	trailingSlash := strings.HasSuffix(p, "/")
+	p = path.Clean("/" + p)
+	if trailingSlash && p != "/" {
+		p += "/"
+	}
+	return p
+}
@@ -0,0 +1,61 @@
+package com
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecode(t *testing.T) {
+	t.Run("happy path: plain values are only normalized", func(t *testing.T) {
+		assert.Equal(t, []string{"hello world"}, Decode("Hello World"))
+		assert.Equal(t, []string{"100%"}, Decode("100%"))
+	})
+
+	t.Run("happy path: url encoding, recursively", func(t *testing.T) {
+		assert.Equal(t, []string{"%253cscript%253e", "%3cscript%3e", "<script>"}, Decode("%253Cscript%253E"))
+		// IIS
+		assert.Contains(t, Decode("%u003Cscript%u003E"), "<script>")
+		// invalid escapes are kept
+		assert.Contains(t, Decode("100% %3Cb%3E"), "100% <b>")
+	})
+
+	t.Run("happy path: html entities, unicode escapes and base64", func(t *testing.T) {
+		assert.Contains(t, Decode("&lt;script&gt;"), "<script>")
+		assert.Contains(t, Decode("&#x3C;script&#62;"), "<script>")
+		assert.Contains(t, Decode(`<script\x3e`), "<script>")
+		assert.Contains(t, Decode("PHNjcmlwdD5hbGVydCgxKTwvc2NyaXB0Pg=="), "<script>alert(1)</script>")
+		// mixed layers
+		assert.Contains(t, Decode("%26lt%3Bscript%26gt%3B"), "<script>")
+	})
+
+	t.Run("happy path: normalization", func(t *testing.T) {
+		assert.Equal(t, []string{"<script>"}, Decode("＜SCRIPT＞"))
+		assert.Equal(t, []string{"<script>"}, Decode("<scr\x00ipt>"))
+	})
+
+	t.Run("not happy path: binary base64 is ignored", func(t *testing.T) {
+		assert.Equal(t, []string{"abcdefgh"}, Decode("abcdefgh"))
+	})
+
+	t.Run("not happy path: limits", func(t *testing.T) {
+		// too deep
+		assert.NotContains(t, Decode("%25252525253Cb%25252525253E"), "<b>")
+		// too big
+		big := strings.Repeat("%41", MAX_DECODED_SIZE)
+		assert.Equal(t, []string{strings.ToLower(big)}, Decode(big))
+		assert.LessOrEqual(t, len(Decode("%25%32%35%33%43 &amp;lt; \\u0026 cGF5bG9hZA==")), MAX_DECODED_VARIANTS)
+	})
+}
+
+func TestNormalizePath(t *testing.T) {
+	assert.Equal(t, "/", NormalizePath(""))
+	assert.Equal(t, "/a/b", NormalizePath("/a/./b"))
+	assert.Equal(t, "/etc/passwd", NormalizePath("/static/../../etc/passwd"))
+	assert.Equal(t, "/etc/passwd", NormalizePath("/static/%2e%2e/%2e%2e/etc/passwd"))
+	assert.Equal(t, "/etc/passwd", NormalizePath("/static/%252e%252e/etc/passwd"))
+	assert.Equal(t, "/windows/win.ini", NormalizePath("/static\\..\\windows\\win.ini"))
+	assert.Equal(t, "/a/b/", NormalizePath("//a//b/"))
+	assert.Equal(t, "/Admin", NormalizePath("/Admin%00"))
+}
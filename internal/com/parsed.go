@@ -0,0 +1,145 @@
+package com
+
+import (
+	"mime"
+	"net/textproto"
+	"net/url"
+	"strings"
+)
+
+// where a field comes from
+const (
+	SOURCE_PATH   = "path"
+	SOURCE_QUERY  = "query"
+	SOURCE_FORM   = "form"
+	SOURCE_COOKIE = "cookie"
+	SOURCE_HEADER = "header"
+	SOURCE_JSON   = "json"
+	SOURCE_XML    = "xml"
+	// the name of an uploaded file
+	SOURCE_FILE = "file"
+)
+
+/*
+Field is a user controlled value of a request
+*/
+type Field struct {
+	Source string
+	Name   string
+	// the value as received (once the transport encoding is removed)
+	Value string
+	// the normalized value followed by its normalized decoded variants
+	Decoded []string
+}
+
+/*
+ParsedRequest is the normalized view of a request, shared by the plugins
+*/
+type ParsedRequest struct {
+	Method string
+	// decoded, with the dot-segments resolved (the case is kept)
+	Path string
+	// the media type of the body (lowercase, without the parameters)
+	ContentType string
+	Fields      []Field
+	Files       []File
+	// why the body could not be (fully) parsed (nil if it was)
+	BodyError error
+}
+
+/*
+Parse builds (once) the normalized view of a request.
+The engine parses the requests before calling the plugins
+*/
+func (t *TaxsiCom) Parse() *ParsedRequest {
+	t.parseOnce.Do(func() {
+		t.parsed = parse(t)
+	})
+	return t.parsed
+}
+
+func newField(source string, name string, value string) Field {
+	return Field{
+		Source:  source,
+		Name:    name,
+		Value:   value,
+		Decoded: Decode(value),
+	}
+}
+
+func parse(t *TaxsiCom) *ParsedRequest {
+	p := &ParsedRequest{
+		Method: strings.ToUpper(t.Method),
+		Path:   "/",
+		Fields: []Field{},
+		Files:  []File{},
+	}
+
+	if t.Url != nil {
+		rawPath := t.Url.EscapedPath()
+		p.Path = NormalizePath(rawPath)
+		p.Fields = append(p.Fields, Field{
+			Source:  SOURCE_PATH,
+			Value:   t.Url.Path,
+			Decoded: []string{Normalize(p.Path)},
+		})
+
+		// the values that can be parsed are kept even on error
+		query, _ := url.ParseQuery(t.Url.RawQuery)
+		p.Fields = appendValues(p.Fields, SOURCE_QUERY, query)
+	}
+
+	for name, values := range t.Headers {
+		name = textproto.CanonicalMIMEHeaderKey(name)
+		if name == "Cookie" {
+			continue
+		}
+		for _, v := range values {
+			p.Fields = append(p.Fields, newField(SOURCE_HEADER, name, v))
+		}
+	}
+	for _, header := range t.GetHeader("Cookie") {
+		for _, pair := range strings.Split(header, ";") {
+			name, value, found := strings.Cut(pair, "=")
+			if !found {
+				continue
+			}
+			if unescaped, err := url.QueryUnescape(strings.TrimSpace(value)); err == nil {
+				value = unescaped
+			}
+			p.Fields = append(p.Fields, newField(SOURCE_COOKIE, strings.TrimSpace(name), value))
+		}
+	}
+
+	params := map[string]string{}
+	if ct := t.GetHeader("Content-Type"); len(ct) > 0 {
+		p.ContentType, params, _ = mime.ParseMediaType(ct[0])
+	}
+	p.BodyError = parseBody(p, t.Body, params)
+	return p
+}
+
+func appendValues(fields []Field, source string, values url.Values) []Field {
+	for name, vs := range values {
+		for _, v := range vs {
+			fields = append(fields, newField(source, name, v))
+		}
+	}
+	return fields
+}
+
+/*
+FieldsFrom returns the fields of some sources
+*/
+func (p *ParsedRequest) FieldsFrom(sources ...string) []Field {
+	fields := []Field{}
+	for _, f := range p.Fields {
+		for _, s := range sources {
+			if f.Source == s {
+				fields = append(fields, f)
+				break
+			}
+		}
+	}
+	return fields
+}
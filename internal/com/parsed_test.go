@@ -0,0 +1,80 @@
+package com
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	request := func(rawurl string, headers map[string][]string, body string) *TaxsiCom {
+		u, _ := url.Parse(rawurl)
+		return &TaxsiCom{
+			Method:  "post",
+			Url:     u,
+			Headers: headers,
+			Body:    []byte(body),
+		}
+	}
+
+	t.Run("happy path: query, form, cookies and headers", func(t *testing.T) {
+		payload := request("http://www.example.com/a/../Search?q=%253Cb%253E", map[string][]string{
+			"content-type": {"application/x-www-form-urlencoded; charset=utf-8"},
+			"cookie":       {"session=abc; lang=%65n"},
+			"user-agent":   {"Mozilla/5.0"},
+		}, "user=admin&comment=%26lt%3Bb%26gt%3B")
+		p := payload.Parse()
+
+		assert.Equal(t, "POST", p.Method)
+		assert.Equal(t, "/Search", p.Path)
+		assert.Equal(t, "application/x-www-form-urlencoded", p.ContentType)
+
+		paths := p.FieldsFrom(SOURCE_PATH)
+		assert.Equal(t, 1, len(paths))
+		assert.Equal(t, []string{"/search"}, paths[0].Decoded)
+
+		query := p.FieldsFrom(SOURCE_QUERY)
+		assert.Equal(t, 1, len(query))
+		assert.Equal(t, "q", query[0].Name)
+		assert.Equal(t, "%3Cb%3E", query[0].Value)
+		assert.Equal(t, []string{"%3cb%3e", "<b>"}, query[0].Decoded)
+
+		form := map[string][]string{}
+		for _, f := range p.FieldsFrom(SOURCE_FORM) {
+			form[f.Name] = f.Decoded
+		}
+		assert.Equal(t, []string{"admin"}, form["user"])
+		assert.Equal(t, []string{"&lt;b&gt;", "<b>"}, form["comment"])
+
+		cookies := map[string]string{}
+		for _, f := range p.FieldsFrom(SOURCE_COOKIE) {
+			cookies[f.Name] = f.Value
+		}
+		assert.Equal(t, map[string]string{"session": "abc", "lang": "en"}, cookies)
+
+		headers := map[string]string{}
+		for _, f := range p.FieldsFrom(SOURCE_HEADER) {
+			headers[f.Name] = f.Value
+		}
+		assert.Equal(t, "Mozilla/5.0", headers["User-Agent"])
+		_, ok := headers["Cookie"]
+		assert.False(t, ok)
+	})
+
+	t.Run("happy path: the view is built once", func(t *testing.T) {
+		payload := request("http://www.example.com/?a=1", map[string][]string{}, "")
+		assert.Same(t, payload.Parse(), payload.Parse())
+	})
+
+	t.Run("not happy path: malformed query and no url", func(t *testing.T) {
+		p := request("http://www.example.com/?a=%zz&b=2", map[string][]string{}, "").Parse()
+		query := p.FieldsFrom(SOURCE_QUERY)
+		assert.Equal(t, 1, len(query))
+		assert.Equal(t, "b", query[0].Name)
+
+		p = (&TaxsiCom{Method: "GET"}).Parse()
+		assert.Equal(t, "/", p.Path)
+		assert.Equal(t, 0, len(p.Fields))
+	})
+}
@@ -7,14 +7,148 @@ import (
 	"io"
 	"net/http"
 	"net/url"
+	"strings"
+	"sync"
 )
 
 type TaxsiCom struct {
-	Headers    map[string][]string
-	Body       []byte
-	RemoteAddr string
-	Method     string
-	Url        *url.URL
+	Headers map[string][]string
+	// HeaderOrder is the order of (first) appearance of the headers
+	// (empty if unknown, for example when built from a http.Request)
+	HeaderOrder []string
+	Body        []byte
+	RemoteAddr  string
+	Method      string
+	Url         *url.URL
+	// Variables set by the plugins during the scan (exposed
+	// in the output format as {{.Variables.<name>}})
+	Variables map[string]string
+	// the claims of a valid bearer token (set by the jwt plugin,
+	// for the next plugins)
+	Claims map[string]string
+
+	// the normalized view, built once by Parse()
+	parseOnce sync.Once
+	parsed    *ParsedRequest
+
+	// guards the variables and the claims, that can be set
+	// by concurrent plugins (see Fork)
+	variablesMu sync.Mutex
+	// the claims were set (on a fork)
+	claimsSet bool
+}
+
+/*
+SetVariable exposes a plugin result to the output
+*/
+func (t *TaxsiCom) SetVariable(key string, value string) {
+	t.variablesMu.Lock()
+	defer t.variablesMu.Unlock()
+	if t.Variables == nil {
+		t.Variables = make(map[string]string)
+	}
+	t.Variables[key] = value
+}
+
+/*
+SetClaims sets the claims of a valid bearer token
+*/
+func (t *TaxsiCom) SetClaims(claims map[string]string) {
+	t.variablesMu.Lock()
+	defer t.variablesMu.Unlock()
+	t.Claims = claims
+	t.claimsSet = true
+}
+
+/*
+Fork returns a copy of the request for a plugin scan. The request
+itself (headers, body...) is shared, and must not be modified, but
+the variables and the claims set on the fork are only visible once
+merged back (see Merge): a plugin that is given up (i.e. it timed out)
+can't change the request anymore
+*/
+func (t *TaxsiCom) Fork() *TaxsiCom {
+	parsed := t.Parse()
+	t.variablesMu.Lock()
+	claims := t.Claims
+	t.variablesMu.Unlock()
+
+	fork := &TaxsiCom{
+		Headers:     t.Headers,
+		HeaderOrder: t.HeaderOrder,
+		Body:        t.Body,
+		RemoteAddr:  t.RemoteAddr,
+		Method:      t.Method,
+		Url:         t.Url,
+		Claims:      claims,
+		parsed:      parsed,
+	}
+	fork.parseOnce.Do(func() {})
+	return fork
+}
+
+/*
+Merge applies the variables and the claims set on a fork
+*/
+func (t *TaxsiCom) Merge(fork *TaxsiCom) {
+	variables := fork.CopyVariables()
+	fork.variablesMu.Lock()
+	claims, claimsSet := fork.Claims, fork.claimsSet
+	fork.variablesMu.Unlock()
+
+	t.variablesMu.Lock()
+	defer t.variablesMu.Unlock()
+	if t.Variables == nil {
+		t.Variables = make(map[string]string)
+	}
+	for k, v := range variables {
+		t.Variables[k] = v
+	}
+	if claimsSet {
+		t.Claims = claims
+		t.claimsSet = true
+	}
+}
+
+/*
+CopyVariables returns a snapshot of the variables (for the output)
+*/
+func (t *TaxsiCom) CopyVariables() map[string]string {
+	t.variablesMu.Lock()
+	defer t.variablesMu.Unlock()
+	variables := make(map[string]string, len(t.Variables))
+	for k, v := range t.Variables {
+		variables[k] = v
+	}
+	return variables
+}
+
+/*
+GetHeader returns the values of a header (the header names
+are not always canonicalized by the connectors)
+*/
+func (t *TaxsiCom) GetHeader(name string) []string {
+	if v, ok := t.Headers[name]; ok {
+		return v
+	}
+	for k, v := range t.Headers {
+		if strings.EqualFold(k, name) {
+			return v
+		}
+	}
+	return nil
+}
+
+/*
+GetCookie returns the value of a request cookie
+*/
+func (t *TaxsiCom) GetCookie(name string) (string, bool) {
+	req := http.Request{Header: http.Header{"Cookie": t.GetHeader("Cookie")}}
+	c, err := req.Cookie(name)
+	if err != nil {
+		return "", false
+	}
+	return c.Value, true
 }
 
 func NewTaxsiCom(req *http.Request) (*TaxsiCom, error) {
@@ -48,8 +182,22 @@ func NewTaxsiCom(req *http.Request) (*TaxsiCom, error) {
 func (t *TaxsiCom) Marshall(w io.Writer) error {
 	e := NewEncoder(w)
 	e.AddString(PROTO_METHOD, t.Method)
-	for k, values := range t.Headers {
-		for _, v := range values {
+	// keep the header order when we know it
+	keys := []string{}
+	known := make(map[string]bool)
+	for _, k := range t.HeaderOrder {
+		if _, ok := t.Headers[k]; ok && !known[k] {
+			keys = append(keys, k)
+			known[k] = true
+		}
+	}
+	for k := range t.Headers {
+		if !known[k] {
+			keys = append(keys, k)
+		}
+	}
+	for _, k := range keys {
+		for _, v := range t.Headers[k] {
 			e.AddString(PROTO_HEADER_KEY, k)
 			e.AddString(PROTO_HEADER_VALUE, v)
 		}
@@ -102,6 +250,7 @@ func Unmarshall(r *bufio.Reader) (*TaxsiCom, error) {
 			}
 			if t.Headers[key] == nil {
 				t.Headers[key] = []string{string(v.Payload)}
+				t.HeaderOrder = append(t.HeaderOrder, key)
 			} else {
 				t.Headers[key] = append(t.Headers[key], string(v.Payload))
 			}
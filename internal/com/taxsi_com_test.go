@@ -4,6 +4,7 @@ import (
 	"bufio"
 	"bytes"
 	"net/http"
+	"net/url"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -36,4 +37,67 @@ func TestCom(t *testing.T) {
 		assert.Equal(t, "application/json", d.Headers["Content-Type"][0])
 		assert.Equal(t, `{"key": "value"}`, string(d.Body))
 	})
+
+	t.Run("header order is kept", func(t *testing.T) {
+		u, err := url.Parse("http://example.com/")
+		assert.Nil(t, err)
+		e := TaxsiCom{
+			Method: "GET",
+			Url:    u,
+			Headers: map[string][]string{
+				"User-Agent":      {"Mozilla/5.0"},
+				"Accept":          {"*/*"},
+				"Accept-Encoding": {"gzip"},
+				"Host":            {"example.com"},
+			},
+			HeaderOrder: []string{"Host", "User-Agent", "Accept", "Accept-Encoding"},
+		}
+
+		var buf bytes.Buffer
+		err = e.Marshall(&buf)
+		assert.Nil(t, err)
+
+		d, err := Unmarshall(bufio.NewReader(&buf))
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"Host", "User-Agent", "Accept", "Accept-Encoding"}, d.HeaderOrder)
+	})
+
+	t.Run("headers and cookies", func(t *testing.T) {
+		e := TaxsiCom{
+			Headers: map[string][]string{
+				"user-agent": {"Mozilla/5.0"},
+				"Cookie":     {"a=1; taxsi_clearance=xyz"},
+			},
+		}
+		assert.Equal(t, []string{"Mozilla/5.0"}, e.GetHeader("User-Agent"))
+		assert.Nil(t, e.GetHeader("Accept"))
+
+		v, ok := e.GetCookie("taxsi_clearance")
+		assert.True(t, ok)
+		assert.Equal(t, "xyz", v)
+		_, ok = e.GetCookie("b")
+		assert.False(t, ok)
+	})
+	t.Run("fork and merge", func(t *testing.T) {
+		req, err := http.NewRequest("GET", "http://www.example.com/?a=1", nil)
+		assert.Nil(t, err)
+		payload, err := NewTaxsiCom(req)
+		assert.Nil(t, err)
+		payload.SetVariable("before", "1")
+
+		fork := payload.Fork()
+		assert.Equal(t, payload.Parse(), fork.Parse())
+		fork.SetVariable("after", "2")
+		fork.SetClaims(map[string]string{"sub": "alice"})
+		assert.Equal(t, map[string]string{"before": "1"}, payload.CopyVariables())
+		assert.Nil(t, payload.Claims)
+
+		payload.Merge(fork)
+		assert.Equal(t, map[string]string{"before": "1", "after": "2"}, payload.CopyVariables())
+		assert.Equal(t, "alice", payload.Claims["sub"])
+
+		// a fork that doesn't set the claims keeps them
+		payload.Merge(payload.Fork())
+		assert.Equal(t, "alice", payload.Claims["sub"])
+	})
 }
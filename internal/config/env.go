@@ -57,7 +57,9 @@ var Config = struct {
 	  - {{.UrlPath}}
 	  - {{.Method}}
 	  - {{.Remoteaddr}}
-	  - {{.Scanresult}} (blocked, dryrun, pass)
+	  - {{.Scanresult}} (blocked, challenged, dryrun, pass)
+	  - {{.Reason}} (the plugin name, allowlist, denylist, banned, malformed_body, score, bypass)
+	  - {{.Variables.<name>}} (set by the plugins, for example {{.Variables.botclass}})
 	*/
 	WafOutputFormat string `env:"TAXSI2_WAF_OUTPUT_FORMAT" envDefault:"{{.Remoteaddr}} {{.Method}} {{.UrlHostname}}:{{.UrlPath}} {{.Scanresult}}"`
 
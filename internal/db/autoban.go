@@ -0,0 +1,69 @@
+package db
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// creator of the bans added automatically (see the engine autoban)
+const AUTOBAN_CREATOR = "autoban"
+
+/*
+AutobanCounter is the number of blocked requests of an IP during
+a (fixed) window, summed over all the nodes
+*/
+type AutobanCounter struct {
+	Ip          string    `gorm:"uniqueIndex:idx_autoban_ip_window;size:64"`
+	WindowStart time.Time `gorm:"uniqueIndex:idx_autoban_ip_window;index"`
+	Hits        int
+}
+
+/*
+AddAutobanHits adds the local (per node) hits of the window to the shared
+counters, and returns the total hits (of all the nodes) for these IPs
+*/
+func (ds *DbServiceImpl) AddAutobanHits(windowStart time.Time, hits map[string]int) (map[string]int, error) {
+	totals := make(map[string]int)
+	if len(hits) == 0 {
+		return totals, nil
+	}
+
+	err := ds.db.Transaction(func(tx *gorm.DB) error {
+		ips := []string{}
+		for ip, n := range hits {
+			counter := AutobanCounter{
+				Ip:          ip,
+				WindowStart: windowStart,
+				Hits:        n,
+			}
+			err := tx.Clauses(clause.OnConflict{
+				Columns:   []clause.Column{{Name: "ip"}, {Name: "window_start"}},
+				DoUpdates: clause.Assignments(map[string]interface{}{"hits": gorm.Expr("autoban_counters.hits + ?", n)}),
+			}).Create(&counter).Error
+			if err != nil {
+				return err
+			}
+			ips = append(ips, ip)
+		}
+
+		var counters []AutobanCounter
+		err := tx.Where("ip IN ? AND window_start = ?", ips, windowStart).Find(&counters).Error
+		if err != nil {
+			return err
+		}
+		for _, c := range counters {
+			totals[c.Ip] = c.Hits
+		}
+		return nil
+	})
+	return totals, err
+}
+
+/*
+PurgeAutobanCounters removes the counters of the windows started before a date
+*/
+func (ds *DbServiceImpl) PurgeAutobanCounters(before time.Time) error {
+	return ds.db.Where("window_start < ?", before).Delete(&AutobanCounter{}).Error
+}
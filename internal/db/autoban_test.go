@@ -0,0 +1,48 @@
+package db
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAutobanCounters(t *testing.T) {
+	t.Run("happy path: hits are summed over the nodes", func(t *testing.T) {
+		tmpFile, err := os.CreateTemp("", "taxsi.temp*")
+		assert.Nil(t, err)
+		defer os.Remove(tmpFile.Name())
+
+		dbs, err := NewDbService("sqlite3", tmpFile.Name(), 1, 1*time.Second)
+		assert.Nil(t, err)
+		assert.NotNil(t, dbs)
+
+		window := time.Now().Truncate(time.Minute)
+
+		totals, err := dbs.AddAutobanHits(window, map[string]int{})
+		assert.Nil(t, err)
+		assert.Equal(t, 0, len(totals))
+
+		// node 1
+		totals, err = dbs.AddAutobanHits(window, map[string]int{"1.2.3.4": 2, "5.6.7.8": 1})
+		assert.Nil(t, err)
+		assert.Equal(t, map[string]int{"1.2.3.4": 2, "5.6.7.8": 1}, totals)
+
+		// node 2
+		totals, err = dbs.AddAutobanHits(window, map[string]int{"1.2.3.4": 3})
+		assert.Nil(t, err)
+		assert.Equal(t, map[string]int{"1.2.3.4": 5}, totals)
+
+		// next window
+		totals, err = dbs.AddAutobanHits(window.Add(time.Minute), map[string]int{"1.2.3.4": 1})
+		assert.Nil(t, err)
+		assert.Equal(t, map[string]int{"1.2.3.4": 1}, totals)
+
+		err = dbs.PurgeAutobanCounters(window.Add(time.Minute))
+		assert.Nil(t, err)
+		var count int64
+		dbs.(*DbServiceImpl).db.Model(&AutobanCounter{}).Count(&count)
+		assert.Equal(t, int64(1), count)
+	})
+}
@@ -0,0 +1,103 @@
+package db
+
+import (
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+/*
+Ban is a time-boxed ban of an IP/CIDR.
+Expired bans are purged (soft deleted) by PurgeExpiredBans(), which
+notifies all the nodes through the changelog.
+*/
+type Ban struct {
+	gorm.Model
+	Cidr      string
+	Reason    string
+	Creator   string
+	ExpiresAt time.Time `gorm:"index"`
+}
+
+/*
+AddBan bans a CIDR until expiresAt
+*/
+func (ds *DbServiceImpl) AddBan(cidr string, reason string, creator string, expiresAt time.Time) (*Ban, error) {
+	ban := Ban{
+		Cidr:      cidr,
+		Reason:    reason,
+		Creator:   creator,
+		ExpiresAt: expiresAt,
+	}
+	if err := ds.db.Create(&ban).Error; err != nil {
+		return nil, err
+	}
+	return &ban, ds.NotifyChange(CHANGELOG_TABLE_BAN, fmt.Sprintf("%d", ban.ID))
+}
+
+/*
+AddBanIfNotBanned bans a CIDR until expiresAt, unless there is already
+an active ban for this CIDR (i.e. another node banned it first).
+Returns the active ban, and whether it was added
+*/
+func (ds *DbServiceImpl) AddBanIfNotBanned(cidr string, reason string, creator string, expiresAt time.Time) (*Ban, bool, error) {
+	var ban *Ban
+	added := false
+	err := ds.withTransaction(func(tx *DbServiceImpl) error {
+		var existing Ban
+		err := tx.db.Where("cidr = ? AND expires_at > ?", cidr, time.Now()).Order("expires_at desc").First(&existing).Error
+		if err == nil {
+			ban = &existing
+			return nil
+		}
+		if err != gorm.ErrRecordNotFound {
+			return err
+		}
+		ban, err = tx.AddBan(cidr, reason, creator, expiresAt)
+		added = err == nil
+		return err
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	return ban, added, nil
+}
+
+/*
+GetActiveBans returns the bans not yet expired (or removed)
+*/
+func (ds *DbServiceImpl) GetActiveBans() ([]Ban, error) {
+	var bans []Ban
+	err := ds.db.Where("expires_at > ?", time.Now()).Order("id asc").Find(&bans).Error
+	return bans, err
+}
+
+/*
+RemoveBan lifts a ban before its expiration.
+Returns gorm.ErrRecordNotFound if there is no such (active) ban
+*/
+func (ds *DbServiceImpl) RemoveBan(id uint) error {
+	res := ds.db.Delete(&Ban{}, id)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return ds.NotifyChange(CHANGELOG_TABLE_BAN, fmt.Sprintf("%d", id))
+}
+
+/*
+PurgeExpiredBans removes the expired bans, and notifies the nodes if any
+*/
+func (ds *DbServiceImpl) PurgeExpiredBans() error {
+	res := ds.db.Where("expires_at <= ?", time.Now()).Delete(&Ban{})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return nil
+	}
+	return ds.NotifyChange(CHANGELOG_TABLE_BAN, "")
+}
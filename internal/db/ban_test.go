@@ -0,0 +1,122 @@
+package db
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestBan(t *testing.T) {
+	t.Run("happy path: add, list and remove bans", func(t *testing.T) {
+		tmpFile, err := os.CreateTemp("", "taxsi.temp*")
+		assert.Nil(t, err)
+		defer os.Remove(tmpFile.Name())
+
+		dbs, err := NewDbService("sqlite3", tmpFile.Name(), 1, 1*time.Second)
+		assert.Nil(t, err)
+		assert.NotNil(t, dbs)
+
+		bans, err := dbs.GetActiveBans()
+		assert.Nil(t, err)
+		assert.Equal(t, 0, len(bans))
+
+		ban, err := dbs.AddBan("1.2.3.4/32", "scanner", "alice", time.Now().Add(2*time.Hour))
+		assert.Nil(t, err)
+		assert.NotEqual(t, uint(0), ban.ID)
+		_, err = dbs.AddBan("5.6.0.0/16", "ddos", "bob", time.Now().Add(2*time.Hour))
+		assert.Nil(t, err)
+
+		bans, err = dbs.GetActiveBans()
+		assert.Nil(t, err)
+		assert.Equal(t, 2, len(bans))
+		assert.Equal(t, "1.2.3.4/32", bans[0].Cidr)
+		assert.Equal(t, "scanner", bans[0].Reason)
+		assert.Equal(t, "alice", bans[0].Creator)
+
+		err = dbs.RemoveBan(ban.ID)
+		assert.Nil(t, err)
+		err = dbs.RemoveBan(ban.ID)
+		assert.Equal(t, gorm.ErrRecordNotFound, err)
+
+		bans, err = dbs.GetActiveBans()
+		assert.Nil(t, err)
+		assert.Equal(t, 1, len(bans))
+		assert.Equal(t, "5.6.0.0/16", bans[0].Cidr)
+
+		// add + add + remove
+		var count int64
+		dbs.(*DbServiceImpl).db.Model(&ChangeLog{}).Where("`table` = ?", CHANGELOG_TABLE_BAN).Count(&count)
+		assert.Equal(t, int64(3), count)
+	})
+
+	t.Run("happy path: purge the expired bans", func(t *testing.T) {
+		tmpFile, err := os.CreateTemp("", "taxsi.temp*")
+		assert.Nil(t, err)
+		defer os.Remove(tmpFile.Name())
+
+		dbs, err := NewDbService("sqlite3", tmpFile.Name(), 1, 1*time.Second)
+		assert.Nil(t, err)
+		assert.NotNil(t, dbs)
+
+		_, err = dbs.AddBan("1.2.3.4/32", "scanner", "alice", time.Now().Add(-1*time.Second))
+		assert.Nil(t, err)
+		_, err = dbs.AddBan("5.6.0.0/16", "ddos", "bob", time.Now().Add(2*time.Hour))
+		assert.Nil(t, err)
+
+		// already expired
+		bans, err := dbs.GetActiveBans()
+		assert.Nil(t, err)
+		assert.Equal(t, 1, len(bans))
+
+		err = dbs.PurgeExpiredBans()
+		assert.Nil(t, err)
+		var count int64
+		dbs.(*DbServiceImpl).db.Model(&ChangeLog{}).Count(&count)
+		assert.Equal(t, int64(3), count)
+
+		// nothing more to purge, no changelog
+		err = dbs.PurgeExpiredBans()
+		assert.Nil(t, err)
+		dbs.(*DbServiceImpl).db.Model(&ChangeLog{}).Count(&count)
+		assert.Equal(t, int64(3), count)
+
+		var all int64
+		dbs.(*DbServiceImpl).db.Unscoped().Model(&Ban{}).Count(&all)
+		assert.Equal(t, int64(2), all)
+	})
+	t.Run("happy path: a CIDR is banned only once", func(t *testing.T) {
+		tmpFile, err := os.CreateTemp("", "taxsi.temp*")
+		assert.Nil(t, err)
+		defer os.Remove(tmpFile.Name())
+
+		dbs, err := NewDbService("sqlite3", tmpFile.Name(), 1, 1*time.Second)
+		assert.Nil(t, err)
+		assert.NotNil(t, dbs)
+
+		// an expired ban doesn't count
+		_, err = dbs.AddBan("1.2.3.4/32", "scanner", "alice", time.Now().Add(-1*time.Second))
+		assert.Nil(t, err)
+
+		ban, added, err := dbs.AddBanIfNotBanned("1.2.3.4/32", "autoban", "node1", time.Now().Add(2*time.Hour))
+		assert.Nil(t, err)
+		assert.True(t, added)
+		assert.Equal(t, "node1", ban.Creator)
+
+		again, added, err := dbs.AddBanIfNotBanned("1.2.3.4/32", "autoban", "node2", time.Now().Add(3*time.Hour))
+		assert.Nil(t, err)
+		assert.False(t, added)
+		assert.Equal(t, ban.ID, again.ID)
+
+		bans, err := dbs.GetActiveBans()
+		assert.Nil(t, err)
+		assert.Equal(t, 1, len(bans))
+
+		// add + add
+		var count int64
+		dbs.(*DbServiceImpl).db.Model(&ChangeLog{}).Where("`table` = ?", CHANGELOG_TABLE_BAN).Count(&count)
+		assert.Equal(t, int64(2), count)
+	})
+}
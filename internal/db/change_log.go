@@ -5,8 +5,28 @@ import "gorm.io/gorm"
 const (
 	CHANGELOG_TABLE_CONFIG = iota
 	CHANGELOG_TABLE_GEOIP
+	CHANGELOG_TABLE_BAN
+	CHANGELOG_TABLE_RATELIMIT
+	CHANGELOG_TABLE_UPLOAD
+	CHANGELOG_TABLE_OPENAPI
+	CHANGELOG_TABLE_JWT
+	CHANGELOG_TABLE_RULE
+	CHANGELOG_TABLE_MODSEC
 )
 
+// all the tables (to be notified of any change)
+var CHANGELOG_TABLES = []int{
+	CHANGELOG_TABLE_CONFIG,
+	CHANGELOG_TABLE_GEOIP,
+	CHANGELOG_TABLE_BAN,
+	CHANGELOG_TABLE_RATELIMIT,
+	CHANGELOG_TABLE_UPLOAD,
+	CHANGELOG_TABLE_OPENAPI,
+	CHANGELOG_TABLE_JWT,
+	CHANGELOG_TABLE_RULE,
+	CHANGELOG_TABLE_MODSEC,
+}
+
 type ChangeLog struct {
 	gorm.Model
 	Table int
@@ -0,0 +1,142 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// the history value of the secrets
+const CONFIG_REDACTED = "<redacted>"
+
+/*
+SECRET_CONFIG_KEYS are the config keys whose values are secrets (like
+the keys of the challenge cookies): their values are not recorded in
+the history (so they can't be rolled back), nor exported in the policy
+*/
+var SECRET_CONFIG_KEYS = map[string]bool{
+	"challenge_keys": true,
+}
+
+/*
+ConfigHistory is the audit trail of the GlobalConfig table:
+every mutation is recorded with its author, the old and the new value
+*/
+type ConfigHistory struct {
+	gorm.Model
+	Key      string `gorm:"index"`
+	OldValue string
+	NewValue string
+	Existed  bool // false if the key was created by this change
+	Removed  bool // true if the key was removed by this change
+	Author   string
+	Comment  string
+}
+
+func (ds *DbServiceImpl) addConfigHistory(entry ConfigHistory) error {
+	if SECRET_CONFIG_KEYS[entry.Key] {
+		if entry.OldValue != "" {
+			entry.OldValue = CONFIG_REDACTED
+		}
+		if entry.NewValue != "" {
+			entry.NewValue = CONFIG_REDACTED
+		}
+	}
+	return ds.db.Create(&entry).Error
+}
+
+/*
+GetConfigHistory returns the config changes (oldest first)
+- of a specific key (or all keys if key is empty)
+- made after since (or all changes if since is zero)
+*/
+func (ds *DbServiceImpl) GetConfigHistory(key string, since time.Time) ([]ConfigHistory, error) {
+	var history []ConfigHistory
+
+	query := ds.db.Order("id asc")
+	if key != "" {
+		query = query.Where(&ConfigHistory{Key: key})
+	}
+	if !since.IsZero() {
+		query = query.Where("created_at > ?", since)
+	}
+
+	err := query.Find(&history).Error
+	if err != nil {
+		return nil, err
+	}
+	return history, nil
+}
+
+/*
+RollbackConfig sets back a key (or the whole config if key is empty)
+to the value it had at a given time (the secrets can't be rolled back,
+they are left unchanged by a whole config rollback).
+Each rollback is itself recorded in the history, and is propagated
+to the other nodes via the changelog
+*/
+func (ds *DbServiceImpl) RollbackConfig(key string, at time.Time, author string, comment string) error {
+	if comment == "" {
+		comment = fmt.Sprintf("rollback to %s", at.Format(time.RFC3339))
+	}
+
+	// (a whole config rollback is applied entirely, or not at all)
+	return ds.withTransaction(func(tx *DbServiceImpl) error {
+		keys := []string{key}
+		if key == "" {
+			keys = []string{}
+			err := tx.db.Model(&ConfigHistory{}).Where("created_at > ?", at).Distinct().Pluck("key", &keys).Error
+			if err != nil {
+				return err
+			}
+		} else if SECRET_CONFIG_KEYS[key] {
+			return fmt.Errorf("%s is a secret, its values are not in the history", key)
+		}
+
+		for _, k := range keys {
+			if SECRET_CONFIG_KEYS[k] {
+				continue
+			}
+			if err := tx.rollbackConfigKey(k, at, author, comment); err != nil {
+				return fmt.Errorf("not able to rollback config %s: %v", k, err)
+			}
+		}
+		return nil
+	})
+}
+
+func (ds *DbServiceImpl) rollbackConfigKey(key string, at time.Time, author string, comment string) error {
+	// the first change after 'at' tells us what the value was at 'at'
+	var first ConfigHistory
+	err := ds.db.Where(&ConfigHistory{Key: key}).Where("created_at > ?", at).Order("id asc").First(&first).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		// nothing changed since
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	current, err := ds.GetConfigValueForKey(key)
+	exists := true
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		exists = false
+	} else if err != nil {
+		return err
+	}
+
+	// the key didn't exist at that time
+	if !first.Existed {
+		if !exists {
+			return nil
+		}
+		return ds.DeleteConfigKeyWithAuthor(key, author, comment)
+	}
+
+	if exists && current == first.OldValue {
+		return nil
+	}
+	return ds.SetConfigValueForKeyWithAuthor(key, first.OldValue, author, comment)
+}
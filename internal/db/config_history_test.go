@@ -0,0 +1,216 @@
+package db
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestConfigHistory(t *testing.T) {
+	t.Run("happy path: read empty history", func(t *testing.T) {
+		tmpFile, err := os.CreateTemp("", "taxsi.temp*")
+		assert.Nil(t, err)
+		defer os.Remove(tmpFile.Name())
+
+		dbs, err := NewDbService("sqlite3", tmpFile.Name(), 1, 1*time.Second)
+		assert.Nil(t, err)
+		assert.NotNil(t, dbs)
+
+		history, err := dbs.GetConfigHistory("", time.Time{})
+		assert.Nil(t, err)
+		assert.Equal(t, 0, len(history))
+	})
+
+	t.Run("happy path: config changes are recorded", func(t *testing.T) {
+		tmpFile, err := os.CreateTemp("", "taxsi.temp*")
+		assert.Nil(t, err)
+		defer os.Remove(tmpFile.Name())
+
+		dbs, err := NewDbService("sqlite3", tmpFile.Name(), 1, 1*time.Second)
+		assert.Nil(t, err)
+		assert.NotNil(t, dbs)
+
+		err = dbs.SetConfigValueForKey("foo", "bar")
+		assert.Nil(t, err)
+		err = dbs.SetConfigValueForKeyWithAuthor("foo", "bar2", "alice", "testing")
+		assert.Nil(t, err)
+		err = dbs.SetConfigValueForKeyWithAuthor("foo2", "bar3", "bob", "")
+		assert.Nil(t, err)
+
+		history, err := dbs.GetConfigHistory("foo", time.Time{})
+		assert.Nil(t, err)
+		assert.Equal(t, 2, len(history))
+
+		assert.Equal(t, "", history[0].OldValue)
+		assert.Equal(t, "bar", history[0].NewValue)
+		assert.False(t, history[0].Existed)
+		assert.Equal(t, CONFIG_DEFAULT_AUTHOR, history[0].Author)
+
+		assert.Equal(t, "bar", history[1].OldValue)
+		assert.Equal(t, "bar2", history[1].NewValue)
+		assert.True(t, history[1].Existed)
+		assert.Equal(t, "alice", history[1].Author)
+		assert.Equal(t, "testing", history[1].Comment)
+
+		history, err = dbs.GetConfigHistory("", time.Time{})
+		assert.Nil(t, err)
+		assert.Equal(t, 3, len(history))
+	})
+
+	t.Run("happy path: rollback a key", func(t *testing.T) {
+		tmpFile, err := os.CreateTemp("", "taxsi.temp*")
+		assert.Nil(t, err)
+		defer os.Remove(tmpFile.Name())
+
+		dbs, err := NewDbService("sqlite3", tmpFile.Name(), 1, 1*time.Second)
+		assert.Nil(t, err)
+		assert.NotNil(t, dbs)
+
+		err = dbs.SetConfigValueForKey("mode", "enabled")
+		assert.Nil(t, err)
+		time.Sleep(10 * time.Millisecond)
+		checkpoint := time.Now()
+		time.Sleep(10 * time.Millisecond)
+
+		err = dbs.SetConfigValueForKey("mode", "dryrun")
+		assert.Nil(t, err)
+		err = dbs.SetConfigValueForKey("mode", "disabled")
+		assert.Nil(t, err)
+
+		err = dbs.RollbackConfig("mode", checkpoint, "alice", "")
+		assert.Nil(t, err)
+
+		value, err := dbs.GetConfigValueForKey("mode")
+		assert.Nil(t, err)
+		assert.Equal(t, "enabled", value)
+
+		// the rollback is itself in the history
+		history, err := dbs.GetConfigHistory("mode", checkpoint)
+		assert.Nil(t, err)
+		assert.Equal(t, 3, len(history))
+		assert.Equal(t, "alice", history[2].Author)
+		assert.Equal(t, "enabled", history[2].NewValue)
+	})
+
+	t.Run("happy path: rollback the whole config", func(t *testing.T) {
+		tmpFile, err := os.CreateTemp("", "taxsi.temp*")
+		assert.Nil(t, err)
+		defer os.Remove(tmpFile.Name())
+
+		dbs, err := NewDbService("sqlite3", tmpFile.Name(), 1, 1*time.Second)
+		assert.Nil(t, err)
+		assert.NotNil(t, dbs)
+
+		err = dbs.SetConfigValueForKey("mode", "enabled")
+		assert.Nil(t, err)
+		time.Sleep(10 * time.Millisecond)
+		checkpoint := time.Now()
+		time.Sleep(10 * time.Millisecond)
+
+		err = dbs.SetConfigValueForKey("mode", "dryrun")
+		assert.Nil(t, err)
+		err = dbs.SetConfigValueForKey("allowlist", "1.1.1.1/32")
+		assert.Nil(t, err)
+
+		// subscribe to check the rollback is propagated
+		l := DbChangeListenerMock{}
+		dbs.SubscribeChanges(CHANGELOG_TABLE_CONFIG, &l)
+		stopChan := make(chan struct{})
+		go dbs.Watch(stopChan)
+		defer close(stopChan)
+		assert.Eventually(t, func() bool {
+			return l.Notifications() == 3
+		}, 5*time.Second, 100*time.Millisecond)
+		l.Reset()
+
+		err = dbs.RollbackConfig("", checkpoint, "alice", "incident #42")
+		assert.Nil(t, err)
+
+		value, err := dbs.GetConfigValueForKey("mode")
+		assert.Nil(t, err)
+		assert.Equal(t, "enabled", value)
+
+		// allowlist didn't exist at the checkpoint
+		_, err = dbs.GetConfigValueForKey("allowlist")
+		assert.Equal(t, gorm.ErrRecordNotFound, err)
+
+		// wait for the events to be read
+		assert.Eventually(t, func() bool {
+			return l.Notifications() == 2
+		}, 5*time.Second, 100*time.Millisecond)
+		assert.Never(t, func() bool {
+			return l.Notifications() > 2
+		}, 1500*time.Millisecond, 100*time.Millisecond)
+	})
+
+	t.Run("not happy path: the secrets are not recorded", func(t *testing.T) {
+		tmpFile, err := os.CreateTemp("", "taxsi.temp*")
+		assert.Nil(t, err)
+		defer os.Remove(tmpFile.Name())
+
+		dbs, err := NewDbService("sqlite3", tmpFile.Name(), 1, 1*time.Second)
+		assert.Nil(t, err)
+		assert.NotNil(t, dbs)
+
+		checkpoint := time.Now()
+		time.Sleep(10 * time.Millisecond)
+		err = dbs.SetConfigValueForKey("challenge_keys", "secret1")
+		assert.Nil(t, err)
+		err = dbs.SetConfigValueForKey("challenge_keys", "secret2")
+		assert.Nil(t, err)
+		err = dbs.SetConfigValueForKey("mode", "dryrun")
+		assert.Nil(t, err)
+
+		history, err := dbs.GetConfigHistory("challenge_keys", time.Time{})
+		assert.Nil(t, err)
+		assert.Equal(t, 2, len(history))
+		assert.Equal(t, "", history[0].OldValue)
+		assert.Equal(t, CONFIG_REDACTED, history[0].NewValue)
+		assert.Equal(t, CONFIG_REDACTED, history[1].OldValue)
+		assert.Equal(t, CONFIG_REDACTED, history[1].NewValue)
+
+		err = dbs.RollbackConfig("challenge_keys", checkpoint, "alice", "")
+		assert.NotNil(t, err)
+
+		// the secrets are left unchanged
+		err = dbs.RollbackConfig("", checkpoint, "alice", "")
+		assert.Nil(t, err)
+		value, err := dbs.GetConfigValueForKey("challenge_keys")
+		assert.Nil(t, err)
+		assert.Equal(t, "secret2", value)
+		_, err = dbs.GetConfigValueForKey("mode")
+		assert.Equal(t, gorm.ErrRecordNotFound, err)
+	})
+
+	t.Run("not happy path: a failed change is not half written", func(t *testing.T) {
+		tmpFile, err := os.CreateTemp("", "taxsi.temp*")
+		assert.Nil(t, err)
+		defer os.Remove(tmpFile.Name())
+
+		dbs, err := NewDbService("sqlite3", tmpFile.Name(), 1, 1*time.Second)
+		assert.Nil(t, err)
+		assert.NotNil(t, dbs)
+
+		err = dbs.SetConfigValueForKey("mode", "enabled")
+		assert.Nil(t, err)
+
+		// the changelog can no longer be written
+		err = dbs.(*DbServiceImpl).db.Migrator().DropTable(&ChangeLog{})
+		assert.Nil(t, err)
+
+		err = dbs.SetConfigValueForKeyWithAuthor("mode", "disabled", "alice", "")
+		assert.NotNil(t, err)
+		err = dbs.DeleteConfigKeyWithAuthor("mode", "alice", "")
+		assert.NotNil(t, err)
+
+		value, err := dbs.GetConfigValueForKey("mode")
+		assert.Nil(t, err)
+		assert.Equal(t, "enabled", value)
+		history, err := dbs.GetConfigHistory("mode", time.Time{})
+		assert.Nil(t, err)
+		assert.Equal(t, 1, len(history))
+	})
+}
@@ -18,8 +18,23 @@ var AutoMigrateTables = []interface{}{
 	GlobalConfig{},
 	GeoipSource{},
 	GeoipCountry{},
+	ConfigHistory{},
+	Ban{},
+	AutobanCounter{},
+	RatelimitRule{},
+	RatelimitCounter{},
+	UploadPolicy{},
+	OpenapiSpec{},
+	JwtPolicy{},
+	JwtKey{},
+	Rule{},
+	ModsecRuleset{},
 }
 
+// how long a missing changelog id is waited for (its transaction
+// may not be committed yet)
+const CHANGELOG_GAP_GRACE = 1 * time.Minute
+
 type DbChangeListener interface {
 	NotifyDbChange(key string)
 }
@@ -49,6 +64,116 @@ type DbServiceConfig interface {
 	SetConfigValueForKey(key string, value string) error
 }
 
+// Configuration audit trail
+type DbServiceConfigHistory interface {
+	SetConfigValueForKeyWithAuthor(key string, value string, author string, comment string) error
+	DeleteConfigKeyWithAuthor(key string, author string, comment string) error
+	GetConfigHistory(key string, since time.Time) ([]ConfigHistory, error)
+	RollbackConfig(key string, at time.Time, author string, comment string) error
+}
+
+// Time-boxed IP bans
+type DbServiceBan interface {
+	DbServiceSubscriber
+	AddBan(cidr string, reason string, creator string, expiresAt time.Time) (*Ban, error)
+	AddBanIfNotBanned(cidr string, reason string, creator string, expiresAt time.Time) (*Ban, bool, error)
+	GetActiveBans() ([]Ban, error)
+	RemoveBan(id uint) error
+	PurgeExpiredBans() error
+}
+
+// Automatic bans (shared counters of blocked requests)
+type DbServiceAutoban interface {
+	AddAutobanHits(windowStart time.Time, hits map[string]int) (map[string]int, error)
+	PurgeAutobanCounters(before time.Time) error
+}
+
+// Ratelimiter plugin specific
+type DbServiceRatelimit interface {
+	DbServiceSubscriber
+	GetConfigValueForKey(key string) (string, error)
+	GetRatelimitRules() ([]RatelimitRule, error)
+	SetRatelimitRule(rule *RatelimitRule) error
+	DeleteRatelimitRule(id uint) error
+	AddRatelimitHits(windowStart time.Time, hits map[string]int) (map[string]int, error)
+	PurgeRatelimitCounters(before time.Time) error
+}
+
+// Upload plugin specific
+type DbServiceUpload interface {
+	DbServiceSubscriber
+	GetUploadPolicies() ([]UploadPolicy, error)
+	SetUploadPolicy(policy *UploadPolicy) error
+	DeleteUploadPolicy(id uint) error
+}
+
+// OpenAPI plugin specific
+type DbServiceOpenapi interface {
+	DbServiceSubscriber
+	GetOpenapiSpecs() ([]OpenapiSpec, error)
+	SetOpenapiSpec(host string, spec []byte) error
+	DeleteOpenapiSpec(host string) error
+}
+
+// JWT plugin specific
+type DbServiceJwt interface {
+	DbServiceSubscriber
+	GetConfigValueForKey(key string) (string, error)
+	GetJwtPolicies() ([]JwtPolicy, error)
+	SetJwtPolicy(policy *JwtPolicy) error
+	DeleteJwtPolicy(id uint) error
+	GetJwtKeys() ([]JwtKey, error)
+	SetJwtKey(kid string, jwk []byte) error
+	DeleteJwtKey(kid string) error
+}
+
+// Rules plugin specific
+type DbServiceRules interface {
+	DbServiceSubscriber
+	GetRules() ([]Rule, error)
+	SetRule(rule *Rule) error
+	DeleteRule(id string) error
+}
+
+// ModSecurity plugin specific
+type DbServiceModsec interface {
+	DbServiceSubscriber
+	GetConfigValueForKey(key string) (string, error)
+	GetModsecRulesets() ([]ModsecRuleset, error)
+	SetModsecRuleset(ruleset *ModsecRuleset) error
+	DeleteModsecRuleset(name string) error
+}
+
+// Bot manager plugin specific
+type DbServiceBotmanager interface {
+	DbServiceSubscriber
+	GetConfigValueForKey(key string) (string, error)
+}
+
+// Protocol plugin specific
+type DbServiceProtocol interface {
+	DbServiceSubscriber
+	GetConfigValueForKey(key string) (string, error)
+}
+
+// GraphQL plugin specific
+type DbServiceGraphql interface {
+	DbServiceSubscriber
+	GetConfigValueForKey(key string) (string, error)
+}
+
+// OS attacks plugin specific
+type DbServiceOsattack interface {
+	DbServiceSubscriber
+	GetConfigValueForKey(key string) (string, error)
+}
+
+// SSRF plugin specific
+type DbServiceSsrf interface {
+	DbServiceSubscriber
+	GetConfigValueForKey(key string) (string, error)
+}
+
 type DbService interface {
 	DbServiceSubscriber
 
@@ -60,14 +185,31 @@ type DbService interface {
 	// Watch() start a go routine, that will periodically
 	// check if there is any changelog
 	Watch(stopChannel chan struct{})
+	// Transaction runs fc within a database transaction:
+	// if fc returns an error, all its changes (and changelogs)
+	// are rolled back
+	Transaction(fc func(tx DbService) error) error
 
 	DbServiceConfig
+	DbServiceConfigHistory
 	DBServiceGeoip
+	DbServiceBan
+	DbServiceAutoban
+	DbServiceRatelimit
+	DbServiceUpload
+	DbServiceOpenapi
+	DbServiceJwt
+	DbServiceRules
+	DbServiceModsec
 }
 
 type DbServiceImpl struct {
-	db                   *gorm.DB
-	lastChangeLog        uint
+	db *gorm.DB
+	// the last changelog read by Watch()
+	lastChangeLog uint
+	// the ids below lastChangeLog that were not read yet, with the
+	// time they were found missing (only used by Watch())
+	missingChangeLogs    map[uint]time.Time
 	changelogSubscribers map[int][]DbChangeListener
 }
 
@@ -124,6 +266,7 @@ func NewDbService(dbtype string, dsn string, retryAttempt uint, retryDelay time.
 	dbservice = &DbServiceImpl{
 		db:                   db,
 		lastChangeLog:        lastChangeLog.ID,
+		missingChangeLogs:    make(map[uint]time.Time),
 		changelogSubscribers: make(map[int][]DbChangeListener),
 	}
 
@@ -140,19 +283,9 @@ func (ds *DbServiceImpl) Watch(stopChannel chan struct{}) {
 			}
 		case <-time.After(1 * time.Second):
 			// check the changelog table
-			// let's load the last change log
-			var lastChangeLog ChangeLog
-			err := ds.db.Order("id desc").First(&lastChangeLog).Error
+			err := ds.readChangelogs(time.Now())
 			if err != nil {
-				logrus.Errorf("error looking for changelog: %v", err)
-				continue
-			}
-
-			for lastChangeLog.ID != ds.lastChangeLog {
-				err := ds.notifySubscriberTo(lastChangeLog.ID)
-				if err != nil {
-					logrus.Errorf("Error reading changelogs: %v", err)
-				}
+				logrus.Errorf("Error reading changelogs: %v", err)
 			}
 		}
 
@@ -160,27 +293,82 @@ func (ds *DbServiceImpl) Watch(stopChannel chan struct{}) {
 }
 
 // this function will read the ChangeLog table starting with
-// ID = ds.lastChangeLog + 1
-// and will distribute notifications across notification subscribers
-// (i.e. ds.changelogSubscribers)
-func (ds *DbServiceImpl) notifySubscriberTo(lastId uint) error {
-	for ds.lastChangeLog < lastId {
-		newId := ds.lastChangeLog + 1
-		var log ChangeLog
-		err := ds.db.First(&log, newId).Error
+// ID > ds.lastChangeLog, and will distribute notifications across
+// notification subscribers (i.e. ds.changelogSubscribers).
+// The ids may have gaps: a rolled back transaction, or a transaction
+// that is not committed yet (its changelog will be read later). So the
+// missing ids are read again, until they are found or CHANGELOG_GAP_GRACE
+// is over
+func (ds *DbServiceImpl) readChangelogs(now time.Time) error {
+	if len(ds.missingChangeLogs) > 0 {
+		first, last := ds.lastChangeLog, uint(0)
+		for id := range ds.missingChangeLogs {
+			if id < first {
+				first = id
+			}
+			if id > last {
+				last = id
+			}
+		}
+		var logs []ChangeLog
+		err := ds.db.Where("id >= ? AND id <= ?", first, last).Order("id asc").Find(&logs).Error
 		if err != nil {
-			return fmt.Errorf("error looking for changelog id %d: %v", newId, err)
+			return fmt.Errorf("error looking for changelogs %d to %d: %v", first, last, err)
+		}
+		for _, log := range logs {
+			if _, ok := ds.missingChangeLogs[log.ID]; ok {
+				ds.notify(log)
+				delete(ds.missingChangeLogs, log.ID)
+			}
 		}
+		for id, since := range ds.missingChangeLogs {
+			if now.Sub(since) > CHANGELOG_GAP_GRACE {
+				delete(ds.missingChangeLogs, id)
+			}
+		}
+	}
 
-		subscribers := ds.changelogSubscribers[log.Table]
-		for _, s := range subscribers {
-			s.NotifyDbChange(log.Key)
+	var logs []ChangeLog
+	err := ds.db.Where("id > ?", ds.lastChangeLog).Order("id asc").Find(&logs).Error
+	if err != nil {
+		return fmt.Errorf("error looking for changelogs after id %d: %v", ds.lastChangeLog, err)
+	}
+	for _, log := range logs {
+		for id := ds.lastChangeLog + 1; id < log.ID; id++ {
+			ds.missingChangeLogs[id] = now
 		}
-		ds.lastChangeLog++
+		ds.notify(log)
+		ds.lastChangeLog = log.ID
 	}
 	return nil
 }
 
+func (ds *DbServiceImpl) notify(log ChangeLog) {
+	subscribers := ds.changelogSubscribers[log.Table]
+	for _, s := range subscribers {
+		s.NotifyDbChange(log.Key)
+	}
+}
+
+func (ds *DbServiceImpl) Transaction(fc func(tx DbService) error) error {
+	return ds.withTransaction(func(tx *DbServiceImpl) error {
+		return fc(tx)
+	})
+}
+
+// withTransaction runs fc within a database transaction
+// (or within a savepoint, if ds is already a transaction).
+// The transaction only writes: the changelog is read by the
+// Watch() of ds
+func (ds *DbServiceImpl) withTransaction(fc func(tx *DbServiceImpl) error) error {
+	return ds.db.Transaction(func(tx *gorm.DB) error {
+		return fc(&DbServiceImpl{
+			db:                   tx,
+			changelogSubscribers: ds.changelogSubscribers,
+		})
+	})
+}
+
 func (ds *DbServiceImpl) SubscribeChanges(table int, listener DbChangeListener) {
 	s := ds.changelogSubscribers[table]
 	if s == nil {
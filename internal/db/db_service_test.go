@@ -1,23 +1,52 @@
 package db
 
 import (
+	"fmt"
 	"os"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
 )
 
+/*
+ * This is a mock implementation of the DbChangeListener interface
+ * (it is notified by the Watch goroutine)
+ */
 type DbChangeListenerMock struct {
+	mu            sync.Mutex
 	notifications int
 	key           string
 }
 
 func (l *DbChangeListenerMock) NotifyDbChange(key string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
 	l.notifications++
 	l.key = key
 }
 
+func (l *DbChangeListenerMock) Notifications() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.notifications
+}
+
+func (l *DbChangeListenerMock) Key() string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.key
+}
+
+func (l *DbChangeListenerMock) Reset() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.notifications = 0
+	l.key = ""
+}
+
 func TestWatch(t *testing.T) {
 	t.Run("happy path: receive notification", func(t *testing.T) {
 		tmpFile, err := os.CreateTemp("", "taxsi.temp*")
@@ -46,8 +75,8 @@ func TestWatch(t *testing.T) {
 		// wait for the event to be read
 		time.Sleep(2 * time.Second)
 
-		assert.Equal(t, "foo", l.key)
-		assert.Equal(t, 1, l.notifications)
+		assert.Equal(t, "foo", l.Key())
+		assert.Equal(t, 1, l.Notifications())
 	})
 
 	t.Run("not happy path: wrong notified table", func(t *testing.T) {
@@ -75,7 +104,122 @@ func TestWatch(t *testing.T) {
 		// wait for the event to be read
 		time.Sleep(2 * time.Second)
 
-		assert.Equal(t, "", l.key)
+		assert.Equal(t, "", l.Key())
+	})
+
+	t.Run("not happy path: gaps in the changelog ids", func(t *testing.T) {
+		tmpFile, err := os.CreateTemp("", "taxsi.temp*")
+		assert.Nil(t, err)
+		defer os.Remove(tmpFile.Name())
+
+		dbs, err := NewDbService("sqlite3", tmpFile.Name(), 1, 1*time.Second)
+		assert.Nil(t, err)
+		assert.NotNil(t, dbs)
+
+		l := DbChangeListenerMock{}
+		dbs.SubscribeChanges(CHANGELOG_TABLE_CONFIG, &l)
+
+		for _, key := range []string{"foo", "bar", "baz"} {
+			err = dbs.NotifyChange(CHANGELOG_TABLE_CONFIG, key)
+			assert.Nil(t, err)
+		}
+		// (like the id of a rolled back transaction, on postgres)
+		err = dbs.(*DbServiceImpl).db.Unscoped().Delete(&ChangeLog{}, 2).Error
+		assert.Nil(t, err)
+
+		stopChan := make(chan struct{})
+		go dbs.Watch(stopChan)
+		defer close(stopChan)
+
+		// wait for the events to be read
+		assert.Eventually(t, func() bool {
+			return l.Notifications() == 2
+		}, 5*time.Second, 100*time.Millisecond)
+		assert.Equal(t, "baz", l.Key())
+	})
+
+	t.Run("not happy path: a changelog committed after a newer one", func(t *testing.T) {
+		tmpFile, err := os.CreateTemp("", "taxsi.temp*")
+		assert.Nil(t, err)
+		defer os.Remove(tmpFile.Name())
+
+		dbs, err := NewDbService("sqlite3", tmpFile.Name(), 1, 1*time.Second)
+		assert.Nil(t, err)
+		assert.NotNil(t, dbs)
+		ds := dbs.(*DbServiceImpl)
+
+		l := DbChangeListenerMock{}
+		dbs.SubscribeChanges(CHANGELOG_TABLE_CONFIG, &l)
+
+		// the transaction of the id 1 is not committed yet
+		err = ds.db.Create(&ChangeLog{Model: gorm.Model{ID: 2}, Table: CHANGELOG_TABLE_CONFIG, Key: "bar"}).Error
+		assert.Nil(t, err)
+		now := time.Now()
+		assert.Nil(t, ds.readChangelogs(now))
+		assert.Equal(t, 1, l.Notifications())
+		assert.Equal(t, uint(2), ds.lastChangeLog)
+
+		// it is committed
+		err = ds.db.Create(&ChangeLog{Model: gorm.Model{ID: 1}, Table: CHANGELOG_TABLE_CONFIG, Key: "foo"}).Error
+		assert.Nil(t, err)
+		assert.Nil(t, ds.readChangelogs(now.Add(time.Second)))
+		assert.Equal(t, 2, l.Notifications())
+		assert.Equal(t, "foo", l.Key())
+		assert.Equal(t, 0, len(ds.missingChangeLogs))
+
+		// the id 3 is never committed (rolled back)
+		err = ds.db.Create(&ChangeLog{Model: gorm.Model{ID: 4}, Table: CHANGELOG_TABLE_CONFIG, Key: "baz"}).Error
+		assert.Nil(t, err)
+		assert.Nil(t, ds.readChangelogs(now.Add(2*time.Second)))
+		assert.Equal(t, 3, l.Notifications())
+		assert.Equal(t, 1, len(ds.missingChangeLogs))
+		assert.Nil(t, ds.readChangelogs(now.Add(2*time.Second+CHANGELOG_GAP_GRACE+time.Second)))
+		assert.Equal(t, 0, len(ds.missingChangeLogs))
+	})
+}
+
+func TestTransaction(t *testing.T) {
+	t.Run("happy path: commit", func(t *testing.T) {
+		tmpFile, err := os.CreateTemp("", "taxsi.temp*")
+		assert.Nil(t, err)
+		defer os.Remove(tmpFile.Name())
+
+		dbs, err := NewDbService("sqlite3", tmpFile.Name(), 1, 1*time.Second)
+		assert.Nil(t, err)
+		assert.NotNil(t, dbs)
+
+		err = dbs.Transaction(func(tx DbService) error {
+			return tx.SetConfigValueForKey("foo", "bar")
+		})
+		assert.Nil(t, err)
+
+		value, err := dbs.GetConfigValueForKey("foo")
+		assert.Nil(t, err)
+		assert.Equal(t, "bar", value)
 	})
 
+	t.Run("not happy path: rollback", func(t *testing.T) {
+		tmpFile, err := os.CreateTemp("", "taxsi.temp*")
+		assert.Nil(t, err)
+		defer os.Remove(tmpFile.Name())
+
+		dbs, err := NewDbService("sqlite3", tmpFile.Name(), 1, 1*time.Second)
+		assert.Nil(t, err)
+		assert.NotNil(t, dbs)
+
+		err = dbs.Transaction(func(tx DbService) error {
+			err := tx.SetConfigValueForKey("foo", "bar")
+			assert.Nil(t, err)
+			return fmt.Errorf("something went wrong")
+		})
+		assert.NotNil(t, err)
+
+		_, err = dbs.GetConfigValueForKey("foo")
+		assert.NotNil(t, err)
+
+		// the changelog has been rolled back too
+		var count int64
+		dbs.(*DbServiceImpl).db.Model(&ChangeLog{}).Count(&count)
+		assert.Equal(t, int64(0), count)
+	})
 }
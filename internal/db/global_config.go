@@ -1,5 +1,14 @@
 package db
 
+import (
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+// author used when a config change doesn't come with an explicit author
+const CONFIG_DEFAULT_AUTHOR = "taxsi2"
+
 type GlobalConfig struct {
 	Key   string
 	Value string
@@ -34,16 +43,78 @@ func (ds *DbServiceImpl) GetConfigValueForKey(key string) (string, error) {
 }
 
 func (ds *DbServiceImpl) SetConfigValueForKey(key string, value string) error {
+	return ds.SetConfigValueForKeyWithAuthor(key, value, CONFIG_DEFAULT_AUTHOR, "")
+}
 
-	config := GlobalConfig{
-		Key:   key,
-		Value: value,
-	}
+/*
+SetConfigValueForKeyWithAuthor sets a config value, and keeps track of
+who did it (and why) in the ConfigHistory table
+*/
+func (ds *DbServiceImpl) SetConfigValueForKeyWithAuthor(key string, value string, author string, comment string) error {
+	// the value, its history and its changelog are written together
+	return ds.withTransaction(func(tx *DbServiceImpl) error {
+		oldValue, err := tx.GetConfigValueForKey(key)
+		existed := true
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			existed = false
+		} else if err != nil {
+			return err
+		}
 
-	err := ds.db.Where(&GlobalConfig{Key: key}).Save(config).Error
-	if err != nil {
-		return err
-	}
+		config := GlobalConfig{
+			Key:   key,
+			Value: value,
+		}
+
+		err = tx.db.Where(&GlobalConfig{Key: key}).Save(config).Error
+		if err != nil {
+			return err
+		}
+
+		err = tx.addConfigHistory(ConfigHistory{
+			Key:      key,
+			OldValue: oldValue,
+			NewValue: value,
+			Existed:  existed,
+			Author:   author,
+			Comment:  comment,
+		})
+		if err != nil {
+			return err
+		}
+
+		return tx.NotifyChange(CHANGELOG_TABLE_CONFIG, key)
+	})
+}
+
+/*
+DeleteConfigKeyWithAuthor removes a config key, and keeps track of
+who did it (and why) in the ConfigHistory table
+*/
+func (ds *DbServiceImpl) DeleteConfigKeyWithAuthor(key string, author string, comment string) error {
+	return ds.withTransaction(func(tx *DbServiceImpl) error {
+		oldValue, err := tx.GetConfigValueForKey(key)
+		if err != nil {
+			return err
+		}
+
+		err = tx.db.Where(&GlobalConfig{Key: key}).Delete(&GlobalConfig{}).Error
+		if err != nil {
+			return err
+		}
+
+		err = tx.addConfigHistory(ConfigHistory{
+			Key:      key,
+			OldValue: oldValue,
+			Existed:  true,
+			Removed:  true,
+			Author:   author,
+			Comment:  comment,
+		})
+		if err != nil {
+			return err
+		}
 
-	return ds.NotifyChange(CHANGELOG_TABLE_CONFIG, key)
+		return tx.NotifyChange(CHANGELOG_TABLE_CONFIG, key)
+	})
 }
@@ -85,7 +85,7 @@ func TestGlobalConfig(t *testing.T) {
 		// wait for the event to be read
 		time.Sleep(2 * time.Second)
 
-		assert.Equal(t, 2, l.notifications)
-		assert.Equal(t, "foo2", l.key)
+		assert.Equal(t, 2, l.Notifications())
+		assert.Equal(t, "foo2", l.Key())
 	})
 }
@@ -0,0 +1,101 @@
+package db
+
+import (
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+/*
+JwtPolicy applies to the bearer tokens (JWT) of the requests whose
+path starts with PathPrefix (the longest matching prefix wins).
+RequiredClaims is a comma separated list of "<claim>" (the claim must
+be present) or "<claim>=<value>" (the claim, or one of its values,
+must be value), for example "sub,scope=admin"
+*/
+type JwtPolicy struct {
+	gorm.Model
+	PathPrefix string
+	// the requests without bearer token are rejected
+	Required bool
+	// the expected iss claim (any if empty)
+	Issuer string
+	// the expected aud claim (any if empty)
+	Audience       string
+	RequiredClaims string
+}
+
+/*
+JwtKey is a public key (or a HMAC secret) as a JWK, used to check the
+signature of the tokens
+*/
+type JwtKey struct {
+	Kid       string `gorm:"primaryKey;size:255"`
+	Jwk       []byte `gorm:"type:blob"`
+	UpdatedAt time.Time
+}
+
+func (ds *DbServiceImpl) GetJwtPolicies() ([]JwtPolicy, error) {
+	var policies []JwtPolicy
+	err := ds.db.Order("id asc").Find(&policies).Error
+	return policies, err
+}
+
+/*
+SetJwtPolicy creates (ID == 0) or updates a policy
+*/
+func (ds *DbServiceImpl) SetJwtPolicy(policy *JwtPolicy) error {
+	if err := ds.db.Save(policy).Error; err != nil {
+		return err
+	}
+	return ds.NotifyChange(CHANGELOG_TABLE_JWT, fmt.Sprintf("policy:%d", policy.ID))
+}
+
+/*
+DeleteJwtPolicy returns gorm.ErrRecordNotFound if there is no such policy
+*/
+func (ds *DbServiceImpl) DeleteJwtPolicy(id uint) error {
+	res := ds.db.Delete(&JwtPolicy{}, id)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return ds.NotifyChange(CHANGELOG_TABLE_JWT, fmt.Sprintf("policy:%d", id))
+}
+
+func (ds *DbServiceImpl) GetJwtKeys() ([]JwtKey, error) {
+	var keys []JwtKey
+	err := ds.db.Order("kid asc").Find(&keys).Error
+	return keys, err
+}
+
+/*
+SetJwtKey creates or replaces a key
+*/
+func (ds *DbServiceImpl) SetJwtKey(kid string, jwk []byte) error {
+	k := JwtKey{
+		Kid: kid,
+		Jwk: jwk,
+	}
+	if err := ds.db.Save(&k).Error; err != nil {
+		return err
+	}
+	return ds.NotifyChange(CHANGELOG_TABLE_JWT, "key:"+kid)
+}
+
+/*
+DeleteJwtKey returns gorm.ErrRecordNotFound if there is no such key
+*/
+func (ds *DbServiceImpl) DeleteJwtKey(kid string) error {
+	res := ds.db.Delete(&JwtKey{}, "kid = ?", kid)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return ds.NotifyChange(CHANGELOG_TABLE_JWT, "key:"+kid)
+}
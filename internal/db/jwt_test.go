@@ -0,0 +1,68 @@
+package db
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestJwtPolicies(t *testing.T) {
+	t.Run("happy path: create, update and delete policies and keys", func(t *testing.T) {
+		tmpFile, err := os.CreateTemp("", "taxsi.temp*")
+		assert.Nil(t, err)
+		defer os.Remove(tmpFile.Name())
+
+		dbs, err := NewDbService("sqlite3", tmpFile.Name(), 1, 1*time.Second)
+		assert.Nil(t, err)
+		assert.NotNil(t, dbs)
+
+		policies, err := dbs.GetJwtPolicies()
+		assert.Nil(t, err)
+		assert.Equal(t, 0, len(policies))
+
+		policy := JwtPolicy{
+			PathPrefix: "/api",
+			Required:   true,
+			Issuer:     "https://auth.example.com",
+		}
+		err = dbs.SetJwtPolicy(&policy)
+		assert.Nil(t, err)
+		assert.NotEqual(t, uint(0), policy.ID)
+
+		policy.RequiredClaims = "sub,scope=admin"
+		err = dbs.SetJwtPolicy(&policy)
+		assert.Nil(t, err)
+
+		policies, err = dbs.GetJwtPolicies()
+		assert.Nil(t, err)
+		assert.Equal(t, 1, len(policies))
+		assert.Equal(t, "sub,scope=admin", policies[0].RequiredClaims)
+
+		err = dbs.DeleteJwtPolicy(policy.ID)
+		assert.Nil(t, err)
+		err = dbs.DeleteJwtPolicy(policy.ID)
+		assert.Equal(t, gorm.ErrRecordNotFound, err)
+
+		err = dbs.SetJwtKey("key1", []byte(`{"kty": "oct", "k": "c2VjcmV0"}`))
+		assert.Nil(t, err)
+		err = dbs.SetJwtKey("key1", []byte(`{"kty": "oct", "k": "c2VjcmV0Mg"}`))
+		assert.Nil(t, err)
+
+		keys, err := dbs.GetJwtKeys()
+		assert.Nil(t, err)
+		assert.Equal(t, 1, len(keys))
+		assert.Equal(t, `{"kty": "oct", "k": "c2VjcmV0Mg"}`, string(keys[0].Jwk))
+
+		err = dbs.DeleteJwtKey("key1")
+		assert.Nil(t, err)
+		err = dbs.DeleteJwtKey("key1")
+		assert.Equal(t, gorm.ErrRecordNotFound, err)
+
+		var count int64
+		dbs.(*DbServiceImpl).db.Model(&ChangeLog{}).Where(&ChangeLog{Table: CHANGELOG_TABLE_JWT}).Count(&count)
+		assert.Equal(t, int64(6), count)
+	})
+}
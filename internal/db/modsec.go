@@ -0,0 +1,48 @@
+package db
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+/*
+ModsecRuleset is a SecLang file (like a file of the OWASP Core Rule Set),
+run by the modsec plugin. The rulesets are loaded in the order of
+their name
+*/
+type ModsecRuleset struct {
+	Name      string `gorm:"primaryKey;size:255"`
+	Content   string `gorm:"type:text"`
+	UpdatedAt time.Time
+}
+
+func (ds *DbServiceImpl) GetModsecRulesets() ([]ModsecRuleset, error) {
+	var rulesets []ModsecRuleset
+	err := ds.db.Order("name asc").Find(&rulesets).Error
+	return rulesets, err
+}
+
+/*
+SetModsecRuleset creates or replaces a ruleset
+*/
+func (ds *DbServiceImpl) SetModsecRuleset(ruleset *ModsecRuleset) error {
+	if err := ds.db.Save(ruleset).Error; err != nil {
+		return err
+	}
+	return ds.NotifyChange(CHANGELOG_TABLE_MODSEC, ruleset.Name)
+}
+
+/*
+DeleteModsecRuleset returns gorm.ErrRecordNotFound if there is no such ruleset
+*/
+func (ds *DbServiceImpl) DeleteModsecRuleset(name string) error {
+	res := ds.db.Delete(&ModsecRuleset{}, "name = ?", name)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return ds.NotifyChange(CHANGELOG_TABLE_MODSEC, name)
+}
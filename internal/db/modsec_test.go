@@ -0,0 +1,48 @@
+package db
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestModsecRulesets(t *testing.T) {
+	t.Run("happy path: create, replace and delete rulesets", func(t *testing.T) {
+		tmpFile, err := os.CreateTemp("", "taxsi.temp*")
+		assert.Nil(t, err)
+		defer os.Remove(tmpFile.Name())
+
+		dbs, err := NewDbService("sqlite3", tmpFile.Name(), 1, 1*time.Second)
+		assert.Nil(t, err)
+		assert.NotNil(t, dbs)
+
+		rulesets, err := dbs.GetModsecRulesets()
+		assert.Nil(t, err)
+		assert.Equal(t, 0, len(rulesets))
+
+		err = dbs.SetModsecRuleset(&ModsecRuleset{Name: "REQUEST-942-APPLICATION-ATTACK-SQLI.conf", Content: "# sqli"})
+		assert.Nil(t, err)
+		err = dbs.SetModsecRuleset(&ModsecRuleset{Name: "REQUEST-901-INITIALIZATION.conf", Content: "# init"})
+		assert.Nil(t, err)
+		err = dbs.SetModsecRuleset(&ModsecRuleset{Name: "REQUEST-901-INITIALIZATION.conf", Content: "# init v2"})
+		assert.Nil(t, err)
+
+		rulesets, err = dbs.GetModsecRulesets()
+		assert.Nil(t, err)
+		assert.Equal(t, 2, len(rulesets))
+		assert.Equal(t, "REQUEST-901-INITIALIZATION.conf", rulesets[0].Name)
+		assert.Equal(t, "# init v2", rulesets[0].Content)
+
+		err = dbs.DeleteModsecRuleset("REQUEST-901-INITIALIZATION.conf")
+		assert.Nil(t, err)
+		err = dbs.DeleteModsecRuleset("REQUEST-901-INITIALIZATION.conf")
+		assert.Equal(t, gorm.ErrRecordNotFound, err)
+
+		rulesets, err = dbs.GetModsecRulesets()
+		assert.Nil(t, err)
+		assert.Equal(t, 1, len(rulesets))
+	})
+}
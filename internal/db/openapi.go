@@ -0,0 +1,51 @@
+package db
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+/*
+OpenapiSpec is the OpenAPI (swagger 2.0) spec of a host,
+in JSON or in YAML
+*/
+type OpenapiSpec struct {
+	Host      string `gorm:"primaryKey;size:255"`
+	Spec      []byte `gorm:"type:blob"`
+	UpdatedAt time.Time
+}
+
+func (ds *DbServiceImpl) GetOpenapiSpecs() ([]OpenapiSpec, error) {
+	var specs []OpenapiSpec
+	err := ds.db.Order("host asc").Find(&specs).Error
+	return specs, err
+}
+
+/*
+SetOpenapiSpec creates or replaces the spec of a host
+*/
+func (ds *DbServiceImpl) SetOpenapiSpec(host string, spec []byte) error {
+	s := OpenapiSpec{
+		Host: host,
+		Spec: spec,
+	}
+	if err := ds.db.Save(&s).Error; err != nil {
+		return err
+	}
+	return ds.NotifyChange(CHANGELOG_TABLE_OPENAPI, host)
+}
+
+/*
+DeleteOpenapiSpec returns gorm.ErrRecordNotFound if there is no spec for this host
+*/
+func (ds *DbServiceImpl) DeleteOpenapiSpec(host string) error {
+	res := ds.db.Delete(&OpenapiSpec{}, "host = ?", host)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return ds.NotifyChange(CHANGELOG_TABLE_OPENAPI, host)
+}
@@ -0,0 +1,53 @@
+package db
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestOpenapiSpecs(t *testing.T) {
+	t.Run("happy path: create, replace and delete specs", func(t *testing.T) {
+		tmpFile, err := os.CreateTemp("", "taxsi.temp*")
+		assert.Nil(t, err)
+		defer os.Remove(tmpFile.Name())
+
+		dbs, err := NewDbService("sqlite3", tmpFile.Name(), 1, 1*time.Second)
+		assert.Nil(t, err)
+		assert.NotNil(t, dbs)
+
+		specs, err := dbs.GetOpenapiSpecs()
+		assert.Nil(t, err)
+		assert.Equal(t, 0, len(specs))
+
+		err = dbs.SetOpenapiSpec("api.example.com", []byte(`{"swagger": "2.0"}`))
+		assert.Nil(t, err)
+		err = dbs.SetOpenapiSpec("api.example.com", []byte(`swagger: "2.0"`))
+		assert.Nil(t, err)
+		err = dbs.SetOpenapiSpec("admin.example.com", []byte(`{"swagger": "2.0"}`))
+		assert.Nil(t, err)
+
+		specs, err = dbs.GetOpenapiSpecs()
+		assert.Nil(t, err)
+		assert.Equal(t, 2, len(specs))
+		assert.Equal(t, "admin.example.com", specs[0].Host)
+		assert.Equal(t, "api.example.com", specs[1].Host)
+		assert.Equal(t, `swagger: "2.0"`, string(specs[1].Spec))
+
+		err = dbs.DeleteOpenapiSpec("api.example.com")
+		assert.Nil(t, err)
+		err = dbs.DeleteOpenapiSpec("api.example.com")
+		assert.Equal(t, gorm.ErrRecordNotFound, err)
+
+		// a deleted host can be added again
+		err = dbs.SetOpenapiSpec("api.example.com", []byte(`{"swagger": "2.0"}`))
+		assert.Nil(t, err)
+
+		var count int64
+		dbs.(*DbServiceImpl).db.Model(&ChangeLog{}).Where(&ChangeLog{Table: CHANGELOG_TABLE_OPENAPI}).Count(&count)
+		assert.Equal(t, int64(5), count)
+	})
+}
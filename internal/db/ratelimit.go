@@ -0,0 +1,124 @@
+package db
+
+import (
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	RATELIMIT_TOKEN_BUCKET   = "token-bucket"
+	RATELIMIT_SLIDING_WINDOW = "sliding-window"
+)
+
+/*
+RatelimitRule limits the requests whose path starts with PathPrefix
+(the longest matching prefix wins) to Limit requests per Period seconds,
+per key.
+Key is a comma separated list of "ip", "path", "header:<name>" and
+"claim:<name>" (a claim of the bearer token validated by the jwt plugin),
+for example "ip,header:X-Api-Key" or "claim:sub"
+*/
+type RatelimitRule struct {
+	gorm.Model
+	PathPrefix string
+	Key        string
+	Algorithm  string // token-bucket or sliding-window
+	Limit      int
+	Period     int // seconds
+	Burst      int // token-bucket capacity (Limit if 0)
+}
+
+/*
+RatelimitCounter is the number of requests of a (rule, key) during
+a (fixed) window, summed over all the nodes
+*/
+type RatelimitCounter struct {
+	Key         string    `gorm:"uniqueIndex:idx_ratelimit_key_window;size:64"`
+	WindowStart time.Time `gorm:"uniqueIndex:idx_ratelimit_key_window;index"`
+	Hits        int
+}
+
+func (ds *DbServiceImpl) GetRatelimitRules() ([]RatelimitRule, error) {
+	var rules []RatelimitRule
+	err := ds.db.Order("id asc").Find(&rules).Error
+	return rules, err
+}
+
+/*
+SetRatelimitRule creates (ID == 0) or updates a rule
+*/
+func (ds *DbServiceImpl) SetRatelimitRule(rule *RatelimitRule) error {
+	if err := ds.db.Save(rule).Error; err != nil {
+		return err
+	}
+	return ds.NotifyChange(CHANGELOG_TABLE_RATELIMIT, fmt.Sprintf("%d", rule.ID))
+}
+
+/*
+DeleteRatelimitRule returns gorm.ErrRecordNotFound if there is no such rule
+*/
+func (ds *DbServiceImpl) DeleteRatelimitRule(id uint) error {
+	res := ds.db.Delete(&RatelimitRule{}, id)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return ds.NotifyChange(CHANGELOG_TABLE_RATELIMIT, fmt.Sprintf("%d", id))
+}
+
+/*
+AddRatelimitHits adds the local (per node) hits of the window to the shared
+counters, and returns the total hits (of all the nodes) for these keys.
+Keys with 0 hits are only read
+*/
+func (ds *DbServiceImpl) AddRatelimitHits(windowStart time.Time, hits map[string]int) (map[string]int, error) {
+	totals := make(map[string]int)
+	if len(hits) == 0 {
+		return totals, nil
+	}
+
+	err := ds.db.Transaction(func(tx *gorm.DB) error {
+		keys := []string{}
+		for key, n := range hits {
+			keys = append(keys, key)
+			if n == 0 {
+				continue
+			}
+			counter := RatelimitCounter{
+				Key:         key,
+				WindowStart: windowStart,
+				Hits:        n,
+			}
+			err := tx.Clauses(clause.OnConflict{
+				Columns:   []clause.Column{{Name: "key"}, {Name: "window_start"}},
+				DoUpdates: clause.Assignments(map[string]interface{}{"hits": gorm.Expr("ratelimit_counters.hits + ?", n)}),
+			}).Create(&counter).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		var counters []RatelimitCounter
+		err := tx.Where(map[string]interface{}{"key": keys, "window_start": windowStart}).Find(&counters).Error
+		if err != nil {
+			return err
+		}
+		for _, c := range counters {
+			totals[c.Key] = c.Hits
+		}
+		return nil
+	})
+	return totals, err
+}
+
+/*
+PurgeRatelimitCounters removes the counters of the windows started before a date
+*/
+func (ds *DbServiceImpl) PurgeRatelimitCounters(before time.Time) error {
+	return ds.db.Where("window_start < ?", before).Delete(&RatelimitCounter{}).Error
+}
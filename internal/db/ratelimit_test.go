@@ -0,0 +1,85 @@
+package db
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestRatelimitRules(t *testing.T) {
+	t.Run("happy path: create, update and delete rules", func(t *testing.T) {
+		tmpFile, err := os.CreateTemp("", "taxsi.temp*")
+		assert.Nil(t, err)
+		defer os.Remove(tmpFile.Name())
+
+		dbs, err := NewDbService("sqlite3", tmpFile.Name(), 1, 1*time.Second)
+		assert.Nil(t, err)
+		assert.NotNil(t, dbs)
+
+		rules, err := dbs.GetRatelimitRules()
+		assert.Nil(t, err)
+		assert.Equal(t, 0, len(rules))
+
+		rule := RatelimitRule{
+			PathPrefix: "/login",
+			Key:        "ip",
+			Algorithm:  RATELIMIT_SLIDING_WINDOW,
+			Limit:      10,
+			Period:     60,
+		}
+		err = dbs.SetRatelimitRule(&rule)
+		assert.Nil(t, err)
+		assert.NotEqual(t, uint(0), rule.ID)
+
+		rule.Limit = 20
+		err = dbs.SetRatelimitRule(&rule)
+		assert.Nil(t, err)
+
+		rules, err = dbs.GetRatelimitRules()
+		assert.Nil(t, err)
+		assert.Equal(t, 1, len(rules))
+		assert.Equal(t, 20, rules[0].Limit)
+
+		err = dbs.DeleteRatelimitRule(rule.ID)
+		assert.Nil(t, err)
+		err = dbs.DeleteRatelimitRule(rule.ID)
+		assert.Equal(t, gorm.ErrRecordNotFound, err)
+
+		var count int64
+		dbs.(*DbServiceImpl).db.Model(&ChangeLog{}).Where(&ChangeLog{Table: CHANGELOG_TABLE_RATELIMIT}).Count(&count)
+		assert.Equal(t, int64(3), count)
+	})
+}
+
+func TestRatelimitCounters(t *testing.T) {
+	t.Run("happy path: hits are summed over the nodes", func(t *testing.T) {
+		tmpFile, err := os.CreateTemp("", "taxsi.temp*")
+		assert.Nil(t, err)
+		defer os.Remove(tmpFile.Name())
+
+		dbs, err := NewDbService("sqlite3", tmpFile.Name(), 1, 1*time.Second)
+		assert.Nil(t, err)
+		assert.NotNil(t, dbs)
+
+		window := time.Now().Truncate(time.Minute)
+
+		// node 1
+		totals, err := dbs.AddRatelimitHits(window, map[string]int{"a": 2, "b": 1})
+		assert.Nil(t, err)
+		assert.Equal(t, map[string]int{"a": 2, "b": 1}, totals)
+
+		// node 2 (only reading b)
+		totals, err = dbs.AddRatelimitHits(window, map[string]int{"a": 3, "b": 0, "c": 0})
+		assert.Nil(t, err)
+		assert.Equal(t, map[string]int{"a": 5, "b": 1}, totals)
+
+		err = dbs.PurgeRatelimitCounters(window.Add(time.Minute))
+		assert.Nil(t, err)
+		var count int64
+		dbs.(*DbServiceImpl).db.Model(&RatelimitCounter{}).Count(&count)
+		assert.Equal(t, int64(0), count)
+	})
+}
@@ -0,0 +1,51 @@
+package db
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+/*
+Rule is a custom rule: a boolean expression over the request fields
+(see the rules plugin for the syntax), and the action to take when
+it matches (block, challenge, log).
+The rules are evaluated in the order of their ID
+*/
+type Rule struct {
+	ID          string `gorm:"primaryKey;size:255"`
+	Expression  string `gorm:"type:text"`
+	Action      string
+	Description string
+	UpdatedAt   time.Time
+}
+
+func (ds *DbServiceImpl) GetRules() ([]Rule, error) {
+	var rules []Rule
+	err := ds.db.Order("id asc").Find(&rules).Error
+	return rules, err
+}
+
+/*
+SetRule creates or replaces a rule
+*/
+func (ds *DbServiceImpl) SetRule(rule *Rule) error {
+	if err := ds.db.Save(rule).Error; err != nil {
+		return err
+	}
+	return ds.NotifyChange(CHANGELOG_TABLE_RULE, rule.ID)
+}
+
+/*
+DeleteRule returns gorm.ErrRecordNotFound if there is no such rule
+*/
+func (ds *DbServiceImpl) DeleteRule(id string) error {
+	res := ds.db.Delete(&Rule{}, "id = ?", id)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return ds.NotifyChange(CHANGELOG_TABLE_RULE, id)
+}
@@ -0,0 +1,48 @@
+package db
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestRules(t *testing.T) {
+	t.Run("happy path: create, replace and delete rules", func(t *testing.T) {
+		tmpFile, err := os.CreateTemp("", "taxsi.temp*")
+		assert.Nil(t, err)
+		defer os.Remove(tmpFile.Name())
+
+		dbs, err := NewDbService("sqlite3", tmpFile.Name(), 1, 1*time.Second)
+		assert.Nil(t, err)
+		assert.NotNil(t, dbs)
+
+		rules, err := dbs.GetRules()
+		assert.Nil(t, err)
+		assert.Equal(t, 0, len(rules))
+
+		err = dbs.SetRule(&Rule{ID: "1001", Expression: `method == "TRACE"`, Action: "block"})
+		assert.Nil(t, err)
+		err = dbs.SetRule(&Rule{ID: "1000", Expression: `path.startsWith("/admin")`, Action: "log"})
+		assert.Nil(t, err)
+		err = dbs.SetRule(&Rule{ID: "1000", Expression: `path.startsWith("/admin")`, Action: "challenge"})
+		assert.Nil(t, err)
+
+		rules, err = dbs.GetRules()
+		assert.Nil(t, err)
+		assert.Equal(t, 2, len(rules))
+		assert.Equal(t, "1000", rules[0].ID)
+		assert.Equal(t, "challenge", rules[0].Action)
+
+		err = dbs.DeleteRule("1000")
+		assert.Nil(t, err)
+		err = dbs.DeleteRule("1000")
+		assert.Equal(t, gorm.ErrRecordNotFound, err)
+
+		rules, err = dbs.GetRules()
+		assert.Nil(t, err)
+		assert.Equal(t, 1, len(rules))
+	})
+}
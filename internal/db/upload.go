@@ -0,0 +1,55 @@
+package db
+
+import (
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+/*
+UploadPolicy applies to the uploaded files (multipart/form-data)
+of the requests whose path starts with PathPrefix (the longest
+matching prefix wins).
+The extension and content type lists are comma separated
+(for example "jpg,png" and "image/jpeg,image/png"), empty means any
+*/
+type UploadPolicy struct {
+	gorm.Model
+	PathPrefix string
+	// no file can be uploaded
+	Deny              bool
+	AllowedExtensions string
+	DeniedExtensions  string
+	AllowedTypes      string
+	MaxFileSize       int // bytes (no limit if 0)
+}
+
+func (ds *DbServiceImpl) GetUploadPolicies() ([]UploadPolicy, error) {
+	var policies []UploadPolicy
+	err := ds.db.Order("id asc").Find(&policies).Error
+	return policies, err
+}
+
+/*
+SetUploadPolicy creates (ID == 0) or updates a policy
+*/
+func (ds *DbServiceImpl) SetUploadPolicy(policy *UploadPolicy) error {
+	if err := ds.db.Save(policy).Error; err != nil {
+		return err
+	}
+	return ds.NotifyChange(CHANGELOG_TABLE_UPLOAD, fmt.Sprintf("%d", policy.ID))
+}
+
+/*
+DeleteUploadPolicy returns gorm.ErrRecordNotFound if there is no such policy
+*/
+func (ds *DbServiceImpl) DeleteUploadPolicy(id uint) error {
+	res := ds.db.Delete(&UploadPolicy{}, id)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return ds.NotifyChange(CHANGELOG_TABLE_UPLOAD, fmt.Sprintf("%d", id))
+}
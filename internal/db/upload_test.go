@@ -0,0 +1,53 @@
+package db
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestUploadPolicies(t *testing.T) {
+	t.Run("happy path: create, update and delete policies", func(t *testing.T) {
+		tmpFile, err := os.CreateTemp("", "taxsi.temp*")
+		assert.Nil(t, err)
+		defer os.Remove(tmpFile.Name())
+
+		dbs, err := NewDbService("sqlite3", tmpFile.Name(), 1, 1*time.Second)
+		assert.Nil(t, err)
+		assert.NotNil(t, dbs)
+
+		policies, err := dbs.GetUploadPolicies()
+		assert.Nil(t, err)
+		assert.Equal(t, 0, len(policies))
+
+		policy := UploadPolicy{
+			PathPrefix:        "/cms/media",
+			AllowedExtensions: "jpg,png",
+			MaxFileSize:       1024,
+		}
+		err = dbs.SetUploadPolicy(&policy)
+		assert.Nil(t, err)
+		assert.NotEqual(t, uint(0), policy.ID)
+
+		policy.MaxFileSize = 2048
+		err = dbs.SetUploadPolicy(&policy)
+		assert.Nil(t, err)
+
+		policies, err = dbs.GetUploadPolicies()
+		assert.Nil(t, err)
+		assert.Equal(t, 1, len(policies))
+		assert.Equal(t, 2048, policies[0].MaxFileSize)
+
+		err = dbs.DeleteUploadPolicy(policy.ID)
+		assert.Nil(t, err)
+		err = dbs.DeleteUploadPolicy(policy.ID)
+		assert.Equal(t, gorm.ErrRecordNotFound, err)
+
+		var count int64
+		dbs.(*DbServiceImpl).db.Model(&ChangeLog{}).Where(&ChangeLog{Table: CHANGELOG_TABLE_UPLOAD}).Count(&count)
+		assert.Equal(t, int64(3), count)
+	})
+}
@@ -1,6 +1,7 @@
 package axsi
 
 import (
+	"context"
 	"github.com/nzin/taxsi2/internal/com"
 	"github.com/nzin/taxsi2/internal/db"
 	"github.com/nzin/taxsi2/internal/engine"
@@ -21,6 +22,6 @@ func (a *AxiWafPlugin) Name() string {
 	return "axi"
 }
 
-func (a *AxiWafPlugin) Scan(payload *com.TaxsiCom) bool {
+func (a *AxiWafPlugin) Scan(ctx context.Context, payload *com.TaxsiCom) bool {
 	return true
 }
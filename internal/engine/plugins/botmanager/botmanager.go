@@ -0,0 +1,409 @@
+package botmanager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nzin/taxsi2/internal/com"
+	"github.com/nzin/taxsi2/internal/db"
+	"github.com/nzin/taxsi2/internal/engine"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	CLASS_HUMAN   = "human"
+	CLASS_GOODBOT = "goodbot"
+	CLASS_BADBOT  = "badbot"
+	CLASS_UNKNOWN = "unknown"
+
+	ACTION_ALLOW     = "allow"
+	ACTION_BLOCK     = "block"
+	ACTION_CHALLENGE = "challenge"
+
+	// GlobalConfig keys
+	// botmanager_action_<class> = allow|block|challenge
+	CONFIG_ACTION_PREFIX = "botmanager_action_"
+	// comma separated list of extra bad User-Agent substrings
+	CONFIG_BAD_UA = "botmanager_bad_ua"
+
+	// output variable
+	VARIABLE_BOTCLASS = "botclass"
+
+	// how long a reverse-DNS verification is remembered
+	DNS_CACHE_TTL = 1 * time.Hour
+	DNS_TIMEOUT   = 2 * time.Second
+	// above this size, the expired verifications are dropped
+	DNS_CACHE_MAX = 10000
+)
+
+var CLASSES = []string{CLASS_HUMAN, CLASS_GOODBOT, CLASS_BADBOT, CLASS_UNKNOWN}
+
+/*
+Resolver is the part of net.Resolver used to verify the crawlers
+(mocked in the tests)
+*/
+type Resolver interface {
+	LookupAddr(ctx context.Context, addr string) ([]string, error)
+	LookupHost(ctx context.Context, host string) ([]string, error)
+}
+
+/*
+crawler is a well known bot, that we can verify with its reverse-DNS
+*/
+type crawler struct {
+	agent   string // lowercase User-Agent substring
+	domains []string
+}
+
+var crawlers = []crawler{
+	{agent: "googlebot", domains: []string{"googlebot.com", "google.com"}},
+	{agent: "bingbot", domains: []string{"search.msn.com"}},
+	{agent: "duckduckbot", domains: []string{"duckduckgo.com"}},
+	{agent: "applebot", domains: []string{"applebot.apple.com"}},
+	{agent: "yandexbot", domains: []string{"yandex.ru", "yandex.com", "yandex.net"}},
+	{agent: "baiduspider", domains: []string{"baidu.com", "baidu.jp"}},
+}
+
+// lowercase User-Agent substrings of the security scanners
+var badAgents = []string{
+	"sqlmap", "nikto", "nmap", "masscan", "zgrab", "nuclei", "wpscan",
+	"acunetix", "dirbuster", "gobuster", "ffuf", "wfuzz", "netsparker",
+	"havij", "hydra", "openvas", "w3af", "jaeles", "whatweb",
+}
+
+// lowercase User-Agent substrings of the http libraries and tools
+var automationAgents = []string{
+	"curl/", "wget/", "python-requests", "python-urllib", "go-http-client",
+	"java/", "okhttp", "libwww-perl", "httpclient", "axios/", "node-fetch",
+	"scrapy", "headlesschrome", "phantomjs",
+}
+
+type dnsResult struct {
+	class   string
+	expires time.Time
+}
+
+type BotmanagerWafPlugin struct {
+	ds       db.DbServiceBotmanager
+	resolver Resolver
+	now      func() time.Time
+
+	mu        sync.RWMutex
+	actions   map[string]string
+	badAgents []string
+
+	cacheMu sync.Mutex
+	cache   map[string]dnsResult
+}
+
+func NewBotmanagerWafPlugin(ds db.DbServiceBotmanager, resolver Resolver) engine.WafEnginePlugin {
+	return newBotmanagerWafPlugin(ds, resolver)
+}
+
+func newBotmanagerWafPlugin(ds db.DbServiceBotmanager, resolver Resolver) *BotmanagerWafPlugin {
+	b := &BotmanagerWafPlugin{
+		ds:       ds,
+		resolver: resolver,
+		now:      time.Now,
+		cache:    make(map[string]dnsResult),
+	}
+	b.loadConfig()
+
+	ds.SubscribeChanges(db.CHANGELOG_TABLE_CONFIG, &configListener{b: b})
+	return b
+}
+
+func (b *BotmanagerWafPlugin) Name() string {
+	return "botmanager"
+}
+
+// Interruptible is true: the DNS lookups stop when the context is done
+func (b *BotmanagerWafPlugin) Interruptible() bool {
+	return true
+}
+
+// IPDependent is true: the crawlers are verified by the reverse DNS of the client ip
+func (b *BotmanagerWafPlugin) IPDependent() bool {
+	return true
+}
+
+/*
+ValidateConfig returns why a value can't be used for a botmanager key
+(nil if it can, or if the key is not a botmanager key)
+*/
+func ValidateConfig(key string, value string) error {
+	if !strings.HasPrefix(key, CONFIG_ACTION_PREFIX) {
+		return nil
+	}
+	class := key[len(CONFIG_ACTION_PREFIX):]
+	known := false
+	for _, c := range CLASSES {
+		known = known || c == class
+	}
+	if !known {
+		return fmt.Errorf("bad %s (the classes are %s)", key, strings.Join(CLASSES, ", "))
+	}
+	if value != ACTION_ALLOW && value != ACTION_BLOCK && value != ACTION_CHALLENGE {
+		return fmt.Errorf("bad %s %s (must be allow, block or challenge)", key, value)
+	}
+	return nil
+}
+
+func (b *BotmanagerWafPlugin) loadConfig() {
+	actions := map[string]string{
+		CLASS_HUMAN:   ACTION_ALLOW,
+		CLASS_GOODBOT: ACTION_ALLOW,
+		CLASS_BADBOT:  ACTION_BLOCK,
+		CLASS_UNKNOWN: ACTION_ALLOW,
+	}
+	for _, class := range CLASSES {
+		action, err := b.ds.GetConfigValueForKey(CONFIG_ACTION_PREFIX + class)
+		if err != nil {
+			continue
+		}
+		if err := ValidateConfig(CONFIG_ACTION_PREFIX+class, action); err != nil {
+			logrus.Errorf("botmanager: %v", err)
+			continue
+		}
+		actions[class] = action
+	}
+
+	agents := append([]string{}, badAgents...)
+	if extra, err := b.ds.GetConfigValueForKey(CONFIG_BAD_UA); err == nil {
+		for _, a := range strings.Split(extra, ",") {
+			if a = strings.ToLower(strings.TrimSpace(a)); a != "" {
+				agents = append(agents, a)
+			}
+		}
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.actions = actions
+	b.badAgents = agents
+}
+
+// configListener reloads the actions when the GlobalConfig changes
+type configListener struct {
+	b *BotmanagerWafPlugin
+}
+
+func (l *configListener) NotifyDbChange(key string) {
+	if strings.HasPrefix(key, CONFIG_ACTION_PREFIX) || key == CONFIG_BAD_UA {
+		l.b.loadConfig()
+	}
+}
+
+func (b *BotmanagerWafPlugin) Scan(ctx context.Context, payload *com.TaxsiCom) bool {
+	return b.ScanVerdict(ctx, payload) == engine.VERDICT_PASS
+}
+
+func (b *BotmanagerWafPlugin) ScanVerdict(ctx context.Context, payload *com.TaxsiCom) engine.Verdict {
+	class := b.Classify(ctx, payload)
+	payload.SetVariable(VARIABLE_BOTCLASS, class)
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	switch b.actions[class] {
+	case ACTION_BLOCK:
+		return engine.VERDICT_BLOCK
+	case ACTION_CHALLENGE:
+		return engine.VERDICT_CHALLENGE
+	}
+	return engine.VERDICT_PASS
+}
+
+/*
+Classify returns the class of the client (human, goodbot, badbot, unknown).
+The DNS lookups stop when the context is done
+*/
+func (b *BotmanagerWafPlugin) Classify(ctx context.Context, payload *com.TaxsiCom) string {
+	ua := strings.ToLower(header(payload, "User-Agent"))
+	if ua == "" {
+		return CLASS_UNKNOWN
+	}
+
+	b.mu.RLock()
+	agents := b.badAgents
+	b.mu.RUnlock()
+	for _, a := range agents {
+		if strings.Contains(ua, a) {
+			return CLASS_BADBOT
+		}
+	}
+
+	for _, c := range crawlers {
+		if strings.Contains(ua, c.agent) {
+			// pretending to be a crawler is not a good sign
+			return b.verifyCrawler(ctx, payload.RemoteAddr, c.domains)
+		}
+	}
+
+	for _, a := range automationAgents {
+		if strings.Contains(ua, a) {
+			return CLASS_UNKNOWN
+		}
+	}
+
+	switch anomalies(ua, payload) {
+	case 0:
+		return CLASS_HUMAN
+	case 1:
+		return CLASS_UNKNOWN
+	default:
+		return CLASS_BADBOT
+	}
+}
+
+/*
+anomalies counts the inconsistencies between what a browser
+claims to be (its User-Agent) and the headers it sends
+*/
+func anomalies(ua string, payload *com.TaxsiCom) int {
+	n := 0
+	// Chromium based browsers send the client hints, Firefox doesn't
+	chromium := strings.Contains(ua, "chrome/") || strings.Contains(ua, "edg/")
+	hints := header(payload, "Sec-Ch-Ua") != ""
+	if chromium && !hints {
+		n++
+	}
+	if strings.Contains(ua, "firefox/") && hints {
+		n++
+	}
+	if header(payload, "Accept") == "" {
+		n++
+	}
+	if header(payload, "Accept-Language") == "" {
+		n++
+	}
+
+	// browsers send these headers in this relative order
+	if len(payload.HeaderOrder) > 0 {
+		last := -1
+		for _, h := range []string{"User-Agent", "Accept", "Accept-Encoding"} {
+			i := headerIndex(payload.HeaderOrder, h)
+			if i < 0 {
+				continue
+			}
+			if i < last {
+				n++
+				break
+			}
+			last = i
+		}
+	}
+	return n
+}
+
+/*
+verifyCrawler checks that the reverse-DNS of the ip belongs to one of
+the crawler domains, and that this name resolves back to the ip
+*/
+func (b *BotmanagerWafPlugin) verifyCrawler(ctx context.Context, remoteAddr string, domains []string) string {
+	ip := remoteAddr
+	if host, _, err := net.SplitHostPort(remoteAddr); err == nil {
+		ip = host
+	}
+	now := b.now()
+
+	b.cacheMu.Lock()
+	if r, ok := b.cache[ip]; ok && now.Before(r.expires) {
+		b.cacheMu.Unlock()
+		return r.class
+	}
+	b.cacheMu.Unlock()
+
+	class, err := b.lookup(ctx, ip, domains)
+	if err != nil {
+		// not cached: the resolver may be back soon
+		logrus.Debugf("botmanager: unable to verify %s: %v", ip, err)
+		return CLASS_UNKNOWN
+	}
+
+	b.cacheMu.Lock()
+	defer b.cacheMu.Unlock()
+	if len(b.cache) >= DNS_CACHE_MAX {
+		for k, r := range b.cache {
+			if !now.Before(r.expires) {
+				delete(b.cache, k)
+			}
+		}
+	}
+	b.cache[ip] = dnsResult{class: class, expires: now.Add(DNS_CACHE_TTL)}
+	return class
+}
+
+/*
+lookup returns an error only if the resolver failed (timeout...),
+not if the ip has no (matching) reverse-DNS
+*/
+func (b *BotmanagerWafPlugin) lookup(ctx context.Context, ip string, domains []string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, DNS_TIMEOUT)
+	defer cancel()
+
+	names, err := b.resolver.LookupAddr(ctx, ip)
+	if isNotFound(err) {
+		return CLASS_BADBOT, nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	for _, name := range names {
+		name = strings.ToLower(strings.TrimSuffix(name, "."))
+		if !inDomains(name, domains) {
+			continue
+		}
+		addrs, err := b.resolver.LookupHost(ctx, name)
+		if isNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+		for _, a := range addrs {
+			if net.ParseIP(a).Equal(net.ParseIP(ip)) {
+				return CLASS_GOODBOT, nil
+			}
+		}
+	}
+	return CLASS_BADBOT, nil
+}
+
+func isNotFound(err error) bool {
+	var dnsErr *net.DNSError
+	return errors.As(err, &dnsErr) && dnsErr.IsNotFound
+}
+
+func inDomains(name string, domains []string) bool {
+	for _, d := range domains {
+		if name == d || strings.HasSuffix(name, "."+d) {
+			return true
+		}
+	}
+	return false
+}
+
+/*
+header returns the first value of a header
+*/
+func header(payload *com.TaxsiCom, name string) string {
+	if v := payload.GetHeader(name); len(v) > 0 {
+		return v[0]
+	}
+	return ""
+}
+
+func headerIndex(order []string, name string) int {
+	for i, k := range order {
+		if strings.EqualFold(k, name) {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,232 @@
+package botmanager
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/nzin/taxsi2/internal/com"
+	"github.com/nzin/taxsi2/internal/db"
+	"github.com/nzin/taxsi2/internal/engine"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+/*
+ * This is a mock implementation of the db.DbServiceBotmanager interface
+ */
+type DbServiceBotmanagerMock struct {
+	config map[string]string
+}
+
+func (m *DbServiceBotmanagerMock) SubscribeChanges(table int, listener db.DbChangeListener) {
+}
+func (m *DbServiceBotmanagerMock) GetConfigValueForKey(key string) (string, error) {
+	value, ok := m.config[key]
+	if !ok {
+		return "", gorm.ErrRecordNotFound
+	}
+	return value, nil
+}
+
+/*
+ * This is a mock (offline) resolver
+ */
+type ResolverMock struct {
+	ptr     map[string][]string
+	hosts   map[string][]string
+	err     error
+	lookups int
+}
+
+func (r *ResolverMock) LookupAddr(ctx context.Context, addr string) ([]string, error) {
+	r.lookups++
+	if r.err != nil {
+		return nil, r.err
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	names, ok := r.ptr[addr]
+	if !ok {
+		return nil, &net.DNSError{Err: "no such host", Name: addr, IsNotFound: true}
+	}
+	return names, nil
+}
+func (r *ResolverMock) LookupHost(ctx context.Context, host string) ([]string, error) {
+	addrs, ok := r.hosts[host]
+	if !ok {
+		return nil, &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+	}
+	return addrs, nil
+}
+
+func request(remoteAddr string, headers ...string) *com.TaxsiCom {
+	u, _ := url.Parse("http://www.example.com/")
+	t := &com.TaxsiCom{
+		RemoteAddr: remoteAddr,
+		Method:     "GET",
+		Url:        u,
+		Headers:    make(map[string][]string),
+	}
+	for i := 0; i+1 < len(headers); i += 2 {
+		t.Headers[headers[i]] = []string{headers[i+1]}
+		t.HeaderOrder = append(t.HeaderOrder, headers[i])
+	}
+	return t
+}
+
+const (
+	chromeUA  = "Mozilla/5.0 (X11; Linux x86_64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36"
+	firefoxUA = "Mozilla/5.0 (X11; Linux x86_64; rv:121.0) Gecko/20100101 Firefox/121.0"
+	googleUA  = "Mozilla/5.0 (compatible; Googlebot/2.1; +http://www.google.com/bot.html)"
+)
+
+func TestClassify(t *testing.T) {
+	ds := &DbServiceBotmanagerMock{config: map[string]string{}}
+	resolver := &ResolverMock{
+		ptr: map[string][]string{
+			"66.249.66.1": {"crawl-66-249-66-1.googlebot.com."},
+			"6.6.6.6":     {"crawl-66-249-66-1.googlebot.com.evil.com."},
+			"7.7.7.7":     {"crawl-66-249-66-1.googlebot.com."},
+		},
+		hosts: map[string][]string{
+			"crawl-66-249-66-1.googlebot.com": {"66.249.66.1"},
+		},
+	}
+	b := newBotmanagerWafPlugin(ds, resolver)
+
+	t.Run("happy path: browsers", func(t *testing.T) {
+		assert.Equal(t, CLASS_HUMAN, b.Classify(context.Background(), request("1.2.3.4",
+			"Sec-Ch-Ua", `"Chromium";v="120"`,
+			"User-Agent", chromeUA,
+			"Accept", "text/html",
+			"Accept-Encoding", "gzip",
+			"Accept-Language", "en-US",
+		)))
+		assert.Equal(t, CLASS_HUMAN, b.Classify(context.Background(), request("1.2.3.4",
+			"User-Agent", firefoxUA,
+			"Accept", "text/html",
+			"Accept-Language", "en-US",
+			"Accept-Encoding", "gzip",
+		)))
+	})
+
+	t.Run("happy path: inconsistent browsers", func(t *testing.T) {
+		// chrome without client hints
+		assert.Equal(t, CLASS_UNKNOWN, b.Classify(context.Background(), request("1.2.3.4",
+			"User-Agent", chromeUA,
+			"Accept", "text/html",
+			"Accept-Language", "en-US",
+		)))
+		// chrome without client hints, and with the headers in a weird order
+		assert.Equal(t, CLASS_BADBOT, b.Classify(context.Background(), request("1.2.3.4",
+			"Accept", "text/html",
+			"User-Agent", chromeUA,
+			"Accept-Language", "en-US",
+		)))
+		// firefox with client hints and no language
+		assert.Equal(t, CLASS_BADBOT, b.Classify(context.Background(), request("1.2.3.4",
+			"User-Agent", firefoxUA,
+			"Sec-Ch-Ua", `"Chromium";v="120"`,
+			"Accept", "text/html",
+		)))
+	})
+
+	t.Run("happy path: tools and scanners", func(t *testing.T) {
+		assert.Equal(t, CLASS_UNKNOWN, b.Classify(context.Background(), request("1.2.3.4")))
+		assert.Equal(t, CLASS_UNKNOWN, b.Classify(context.Background(), request("1.2.3.4", "User-Agent", "curl/8.4.0")))
+		assert.Equal(t, CLASS_BADBOT, b.Classify(context.Background(), request("1.2.3.4", "User-Agent", "sqlmap/1.7#stable (https://sqlmap.org)")))
+		// not canonicalized header
+		assert.Equal(t, CLASS_BADBOT, b.Classify(context.Background(), request("1.2.3.4", "user-agent", "Nuclei - Open-source project")))
+	})
+
+	t.Run("happy path: crawlers are verified with the reverse-DNS", func(t *testing.T) {
+		assert.Equal(t, CLASS_GOODBOT, b.Classify(context.Background(), request("66.249.66.1", "User-Agent", googleUA)))
+		// no PTR record
+		assert.Equal(t, CLASS_BADBOT, b.Classify(context.Background(), request("1.2.3.4", "User-Agent", googleUA)))
+		// bad domain
+		assert.Equal(t, CLASS_BADBOT, b.Classify(context.Background(), request("6.6.6.6", "User-Agent", googleUA)))
+		// the forward lookup doesn't match
+		assert.Equal(t, CLASS_BADBOT, b.Classify(context.Background(), request("7.7.7.7", "User-Agent", googleUA)))
+
+		// cached
+		lookups := resolver.lookups
+		assert.Equal(t, CLASS_GOODBOT, b.Classify(context.Background(), request("66.249.66.1:4242", "User-Agent", googleUA)))
+		assert.Equal(t, lookups, resolver.lookups)
+	})
+
+	t.Run("not happy path: the resolver fails", func(t *testing.T) {
+		resolver := &ResolverMock{err: errors.New("timeout")}
+		b := newBotmanagerWafPlugin(ds, resolver)
+		assert.Equal(t, CLASS_UNKNOWN, b.Classify(context.Background(), request("66.249.66.1", "User-Agent", googleUA)))
+		// not cached
+		assert.Equal(t, CLASS_UNKNOWN, b.Classify(context.Background(), request("66.249.66.1", "User-Agent", googleUA)))
+		assert.Equal(t, 2, resolver.lookups)
+	})
+
+	t.Run("not happy path: the scan is cancelled", func(t *testing.T) {
+		resolver := &ResolverMock{
+			ptr:   map[string][]string{"66.249.66.1": {"crawl-66-249-66-1.googlebot.com."}},
+			hosts: map[string][]string{"crawl-66-249-66-1.googlebot.com": {"66.249.66.1"}},
+		}
+		b := newBotmanagerWafPlugin(ds, resolver)
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		assert.Equal(t, CLASS_UNKNOWN, b.Classify(ctx, request("66.249.66.1", "User-Agent", googleUA)))
+		// not cached
+		assert.Equal(t, CLASS_GOODBOT, b.Classify(context.Background(), request("66.249.66.1", "User-Agent", googleUA)))
+	})
+
+	t.Run("happy path: the cache expires", func(t *testing.T) {
+		resolver := &ResolverMock{}
+		b := newBotmanagerWafPlugin(ds, resolver)
+		now := time.Now()
+		b.now = func() time.Time { return now }
+
+		assert.Equal(t, CLASS_BADBOT, b.Classify(context.Background(), request("1.2.3.4", "User-Agent", googleUA)))
+		now = now.Add(DNS_CACHE_TTL)
+		assert.Equal(t, CLASS_BADBOT, b.Classify(context.Background(), request("1.2.3.4", "User-Agent", googleUA)))
+		assert.Equal(t, 2, resolver.lookups)
+	})
+}
+
+func TestScan(t *testing.T) {
+	t.Run("happy path: default actions", func(t *testing.T) {
+		ds := &DbServiceBotmanagerMock{config: map[string]string{}}
+		b := newBotmanagerWafPlugin(ds, &ResolverMock{})
+
+		payload := request("1.2.3.4", "User-Agent", "nikto")
+		assert.False(t, b.Scan(context.Background(), payload))
+		assert.Equal(t, CLASS_BADBOT, payload.Variables[VARIABLE_BOTCLASS])
+
+		payload = request("1.2.3.4", "User-Agent", "curl/8.4.0")
+		assert.True(t, b.Scan(context.Background(), payload))
+		assert.Equal(t, CLASS_UNKNOWN, payload.Variables[VARIABLE_BOTCLASS])
+	})
+
+	t.Run("happy path: configured actions", func(t *testing.T) {
+		ds := &DbServiceBotmanagerMock{config: map[string]string{
+			CONFIG_ACTION_PREFIX + CLASS_BADBOT:  ACTION_ALLOW,
+			CONFIG_ACTION_PREFIX + CLASS_UNKNOWN: ACTION_CHALLENGE,
+			CONFIG_ACTION_PREFIX + CLASS_HUMAN:   "captcha",
+		}}
+		b := newBotmanagerWafPlugin(ds, &ResolverMock{})
+
+		assert.True(t, b.Scan(context.Background(), request("1.2.3.4", "User-Agent", "nikto")))
+		assert.Equal(t, engine.VERDICT_CHALLENGE, b.ScanVerdict(context.Background(), request("1.2.3.4", "User-Agent", "curl/8.4.0")))
+		// a challenge can't be expressed as a boolean
+		assert.False(t, b.Scan(context.Background(), request("1.2.3.4", "User-Agent", "curl/8.4.0")))
+		// bad action: default
+		assert.Equal(t, ACTION_ALLOW, b.actions[CLASS_HUMAN])
+
+		// extra bad agents
+		ds.config[CONFIG_BAD_UA] = "MyScanner, "
+		ds.config[CONFIG_ACTION_PREFIX+CLASS_BADBOT] = ACTION_BLOCK
+		(&configListener{b: b}).NotifyDbChange(CONFIG_BAD_UA)
+		assert.False(t, b.Scan(context.Background(), request("1.2.3.4", "User-Agent", "myscanner/1.0")))
+	})
+}
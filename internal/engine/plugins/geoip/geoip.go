@@ -3,10 +3,12 @@ package geoip
 import (
 	"bytes"
 	"compress/gzip"
+	"context"
 	"fmt"
 	"io"
 	"net"
 	"net/http"
+	"sync"
 	"time"
 
 	"github.com/nzin/taxsi2/internal/com"
@@ -21,7 +23,9 @@ type MaxMindDbReader interface {
 }
 
 type GeoipWafPlugin struct {
-	ds             db.DBServiceGeoip
+	ds db.DBServiceGeoip
+	// guards the allow/deny table (reloaded by the db watcher)
+	mu             sync.RWMutex
 	allowDenyTable map[string]bool
 	allowMode      bool
 	geoipdb        MaxMindDbReader
@@ -169,9 +173,24 @@ func (g *GeoipWafPlugin) Name() string {
 	return "geoip"
 }
 
-func (g *GeoipWafPlugin) Scan(payload *com.TaxsiCom) bool {
+// Interruptible is true: the lookups are in memory
+func (g *GeoipWafPlugin) Interruptible() bool {
+	return true
+}
+
+// IPDependent is true: the country of a client ip is not the one of its network
+func (g *GeoipWafPlugin) IPDependent() bool {
+	return true
+}
+
+func (g *GeoipWafPlugin) Scan(ctx context.Context, payload *com.TaxsiCom) bool {
+	g.mu.RLock()
+	allowDenyTable := g.allowDenyTable
+	allowMode := g.allowMode
+	g.mu.RUnlock()
+
 	// no geoip restriction?
-	if len(g.allowDenyTable) == 0 {
+	if len(allowDenyTable) == 0 {
 		return true
 	}
 
@@ -182,14 +201,14 @@ func (g *GeoipWafPlugin) Scan(payload *com.TaxsiCom) bool {
 		return true
 	}
 
-	if g.allowMode {
+	if allowMode {
 		// allow only
-		if _, found := g.allowDenyTable[record.Country.IsoCode]; !found {
+		if _, found := allowDenyTable[record.Country.IsoCode]; !found {
 			return false
 		}
 	} else {
 		// deny
-		if _, found := g.allowDenyTable[record.Country.IsoCode]; found {
+		if _, found := allowDenyTable[record.Country.IsoCode]; found {
 			return false
 		}
 	}
@@ -197,6 +216,18 @@ func (g *GeoipWafPlugin) Scan(payload *com.TaxsiCom) bool {
 	return true
 }
 
+/*
+Country returns the ISO code of the country of an IP ("" if unknown),
+used by the custom rules
+*/
+func (g *GeoipWafPlugin) Country(ip net.IP) string {
+	var record GeoIP
+	if err := g.geoipdb.Lookup(ip, &record); err != nil {
+		return ""
+	}
+	return record.Country.IsoCode
+}
+
 // NotifyDbChange is called when the database changes
 // we need to reload the allow/deny table
 func (g *GeoipWafPlugin) NotifyDbChange(key string) {
@@ -205,7 +236,6 @@ func (g *GeoipWafPlugin) NotifyDbChange(key string) {
 		return
 	}
 
-	g.allowDenyTable = allowdeny
 	allowMode := true
 
 	// let's read one element to see if we are in an allow
@@ -214,5 +244,10 @@ func (g *GeoipWafPlugin) NotifyDbChange(key string) {
 		allowMode = v
 		break
 	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	// (the table is replaced, never modified: the running scans keep the old one)
+	g.allowDenyTable = allowdeny
 	g.allowMode = allowMode
 }
@@ -2,7 +2,10 @@ package geoip
 
 import (
 	"compress/gzip"
+	"context"
 	"encoding/base64"
+	"fmt"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
@@ -243,7 +246,7 @@ func TestScan(t *testing.T) {
 		url, err := url.Parse("http://www.google.fr")
 		assert.Nil(t, err)
 
-		res := plugin.Scan(&com.TaxsiCom{
+		res := plugin.Scan(context.Background(), &com.TaxsiCom{
 			RemoteAddr: "34.130.155.108",
 			Url:        url,
 			Method:     "GET",
@@ -276,7 +279,7 @@ func TestScan(t *testing.T) {
 		url, err := url.Parse("http://www.google.com")
 		assert.Nil(t, err)
 
-		res := plugin.Scan(&com.TaxsiCom{
+		res := plugin.Scan(context.Background(), &com.TaxsiCom{
 			RemoteAddr: "34.130.155.108",
 			Url:        url,
 			Method:     "GET",
@@ -287,6 +290,32 @@ func TestScan(t *testing.T) {
 	})
 }
 
+/*
+ * This is a mock implementation of the MaxMindDbReader interface
+ */
+type MaxMindDbReaderMock struct {
+	countries map[string]string
+}
+
+func (m *MaxMindDbReaderMock) Lookup(ip net.IP, result any) error {
+	country, ok := m.countries[ip.String()]
+	if !ok {
+		return fmt.Errorf("not found")
+	}
+	result.(*GeoIP).Country.IsoCode = country
+	return nil
+}
+
+func TestCountry(t *testing.T) {
+	t.Run("happy path: country of an ip", func(t *testing.T) {
+		plugin := &GeoipWafPlugin{
+			geoipdb: &MaxMindDbReaderMock{countries: map[string]string{"34.130.155.108": "CA"}},
+		}
+		assert.Equal(t, "CA", plugin.Country(net.ParseIP("34.130.155.108")))
+		assert.Equal(t, "", plugin.Country(net.ParseIP("127.0.0.1")))
+	})
+}
+
 func TestNotification(t *testing.T) {
 	t.Run("happy path: receive notification db change", func(t *testing.T) {
 		data, err := base64.StdEncoding.DecodeString(base64MmdContent)
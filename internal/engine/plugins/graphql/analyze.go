@@ -0,0 +1,340 @@
+package graphql
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/nzin/taxsi2/internal/com"
+	"github.com/nzin/taxsi2/internal/engine/plugins/sqli"
+	"github.com/nzin/taxsi2/internal/engine/plugins/xss"
+)
+
+// the kinds of violations
+const (
+	VIOLATION_SYNTAX        = "syntax"
+	VIOLATION_DEPTH         = "depth"
+	VIOLATION_ALIASES       = "aliases"
+	VIOLATION_COST          = "cost"
+	VIOLATION_BATCH         = "batch"
+	VIOLATION_INTROSPECTION = "introspection"
+	VIOLATION_INJECTION     = "injection"
+
+	// the cost is not computed beyond (to not overflow)
+	MAX_COST_VALUE = 1 << 40
+)
+
+// the arguments that multiply the cost of the sub selections
+var paginationArguments = []string{"first", "last", "limit", "pageSize", "perPage"}
+
+/*
+Violation is why a GraphQL request is rejected
+*/
+type Violation struct {
+	Kind   string
+	Detail string
+}
+
+func (v *Violation) Error() string {
+	return fmt.Sprintf("%s: %s", v.Kind, v.Detail)
+}
+
+/*
+Limits are the limits of a GraphQL request (0 means no limit)
+*/
+type Limits struct {
+	MaxDepth      int
+	MaxAliases    int
+	Introspection bool
+}
+
+type analyzer struct {
+	doc       *Document
+	variables map[string]interface{}
+	// the fragments depth and cost (they don't depend on where they are used)
+	depths   map[string]int
+	costs    map[string]int
+	visiting map[string]bool
+}
+
+/*
+Analyze checks a document against the limits, and returns its cost
+(the cost limit is checked by the caller, it applies to a whole batch)
+*/
+func Analyze(doc *Document, variables map[string]interface{}, limits Limits) (int, *Violation) {
+	a := &analyzer{
+		doc:       doc,
+		variables: variables,
+		depths:    make(map[string]int),
+		costs:     make(map[string]int),
+		visiting:  make(map[string]bool),
+	}
+
+	// each definition is walked once, the fragments are checked even if they are not used
+	roots := [][]*Selection{}
+	for _, op := range doc.Operations {
+		roots = append(roots, op.Selections)
+	}
+	for _, name := range a.fragmentNames() {
+		roots = append(roots, doc.Fragments[name].Selections)
+	}
+	aliases := 0
+	for _, selections := range roots {
+		var v *Violation
+		walk(selections, func(s *Selection) {
+			if s.Alias != "" {
+				aliases++
+			}
+			if !limits.Introspection && (s.Name == "__schema" || s.Name == "__type") && v == nil {
+				v = &Violation{Kind: VIOLATION_INTROSPECTION, Detail: fmt.Sprintf("%s is not allowed", s.Name)}
+			}
+		})
+		if v != nil {
+			return 0, v
+		}
+	}
+	if limits.MaxAliases > 0 && aliases > limits.MaxAliases {
+		return 0, &Violation{Kind: VIOLATION_ALIASES, Detail: fmt.Sprintf("%d aliases (max %d)", aliases, limits.MaxAliases)}
+	}
+
+	cost := 0
+	for _, op := range doc.Operations {
+		depth, err := a.depth(op.Selections)
+		if err != nil {
+			return 0, err
+		}
+		if limits.MaxDepth > 0 && depth > limits.MaxDepth {
+			return 0, &Violation{Kind: VIOLATION_DEPTH, Detail: fmt.Sprintf("depth %d (max %d)", depth, limits.MaxDepth)}
+		}
+		c, err := a.cost(op.Selections)
+		if err != nil {
+			return 0, err
+		}
+		cost = saturate(cost + c)
+	}
+	return cost, nil
+}
+
+func (a *analyzer) fragmentNames() []string {
+	names := []string{}
+	for name := range a.doc.Fragments {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+/*
+walk calls f on every selection, without following the fragment spreads
+*/
+func walk(selections []*Selection, f func(*Selection)) {
+	for _, s := range selections {
+		f(s)
+		walk(s.Selections, f)
+	}
+}
+
+/*
+fragment returns a fragment, detecting the unknown fragments and the cycles
+(the caller has to call done once the fragment is analyzed)
+*/
+func (a *analyzer) fragment(name string) (*Fragment, *Violation) {
+	f, ok := a.doc.Fragments[name]
+	if !ok {
+		return nil, &Violation{Kind: VIOLATION_SYNTAX, Detail: fmt.Sprintf("unknown fragment %s", name)}
+	}
+	if a.visiting[name] {
+		return nil, &Violation{Kind: VIOLATION_SYNTAX, Detail: fmt.Sprintf("fragment cycle through %s", name)}
+	}
+	a.visiting[name] = true
+	return f, nil
+}
+
+func (a *analyzer) done(name string) {
+	delete(a.visiting, name)
+}
+
+/*
+depth returns the number of nested fields (a single field is 1)
+*/
+func (a *analyzer) depth(selections []*Selection) (int, *Violation) {
+	max := 0
+	for _, s := range selections {
+		d := 0
+		switch {
+		case s.Spread != "":
+			if cached, ok := a.depths[s.Spread]; ok {
+				d = cached
+				break
+			}
+			f, err := a.fragment(s.Spread)
+			if err != nil {
+				return 0, err
+			}
+			if d, err = a.depth(f.Selections); err != nil {
+				return 0, err
+			}
+			a.done(s.Spread)
+			a.depths[s.Spread] = d
+		case s.Name == "":
+			var err *Violation
+			if d, err = a.depth(s.Selections); err != nil {
+				return 0, err
+			}
+		default:
+			sub, err := a.depth(s.Selections)
+			if err != nil {
+				return 0, err
+			}
+			d = 1 + sub
+		}
+		if d > max {
+			max = d
+		}
+	}
+	return max, nil
+}
+
+/*
+cost returns the number of fields that can be resolved: each field
+costs 1, plus the cost of its sub selections multiplied by its page size
+*/
+func (a *analyzer) cost(selections []*Selection) (int, *Violation) {
+	total := 0
+	for _, s := range selections {
+		c := 0
+		switch {
+		case s.Spread != "":
+			if cached, ok := a.costs[s.Spread]; ok {
+				c = cached
+				break
+			}
+			f, err := a.fragment(s.Spread)
+			if err != nil {
+				return 0, err
+			}
+			if c, err = a.cost(f.Selections); err != nil {
+				return 0, err
+			}
+			a.done(s.Spread)
+			a.costs[s.Spread] = c
+		case s.Name == "":
+			var err *Violation
+			if c, err = a.cost(s.Selections); err != nil {
+				return 0, err
+			}
+		default:
+			sub, err := a.cost(s.Selections)
+			if err != nil {
+				return 0, err
+			}
+			c = saturate(1 + multiply(a.multiplier(s), sub))
+		}
+		total = saturate(total + c)
+	}
+	return total, nil
+}
+
+/*
+multiplier returns the page size asked for a field (1 if none)
+*/
+func (a *analyzer) multiplier(s *Selection) int {
+	for _, name := range paginationArguments {
+		value, ok := s.Arguments[name]
+		if !ok {
+			continue
+		}
+		if v, ok := value.(Variable); ok {
+			value = a.variables[string(v)]
+		}
+		n := float64(1)
+		switch v := value.(type) {
+		case int64:
+			n = float64(v)
+		case float64:
+			n = v
+		}
+		if n > MAX_COST_VALUE {
+			return MAX_COST_VALUE
+		}
+		if n > 1 {
+			return int(n)
+		}
+	}
+	return 1
+}
+
+func multiply(a int, b int) int {
+	if b != 0 && a > MAX_COST_VALUE/b {
+		return MAX_COST_VALUE
+	}
+	return a * b
+}
+
+func saturate(n int) int {
+	if n > MAX_COST_VALUE || n < 0 {
+		return MAX_COST_VALUE
+	}
+	return n
+}
+
+/*
+Injection applies the SQL injection and XSS checks to the variables
+and to the string arguments of a document
+*/
+func Injection(doc *Document, variables map[string]interface{}) *Violation {
+	inputs := map[string]string{}
+	for name, value := range variables {
+		collectStrings(value, "$"+name, inputs)
+	}
+	if doc != nil {
+		roots := [][]*Selection{}
+		for _, op := range doc.Operations {
+			roots = append(roots, op.Selections)
+		}
+		for _, f := range doc.Fragments {
+			roots = append(roots, f.Selections)
+		}
+		for _, selections := range roots {
+			walk(selections, func(s *Selection) {
+				for name, value := range s.Arguments {
+					collectStrings(value, s.Name+"."+name, inputs)
+				}
+			})
+		}
+	}
+
+	names := []string{}
+	for name := range inputs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		for _, variant := range com.Decode(inputs[name]) {
+			if fp, ok := sqli.IsSQLi(variant); ok {
+				return &Violation{Kind: VIOLATION_INJECTION, Detail: fmt.Sprintf("sqli in %s (%s)", name, fp)}
+			}
+		}
+		if snippet, ok := xss.IsXSS(inputs[name]); ok {
+			return &Violation{Kind: VIOLATION_INJECTION, Detail: fmt.Sprintf("xss in %s (%s)", name, snippet)}
+		}
+	}
+	return nil
+}
+
+/*
+collectStrings collects the strings of a value, by path (like $user.emails[0])
+*/
+func collectStrings(value interface{}, path string, inputs map[string]string) {
+	switch v := value.(type) {
+	case string:
+		inputs[path] = v
+	case []interface{}:
+		for i, item := range v {
+			collectStrings(item, fmt.Sprintf("%s[%d]", path, i), inputs)
+		}
+	case map[string]interface{}:
+		for k, item := range v {
+			collectStrings(item, path+"."+k, inputs)
+		}
+	}
+}
@@ -0,0 +1,265 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/nzin/taxsi2/internal/com"
+	"github.com/nzin/taxsi2/internal/db"
+	"github.com/nzin/taxsi2/internal/engine"
+)
+
+const (
+	// GlobalConfig keys
+	// comma separated list of the GraphQL endpoints
+	CONFIG_PATHS       = "graphql_paths"
+	CONFIG_MAX_DEPTH   = "graphql_max_depth"
+	CONFIG_MAX_ALIASES = "graphql_max_aliases"
+	CONFIG_MAX_COST    = "graphql_max_cost"
+	// maximum number of operations in a batch (a JSON array)
+	CONFIG_MAX_BATCH = "graphql_max_batch"
+	// allow or deny
+	CONFIG_INTROSPECTION = "graphql_introspection"
+
+	DEFAULT_PATHS         = "/graphql"
+	DEFAULT_MAX_DEPTH     = 10
+	DEFAULT_MAX_ALIASES   = 15
+	DEFAULT_MAX_COST      = 1000
+	DEFAULT_MAX_BATCH     = 5
+	DEFAULT_INTROSPECTION = "deny"
+
+	// output variables
+	// the kind of violation (depth, aliases, cost, batch, introspection, injection, syntax)
+	VARIABLE_VIOLATION = "graphql_violation"
+	// what was found (like "depth 12 (max 10)")
+	VARIABLE_DETAIL = "graphql_detail"
+)
+
+/*
+GraphqlWafPlugin inspects the GraphQL documents sent to the GraphQL
+endpoints (in the POST bodies and in the GET query params)
+*/
+type GraphqlWafPlugin struct {
+	ds       db.DbServiceGraphql
+	mu       sync.RWMutex
+	paths    []string
+	maxBatch int
+	maxCost  int
+	limits   Limits
+}
+
+func NewGraphqlWafPlugin(ds db.DbServiceGraphql) engine.WafEnginePlugin {
+	return newGraphqlWafPlugin(ds)
+}
+
+func newGraphqlWafPlugin(ds db.DbServiceGraphql) *GraphqlWafPlugin {
+	g := &GraphqlWafPlugin{
+		ds: ds,
+	}
+	g.loadConfig()
+
+	ds.SubscribeChanges(db.CHANGELOG_TABLE_CONFIG, &configListener{g: g})
+	return g
+}
+
+func (g *GraphqlWafPlugin) Name() string {
+	return "graphql"
+}
+
+/*
+ValidateConfig returns why a value can't be used for a graphql key
+(nil if it can, or if the key is not a graphql key)
+*/
+func ValidateConfig(key string, value string) error {
+	switch key {
+	case CONFIG_MAX_DEPTH, CONFIG_MAX_ALIASES, CONFIG_MAX_COST, CONFIG_MAX_BATCH:
+		_, err := parseLimit(key, value)
+		return err
+	case CONFIG_INTROSPECTION:
+		if value != "allow" && value != "deny" {
+			return fmt.Errorf("bad %s %s (must be allow or deny)", key, value)
+		}
+	}
+	return nil
+}
+
+func parseLimit(key string, value string) (int, error) {
+	n, err := strconv.Atoi(value)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("bad %s %s (must be a positive integer)", key, value)
+	}
+	return n, nil
+}
+
+func (g *GraphqlWafPlugin) configInt(key string, defaultValue int) int {
+	if v, err := g.ds.GetConfigValueForKey(key); err == nil {
+		if n, err := parseLimit(key, v); err == nil {
+			return n
+		}
+	}
+	return defaultValue
+}
+
+func (g *GraphqlWafPlugin) loadConfig() {
+	paths := []string{}
+	value := DEFAULT_PATHS
+	if v, err := g.ds.GetConfigValueForKey(CONFIG_PATHS); err == nil {
+		value = v
+	}
+	for _, p := range strings.Split(value, ",") {
+		if p = strings.TrimRight(strings.TrimSpace(p), "/"); p != "" {
+			paths = append(paths, p)
+		}
+	}
+	introspection := DEFAULT_INTROSPECTION
+	if v, err := g.ds.GetConfigValueForKey(CONFIG_INTROSPECTION); err == nil {
+		introspection = v
+	}
+	limits := Limits{
+		MaxDepth:      g.configInt(CONFIG_MAX_DEPTH, DEFAULT_MAX_DEPTH),
+		MaxAliases:    g.configInt(CONFIG_MAX_ALIASES, DEFAULT_MAX_ALIASES),
+		Introspection: introspection == "allow",
+	}
+	maxBatch := g.configInt(CONFIG_MAX_BATCH, DEFAULT_MAX_BATCH)
+	maxCost := g.configInt(CONFIG_MAX_COST, DEFAULT_MAX_COST)
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.paths = paths
+	g.limits = limits
+	g.maxBatch = maxBatch
+	g.maxCost = maxCost
+}
+
+// configListener reloads the limits when the GlobalConfig changes
+type configListener struct {
+	g *GraphqlWafPlugin
+}
+
+func (l *configListener) NotifyDbChange(key string) {
+	if strings.HasPrefix(key, "graphql_") {
+		l.g.loadConfig()
+	}
+}
+
+func (g *GraphqlWafPlugin) Scan(ctx context.Context, payload *com.TaxsiCom) bool {
+	if v := g.Check(payload); v != nil {
+		payload.SetVariable(VARIABLE_VIOLATION, v.Kind)
+		payload.SetVariable(VARIABLE_DETAIL, v.Detail)
+		return false
+	}
+	return true
+}
+
+/*
+request is a GraphQL request, as sent by the clients
+*/
+type request struct {
+	Query     string                 `json:"query"`
+	Variables map[string]interface{} `json:"variables"`
+}
+
+/*
+Check returns why a request to a GraphQL endpoint is rejected (nil if it's not)
+*/
+func (g *GraphqlWafPlugin) Check(payload *com.TaxsiCom) *Violation {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	parsed := payload.Parse()
+	if !g.isEndpoint(parsed.Path) {
+		return nil
+	}
+	requests, v := extractRequests(payload, parsed)
+	if v != nil {
+		return v
+	}
+	if len(requests) > g.maxBatch {
+		return &Violation{Kind: VIOLATION_BATCH, Detail: fmt.Sprintf("%d operations (max %d)", len(requests), g.maxBatch)}
+	}
+
+	// the limits apply to the whole batch
+	cost := 0
+	for _, r := range requests {
+		var doc *Document
+		// (a persisted query can be sent without its document)
+		if r.Query != "" {
+			var err error
+			if doc, err = Parse(r.Query); err != nil {
+				return &Violation{Kind: VIOLATION_SYNTAX, Detail: err.Error()}
+			}
+			c, v := Analyze(doc, r.Variables, g.limits)
+			if v != nil {
+				return v
+			}
+			cost = saturate(cost + c)
+		}
+		if v := Injection(doc, r.Variables); v != nil {
+			return v
+		}
+	}
+	if cost > g.maxCost {
+		return &Violation{Kind: VIOLATION_COST, Detail: fmt.Sprintf("cost %d (max %d)", cost, g.maxCost)}
+	}
+	return nil
+}
+
+func (g *GraphqlWafPlugin) isEndpoint(path string) bool {
+	for _, p := range g.paths {
+		if path == p || strings.HasPrefix(path, p+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+/*
+extractRequests extracts the GraphQL requests of a payload: the query params
+(query and variables), a JSON body (an object, or an array for a batch)
+or an application/graphql body
+*/
+func extractRequests(payload *com.TaxsiCom, parsed *com.ParsedRequest) ([]request, *Violation) {
+	requests := []request{}
+
+	if payload.Url != nil {
+		query, _ := url.ParseQuery(payload.Url.RawQuery)
+		if query.Has("query") || query.Has("variables") {
+			r := request{Query: query.Get("query")}
+			if v := query.Get("variables"); v != "" {
+				if err := json.Unmarshal([]byte(v), &r.Variables); err != nil {
+					return nil, &Violation{Kind: VIOLATION_SYNTAX, Detail: "invalid variables"}
+				}
+			}
+			requests = append(requests, r)
+		}
+	}
+
+	body := strings.TrimSpace(string(payload.Body))
+	if body == "" {
+		return requests, nil
+	}
+	switch {
+	case parsed.ContentType == "application/graphql":
+		requests = append(requests, request{Query: body})
+	case parsed.ContentType == "application/json" || strings.HasSuffix(parsed.ContentType, "+json"):
+		if strings.HasPrefix(body, "[") {
+			batch := []request{}
+			if err := json.Unmarshal([]byte(body), &batch); err != nil {
+				return nil, &Violation{Kind: VIOLATION_SYNTAX, Detail: "invalid JSON batch"}
+			}
+			requests = append(requests, batch...)
+		} else {
+			r := request{}
+			if err := json.Unmarshal([]byte(body), &r); err != nil {
+				return nil, &Violation{Kind: VIOLATION_SYNTAX, Detail: "invalid JSON request"}
+			}
+			requests = append(requests, r)
+		}
+	}
+	return requests, nil
+}
@@ -0,0 +1,231 @@
+package graphql
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/nzin/taxsi2/internal/com"
+	"github.com/nzin/taxsi2/internal/db"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+/*
+ * This is a mock implementation of the db.DbServiceGraphql interface
+ */
+type DbServiceGraphqlMock struct {
+	config    map[string]string
+	listeners []db.DbChangeListener
+}
+
+func (m *DbServiceGraphqlMock) SubscribeChanges(table int, listener db.DbChangeListener) {
+	m.listeners = append(m.listeners, listener)
+}
+func (m *DbServiceGraphqlMock) GetConfigValueForKey(key string) (string, error) {
+	value, ok := m.config[key]
+	if !ok {
+		return "", gorm.ErrRecordNotFound
+	}
+	return value, nil
+}
+
+func post(path string, contentType string, body string) *com.TaxsiCom {
+	u, _ := url.Parse("http://api.example.com" + path)
+	return &com.TaxsiCom{
+		Method:  "POST",
+		Url:     u,
+		Headers: map[string][]string{"Content-Type": {contentType}},
+		Body:    []byte(body),
+	}
+}
+
+func get(rawurl string) *com.TaxsiCom {
+	u, _ := url.Parse(rawurl)
+	return &com.TaxsiCom{
+		Method:  "GET",
+		Url:     u,
+		Headers: map[string][]string{},
+	}
+}
+
+func TestParse(t *testing.T) {
+	t.Run("happy path: operations, fragments and arguments", func(t *testing.T) {
+		doc, err := Parse(`
+			# a comment
+			query Users($first: Int = 10, $filter: [String!]!) @cached {
+				list: users(first: $first, filter: {name: "bob", tags: ["a", "b"]}, order: ASC) {
+					id, ...userFields
+					... on Admin @include(if: true) { rights }
+				}
+			}
+			fragment userFields on User { name email }
+			mutation { logout }
+			{ me { id } }
+		`)
+		assert.Nil(t, err)
+		assert.Equal(t, 3, len(doc.Operations))
+		assert.Equal(t, "Users", doc.Operations[0].Name)
+		assert.Equal(t, "mutation", doc.Operations[1].Type)
+		assert.Equal(t, "query", doc.Operations[2].Type)
+
+		users := doc.Operations[0].Selections[0]
+		assert.Equal(t, "list", users.Alias)
+		assert.Equal(t, "users", users.Name)
+		assert.Equal(t, Variable("first"), users.Arguments["first"])
+		assert.Equal(t, "ASC", users.Arguments["order"])
+		assert.Equal(t, map[string]interface{}{"name": "bob", "tags": []interface{}{"a", "b"}}, users.Arguments["filter"])
+		assert.Equal(t, 3, len(users.Selections))
+		assert.Equal(t, "userFields", users.Selections[1].Spread)
+		assert.Equal(t, "", users.Selections[2].Name)
+		assert.Equal(t, 2, len(doc.Fragments["userFields"].Selections))
+	})
+
+	t.Run("happy path: strings", func(t *testing.T) {
+		doc, err := Parse(`{ a(s: "x\"yA", b: """multi
+"line" \""" """) }`)
+		assert.Nil(t, err)
+		assert.Equal(t, `x"yA`, doc.Operations[0].Selections[0].Arguments["s"])
+		assert.Equal(t, "multi\n\"line\" \"\"\" ", doc.Operations[0].Selections[0].Arguments["b"])
+	})
+
+	t.Run("not happy path: invalid documents", func(t *testing.T) {
+		for _, query := range []string{
+			"",
+			"{",
+			"{ }",
+			"{ a(b: ) }",
+			`{ a(b: "unterminated) }`,
+			"query ($a: ) { a }",
+			"fragment on on User { a }",
+			"fragment f on User { a } fragment f on User { b } { a }",
+			"fragment f on User { a }",
+			"{ a } }",
+			"{ a.b }",
+			"type User { id: ID }",
+			strings.Repeat("{ a ", 200) + strings.Repeat("}", 200),
+			"{ a(b: " + strings.Repeat("[", 200) + strings.Repeat("]", 200) + ") }",
+		} {
+			_, err := Parse(query)
+			assert.NotNil(t, err, query)
+		}
+	})
+}
+
+func TestAnalyze(t *testing.T) {
+	limits := Limits{MaxDepth: 3, MaxAliases: 2}
+
+	analyze := func(query string, variables map[string]interface{}, limits Limits) (int, *Violation) {
+		doc, err := Parse(query)
+		assert.Nil(t, err)
+		return Analyze(doc, variables, limits)
+	}
+
+	t.Run("happy path: depth and cost", func(t *testing.T) {
+		cost, v := analyze("{ a { b { c } } d }", nil, limits)
+		assert.Nil(t, v)
+		assert.Equal(t, 4, cost)
+
+		// the page size multiplies the cost of the sub selections
+		cost, v = analyze("query ($n: Int) { users(first: 10) { id name } posts(last: $n) { id } }", map[string]interface{}{"n": float64(5)}, limits)
+		assert.Nil(t, v)
+		assert.Equal(t, 1+10*2+1+5, cost)
+
+		// the fragments are counted where they are used
+		cost, v = analyze("{ a { ...f } b { ...f } } fragment f on T { x { y } }", nil, limits)
+		assert.Nil(t, v)
+		assert.Equal(t, 2*(1+2), cost)
+
+		_, v = analyze("{ __typename a { id } }", nil, limits)
+		assert.Nil(t, v)
+	})
+
+	t.Run("not happy path: limits", func(t *testing.T) {
+		_, v := analyze("{ a { b { c { d } } } }", nil, limits)
+		assert.Equal(t, VIOLATION_DEPTH, v.Kind)
+		assert.Equal(t, "depth 4 (max 3)", v.Detail)
+
+		// through fragments
+		_, v = analyze("{ a { ...f } } fragment f on T { b { ... on T { c { d } } } }", nil, limits)
+		assert.Equal(t, VIOLATION_DEPTH, v.Kind)
+
+		_, v = analyze("{ a: me { id } b: me { id } c: me { id } }", nil, limits)
+		assert.Equal(t, VIOLATION_ALIASES, v.Kind)
+
+		_, v = analyze("{ __schema { types { name } } }", nil, limits)
+		assert.Equal(t, VIOLATION_INTROSPECTION, v.Kind)
+		_, v = analyze("{ __schema { types { name } } }", nil, Limits{Introspection: true})
+		assert.Nil(t, v)
+
+		_, v = analyze("{ a { ...f } } fragment f on T { b { ...g } } fragment g on T { ...f }", nil, limits)
+		assert.Equal(t, VIOLATION_SYNTAX, v.Kind)
+		assert.Contains(t, v.Detail, "cycle")
+
+		_, v = analyze("{ a { ...missing } }", nil, limits)
+		assert.Equal(t, VIOLATION_SYNTAX, v.Kind)
+
+		// the cost doesn't overflow
+		cost, v := analyze("{ a(first: 1e300) { b(first: 1e300) { c(first: 1e300) } } }", nil, limits)
+		assert.Nil(t, v)
+		assert.Equal(t, MAX_COST_VALUE, cost)
+	})
+}
+
+func TestGraphqlPlugin(t *testing.T) {
+	ds := &DbServiceGraphqlMock{config: map[string]string{}}
+	plugin := newGraphqlWafPlugin(ds)
+
+	check := func(t *testing.T, payload *com.TaxsiCom, kind string) {
+		assert.False(t, plugin.Scan(context.Background(), payload))
+		assert.Equal(t, kind, payload.Variables[VARIABLE_VIOLATION])
+		assert.NotEqual(t, "", payload.Variables[VARIABLE_DETAIL])
+	}
+
+	t.Run("happy path: legit requests", func(t *testing.T) {
+		assert.True(t, plugin.Scan(context.Background(), post("/graphql", "application/json",
+			`{"query": "query ($id: ID!) { user(id: $id) { name friends(first: 10) { name } } }", "variables": {"id": "42"}}`)))
+		assert.True(t, plugin.Scan(context.Background(), post("/graphql", "application/graphql", `{ me { name } }`)))
+		assert.True(t, plugin.Scan(context.Background(), post("/graphql", "application/json",
+			`[{"query": "{ me { name } }"}, {"query": "{ posts(first: 5) { title } }"}]`)))
+		assert.True(t, plugin.Scan(context.Background(), get(`http://api.example.com/graphql?query=`+url.QueryEscape(`{ me { name } }`))))
+		// a persisted query
+		assert.True(t, plugin.Scan(context.Background(), post("/graphql", "application/json",
+			`{"extensions": {"persistedQuery": {"sha256Hash": "abc"}}, "variables": {"id": "42"}}`)))
+
+		// not a GraphQL endpoint
+		assert.True(t, plugin.Scan(context.Background(), post("/api/users", "application/json", `{"query": "{ __schema { types { name } } }"}`)))
+	})
+
+	t.Run("not happy path: violations", func(t *testing.T) {
+		check(t, post("/graphql", "application/json", `{"query": "{ a { b { c { d { e { f { g { h { i { j { k } } } } } } } } } } }"}`), VIOLATION_DEPTH)
+		check(t, post("/graphql/v2", "application/json", `{"query": "{ __schema { types { name } } }"}`), VIOLATION_INTROSPECTION)
+		check(t, get(`http://api.example.com/graphql?query=`+url.QueryEscape(`{ __type(name: "User") { name } }`)), VIOLATION_INTROSPECTION)
+		check(t, post("/graphql", "application/json", `{"query": "{ users(first: 100) { friends(first: 100) { name } } }"}`), VIOLATION_COST)
+		check(t, post("/graphql", "application/json", `[{"query": "{ a }"}, {"query": "{ a }"}, {"query": "{ a }"}, {"query": "{ a }"}, {"query": "{ a }"}, {"query": "{ a }"}]`), VIOLATION_BATCH)
+		check(t, post("/graphql", "application/json", `{"query": "{ `+strings.Repeat("a: me ", 20)+`}"}`), VIOLATION_ALIASES)
+		check(t, post("/graphql", "application/json", `{"query": "{ me "}`), VIOLATION_SYNTAX)
+		check(t, post("/graphql", "application/json", `{"query": `), VIOLATION_SYNTAX)
+
+		// injections in the variables, and in the arguments
+		check(t, post("/graphql", "application/json",
+			`{"query": "query ($id: ID!) { user(id: $id) { name } }", "variables": {"id": "1' OR '1'='1"}}`), VIOLATION_INJECTION)
+		check(t, post("/graphql", "application/json",
+			`{"query": "mutation ($p: Profile) { update(profile: $p) { id } }", "variables": {"p": {"bio": ["<script>alert(1)</script>"]}}}`), VIOLATION_INJECTION)
+		check(t, get(`http://api.example.com/graphql?query=`+url.QueryEscape(`{ user(name: "x' union select password from users--") { id } }`)), VIOLATION_INJECTION)
+	})
+
+	t.Run("happy path: the config is reloaded", func(t *testing.T) {
+		introspection := post("/graphql", "application/json", `{"query": "{ __schema { types { name } } }"}`)
+		assert.False(t, plugin.Scan(context.Background(), introspection))
+
+		ds.config[CONFIG_INTROSPECTION] = "allow"
+		ds.config[CONFIG_PATHS] = "/gql, /api/graphql/"
+		for _, l := range ds.listeners {
+			l.NotifyDbChange(CONFIG_INTROSPECTION)
+		}
+		assert.True(t, plugin.Scan(context.Background(), post("/graphql", "application/json", `{"query": "{ __schema { types { name } } }"}`)))
+		assert.True(t, plugin.Scan(context.Background(), post("/api/graphql", "application/json", `{"query": "{ __schema { types { name } } }"}`)))
+		check(t, post("/gql", "application/json", `{"query": "{ me "}`), VIOLATION_SYNTAX)
+	})
+}
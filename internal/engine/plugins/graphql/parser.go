@@ -0,0 +1,552 @@
+package graphql
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	// the maximum nesting of the selection sets and values while parsing
+	// (deeper documents are rejected before any other check)
+	MAX_PARSE_DEPTH = 128
+	// the maximum number of tokens of a document
+	MAX_TOKENS = 50000
+)
+
+/*
+Document is a parsed GraphQL executable document (the type
+system definitions are not supported, a gateway doesn't accept them)
+*/
+type Document struct {
+	Operations []*Operation
+	Fragments  map[string]*Fragment
+}
+
+type Operation struct {
+	// query, mutation or subscription
+	Type       string
+	Name       string
+	Selections []*Selection
+}
+
+type Fragment struct {
+	Name       string
+	Selections []*Selection
+}
+
+/*
+Selection is a field, a fragment spread (Spread is set)
+or an inline fragment (no Name and no Spread)
+*/
+type Selection struct {
+	Alias     string
+	Name      string
+	Spread    string
+	Arguments map[string]interface{}
+	// the sub selections (empty for the leaf fields and the spreads)
+	Selections []*Selection
+}
+
+/*
+Variable is a reference to a variable, used as an argument value
+*/
+type Variable string
+
+type token struct {
+	kind  byte
+	value string
+}
+
+// token kinds (the punctuators are their own kind)
+const (
+	TOKEN_NAME   = 'n'
+	TOKEN_INT    = 'i'
+	TOKEN_FLOAT  = 'f'
+	TOKEN_STRING = 's'
+	TOKEN_SPREAD = '.'
+	TOKEN_EOF    = 0
+)
+
+func isNameStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+/*
+tokenize splits a document in tokens (the whitespaces, commas
+and comments are ignored)
+*/
+func tokenize(s string) ([]token, error) {
+	tokens := []token{}
+	s = strings.TrimPrefix(s, "\ufeff")
+	for i := 0; i < len(s); {
+		if len(tokens) > MAX_TOKENS {
+			return nil, fmt.Errorf("more than %d tokens", MAX_TOKENS)
+		}
+		c := s[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == ',':
+			i++
+		case c == '#':
+			for i < len(s) && s[i] != '\n' && s[i] != '\r' {
+				i++
+			}
+		case strings.IndexByte("!$&()=:@[]{}|", c) >= 0:
+			tokens = append(tokens, token{kind: c, value: string(c)})
+			i++
+		case c == '.':
+			if !strings.HasPrefix(s[i:], "...") {
+				return nil, fmt.Errorf("unexpected . at %d", i)
+			}
+			tokens = append(tokens, token{kind: TOKEN_SPREAD, value: "..."})
+			i += 3
+		case isNameStart(c):
+			j := i + 1
+			for j < len(s) && (isNameStart(s[j]) || isDigit(s[j])) {
+				j++
+			}
+			tokens = append(tokens, token{kind: TOKEN_NAME, value: s[i:j]})
+			i = j
+		case c == '-' || isDigit(c):
+			j := i + 1
+			kind := byte(TOKEN_INT)
+			for j < len(s) && (isDigit(s[j]) || strings.IndexByte(".eE+-", s[j]) >= 0) {
+				if !isDigit(s[j]) {
+					kind = TOKEN_FLOAT
+				}
+				j++
+			}
+			tokens = append(tokens, token{kind: kind, value: s[i:j]})
+			i = j
+		case c == '"':
+			value, end, err := readString(s, i)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, token{kind: TOKEN_STRING, value: value})
+			i = end
+		default:
+			return nil, fmt.Errorf("unexpected character %q at %d", c, i)
+		}
+	}
+	return tokens, nil
+}
+
+/*
+readString reads a "string" or a """block string""" starting at i,
+and returns its value and the position after it
+*/
+func readString(s string, i int) (string, int, error) {
+	if strings.HasPrefix(s[i:], `"""`) {
+		for j := i + 3; j < len(s); j++ {
+			if strings.HasPrefix(s[j:], `\"""`) {
+				j += 3
+				continue
+			}
+			if strings.HasPrefix(s[j:], `"""`) {
+				return strings.ReplaceAll(s[i+3:j], `\"""`, `"""`), j + 3, nil
+			}
+		}
+		return "", 0, fmt.Errorf("unterminated block string at %d", i)
+	}
+
+	for j := i + 1; j < len(s); j++ {
+		switch s[j] {
+		case '\\':
+			j++
+		case '\n', '\r':
+			return "", 0, fmt.Errorf("unterminated string at %d", i)
+		case '"':
+			// the GraphQL escapes are the JSON ones
+			value, err := strconv.Unquote(s[i : j+1])
+			if err != nil {
+				value = s[i+1 : j]
+			}
+			return value, j + 1, nil
+		}
+	}
+	return "", 0, fmt.Errorf("unterminated string at %d", i)
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+	depth  int
+}
+
+/*
+Parse parses a GraphQL executable document
+*/
+func Parse(query string) (*Document, error) {
+	tokens, err := tokenize(query)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens}
+	doc := &Document{
+		Operations: []*Operation{},
+		Fragments:  make(map[string]*Fragment),
+	}
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("empty document")
+	}
+	for p.peek().kind != TOKEN_EOF {
+		t := p.peek()
+		switch {
+		case t.kind == '{':
+			selections, err := p.selectionSet()
+			if err != nil {
+				return nil, err
+			}
+			doc.Operations = append(doc.Operations, &Operation{Type: "query", Selections: selections})
+		case t.kind == TOKEN_NAME && (t.value == "query" || t.value == "mutation" || t.value == "subscription"):
+			op, err := p.operation()
+			if err != nil {
+				return nil, err
+			}
+			doc.Operations = append(doc.Operations, op)
+		case t.kind == TOKEN_NAME && t.value == "fragment":
+			f, err := p.fragment()
+			if err != nil {
+				return nil, err
+			}
+			if _, ok := doc.Fragments[f.Name]; ok {
+				return nil, fmt.Errorf("duplicate fragment %s", f.Name)
+			}
+			doc.Fragments[f.Name] = f
+		default:
+			return nil, fmt.Errorf("unexpected %q", t.value)
+		}
+	}
+	if len(doc.Operations) == 0 {
+		return nil, fmt.Errorf("no operation")
+	}
+	return doc, nil
+}
+
+func (p *parser) peek() token {
+	if p.pos >= len(p.tokens) {
+		return token{kind: TOKEN_EOF, value: "end of document"}
+	}
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.peek()
+	if t.kind != TOKEN_EOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *parser) expect(kind byte) (token, error) {
+	t := p.next()
+	if t.kind != kind {
+		return t, fmt.Errorf("unexpected %q", t.value)
+	}
+	return t, nil
+}
+
+func (p *parser) name() (string, error) {
+	t, err := p.expect(TOKEN_NAME)
+	return t.value, err
+}
+
+/*
+enter and leave track the nesting, to stop the too deep documents
+before they exhaust the stack
+*/
+func (p *parser) enter() error {
+	p.depth++
+	if p.depth > MAX_PARSE_DEPTH {
+		return fmt.Errorf("nested more than %d times", MAX_PARSE_DEPTH)
+	}
+	return nil
+}
+
+func (p *parser) leave() {
+	p.depth--
+}
+
+func (p *parser) operation() (*Operation, error) {
+	op := &Operation{Type: p.next().value}
+	if p.peek().kind == TOKEN_NAME {
+		op.Name = p.next().value
+	}
+	if p.peek().kind == '(' {
+		if err := p.variableDefinitions(); err != nil {
+			return nil, err
+		}
+	}
+	if err := p.directives(); err != nil {
+		return nil, err
+	}
+	selections, err := p.selectionSet()
+	if err != nil {
+		return nil, err
+	}
+	op.Selections = selections
+	return op, nil
+}
+
+func (p *parser) variableDefinitions() error {
+	p.next()
+	for p.peek().kind != ')' {
+		if _, err := p.expect('$'); err != nil {
+			return err
+		}
+		if _, err := p.name(); err != nil {
+			return err
+		}
+		if _, err := p.expect(':'); err != nil {
+			return err
+		}
+		if err := p.typeReference(); err != nil {
+			return err
+		}
+		if p.peek().kind == '=' {
+			p.next()
+			if _, err := p.value(true); err != nil {
+				return err
+			}
+		}
+		if err := p.directives(); err != nil {
+			return err
+		}
+	}
+	p.next()
+	return nil
+}
+
+func (p *parser) typeReference() error {
+	if err := p.enter(); err != nil {
+		return err
+	}
+	defer p.leave()
+
+	if p.peek().kind == '[' {
+		p.next()
+		if err := p.typeReference(); err != nil {
+			return err
+		}
+		if _, err := p.expect(']'); err != nil {
+			return err
+		}
+	} else if _, err := p.name(); err != nil {
+		return err
+	}
+	if p.peek().kind == '!' {
+		p.next()
+	}
+	return nil
+}
+
+func (p *parser) fragment() (*Fragment, error) {
+	p.next()
+	name, err := p.name()
+	if err != nil {
+		return nil, err
+	}
+	if name == "on" {
+		return nil, fmt.Errorf("a fragment can't be named on")
+	}
+	if t := p.next(); t.kind != TOKEN_NAME || t.value != "on" {
+		return nil, fmt.Errorf("unexpected %q", t.value)
+	}
+	if _, err := p.name(); err != nil {
+		return nil, err
+	}
+	if err := p.directives(); err != nil {
+		return nil, err
+	}
+	selections, err := p.selectionSet()
+	if err != nil {
+		return nil, err
+	}
+	return &Fragment{Name: name, Selections: selections}, nil
+}
+
+func (p *parser) directives() error {
+	for p.peek().kind == '@' {
+		p.next()
+		if _, err := p.name(); err != nil {
+			return err
+		}
+		if p.peek().kind == '(' {
+			if _, err := p.arguments(); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (p *parser) selectionSet() ([]*Selection, error) {
+	if err := p.enter(); err != nil {
+		return nil, err
+	}
+	defer p.leave()
+
+	if _, err := p.expect('{'); err != nil {
+		return nil, err
+	}
+	selections := []*Selection{}
+	for p.peek().kind != '}' {
+		s, err := p.selection()
+		if err != nil {
+			return nil, err
+		}
+		selections = append(selections, s)
+	}
+	p.next()
+	if len(selections) == 0 {
+		return nil, fmt.Errorf("empty selection set")
+	}
+	return selections, nil
+}
+
+func (p *parser) selection() (*Selection, error) {
+	s := &Selection{}
+	if p.peek().kind == TOKEN_SPREAD {
+		p.next()
+		if t := p.peek(); t.kind == TOKEN_NAME && t.value != "on" {
+			s.Spread = p.next().value
+			return s, p.directives()
+		}
+		// inline fragment
+		if t := p.peek(); t.kind == TOKEN_NAME && t.value == "on" {
+			p.next()
+			if _, err := p.name(); err != nil {
+				return nil, err
+			}
+		}
+		if err := p.directives(); err != nil {
+			return nil, err
+		}
+		selections, err := p.selectionSet()
+		s.Selections = selections
+		return s, err
+	}
+
+	name, err := p.name()
+	if err != nil {
+		return nil, err
+	}
+	s.Name = name
+	if p.peek().kind == ':' {
+		p.next()
+		s.Alias = name
+		if s.Name, err = p.name(); err != nil {
+			return nil, err
+		}
+	}
+	if p.peek().kind == '(' {
+		if s.Arguments, err = p.arguments(); err != nil {
+			return nil, err
+		}
+	}
+	if err := p.directives(); err != nil {
+		return nil, err
+	}
+	if p.peek().kind == '{' {
+		if s.Selections, err = p.selectionSet(); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+func (p *parser) arguments() (map[string]interface{}, error) {
+	p.next()
+	arguments := make(map[string]interface{})
+	for p.peek().kind != ')' {
+		name, err := p.name()
+		if err != nil {
+			return nil, err
+		}
+		if _, err := p.expect(':'); err != nil {
+			return nil, err
+		}
+		if arguments[name], err = p.value(false); err != nil {
+			return nil, err
+		}
+	}
+	p.next()
+	return arguments, nil
+}
+
+/*
+value parses an argument value (the enum values are kept as strings)
+*/
+func (p *parser) value(constant bool) (interface{}, error) {
+	if err := p.enter(); err != nil {
+		return nil, err
+	}
+	defer p.leave()
+
+	t := p.next()
+	switch t.kind {
+	case '$':
+		if constant {
+			return nil, fmt.Errorf("unexpected variable in a default value")
+		}
+		name, err := p.name()
+		return Variable(name), err
+	case TOKEN_INT:
+		n, err := strconv.ParseInt(t.value, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid integer %s", t.value)
+		}
+		return n, nil
+	case TOKEN_FLOAT:
+		f, err := strconv.ParseFloat(t.value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid float %s", t.value)
+		}
+		return f, nil
+	case TOKEN_STRING:
+		return t.value, nil
+	case TOKEN_NAME:
+		switch t.value {
+		case "true":
+			return true, nil
+		case "false":
+			return false, nil
+		case "null":
+			return nil, nil
+		}
+		return t.value, nil
+	case '[':
+		list := []interface{}{}
+		for p.peek().kind != ']' {
+			v, err := p.value(constant)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, v)
+		}
+		p.next()
+		return list, nil
+	case '{':
+		object := make(map[string]interface{})
+		for p.peek().kind != '}' {
+			name, err := p.name()
+			if err != nil {
+				return nil, err
+			}
+			if _, err := p.expect(':'); err != nil {
+				return nil, err
+			}
+			if object[name], err = p.value(constant); err != nil {
+				return nil, err
+			}
+		}
+		p.next()
+		return object, nil
+	}
+	return nil, fmt.Errorf("unexpected %q", t.value)
+}
@@ -0,0 +1,233 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+const (
+	// the smallest RSA keys accepted
+	MIN_RSA_BITS = 2048
+	// the smallest HMAC secrets accepted
+	MIN_SECRET_SIZE = 32
+)
+
+/*
+Key is a verification key: a public key, or a HMAC secret
+*/
+type Key struct {
+	Kid string
+	// the only algorithm the key can be used with (any of its family if empty)
+	Alg string
+	// RSA, EC, OKP or oct
+	Kty string
+	// the curve of the EC and OKP keys
+	Crv string
+
+	public crypto.PublicKey
+	secret []byte
+}
+
+/*
+jwk is the JSON representation of a key (RFC 7517), the private
+parts are ignored
+*/
+type jwk struct {
+	Kty string   `json:"kty"`
+	Kid string   `json:"kid"`
+	Alg string   `json:"alg"`
+	Use string   `json:"use"`
+	Crv string   `json:"crv"`
+	N   string   `json:"n"`
+	E   string   `json:"e"`
+	X   string   `json:"x"`
+	Y   string   `json:"y"`
+	K   string   `json:"k"`
+	X5c []string `json:"x5c"`
+}
+
+/*
+ParseJWKS parses a key set ({"keys": [...]}) or a single key
+*/
+func ParseJWKS(data []byte) ([]*Key, error) {
+	var set struct {
+		Keys []json.RawMessage `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("invalid JWKS: %v", err)
+	}
+	if set.Keys == nil {
+		k, err := ParseJWK(data)
+		if err != nil {
+			return nil, err
+		}
+		return []*Key{k}, nil
+	}
+
+	keys := []*Key{}
+	for i, raw := range set.Keys {
+		k, err := ParseJWK(raw)
+		if err != nil {
+			return nil, fmt.Errorf("key %d: %v", i, err)
+		}
+		// (the encryption keys are skipped)
+		if k != nil {
+			keys = append(keys, k)
+		}
+	}
+	return keys, nil
+}
+
+func decodeSegment(s string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(strings.TrimRight(s, "="))
+}
+
+/*
+ParseJWK parses a key. It returns nil (and no error) for the
+encryption keys
+*/
+func ParseJWK(data []byte) (*Key, error) {
+	var j jwk
+	if err := json.Unmarshal(data, &j); err != nil {
+		return nil, fmt.Errorf("invalid JWK: %v", err)
+	}
+	if j.Use != "" && j.Use != "sig" {
+		return nil, nil
+	}
+	k := &Key{Kid: j.Kid, Alg: j.Alg, Kty: j.Kty, Crv: j.Crv}
+
+	if len(j.X5c) > 0 && j.N == "" && j.X == "" {
+		der, err := base64.StdEncoding.DecodeString(j.X5c[0])
+		if err != nil {
+			return nil, fmt.Errorf("invalid x5c: %v", err)
+		}
+		cert, err := x509.ParseCertificate(der)
+		if err != nil {
+			return nil, fmt.Errorf("invalid x5c: %v", err)
+		}
+		k.public = cert.PublicKey
+		return k, k.check()
+	}
+
+	switch j.Kty {
+	case "RSA":
+		n, err := decodeSegment(j.N)
+		if err != nil || len(n) == 0 {
+			return nil, fmt.Errorf("invalid RSA modulus")
+		}
+		e, err := decodeSegment(j.E)
+		if err != nil || len(e) == 0 || len(e) > 4 {
+			return nil, fmt.Errorf("invalid RSA exponent")
+		}
+		k.public = &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}
+	case "EC":
+		curves := map[string]ecdh.Curve{"P-256": ecdh.P256(), "P-384": ecdh.P384(), "P-521": ecdh.P521()}
+		ellipticCurves := map[string]elliptic.Curve{"P-256": elliptic.P256(), "P-384": elliptic.P384(), "P-521": elliptic.P521()}
+		curve, ok := curves[j.Crv]
+		if !ok {
+			return nil, fmt.Errorf("unsupported curve %s", j.Crv)
+		}
+		x, errx := decodeSegment(j.X)
+		y, erry := decodeSegment(j.Y)
+		size := (ellipticCurves[j.Crv].Params().BitSize + 7) / 8
+		if errx != nil || erry != nil || len(x) != size || len(y) != size {
+			return nil, fmt.Errorf("invalid EC point")
+		}
+		// (ecdh checks that the point is on the curve)
+		point := append(append([]byte{4}, x...), y...)
+		if _, err := curve.NewPublicKey(point); err != nil {
+			return nil, fmt.Errorf("invalid EC point: %v", err)
+		}
+		k.public = &ecdsa.PublicKey{
+			Curve: ellipticCurves[j.Crv],
+			X:     new(big.Int).SetBytes(x),
+			Y:     new(big.Int).SetBytes(y),
+		}
+	case "OKP":
+		if j.Crv != "Ed25519" {
+			return nil, fmt.Errorf("unsupported curve %s", j.Crv)
+		}
+		x, err := decodeSegment(j.X)
+		if err != nil || len(x) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("invalid Ed25519 key")
+		}
+		k.public = ed25519.PublicKey(x)
+	case "oct":
+		secret, err := decodeSegment(j.K)
+		if err != nil {
+			return nil, fmt.Errorf("invalid secret")
+		}
+		k.secret = secret
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", j.Kty)
+	}
+	return k, k.check()
+}
+
+/*
+check rejects the weak keys, and fills the key type of the x5c keys
+*/
+func (k *Key) check() error {
+	switch pub := k.public.(type) {
+	case *rsa.PublicKey:
+		k.Kty = "RSA"
+		if pub.N.BitLen() < MIN_RSA_BITS {
+			return fmt.Errorf("RSA key smaller than %d bits", MIN_RSA_BITS)
+		}
+		if pub.E < 3 || pub.E%2 == 0 {
+			return fmt.Errorf("invalid RSA exponent")
+		}
+	case *ecdsa.PublicKey:
+		k.Kty = "EC"
+		k.Crv = pub.Curve.Params().Name
+	case ed25519.PublicKey:
+		k.Kty = "OKP"
+		k.Crv = "Ed25519"
+	case nil:
+		if len(k.secret) < MIN_SECRET_SIZE {
+			return fmt.Errorf("secret smaller than %d bytes", MIN_SECRET_SIZE)
+		}
+	default:
+		return fmt.Errorf("unsupported key type")
+	}
+	if k.Alg != "" && !k.accepts(k.Alg) {
+		return fmt.Errorf("the alg %s doesn't match the key type", k.Alg)
+	}
+	return nil
+}
+
+/*
+accepts tells if a key can check a signature of an algorithm: the key
+type must be the one of the algorithm (a RSA public key can't be used
+as a HMAC secret)
+*/
+func (k *Key) accepts(alg string) bool {
+	if k.Alg != "" && k.Alg != alg {
+		return false
+	}
+	switch alg {
+	case "HS256", "HS384", "HS512":
+		return k.Kty == "oct"
+	case "RS256", "RS384", "RS512", "PS256", "PS384", "PS512":
+		return k.Kty == "RSA"
+	case "ES256":
+		return k.Kty == "EC" && k.Crv == "P-256"
+	case "ES384":
+		return k.Kty == "EC" && k.Crv == "P-384"
+	case "ES512":
+		return k.Kty == "EC" && k.Crv == "P-521"
+	case "EdDSA":
+		return k.Kty == "OKP" && k.Crv == "Ed25519"
+	}
+	return false
+}
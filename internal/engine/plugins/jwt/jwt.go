@@ -0,0 +1,381 @@
+package jwt
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nzin/taxsi2/internal/com"
+	"github.com/nzin/taxsi2/internal/db"
+	"github.com/nzin/taxsi2/internal/engine"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// GlobalConfig keys
+	// comma separated list of local JWKS files
+	CONFIG_JWKS_FILES = "jwt_jwks_files"
+	// the tolerated clock skew for exp, nbf and iat, in seconds
+	CONFIG_CLOCK_SKEW = "jwt_clock_skew"
+
+	DEFAULT_CLOCK_SKEW = 60
+
+	// output variables
+	// why the token was rejected
+	VARIABLE_REASON = "jwt_reason"
+	// the sub claim of a valid token
+	VARIABLE_SUBJECT = "jwt_subject"
+)
+
+/*
+policy is a db.JwtPolicy with its required claims parsed
+*/
+type policy struct {
+	db.JwtPolicy
+	// claim -> expected value ("" if the claim just has to be present)
+	requiredClaims map[string]string
+}
+
+func parseRequiredClaims(list string) map[string]string {
+	claims := make(map[string]string)
+	for _, c := range strings.Split(list, ",") {
+		name, value, _ := strings.Cut(c, "=")
+		if name = strings.TrimSpace(name); name != "" {
+			claims[name] = strings.TrimSpace(value)
+		}
+	}
+	return claims
+}
+
+/*
+ValidatePolicy returns why a policy can't be used (nil if it can)
+*/
+func ValidatePolicy(p db.JwtPolicy) error {
+	for _, c := range strings.Split(p.RequiredClaims, ",") {
+		if name, _, _ := strings.Cut(c, "="); strings.TrimSpace(name) == "" && strings.TrimSpace(c) != "" {
+			return fmt.Errorf("bad required claim %s (must be claim or claim=value)", c)
+		}
+	}
+	return nil
+}
+
+/*
+JwtWafPlugin validates the bearer tokens (JWT) of the requests,
+according to the JWT policy of the path (the requests whose path
+has no policy are not checked).
+The claims of a valid token are exposed to the other plugins
+(see com.TaxsiCom.Claims)
+*/
+type JwtWafPlugin struct {
+	ds db.DbServiceJwt
+	mu sync.RWMutex
+	// sorted by decreasing path prefix length
+	policies []policy
+	keys     []*Key
+	skew     time.Duration
+	now      func() time.Time
+}
+
+func NewJwtWafPlugin(ds db.DbServiceJwt) (engine.WafEnginePlugin, error) {
+	return newJwtWafPlugin(ds)
+}
+
+func newJwtWafPlugin(ds db.DbServiceJwt) (*JwtWafPlugin, error) {
+	j := &JwtWafPlugin{
+		ds:  ds,
+		now: time.Now,
+	}
+	if err := j.load(); err != nil {
+		return nil, err
+	}
+	ds.SubscribeChanges(db.CHANGELOG_TABLE_JWT, j)
+	ds.SubscribeChanges(db.CHANGELOG_TABLE_CONFIG, &configListener{j: j})
+	return j, nil
+}
+
+func (j *JwtWafPlugin) Name() string {
+	return "jwt"
+}
+
+// Interruptible is true: the keys are already loaded
+func (j *JwtWafPlugin) Interruptible() bool {
+	return true
+}
+
+/*
+load reads the policies, the keys (from the JWKS files and from
+the db) and the config. An invalid key is logged and skipped
+*/
+func (j *JwtWafPlugin) load() error {
+	rows, err := j.ds.GetJwtPolicies()
+	if err != nil {
+		return err
+	}
+	policies := []policy{}
+	for _, p := range rows {
+		policies = append(policies, policy{
+			JwtPolicy:      p,
+			requiredClaims: parseRequiredClaims(p.RequiredClaims),
+		})
+	}
+	sort.SliceStable(policies, func(a, b int) bool {
+		return len(policies[a].PathPrefix) > len(policies[b].PathPrefix)
+	})
+
+	keys := []*Key{}
+	if files, err := j.ds.GetConfigValueForKey(CONFIG_JWKS_FILES); err == nil {
+		for _, f := range strings.Split(files, ",") {
+			if f = strings.TrimSpace(f); f == "" {
+				continue
+			}
+			data, err := os.ReadFile(f)
+			if err != nil {
+				logrus.Errorf("unable to read the JWKS file %s: %v", f, err)
+				continue
+			}
+			set, err := ParseJWKS(data)
+			if err != nil {
+				logrus.Errorf("JWKS file %s: %v", f, err)
+				continue
+			}
+			keys = append(keys, set...)
+		}
+	}
+	dbKeys, err := j.ds.GetJwtKeys()
+	if err != nil {
+		return err
+	}
+	for _, row := range dbKeys {
+		k, err := ParseJWK(row.Jwk)
+		if err != nil || k == nil {
+			logrus.Errorf("JWT key %s: not a signature key (%v)", row.Kid, err)
+			continue
+		}
+		k.Kid = row.Kid
+		keys = append(keys, k)
+	}
+
+	skew := DEFAULT_CLOCK_SKEW
+	if v, err := j.ds.GetConfigValueForKey(CONFIG_CLOCK_SKEW); err == nil {
+		if n, err := parseClockSkew(v); err == nil {
+			skew = n
+		}
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.policies = policies
+	j.keys = keys
+	j.skew = time.Duration(skew) * time.Second
+	return nil
+}
+
+/*
+ValidateConfig returns why a value can't be used for a jwt key
+(nil if it can, or if the key is not a jwt key)
+*/
+func ValidateConfig(key string, value string) error {
+	if key == CONFIG_CLOCK_SKEW {
+		_, err := parseClockSkew(value)
+		return err
+	}
+	return nil
+}
+
+func parseClockSkew(value string) (int, error) {
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("bad %s %s (must be a number of seconds)", CONFIG_CLOCK_SKEW, value)
+	}
+	return n, nil
+}
+
+// NotifyDbChange is called when the policies or the keys change
+func (j *JwtWafPlugin) NotifyDbChange(key string) {
+	if err := j.load(); err != nil {
+		logrus.Errorf("Error reading JWT policies: %v", err)
+	}
+}
+
+// configListener reloads the keys when the GlobalConfig changes
+type configListener struct {
+	j *JwtWafPlugin
+}
+
+func (l *configListener) NotifyDbChange(key string) {
+	if key == CONFIG_JWKS_FILES || key == CONFIG_CLOCK_SKEW {
+		l.j.NotifyDbChange(key)
+	}
+}
+
+/*
+matchPolicy returns the policy with the longest matching path prefix
+*/
+func (j *JwtWafPlugin) matchPolicy(path string) *policy {
+	for i := range j.policies {
+		if strings.HasPrefix(path, j.policies[i].PathPrefix) {
+			return &j.policies[i]
+		}
+	}
+	return nil
+}
+
+/*
+bearer returns the bearer token of a request ("" if none)
+*/
+func bearer(payload *com.TaxsiCom) string {
+	for _, v := range payload.GetHeader("Authorization") {
+		scheme, token, ok := strings.Cut(strings.TrimSpace(v), " ")
+		if ok && strings.EqualFold(scheme, "bearer") {
+			return strings.TrimSpace(token)
+		}
+	}
+	return ""
+}
+
+func (j *JwtWafPlugin) Scan(ctx context.Context, payload *com.TaxsiCom) bool {
+	j.mu.RLock()
+	defer j.mu.RUnlock()
+
+	p := j.matchPolicy(payload.Parse().Path)
+	if p == nil {
+		return true
+	}
+	raw := bearer(payload)
+	if raw == "" {
+		if p.Required {
+			payload.SetVariable(VARIABLE_REASON, "missing bearer token")
+			return false
+		}
+		return true
+	}
+
+	t, err := ParseToken(raw)
+	if err == nil {
+		err = t.Verify(j.keys)
+	}
+	if err == nil {
+		err = j.validate(t.Claims, p)
+	}
+	if err != nil {
+		payload.SetVariable(VARIABLE_REASON, err.Error())
+		return false
+	}
+
+	claims := make(map[string]string)
+	for name, value := range t.Claims {
+		claims[name] = claimString(value)
+	}
+	payload.SetClaims(claims)
+	if sub, ok := t.Claims["sub"].(string); ok {
+		payload.SetVariable(VARIABLE_SUBJECT, sub)
+	}
+	return true
+}
+
+/*
+validate checks the time claims (if present), and the claims
+expected by the policy
+*/
+func (j *JwtWafPlugin) validate(claims map[string]interface{}, p *policy) error {
+	now := j.now()
+	times := map[string]time.Time{}
+	for _, name := range []string{"exp", "nbf", "iat"} {
+		value, ok := claims[name]
+		if !ok {
+			continue
+		}
+		n, isNumber := value.(json.Number)
+		seconds, err := n.Float64()
+		if !isNumber || err != nil {
+			return fmt.Errorf("invalid %s claim", name)
+		}
+		times[name] = time.Unix(int64(seconds), 0)
+	}
+	if exp, ok := times["exp"]; ok && now.After(exp.Add(j.skew)) {
+		return fmt.Errorf("expired token")
+	}
+	if nbf, ok := times["nbf"]; ok && now.Add(j.skew).Before(nbf) {
+		return fmt.Errorf("token not valid yet")
+	}
+	if iat, ok := times["iat"]; ok && now.Add(j.skew).Before(iat) {
+		return fmt.Errorf("token issued in the future")
+	}
+
+	if p.Issuer != "" && claims["iss"] != p.Issuer {
+		return fmt.Errorf("bad issuer %s", claimString(claims["iss"]))
+	}
+	if p.Audience != "" && !hasValue(claims["aud"], p.Audience) {
+		return fmt.Errorf("bad audience %s", claimString(claims["aud"]))
+	}
+
+	names := []string{}
+	for name := range p.requiredClaims {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		value, ok := claims[name]
+		if !ok {
+			return fmt.Errorf("missing %s claim", name)
+		}
+		if expected := p.requiredClaims[name]; expected != "" && !hasValue(value, expected) {
+			return fmt.Errorf("bad %s claim", name)
+		}
+	}
+	return nil
+}
+
+/*
+hasValue tells if a claim is (or contains) a value: the claim can be
+a string, a list, or a space separated list (like the OAuth scope)
+*/
+func hasValue(claim interface{}, expected string) bool {
+	switch v := claim.(type) {
+	case string:
+		for _, s := range strings.Fields(v) {
+			if s == expected {
+				return true
+			}
+		}
+		return v == expected
+	case []interface{}:
+		for _, item := range v {
+			if s, ok := item.(string); ok && s == expected {
+				return true
+			}
+		}
+	case json.Number, bool:
+		return claimString(v) == expected
+	}
+	return false
+}
+
+/*
+claimString returns a claim as a string (the lists are comma separated,
+the objects are kept in JSON)
+*/
+func claimString(claim interface{}) string {
+	switch v := claim.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	case []interface{}:
+		items := []string{}
+		for _, item := range v {
+			items = append(items, claimString(item))
+		}
+		return strings.Join(items, ",")
+	}
+	data, _ := json.Marshal(claim)
+	return string(data)
+}
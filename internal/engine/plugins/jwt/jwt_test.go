@@ -0,0 +1,288 @@
+package jwt
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/nzin/taxsi2/internal/com"
+	"github.com/nzin/taxsi2/internal/db"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+/*
+ * This is a mock implementation of the db.DbServiceJwt interface
+ */
+type DbServiceJwtMock struct {
+	config    map[string]string
+	policies  []db.JwtPolicy
+	keys      []db.JwtKey
+	listeners []db.DbChangeListener
+}
+
+func (m *DbServiceJwtMock) SubscribeChanges(table int, listener db.DbChangeListener) {
+	m.listeners = append(m.listeners, listener)
+}
+func (m *DbServiceJwtMock) GetConfigValueForKey(key string) (string, error) {
+	value, ok := m.config[key]
+	if !ok {
+		return "", gorm.ErrRecordNotFound
+	}
+	return value, nil
+}
+func (m *DbServiceJwtMock) GetJwtPolicies() ([]db.JwtPolicy, error) {
+	return m.policies, nil
+}
+func (m *DbServiceJwtMock) SetJwtPolicy(policy *db.JwtPolicy) error {
+	return nil
+}
+func (m *DbServiceJwtMock) DeleteJwtPolicy(id uint) error {
+	return nil
+}
+func (m *DbServiceJwtMock) GetJwtKeys() ([]db.JwtKey, error) {
+	return m.keys, nil
+}
+func (m *DbServiceJwtMock) SetJwtKey(kid string, jwk []byte) error {
+	return nil
+}
+func (m *DbServiceJwtMock) DeleteJwtKey(kid string) error {
+	return nil
+}
+
+func b64(data []byte) string {
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+/*
+sign builds a token, signed with a RSA, EC, Ed25519 private key or a HMAC secret
+*/
+func sign(t *testing.T, header map[string]interface{}, claims map[string]interface{}, key interface{}) string {
+	h, _ := json.Marshal(header)
+	c, _ := json.Marshal(claims)
+	input := b64(h) + "." + b64(c)
+
+	alg, _ := header["alg"].(string)
+	var signature []byte
+	var err error
+	switch k := key.(type) {
+	case []byte:
+		mac := hmac.New(hashes[alg[2:]].New, k)
+		mac.Write([]byte(input))
+		signature = mac.Sum(nil)
+	case *rsa.PrivateKey:
+		hash := hashes[alg[2:]]
+		digest := hash.New()
+		digest.Write([]byte(input))
+		if alg[:2] == "PS" {
+			signature, err = rsa.SignPSS(rand.Reader, k, hash, digest.Sum(nil), &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash})
+		} else {
+			signature, err = rsa.SignPKCS1v15(rand.Reader, k, hash, digest.Sum(nil))
+		}
+	case *ecdsa.PrivateKey:
+		digest := crypto.SHA256.New()
+		digest.Write([]byte(input))
+		var r, s *big.Int
+		r, s, err = ecdsa.Sign(rand.Reader, k, digest.Sum(nil))
+		signature = append(r.FillBytes(make([]byte, 32)), s.FillBytes(make([]byte, 32))...)
+	case ed25519.PrivateKey:
+		signature = ed25519.Sign(k, []byte(input))
+	}
+	assert.Nil(t, err)
+	return input + "." + b64(signature)
+}
+
+func bearerRequest(path string, token string) *com.TaxsiCom {
+	u, _ := url.Parse("https://api.example.com" + path)
+	headers := map[string][]string{}
+	if token != "" {
+		headers["Authorization"] = []string{"Bearer " + token}
+	}
+	return &com.TaxsiCom{
+		Method:  "GET",
+		Url:     u,
+		Headers: headers,
+	}
+}
+
+func TestParseJWK(t *testing.T) {
+	t.Run("happy path: key types", func(t *testing.T) {
+		rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+		k, err := ParseJWK([]byte(fmt.Sprintf(`{"kty": "RSA", "kid": "r1", "n": "%s", "e": "AQAB"}`, b64(rsaKey.N.Bytes()))))
+		assert.Nil(t, err)
+		assert.Equal(t, "r1", k.Kid)
+		assert.True(t, k.accepts("RS256"))
+		assert.True(t, k.accepts("PS512"))
+		assert.False(t, k.accepts("HS256"))
+
+		ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		k, err = ParseJWK([]byte(fmt.Sprintf(`{"kty": "EC", "crv": "P-256", "x": "%s", "y": "%s"}`,
+			b64(ecKey.X.FillBytes(make([]byte, 32))), b64(ecKey.Y.FillBytes(make([]byte, 32))))))
+		assert.Nil(t, err)
+		assert.True(t, k.accepts("ES256"))
+		assert.False(t, k.accepts("ES384"))
+
+		// the encryption keys are skipped
+		keys, err := ParseJWKS([]byte(`{"keys": [{"kty": "oct", "use": "enc", "k": "AAAA"}]}`))
+		assert.Nil(t, err)
+		assert.Equal(t, 0, len(keys))
+	})
+
+	t.Run("not happy path: invalid or weak keys", func(t *testing.T) {
+		smallKey, _ := rsa.GenerateKey(rand.Reader, 1024)
+		for _, jwk := range []string{
+			`{`,
+			`{"kty": "foo"}`,
+			fmt.Sprintf(`{"kty": "RSA", "n": "%s", "e": "AQAB"}`, b64(smallKey.N.Bytes())),
+			`{"kty": "EC", "crv": "P-256", "x": "AAAA", "y": "AAAA"}`,
+			// not on the curve
+			fmt.Sprintf(`{"kty": "EC", "crv": "P-256", "x": "%s", "y": "%s"}`, b64(make([]byte, 32)), b64(make([]byte, 32))),
+			`{"kty": "OKP", "crv": "X25519", "x": "AAAA"}`,
+			`{"kty": "oct", "k": "c2hvcnQ"}`,
+			// the alg doesn't match the key type
+			fmt.Sprintf(`{"kty": "oct", "alg": "RS256", "k": "%s"}`, b64(make([]byte, 32))),
+		} {
+			_, err := ParseJWK([]byte(jwk))
+			assert.NotNil(t, err, jwk)
+		}
+	})
+}
+
+func TestJwtPlugin(t *testing.T) {
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	edPublic, edKey, _ := ed25519.GenerateKey(rand.Reader)
+	secret := []byte("a very long secret of 32 bytes!!")
+
+	// the RSA and EC keys are in a JWKS file, the others in the db
+	jwks := fmt.Sprintf(`{"keys": [
+		{"kty": "RSA", "kid": "rsa", "use": "sig", "n": "%s", "e": "AQAB"},
+		{"kty": "EC", "kid": "ec", "crv": "P-256", "x": "%s", "y": "%s"}
+	]}`, b64(rsaKey.N.Bytes()), b64(ecKey.X.FillBytes(make([]byte, 32))), b64(ecKey.Y.FillBytes(make([]byte, 32))))
+	jwksFile := filepath.Join(t.TempDir(), "jwks.json")
+	assert.Nil(t, os.WriteFile(jwksFile, []byte(jwks), 0600))
+
+	ds := &DbServiceJwtMock{
+		config: map[string]string{CONFIG_JWKS_FILES: jwksFile},
+		policies: []db.JwtPolicy{
+			{PathPrefix: "/api", Required: true, Issuer: "https://auth.example.com", Audience: "api"},
+			{PathPrefix: "/api/admin", Required: true, RequiredClaims: "sub, scope=admin"},
+			{PathPrefix: "/public", Required: false},
+		},
+		keys: []db.JwtKey{
+			{Kid: "ed", Jwk: []byte(fmt.Sprintf(`{"kty": "OKP", "crv": "Ed25519", "x": "%s"}`, b64(edPublic)))},
+			{Kid: "hmac", Jwk: []byte(fmt.Sprintf(`{"kty": "oct", "k": "%s"}`, b64(secret)))},
+		},
+	}
+	plugin, err := newJwtWafPlugin(ds)
+	assert.Nil(t, err)
+	assert.Equal(t, 4, len(plugin.keys))
+	now := time.Now()
+	plugin.now = func() time.Time { return now }
+
+	claims := func(extra map[string]interface{}) map[string]interface{} {
+		c := map[string]interface{}{
+			"iss": "https://auth.example.com",
+			"aud": []string{"api", "web"},
+			"sub": "alice",
+			"exp": now.Add(time.Hour).Unix(),
+			"iat": now.Unix(),
+		}
+		for k, v := range extra {
+			c[k] = v
+		}
+		return c
+	}
+	check := func(t *testing.T, payload *com.TaxsiCom, reason string) {
+		assert.False(t, plugin.Scan(context.Background(), payload))
+		assert.Equal(t, reason, payload.Variables[VARIABLE_REASON])
+	}
+
+	t.Run("happy path: valid tokens", func(t *testing.T) {
+		for _, token := range []string{
+			sign(t, map[string]interface{}{"alg": "RS256", "kid": "rsa"}, claims(nil), rsaKey),
+			sign(t, map[string]interface{}{"alg": "PS384"}, claims(nil), rsaKey),
+			sign(t, map[string]interface{}{"alg": "ES256", "kid": "ec"}, claims(nil), ecKey),
+			sign(t, map[string]interface{}{"alg": "EdDSA", "kid": "ed"}, claims(nil), edKey),
+			sign(t, map[string]interface{}{"alg": "HS256"}, claims(nil), secret),
+		} {
+			payload := bearerRequest("/api/users", token)
+			assert.True(t, plugin.Scan(context.Background(), payload), payload.Variables[VARIABLE_REASON])
+			assert.Equal(t, "alice", payload.Variables[VARIABLE_SUBJECT])
+			assert.Equal(t, "alice", payload.Claims["sub"])
+			assert.Equal(t, "api,web", payload.Claims["aud"])
+		}
+
+		// the required claims of the path
+		token := sign(t, map[string]interface{}{"alg": "RS256"}, claims(map[string]interface{}{"scope": "read admin"}), rsaKey)
+		assert.True(t, plugin.Scan(context.Background(), bearerRequest("/api/admin/users", token)))
+
+		// no policy, or the token is optional
+		assert.True(t, plugin.Scan(context.Background(), bearerRequest("/", "")))
+		assert.True(t, plugin.Scan(context.Background(), bearerRequest("/public/page", "")))
+
+		// within the clock skew
+		token = sign(t, map[string]interface{}{"alg": "RS256"}, claims(map[string]interface{}{"exp": now.Add(-30 * time.Second).Unix()}), rsaKey)
+		assert.True(t, plugin.Scan(context.Background(), bearerRequest("/api/users", token)))
+	})
+
+	t.Run("not happy path: invalid tokens", func(t *testing.T) {
+		check(t, bearerRequest("/api/users", ""), "missing bearer token")
+		check(t, bearerRequest("/api/users", "abc"), "malformed token")
+
+		// an invalid token is rejected even where it's optional
+		check(t, bearerRequest("/public/page", "a.b.c"), "malformed token header")
+
+		unsigned := b64([]byte(`{"alg":"none"}`)) + "." + b64([]byte(`{"sub":"admin"}`)) + "."
+		check(t, bearerRequest("/api/users", unsigned), "unsigned token")
+		check(t, bearerRequest("/api/users", sign(t, map[string]interface{}{"alg": "nOnE"}, claims(nil), nil)), "unsigned token")
+
+		// algorithm confusion: the RSA public key used as a HMAC secret
+		rsaJwk := []byte(fmt.Sprintf(`{"kty":"RSA","kid":"rsa","n":"%s","e":"AQAB"}`, b64(rsaKey.N.Bytes())))
+		check(t, bearerRequest("/api/users", sign(t, map[string]interface{}{"alg": "HS256", "kid": "rsa"}, claims(nil), rsaJwk)),
+			`no key for alg HS256 and kid "rsa"`)
+
+		otherKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+		check(t, bearerRequest("/api/users", sign(t, map[string]interface{}{"alg": "RS256"}, claims(nil), otherKey)), "bad signature")
+		check(t, bearerRequest("/api/users", sign(t, map[string]interface{}{"alg": "RS256", "kid": "unknown"}, claims(nil), rsaKey)),
+			`no key for alg RS256 and kid "unknown"`)
+		check(t, bearerRequest("/api/users", sign(t, map[string]interface{}{"alg": "HS256", "crit": []string{"b64"}}, claims(nil), secret)),
+			"unsupported critical header b64")
+
+		check(t, bearerRequest("/api/users", sign(t, map[string]interface{}{"alg": "RS256"}, claims(map[string]interface{}{"exp": now.Add(-time.Hour).Unix()}), rsaKey)),
+			"expired token")
+		check(t, bearerRequest("/api/users", sign(t, map[string]interface{}{"alg": "RS256"}, claims(map[string]interface{}{"nbf": now.Add(time.Hour).Unix()}), rsaKey)),
+			"token not valid yet")
+		check(t, bearerRequest("/api/users", sign(t, map[string]interface{}{"alg": "RS256"}, claims(map[string]interface{}{"exp": "tomorrow"}), rsaKey)),
+			"invalid exp claim")
+		check(t, bearerRequest("/api/users", sign(t, map[string]interface{}{"alg": "RS256"}, claims(map[string]interface{}{"iss": "https://evil.com"}), rsaKey)),
+			"bad issuer https://evil.com")
+		check(t, bearerRequest("/api/users", sign(t, map[string]interface{}{"alg": "RS256"}, claims(map[string]interface{}{"aud": "web"}), rsaKey)),
+			"bad audience web")
+		check(t, bearerRequest("/api/admin/users", sign(t, map[string]interface{}{"alg": "RS256"}, claims(nil), rsaKey)),
+			"missing scope claim")
+		check(t, bearerRequest("/api/admin/users", sign(t, map[string]interface{}{"alg": "RS256"}, claims(map[string]interface{}{"scope": "read administrator"}), rsaKey)),
+			"bad scope claim")
+	})
+
+	t.Run("happy path: the keys are reloaded", func(t *testing.T) {
+		ds.keys = nil
+		plugin.NotifyDbChange("key:hmac")
+		check(t, bearerRequest("/api/users", sign(t, map[string]interface{}{"alg": "HS256"}, claims(nil), secret)),
+			`no key for alg HS256 and kid ""`)
+	})
+}
@@ -0,0 +1,152 @@
+package jwt
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/rsa"
+	_ "crypto/sha256"
+	_ "crypto/sha512"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// the maximum size of a token
+const MAX_TOKEN_SIZE = 16 * 1024
+
+var hashes = map[string]crypto.Hash{
+	"256": crypto.SHA256,
+	"384": crypto.SHA384,
+	"512": crypto.SHA512,
+}
+
+/*
+Token is a parsed (but not yet verified) JWS compact token
+*/
+type Token struct {
+	Alg    string
+	Kid    string
+	Claims map[string]interface{}
+
+	signingInput string
+	signature    []byte
+}
+
+/*
+ParseToken decodes a token, without checking its signature
+*/
+func ParseToken(raw string) (*Token, error) {
+	if len(raw) > MAX_TOKEN_SIZE {
+		return nil, fmt.Errorf("token bigger than %d bytes", MAX_TOKEN_SIZE)
+	}
+	parts := strings.Split(raw, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("malformed token")
+	}
+
+	var header struct {
+		Alg  string   `json:"alg"`
+		Kid  string   `json:"kid"`
+		Crit []string `json:"crit"`
+	}
+	data, err := decodeSegment(parts[0])
+	if err != nil || json.Unmarshal(data, &header) != nil {
+		return nil, fmt.Errorf("malformed token header")
+	}
+	// no extension is supported
+	if len(header.Crit) > 0 {
+		return nil, fmt.Errorf("unsupported critical header %s", header.Crit[0])
+	}
+
+	t := &Token{
+		Alg:          header.Alg,
+		Kid:          header.Kid,
+		signingInput: parts[0] + "." + parts[1],
+	}
+	data, err = decodeSegment(parts[1])
+	if err != nil {
+		return nil, fmt.Errorf("malformed token claims")
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&t.Claims); err != nil || t.Claims == nil {
+		return nil, fmt.Errorf("malformed token claims")
+	}
+	if t.signature, err = decodeSegment(parts[2]); err != nil {
+		return nil, fmt.Errorf("malformed token signature")
+	}
+	return t, nil
+}
+
+/*
+Verify checks the signature of a token with the keys that accept its
+algorithm (and its kid, if any)
+*/
+func (t *Token) Verify(keys []*Key) error {
+	if strings.EqualFold(t.Alg, "none") || t.Alg == "" {
+		return fmt.Errorf("unsigned token")
+	}
+	if len(t.Alg) != 5 && t.Alg != "EdDSA" {
+		return fmt.Errorf("unsupported alg %q", t.Alg)
+	}
+
+	candidates := 0
+	for _, k := range keys {
+		if t.Kid != "" && k.Kid != "" && k.Kid != t.Kid {
+			continue
+		}
+		if !k.accepts(t.Alg) {
+			continue
+		}
+		candidates++
+		if verify(t.Alg, k, []byte(t.signingInput), t.signature) {
+			return nil
+		}
+	}
+	if candidates == 0 {
+		return fmt.Errorf("no key for alg %s and kid %q", t.Alg, t.Kid)
+	}
+	return fmt.Errorf("bad signature")
+}
+
+func verify(alg string, k *Key, input []byte, signature []byte) bool {
+	if alg == "EdDSA" {
+		return ed25519.Verify(k.public.(ed25519.PublicKey), input, signature)
+	}
+
+	hash, ok := hashes[alg[2:]]
+	if !ok {
+		return false
+	}
+	if alg[:2] == "HS" {
+		mac := hmac.New(hash.New, k.secret)
+		mac.Write(input)
+		return hmac.Equal(mac.Sum(nil), signature)
+	}
+
+	h := hash.New()
+	h.Write(input)
+	digest := h.Sum(nil)
+	switch alg[:2] {
+	case "RS":
+		return rsa.VerifyPKCS1v15(k.public.(*rsa.PublicKey), hash, digest, signature) == nil
+	case "PS":
+		opts := &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash, Hash: hash}
+		return rsa.VerifyPSS(k.public.(*rsa.PublicKey), hash, digest, signature, opts) == nil
+	case "ES":
+		pub := k.public.(*ecdsa.PublicKey)
+		// r and s, with the size of the curve
+		size := (pub.Curve.Params().BitSize + 7) / 8
+		if len(signature) != 2*size {
+			return false
+		}
+		r := new(big.Int).SetBytes(signature[:size])
+		s := new(big.Int).SetBytes(signature[size:])
+		return ecdsa.Verify(pub, digest, r, s)
+	}
+	return false
+}
@@ -0,0 +1,190 @@
+package modsec
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+
+	"github.com/nzin/taxsi2/internal/com"
+	"github.com/nzin/taxsi2/internal/db"
+	"github.com/nzin/taxsi2/internal/engine"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// GlobalConfig keys
+	// the anomaly score (of the block rules) that blocks a request
+	CONFIG_ANOMALY_THRESHOLD = "modsec_anomaly_threshold"
+
+	// a critical rule is enough (like the CRS default)
+	DEFAULT_ANOMALY_THRESHOLD = 5
+
+	// output variables
+	// the id of the rule that blocked (the last block rule for the
+	// anomaly score)
+	VARIABLE_RULE  = "modsec_rule"
+	VARIABLE_MSG   = "modsec_msg"
+	VARIABLE_SCORE = "modsec_score"
+)
+
+/*
+ModsecWafPlugin runs SecLang rulesets (like the OWASP Core Rule Set)
+stored in the database, on the request phases (1 and 2):
+  - a deny (or drop) rule blocks the request
+  - an allow rule passes the request
+  - a block rule adds its severity to the anomaly score, the request is
+    blocked when the score reaches CONFIG_ANOMALY_THRESHOLD
+
+The rules that use something that is not supported (see Parse) are skipped
+*/
+type ModsecWafPlugin struct {
+	ds        db.DbServiceModsec
+	mu        sync.RWMutex
+	rules     []*Rule
+	threshold int
+}
+
+func NewModsecWafPlugin(ds db.DbServiceModsec) (engine.WafEnginePlugin, error) {
+	return newModsecWafPlugin(ds)
+}
+
+func newModsecWafPlugin(ds db.DbServiceModsec) (*ModsecWafPlugin, error) {
+	m := &ModsecWafPlugin{
+		ds:        ds,
+		threshold: DEFAULT_ANOMALY_THRESHOLD,
+	}
+	if err := m.load(); err != nil {
+		return nil, err
+	}
+	m.loadConfig()
+
+	ds.SubscribeChanges(db.CHANGELOG_TABLE_MODSEC, m)
+	ds.SubscribeChanges(db.CHANGELOG_TABLE_CONFIG, &configListener{m: m})
+	return m, nil
+}
+
+func (m *ModsecWafPlugin) Name() string {
+	return "modsec"
+}
+
+/*
+load parses the rulesets, in the order of their name
+*/
+func (m *ModsecWafPlugin) load() error {
+	rows, err := m.ds.GetModsecRulesets()
+	if err != nil {
+		return err
+	}
+	rules := []*Rule{}
+	for _, row := range rows {
+		rs := Parse(row.Name, row.Content)
+		for _, w := range rs.Warnings {
+			logrus.Debug(w)
+		}
+		logrus.Infof("modsec ruleset %s: %d rules loaded, %d skipped", row.Name, len(rs.Rules), len(rs.Warnings))
+		rules = append(rules, rs.Rules...)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.rules = rules
+	return nil
+}
+
+/*
+ValidateConfig returns why a value can't be used for a modsec key
+(nil if it can, or if the key is not a modsec key)
+*/
+func ValidateConfig(key string, value string) error {
+	if key == CONFIG_ANOMALY_THRESHOLD {
+		_, err := parseThreshold(value)
+		return err
+	}
+	return nil
+}
+
+func parseThreshold(value string) (int, error) {
+	t, err := strconv.Atoi(value)
+	if err != nil || t <= 0 {
+		return 0, fmt.Errorf("bad %s %s (must be a positive integer)", CONFIG_ANOMALY_THRESHOLD, value)
+	}
+	return t, nil
+}
+
+func (m *ModsecWafPlugin) loadConfig() {
+	threshold := DEFAULT_ANOMALY_THRESHOLD
+	if v, err := m.ds.GetConfigValueForKey(CONFIG_ANOMALY_THRESHOLD); err == nil {
+		if t, err := parseThreshold(v); err == nil {
+			threshold = t
+		} else {
+			logrus.Errorf("%v", err)
+		}
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.threshold = threshold
+}
+
+// NotifyDbChange is called when the rulesets change
+func (m *ModsecWafPlugin) NotifyDbChange(key string) {
+	if err := m.load(); err != nil {
+		logrus.Errorf("Error reading modsec rulesets: %v", err)
+	}
+}
+
+// configListener reloads the config when the GlobalConfig changes
+type configListener struct {
+	m *ModsecWafPlugin
+}
+
+func (l *configListener) NotifyDbChange(key string) {
+	if key == CONFIG_ANOMALY_THRESHOLD {
+		l.m.loadConfig()
+	}
+}
+
+func (m *ModsecWafPlugin) Scan(ctx context.Context, payload *com.TaxsiCom) bool {
+	m.mu.RLock()
+	rules := m.rules
+	threshold := m.threshold
+	m.mu.RUnlock()
+
+	t := newTransaction(payload)
+	for _, phase := range []int{1, 2} {
+		r := t.run(rules, phase)
+		if r == nil {
+			continue
+		}
+		if r.Disruptive == DISRUPTIVE_ALLOW {
+			return true
+		}
+		m.report(t, r)
+		return false
+	}
+	if t.score >= threshold {
+		m.report(t, t.scoreRule)
+		return false
+	}
+	return true
+}
+
+func (m *ModsecWafPlugin) report(t *transaction, r *Rule) {
+	t.rule = r
+	t.payload.SetVariable(VARIABLE_RULE, strconv.Itoa(r.ID))
+	t.payload.SetVariable(VARIABLE_MSG, t.expand(r.Msg))
+	t.payload.SetVariable(VARIABLE_SCORE, strconv.Itoa(t.score))
+}
+
+/*
+SaveRuleset parses a SecLang file and saves it as a ruleset. The file is
+saved even if some rules are skipped (see the warnings of the Ruleset)
+*/
+func SaveRuleset(ds db.DbServiceModsec, ruleset *db.ModsecRuleset) (*Ruleset, error) {
+	rs := Parse(ruleset.Name, ruleset.Content)
+	if err := ds.SetModsecRuleset(ruleset); err != nil {
+		return nil, err
+	}
+	return rs, nil
+}
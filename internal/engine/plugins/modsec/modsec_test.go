@@ -0,0 +1,218 @@
+package modsec
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/nzin/taxsi2/internal/com"
+	"github.com/nzin/taxsi2/internal/db"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+/*
+ * This is a mock implementation of the db.DbServiceModsec interface
+ */
+type DbServiceModsecMock struct {
+	configs   map[string]string
+	rulesets  map[string]db.ModsecRuleset
+	listeners map[int][]db.DbChangeListener
+}
+
+func (m *DbServiceModsecMock) SubscribeChanges(table int, listener db.DbChangeListener) {
+	if m.listeners == nil {
+		m.listeners = make(map[int][]db.DbChangeListener)
+	}
+	m.listeners[table] = append(m.listeners[table], listener)
+}
+func (m *DbServiceModsecMock) notify(table int, key string) {
+	for _, l := range m.listeners[table] {
+		l.NotifyDbChange(key)
+	}
+}
+func (m *DbServiceModsecMock) GetConfigValueForKey(key string) (string, error) {
+	if v, ok := m.configs[key]; ok {
+		return v, nil
+	}
+	return "", gorm.ErrRecordNotFound
+}
+func (m *DbServiceModsecMock) GetModsecRulesets() ([]db.ModsecRuleset, error) {
+	rulesets := []db.ModsecRuleset{}
+	for _, r := range m.rulesets {
+		rulesets = append(rulesets, r)
+	}
+	sort.Slice(rulesets, func(a, b int) bool { return rulesets[a].Name < rulesets[b].Name })
+	return rulesets, nil
+}
+func (m *DbServiceModsecMock) SetModsecRuleset(ruleset *db.ModsecRuleset) error {
+	m.rulesets[ruleset.Name] = *ruleset
+	m.notify(db.CHANGELOG_TABLE_MODSEC, ruleset.Name)
+	return nil
+}
+func (m *DbServiceModsecMock) DeleteModsecRuleset(name string) error {
+	delete(m.rulesets, name)
+	m.notify(db.CHANGELOG_TABLE_MODSEC, name)
+	return nil
+}
+
+/*
+crsRulesets loads the CRS snapshot of testdata/crs
+*/
+func crsRulesets(t *testing.T) map[string]db.ModsecRuleset {
+	files, err := filepath.Glob("testdata/crs/*.conf")
+	assert.Nil(t, err)
+	rulesets := map[string]db.ModsecRuleset{}
+	for _, f := range files {
+		content, err := os.ReadFile(f)
+		assert.Nil(t, err)
+		rulesets[filepath.Base(f)] = db.ModsecRuleset{Name: filepath.Base(f), Content: string(content)}
+	}
+	return rulesets
+}
+
+func TestModsecPlugin(t *testing.T) {
+	ds := &DbServiceModsecMock{configs: map[string]string{}, rulesets: crsRulesets(t)}
+	plugin, err := newModsecWafPlugin(ds)
+	assert.Nil(t, err)
+	assert.Equal(t, "modsec", plugin.Name())
+	// (with the marker) 930120, 942190 and 901321 are skipped, 950100 is a response rule
+	assert.Equal(t, 20, len(plugin.rules))
+
+	t.Run("happy path: benign requests pass", func(t *testing.T) {
+		for _, payload := range []*com.TaxsiCom{
+			newPayload("GET", "http://www.example.com/products?id=12&sort=price", map[string][]string{"Host": {"www.example.com"}}, ""),
+			newPayload("POST", "http://www.example.com/comments", map[string][]string{
+				"Host":         {"www.example.com"},
+				"Content-Type": {"application/x-www-form-urlencoded"},
+			}, "text=I+selected+the+best+one%2C+see+docs%2Fguide.html"),
+			// the health checks are allowed (even with a traversal)
+			newPayload("GET", "http://www.example.com/healthz?file=../../etc/passwd", nil, ""),
+		} {
+			assert.True(t, plugin.Scan(context.Background(), payload), payload.Url.String())
+			assert.Equal(t, "", payload.Variables[VARIABLE_RULE])
+		}
+	})
+
+	t.Run("not happy path: attacks are blocked by the anomaly evaluation", func(t *testing.T) {
+		for _, c := range []struct {
+			method  string
+			url     string
+			headers map[string][]string
+			body    string
+			score   string
+		}{
+			// 942100
+			{"GET", "http://www.example.com/products?id=1%27%20OR%20%271%27%3D%271", nil, "", "5"},
+			// 942100 and 942160
+			{"POST", "http://www.example.com/search", map[string][]string{"Host": {"www.example.com"}, "Content-Type": {"application/x-www-form-urlencoded"}}, "q=1%27+AND+SLEEP(5)--+", "10"},
+			// 941100
+			{"GET", "http://www.example.com/search?q=%3Cscript%3Ealert(1)%3C/script%3E", nil, "", "5"},
+			// 930110
+			{"GET", "http://www.example.com/download?file=..%2f..%2fetc%2fpasswd", nil, "", "5"},
+			// 911100
+			{"TRACE", "http://www.example.com/", nil, "", "5"},
+			// 920170
+			{"GET", "http://www.example.com/", map[string][]string{"Host": {"www.example.com"}, "Content-Length": {"12"}}, "", "5"},
+			// 920280 (no host) and 920270 (920350 is skipped)
+			{"GET", "http://www.example.com/?a=%00", map[string][]string{}, "", "8"},
+		} {
+			headers := c.headers
+			if headers == nil {
+				headers = map[string][]string{"Host": {"www.example.com"}}
+			}
+			payload := newPayload(c.method, c.url, headers, c.body)
+			assert.False(t, plugin.Scan(context.Background(), payload), c.url)
+			assert.Equal(t, "949110", payload.Variables[VARIABLE_RULE], c.url)
+			assert.Equal(t, "Inbound Anomaly Score Exceeded (Total Score: "+c.score+")", payload.Variables[VARIABLE_MSG], c.url)
+		}
+	})
+
+	t.Run("happy path: the anomaly threshold", func(t *testing.T) {
+		ds := &DbServiceModsecMock{configs: map[string]string{}, rulesets: map[string]db.ModsecRuleset{
+			"custom.conf": {Name: "custom.conf", Content: `
+SecRule REQUEST_HEADERS:User-Agent "@pm nikto sqlmap" "id:1000,phase:1,block,msg:'Scanner %{MATCHED_VAR}',severity:'WARNING',t:lowercase"
+SecRule ARGS:debug "@streq 1" "id:1001,phase:2,block,msg:'Debug',severity:'NOTICE'"
+SecRule ARGS:cmd "@rx ^rm " "id:1002,phase:2,deny,msg:'Command'"
+`},
+		}}
+		plugin, err := newModsecWafPlugin(ds)
+		assert.Nil(t, err)
+
+		// 3 < 5
+		payload := newPayload("GET", "http://www.example.com/", map[string][]string{"User-Agent": {"Nikto/2.1"}}, "")
+		assert.True(t, plugin.Scan(context.Background(), payload))
+
+		// 3 + 2
+		payload = newPayload("GET", "http://www.example.com/?debug=1", map[string][]string{"User-Agent": {"Nikto/2.1"}}, "")
+		assert.False(t, plugin.Scan(context.Background(), payload))
+		assert.Equal(t, "1001", payload.Variables[VARIABLE_RULE])
+		assert.Equal(t, "5", payload.Variables[VARIABLE_SCORE])
+
+		// deny blocks whatever the score
+		payload = newPayload("GET", "http://www.example.com/?cmd=rm%20-rf", nil, "")
+		assert.False(t, plugin.Scan(context.Background(), payload))
+		assert.Equal(t, "1002", payload.Variables[VARIABLE_RULE])
+		assert.Equal(t, "Command", payload.Variables[VARIABLE_MSG])
+		assert.Equal(t, "0", payload.Variables[VARIABLE_SCORE])
+
+		ds.configs[CONFIG_ANOMALY_THRESHOLD] = "6"
+		ds.notify(db.CHANGELOG_TABLE_CONFIG, CONFIG_ANOMALY_THRESHOLD)
+		payload = newPayload("GET", "http://www.example.com/?debug=1", map[string][]string{"User-Agent": {"Nikto/2.1"}}, "")
+		assert.True(t, plugin.Scan(context.Background(), payload))
+
+		// a bad threshold is ignored
+		ds.configs[CONFIG_ANOMALY_THRESHOLD] = "-1"
+		ds.notify(db.CHANGELOG_TABLE_CONFIG, CONFIG_ANOMALY_THRESHOLD)
+		assert.Equal(t, DEFAULT_ANOMALY_THRESHOLD, plugin.threshold)
+	})
+
+	t.Run("happy path: rulesets are hot reloaded", func(t *testing.T) {
+		ds := &DbServiceModsecMock{configs: map[string]string{}, rulesets: map[string]db.ModsecRuleset{}}
+		plugin, err := newModsecWafPlugin(ds)
+		assert.Nil(t, err)
+		assert.True(t, plugin.Scan(context.Background(), newPayload("GET", "http://www.example.com/wp-login.php", nil, "")))
+
+		ds.SetModsecRuleset(&db.ModsecRuleset{Name: "wp.conf", Content: `SecRule REQUEST_BASENAME "@streq wp-login.php" "id:1,deny"`})
+		assert.False(t, plugin.Scan(context.Background(), newPayload("GET", "http://www.example.com/wp-login.php", nil, "")))
+
+		ds.DeleteModsecRuleset("wp.conf")
+		assert.True(t, plugin.Scan(context.Background(), newPayload("GET", "http://www.example.com/wp-login.php", nil, "")))
+	})
+
+	t.Run("happy path: skipAfter, chain, capture and allow", func(t *testing.T) {
+		ds := &DbServiceModsecMock{configs: map[string]string{}, rulesets: map[string]db.ModsecRuleset{
+			"custom.conf": {Name: "custom.conf", Content: `
+SecRule REQUEST_FILENAME "@beginsWith /static/" "id:1,phase:1,pass,nolog,skipAfter:END-STATIC"
+SecRule ARGS "@rx (\w+)=(\d+)" "id:2,phase:1,pass,capture,setvar:tx.key=%{tx.1},setvar:tx.value=%{TX.2}"
+SecMarker END-STATIC
+SecRule TX:key "@streq admin" "id:3,phase:2,deny,msg:'Admin %{tx.value}',chain"
+    SecRule TX:value "@gt 10" "t:none"
+SecRule REQUEST_METHOD "@streq OPTIONS" "id:4,phase:2,allow"
+SecRule ARGS_NAMES "@rx ." "id:5,phase:2,deny"
+`},
+		}}
+		plugin, err := newModsecWafPlugin(ds)
+		assert.Nil(t, err)
+
+		payload := newPayload("GET", "http://www.example.com/?q=admin%3D12", nil, "")
+		assert.False(t, plugin.Scan(context.Background(), payload))
+		assert.Equal(t, "3", payload.Variables[VARIABLE_RULE])
+		assert.Equal(t, "Admin 12", payload.Variables[VARIABLE_MSG])
+
+		// 5
+		payload = newPayload("GET", "http://www.example.com/?q=admin%3D9", nil, "")
+		assert.False(t, plugin.Scan(context.Background(), payload))
+		assert.Equal(t, "5", payload.Variables[VARIABLE_RULE])
+
+		// 2 is skipped (then 5)
+		payload = newPayload("GET", "http://www.example.com/static/?q=admin%3D12", nil, "")
+		assert.False(t, plugin.Scan(context.Background(), payload))
+		assert.Equal(t, "5", payload.Variables[VARIABLE_RULE])
+
+		assert.True(t, plugin.Scan(context.Background(), newPayload("OPTIONS", "http://www.example.com/?q=1", nil, "")))
+		assert.True(t, plugin.Scan(context.Background(), newPayload("GET", "http://www.example.com/", nil, "")))
+	})
+}
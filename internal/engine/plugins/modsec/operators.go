@@ -0,0 +1,268 @@
+package modsec
+
+import (
+	"fmt"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/nzin/taxsi2/internal/engine"
+	"github.com/nzin/taxsi2/internal/engine/plugins/sqli"
+	"github.com/nzin/taxsi2/internal/engine/plugins/xss"
+)
+
+/*
+Operator is the operator of a SecRule, like @rx or !@pm
+*/
+type Operator struct {
+	Name    string
+	Negated bool
+	Arg     string
+	match   matcher
+}
+
+/*
+matcher returns if a value matches, and what it captured
+*/
+type matcher func(t *transaction, value string) (bool, []string)
+
+// the operators, their argument is parsed once
+var operators = map[string]func(arg string) (matcher, error){
+	"rx": func(arg string) (matcher, error) {
+		re, err := regexp.Compile(arg)
+		if err != nil {
+			return nil, fmt.Errorf("regexp not supported: %v", err)
+		}
+		return func(t *transaction, value string) (bool, []string) {
+			m := re.FindStringSubmatch(value)
+			return m != nil, m
+		}, nil
+	},
+	"pm": phrases,
+	"pmf": func(arg string) (matcher, error) {
+		return nil, fmt.Errorf("@pmf is not supported")
+	},
+	"streq":        compareStrings(func(value string, arg string) bool { return value == arg }),
+	"contains":     compareStrings(strings.Contains),
+	"strmatch":     compareStrings(strings.Contains),
+	"containsWord": compareStrings(containsWord),
+	"beginsWith":   compareStrings(strings.HasPrefix),
+	"endsWith":     compareStrings(strings.HasSuffix),
+	// the value is a part of the argument (like a word of a list)
+	"within": compareStrings(func(value string, arg string) bool {
+		return value != "" && strings.Contains(arg, value)
+	}),
+	"eq": compareInts(func(a, b int) bool { return a == b }),
+	"ge": compareInts(func(a, b int) bool { return a >= b }),
+	"gt": compareInts(func(a, b int) bool { return a > b }),
+	"le": compareInts(func(a, b int) bool { return a <= b }),
+	"lt": compareInts(func(a, b int) bool { return a < b }),
+	"ipMatch": func(arg string) (matcher, error) {
+		list := strings.Split(arg, ",")
+		for i, n := range list {
+			n = strings.TrimSpace(n)
+			if n != "" && !strings.Contains(n, "/") {
+				if strings.Contains(n, ":") {
+					n += "/128"
+				} else {
+					n += "/32"
+				}
+			}
+			list[i] = n
+		}
+		networks, err := engine.ParseNetworks(strings.Join(list, ","))
+		if err != nil {
+			return nil, err
+		}
+		return func(t *transaction, value string) (bool, []string) {
+			ip := net.ParseIP(value)
+			return ip != nil && engine.IsIpInNetworks(ip, networks), nil
+		}, nil
+	},
+	"detectSQLi": func(arg string) (matcher, error) {
+		return func(t *transaction, value string) (bool, []string) {
+			fingerprint, ok := sqli.IsSQLi(value)
+			return ok, []string{fingerprint}
+		}, nil
+	},
+	"detectXSS": func(arg string) (matcher, error) {
+		return func(t *transaction, value string) (bool, []string) {
+			_, ok := xss.IsXSS(value)
+			return ok, nil
+		}, nil
+	},
+	"unconditionalMatch": func(arg string) (matcher, error) {
+		return func(t *transaction, value string) (bool, []string) { return true, nil }, nil
+	},
+	"noMatch": func(arg string) (matcher, error) {
+		return func(t *transaction, value string) (bool, []string) { return false, nil }, nil
+	},
+	"validateByteRange":    validateByteRange,
+	"validateUrlEncoding":  validate(validUrlEncoding),
+	"validateUtf8Encoding": validate(utf8.ValidString),
+}
+
+/*
+parseOperator parses an operator (@rx when the name is omitted)
+*/
+func parseOperator(s string) (*Operator, error) {
+	op := &Operator{Name: "rx", Arg: s}
+	if strings.HasPrefix(s, "!") {
+		op.Negated = true
+		s = s[1:]
+		op.Arg = s
+	}
+	if strings.HasPrefix(s, "@") {
+		name, arg, _ := strings.Cut(s[1:], " ")
+		op.Name = name
+		op.Arg = strings.TrimSpace(arg)
+	}
+	if op.Name == "pmFromFile" {
+		op.Name = "pmf"
+	}
+	parse, ok := operators[op.Name]
+	if !ok {
+		return nil, fmt.Errorf("unsupported operator @%s", op.Name)
+	}
+	match, err := parse(op.Arg)
+	if err != nil {
+		return nil, err
+	}
+	op.match = match
+	return op, nil
+}
+
+/*
+phrases matches (case insensitively) one of the space separated
+phrases of the argument
+*/
+func phrases(arg string) (matcher, error) {
+	list := strings.Fields(strings.ToLower(arg))
+	if len(list) == 0 {
+		return nil, fmt.Errorf("@pm expects phrases")
+	}
+	return func(t *transaction, value string) (bool, []string) {
+		value = strings.ToLower(value)
+		for _, p := range list {
+			if strings.Contains(value, p) {
+				return true, []string{p}
+			}
+		}
+		return false, nil
+	}, nil
+}
+
+/*
+compareStrings returns an operator comparing the value with its
+argument (once its macros are expanded)
+*/
+func compareStrings(compare func(value string, arg string) bool) func(arg string) (matcher, error) {
+	return func(arg string) (matcher, error) {
+		return func(t *transaction, value string) (bool, []string) {
+			a := t.expand(arg)
+			if compare(value, a) {
+				return true, []string{a}
+			}
+			return false, nil
+		}, nil
+	}
+}
+
+/*
+compareInts returns an operator comparing the value with its argument
+as numbers (a value that is not a number is 0)
+*/
+func compareInts(compare func(a, b int) bool) func(arg string) (matcher, error) {
+	return func(arg string) (matcher, error) {
+		return func(t *transaction, value string) (bool, []string) {
+			a, _ := strconv.Atoi(strings.TrimSpace(value))
+			b, _ := strconv.Atoi(strings.TrimSpace(t.expand(arg)))
+			return compare(a, b), nil
+		}, nil
+	}
+}
+
+func isWordByte(c byte) bool {
+	return c == '_' || (c >= '0' && c <= '9') || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+/*
+containsWord is true when the word is in the value, not as a part of
+a bigger word
+*/
+func containsWord(value string, word string) bool {
+	if word == "" {
+		return true
+	}
+	for start := 0; ; {
+		i := strings.Index(value[start:], word)
+		if i < 0 {
+			return false
+		}
+		i += start
+		end := i + len(word)
+		if (i == 0 || !isWordByte(value[i-1])) && (end == len(value) || !isWordByte(value[end])) {
+			return true
+		}
+		start = i + 1
+	}
+}
+
+/*
+validate returns an operator that matches the values that are not valid
+*/
+func validate(valid func(string) bool) func(arg string) (matcher, error) {
+	return func(arg string) (matcher, error) {
+		return func(t *transaction, value string) (bool, []string) {
+			return !valid(value), nil
+		}, nil
+	}
+}
+
+/*
+validUrlEncoding checks that the % are followed by two hexadecimal digits
+*/
+func validUrlEncoding(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] != '%' {
+			continue
+		}
+		if i+2 >= len(s) || !isHex(s[i+1]) || !isHex(s[i+2]) {
+			return false
+		}
+		i += 2
+	}
+	return true
+}
+
+/*
+validateByteRange matches the values with a byte out of the ranges,
+like 1-255 or 32,34,38,42-59
+*/
+func validateByteRange(arg string) (matcher, error) {
+	var allowed [256]bool
+	for _, r := range strings.Split(arg, ",") {
+		from, to, isRange := strings.Cut(strings.TrimSpace(r), "-")
+		if !isRange {
+			to = from
+		}
+		a, errA := strconv.Atoi(from)
+		b, errB := strconv.Atoi(to)
+		if errA != nil || errB != nil || a < 0 || b > 255 || a > b {
+			return nil, fmt.Errorf("bad byte range %s", r)
+		}
+		for c := a; c <= b; c++ {
+			allowed[c] = true
+		}
+	}
+	return func(t *transaction, value string) (bool, []string) {
+		for i := 0; i < len(value); i++ {
+			if !allowed[value[i]] {
+				return true, []string{value[i : i+1]}
+			}
+		}
+		return false, nil
+	}, nil
+}
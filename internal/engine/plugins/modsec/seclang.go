@@ -0,0 +1,399 @@
+package modsec
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+/*
+Rule is a parsed SecRule (or SecAction, or SecMarker).
+The chained rules are in Chain, their actions are the ones of the
+first rule of the chain (except setvar and capture)
+*/
+type Rule struct {
+	ID       int
+	Phase    int
+	Msg      string
+	LogData  string
+	Severity int
+	// DISRUPTIVE_* ("" if none)
+	Disruptive string
+	Capture    bool
+	SetVars    []string
+	SkipAfter  string
+	// a SecMarker (the rule has no variables and no operator)
+	Marker string
+
+	Variables  []*Variable
+	Operator   *Operator
+	Transforms []transform
+	Chain      *Rule
+	// the rule is a SecAction (it always matches)
+	unconditional bool
+	// the next rule is chained to this one
+	chained bool
+}
+
+// the disruptive actions
+const (
+	DISRUPTIVE_DENY  = "deny"
+	DISRUPTIVE_BLOCK = "block"
+	DISRUPTIVE_PASS  = "pass"
+	DISRUPTIVE_ALLOW = "allow"
+)
+
+// the severities, by name
+var severities = map[string]int{
+	"EMERGENCY": 0, "ALERT": 1, "CRITICAL": 2, "ERROR": 3, "WARNING": 4, "NOTICE": 5, "INFO": 6, "DEBUG": 7,
+}
+
+// the actions that are accepted, but that have no effect here
+var ignoredActions = map[string]bool{
+	"log": true, "nolog": true, "auditlog": true, "noauditlog": true, "tag": true, "ver": true,
+	"rev": true, "maturity": true, "accuracy": true, "ctl": true, "status": true, "multiMatch": true,
+	"expirevar": true, "initcol": true, "setuid": true, "setsid": true,
+}
+
+// the directives that are accepted, but that have no effect here
+var ignoredDirectives = map[string]bool{
+	"SecDefaultAction": true, "SecComponentSignature": true, "SecRuleEngine": true,
+	"SecRequestBodyAccess": true, "SecResponseBodyAccess": true, "SecRuleRemoveById": true,
+	"SecRuleRemoveByTag": true, "SecRuleUpdateTargetById": true, "SecRuleUpdateTargetByTag": true,
+	"SecCollectionTimeout": true, "SecAuditEngine": true, "SecAuditLog": true, "SecAuditLogParts": true,
+	"SecDebugLog": true, "SecDebugLogLevel": true, "SecArgumentSeparator": true, "SecCookieFormat": true,
+	"SecUnicodeMapFile": true, "SecStatusEngine": true, "SecTmpDir": true, "SecDataDir": true,
+}
+
+/*
+Ruleset is the result of the parsing of SecLang files: the rules that can
+run here, and why the other ones were skipped
+*/
+type Ruleset struct {
+	Rules    []*Rule
+	Warnings []string
+	// the rules of the phases 3 to 5 (they apply to the responses)
+	ResponseRules int
+}
+
+/*
+Parse parses a SecLang file (named name in the warnings). A rule that
+uses something that is not supported is skipped with a warning
+*/
+func Parse(name string, content string) *Ruleset {
+	rs := &Ruleset{}
+	var chainStart *Rule
+	var chainEnd *Rule
+	chainSkipped := false
+
+	for _, d := range directives(content) {
+		warn := func(format string, args ...interface{}) {
+			rs.Warnings = append(rs.Warnings, fmt.Sprintf("%s:%d: ", name, d.line)+fmt.Sprintf(format, args...))
+		}
+		words, err := splitWords(d.text)
+		if err != nil {
+			warn("%v", err)
+			continue
+		}
+		if len(words) == 0 {
+			continue
+		}
+
+		var r *Rule
+		switch words[0] {
+		case "SecRule":
+			if len(words) < 3 || len(words) > 4 {
+				warn("SecRule expects variables, an operator and actions")
+				break
+			}
+			actions := ""
+			if len(words) == 4 {
+				actions = words[3]
+			}
+			r, err = parseRule(words[1], words[2], actions)
+		case "SecAction":
+			if len(words) != 2 {
+				warn("SecAction expects actions")
+				break
+			}
+			r, err = parseRule("", "", words[1])
+			if r != nil {
+				r.unconditional = true
+			}
+		case "SecMarker":
+			if len(words) != 2 {
+				warn("SecMarker expects a name")
+				break
+			}
+			rs.Rules = append(rs.Rules, &Rule{Marker: words[1]})
+			continue
+		default:
+			if !ignoredDirectives[words[0]] {
+				warn("unsupported directive %s", words[0])
+			}
+			continue
+		}
+
+		// a chained rule continues the previous one
+		if chainEnd != nil {
+			if r == nil || err != nil {
+				if !chainSkipped && chainStart.Phase <= 2 {
+					warn("rule %d skipped: bad chained rule (%v)", chainStart.ID, err)
+				}
+				chainSkipped = true
+			} else {
+				chainEnd.Chain = r
+			}
+			if r != nil && r.chained {
+				chainEnd = r
+				continue
+			}
+			if !chainSkipped || chainStart.Phase > 2 {
+				rs.add(chainStart)
+			}
+			chainStart, chainEnd, chainSkipped = nil, nil, false
+			continue
+		}
+		if r == nil {
+			continue
+		}
+		// (the response rules are not run, whatever they use)
+		if err != nil && r.Phase <= 2 {
+			warn("rule %d skipped: %v", r.ID, err)
+			if r.chained {
+				chainStart, chainEnd, chainSkipped = r, r, true
+			}
+			continue
+		}
+		if r.chained {
+			chainStart, chainEnd = r, r
+			continue
+		}
+		rs.add(r)
+	}
+	if chainStart != nil {
+		rs.Warnings = append(rs.Warnings, fmt.Sprintf("%s: rule %d: unterminated chain", name, chainStart.ID))
+	}
+	return rs
+}
+
+func (rs *Ruleset) add(r *Rule) {
+	if r.Phase > 2 {
+		rs.ResponseRules++
+		return
+	}
+	rs.Rules = append(rs.Rules, r)
+}
+
+type directive struct {
+	line int
+	text string
+}
+
+/*
+directives splits a file in directives: the comments are removed,
+and the lines ending with a backslash are joined
+*/
+func directives(content string) []directive {
+	result := []directive{}
+	current := ""
+	start := 0
+	for i, line := range strings.Split(strings.ReplaceAll(content, "\r\n", "\n"), "\n") {
+		trimmed := strings.TrimSpace(line)
+		if current == "" {
+			start = i + 1
+			if strings.HasPrefix(trimmed, "#") {
+				continue
+			}
+		}
+		if strings.HasSuffix(trimmed, "\\") {
+			current += strings.TrimSuffix(trimmed, "\\") + " "
+			continue
+		}
+		current += trimmed
+		if strings.TrimSpace(current) != "" {
+			result = append(result, directive{line: start, text: current})
+		}
+		current = ""
+	}
+	if strings.TrimSpace(current) != "" {
+		result = append(result, directive{line: start, text: current})
+	}
+	return result
+}
+
+/*
+splitWords splits a directive in words, a word can be double quoted
+(only the escaped double quotes are unescaped, the other backslashes
+are kept for the regexps)
+*/
+func splitWords(s string) ([]string, error) {
+	words := []string{}
+	for i := 0; i < len(s); {
+		switch {
+		case s[i] == ' ' || s[i] == '\t':
+			i++
+		case s[i] == '"':
+			var b strings.Builder
+			j := i + 1
+			for ; j < len(s) && s[j] != '"'; j++ {
+				if s[j] == '\\' && j+1 < len(s) && s[j+1] == '"' {
+					j++
+				}
+				b.WriteByte(s[j])
+			}
+			if j >= len(s) {
+				return nil, fmt.Errorf("unterminated quoted string")
+			}
+			words = append(words, b.String())
+			i = j + 1
+		default:
+			j := i
+			for j < len(s) && s[j] != ' ' && s[j] != '\t' {
+				j++
+			}
+			words = append(words, s[i:j])
+			i = j
+		}
+	}
+	return words, nil
+}
+
+/*
+splitActions splits a list of actions on the commas that are not
+in a single quoted value
+*/
+func splitActions(s string) []string {
+	actions := []string{}
+	quoted := false
+	start := 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '\'':
+			quoted = !quoted
+		case ',':
+			if !quoted {
+				actions = append(actions, strings.TrimSpace(s[start:i]))
+				start = i + 1
+			}
+		}
+	}
+	if last := strings.TrimSpace(s[start:]); last != "" {
+		actions = append(actions, last)
+	}
+	return actions
+}
+
+func unquote(s string) string {
+	if len(s) >= 2 && s[0] == '\'' && s[len(s)-1] == '\'' {
+		return strings.ReplaceAll(s[1:len(s)-1], `\'`, "'")
+	}
+	return s
+}
+
+/*
+parseRule parses the variables, the operator and the actions of a rule.
+The rule is returned with an error (to know its ID and if it is chained)
+when it can't be used
+*/
+func parseRule(variables string, operator string, actions string) (*Rule, error) {
+	r := &Rule{Phase: 2, Severity: -1}
+	var transforms []string
+	var err error
+
+	for _, a := range splitActions(actions) {
+		name, value, _ := strings.Cut(a, ":")
+		name = strings.TrimSpace(name)
+		value = unquote(strings.TrimSpace(value))
+		switch name {
+		case "id":
+			r.ID, _ = strconv.Atoi(value)
+		case "phase":
+			switch value {
+			case "request":
+				r.Phase = 2
+			case "response":
+				r.Phase = 4
+			case "logging":
+				r.Phase = 5
+			default:
+				r.Phase, _ = strconv.Atoi(value)
+			}
+		case "msg":
+			r.Msg = value
+		case "severity":
+			if n, e := strconv.Atoi(value); e == nil {
+				r.Severity = n
+			} else if s, ok := severities[strings.ToUpper(value)]; ok {
+				r.Severity = s
+			}
+		case "t":
+			transforms = append(transforms, value)
+		case "chain":
+			r.chained = true
+		case "capture":
+			r.Capture = true
+		case "setvar":
+			if e := parseSetVar(value); e != nil && err == nil {
+				err = e
+			}
+			r.SetVars = append(r.SetVars, value)
+		case "skipAfter":
+			r.SkipAfter = value
+		case "deny", "drop":
+			r.Disruptive = DISRUPTIVE_DENY
+		case "block":
+			r.Disruptive = DISRUPTIVE_BLOCK
+		case "pass":
+			r.Disruptive = DISRUPTIVE_PASS
+		case "allow":
+			r.Disruptive = DISRUPTIVE_ALLOW
+		default:
+			if name == "logdata" {
+				r.LogData = value
+			} else if !ignoredActions[name] && err == nil {
+				err = fmt.Errorf("unsupported action %s", name)
+			}
+		}
+	}
+	if err != nil {
+		return r, err
+	}
+
+	for _, t := range transforms {
+		if t == "none" {
+			r.Transforms = nil
+			continue
+		}
+		f, ok := transformations[t]
+		if !ok {
+			return r, fmt.Errorf("unsupported transformation %s", t)
+		}
+		r.Transforms = append(r.Transforms, f)
+	}
+	if variables == "" {
+		return r, nil
+	}
+	if r.Variables, err = parseVariables(variables); err != nil {
+		return r, err
+	}
+	if r.Operator, err = parseOperator(operator); err != nil {
+		return r, err
+	}
+	return r, nil
+}
+
+/*
+parseSetVar checks a setvar action, only the TX collection is supported
+(the persistent collections, like IP, are not)
+*/
+func parseSetVar(s string) error {
+	name, _, _ := strings.Cut(s, "=")
+	collection, key, _ := strings.Cut(strings.TrimPrefix(name, "!"), ".")
+	if !strings.EqualFold(collection, "tx") || key == "" {
+		return fmt.Errorf("unsupported setvar %s", name)
+	}
+	return nil
+}
@@ -0,0 +1,244 @@
+package modsec
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/nzin/taxsi2/internal/com"
+	"github.com/stretchr/testify/assert"
+)
+
+func newPayload(method string, rawurl string, headers map[string][]string, body string) *com.TaxsiCom {
+	u, _ := url.Parse(rawurl)
+	if headers == nil {
+		headers = map[string][]string{}
+	}
+	return &com.TaxsiCom{
+		Method:     method,
+		Url:        u,
+		Headers:    headers,
+		Body:       []byte(body),
+		RemoteAddr: "192.168.1.10",
+	}
+}
+
+func TestParse(t *testing.T) {
+	t.Run("happy path: rule", func(t *testing.T) {
+		rs := Parse("test.conf", `
+# a comment
+SecRule ARGS|!ARGS:/^(?:utm_|ga)/|REQUEST_HEADERS:User-Agent "@rx (?i)select.+from" \
+    "id:1000,phase:1,block,capture,t:none,t:lowercase,msg:'SQL \'select\'',severity:'ERROR',\
+    setvar:'tx.score=+%{tx.error_anomaly_score}',tag:'sqli'"
+`)
+		assert.Equal(t, 0, len(rs.Warnings))
+		assert.Equal(t, 1, len(rs.Rules))
+		r := rs.Rules[0]
+		assert.Equal(t, 1000, r.ID)
+		assert.Equal(t, 1, r.Phase)
+		assert.Equal(t, "SQL 'select'", r.Msg)
+		assert.Equal(t, 3, r.Severity)
+		assert.Equal(t, DISRUPTIVE_BLOCK, r.Disruptive)
+		assert.True(t, r.Capture)
+		assert.Equal(t, []string{"tx.score=+%{tx.error_anomaly_score}"}, r.SetVars)
+		assert.Equal(t, 1, len(r.Transforms))
+		assert.Equal(t, "rx", r.Operator.Name)
+		assert.Equal(t, 3, len(r.Variables))
+		assert.True(t, r.Variables[1].Exclude)
+		assert.NotNil(t, r.Variables[1].Regexp)
+		assert.Equal(t, "user-agent", r.Variables[2].Key)
+	})
+
+	t.Run("happy path: chain, marker, action and defaults", func(t *testing.T) {
+		rs := Parse("test.conf", `
+SecRule REQUEST_METHOD "POST" "id:1,deny,chain"
+    SecRule &ARGS:id "!@eq 1" "setvar:tx.bad"
+SecMarker END
+SecAction "id:2,phase:1,pass,nolog,skipAfter:END"
+SecRule RESPONSE_BODY "@contains secret" "id:3,phase:4,deny"
+SecRuleEngine On
+`)
+		assert.Equal(t, 0, len(rs.Warnings))
+		assert.Equal(t, 3, len(rs.Rules))
+		assert.Equal(t, 1, rs.ResponseRules)
+
+		r := rs.Rules[0]
+		assert.Equal(t, 2, r.Phase)
+		assert.Equal(t, -1, r.Severity)
+		assert.Equal(t, DISRUPTIVE_DENY, r.Disruptive)
+		assert.Equal(t, "rx", r.Operator.Name)
+		assert.NotNil(t, r.Chain)
+		assert.True(t, r.Chain.Variables[0].Count)
+		assert.True(t, r.Chain.Operator.Negated)
+		assert.Equal(t, "eq", r.Chain.Operator.Name)
+		assert.Equal(t, "END", rs.Rules[1].Marker)
+		assert.True(t, rs.Rules[2].unconditional)
+		assert.Equal(t, "END", rs.Rules[2].SkipAfter)
+	})
+
+	t.Run("not happy path: unsupported rules are skipped", func(t *testing.T) {
+		rs := Parse("test.conf", `
+SecRule ARGS "@pmFromFile words.data" "id:1,block"
+SecRule ARGS "@rx (?!foo)bar" "id:2,block"
+SecRule ARGS "@inspectFile check.sh" "id:3,block"
+SecRule GEO:COUNTRY_CODE "@streq FR" "id:4,block"
+SecRule ARGS "@rx foo" "id:5,block,t:sqlHexDecode"
+SecRule ARGS "@rx foo" "id:6,block,exec:run.lua"
+SecAction "id:7,setvar:ip.blocked=1"
+SecRule ARGS "@rx foo" "id:8,block,chain"
+    SecRule GEO:COUNTRY_CODE "@streq FR" ""
+SecRule ARGS "@rx foo
+Include other.conf
+SecRule ARGS "@rx foo" "id:10,block"
+SecRule ARGS "@rx foo" "id:9,block,chain"
+`)
+		assert.Equal(t, 1, len(rs.Rules))
+		assert.Equal(t, 10, rs.Rules[0].ID)
+		assert.Equal(t, []string{
+			"test.conf:2: rule 1 skipped: @pmf is not supported",
+			"test.conf:3: rule 2 skipped: regexp not supported: error parsing regexp: invalid or unsupported Perl syntax: `(?!`",
+			"test.conf:4: rule 3 skipped: unsupported operator @inspectFile",
+			"test.conf:5: rule 4 skipped: unsupported variable GEO:COUNTRY_CODE",
+			"test.conf:6: rule 5 skipped: unsupported transformation sqlHexDecode",
+			"test.conf:7: rule 6 skipped: unsupported action exec",
+			"test.conf:8: rule 7 skipped: unsupported setvar ip.blocked",
+			"test.conf:10: rule 8 skipped: bad chained rule (unsupported variable GEO:COUNTRY_CODE)",
+			"test.conf:11: unterminated quoted string",
+			"test.conf:12: unsupported directive Include",
+			"test.conf: rule 9: unterminated chain",
+		}, rs.Warnings)
+	})
+}
+
+func TestVariables(t *testing.T) {
+	post := newPayload("POST", "http://www.example.com/shop/cart.php?id=12&utm_source=mail", map[string][]string{
+		"Content-Type": {"application/x-www-form-urlencoded"},
+		"User-Agent":   {"curl/8.0"},
+		"Cookie":       {"session=abc; __utma=1"},
+	}, "name=Bob&ID2=x")
+
+	targets := func(variables string) []string {
+		vars, err := parseVariables(variables)
+		assert.Nil(t, err)
+		values := []string{}
+		for _, target := range newTransaction(post).targets(vars) {
+			values = append(values, target.name+"="+target.value)
+		}
+		return values
+	}
+
+	t.Run("happy path: collections and scalars", func(t *testing.T) {
+		assert.ElementsMatch(t, []string{"ARGS:id=12", "ARGS:utm_source=mail", "ARGS:name=Bob", "ARGS:ID2=x"}, targets("ARGS"))
+		assert.ElementsMatch(t, []string{"ARGS_GET:id=12", "ARGS_GET:utm_source=mail"}, targets("ARGS_GET"))
+		assert.ElementsMatch(t, []string{"ARGS_POST_NAMES:name=name", "ARGS_POST_NAMES:ID2=ID2"}, targets("ARGS_POST_NAMES"))
+		assert.ElementsMatch(t, []string{"ARGS:ID2=x", "REQUEST_HEADERS:User-Agent=curl/8.0"}, targets("ARGS:id2|REQUEST_HEADERS:user-agent"))
+		assert.ElementsMatch(t, []string{"ARGS:id=12", "ARGS:name=Bob", "ARGS:ID2=x"}, targets("ARGS|!ARGS:/^(?:utm_|ga)/"))
+		assert.ElementsMatch(t, []string{"REQUEST_COOKIES:session=abc"}, targets("REQUEST_COOKIES|!REQUEST_COOKIES:/__utm/"))
+		assert.Equal(t, []string{"&ARGS=4", "&REQUEST_HEADERS:host=0"}, targets("&ARGS|&REQUEST_HEADERS:Host"))
+		assert.Equal(t, []string{
+			"REQUEST_URI=/shop/cart.php?id=12&utm_source=mail",
+			"REQUEST_FILENAME=/shop/cart.php",
+			"REQUEST_BASENAME=cart.php",
+			"QUERY_STRING=id=12&utm_source=mail",
+			"REQUEST_LINE=POST /shop/cart.php?id=12&utm_source=mail HTTP/1.1",
+			"REQUEST_BODY_LENGTH=14",
+			"REQBODY_ERROR=0",
+		}, targets("REQUEST_URI|REQUEST_FILENAME|REQUEST_BASENAME|QUERY_STRING|REQUEST_LINE|REQUEST_BODY_LENGTH|REQBODY_ERROR"))
+	})
+
+	t.Run("not happy path: bad variables", func(t *testing.T) {
+		for _, variables := range []string{"FOO", "REQUEST_URI:foo", "ARGS:/(/", "!&ARGS"} {
+			_, err := parseVariables(variables)
+			assert.NotNil(t, err, variables)
+		}
+	})
+}
+
+func TestOperators(t *testing.T) {
+	t.Run("happy path: operators", func(t *testing.T) {
+		tx := newTransaction(newPayload("GET", "http://www.example.com/", nil, ""))
+		tx.tx["allowed_methods"] = "GET HEAD POST"
+		tx.tx["threshold"] = "5"
+
+		for _, c := range []struct {
+			operator string
+			value    string
+			expected bool
+		}{
+			{"@rx ^a+b$", "aab", true},
+			{"(?i)SELECT", "select", true},
+			{"!@rx ^a", "abc", false},
+			{"@pm sleep( benchmark(", "SELECT BENCHMARK(1000)", true},
+			{"@pm sleep( benchmark(", "benchmark", false},
+			{"@streq admin", "admin", true},
+			{"@contains dmi", "admin", true},
+			{"@containsWord select", "select * from", true},
+			{"@containsWord select", "selected", false},
+			{"@beginsWith /admin", "/admin/users", true},
+			{"@endsWith .php", "/index.php", true},
+			{"@within %{tx.allowed_methods}", "POST", true},
+			{"!@within %{tx.allowed_methods}", "TRACE", true},
+			{"@eq 0", "0", true},
+			{"@ge %{tx.threshold}", "5", true},
+			{"@gt %{tx.threshold}", "5", false},
+			{"@lt 10", "9", true},
+			{"@le 10", "11", false},
+			{"@ipMatch 10.0.0.0/8,192.168.1.10", "192.168.1.10", true},
+			{"@ipMatch 10.0.0.0/8,192.168.1.10", "192.168.1.11", false},
+			{"@detectSQLi", "1' OR '1'='1", true},
+			{"@detectSQLi", "hello world", false},
+			{"@detectXSS", "<script>alert(1)</script>", true},
+			{"@detectXSS", "hello world", false},
+			{"@unconditionalMatch", "", true},
+			{"@noMatch", "", false},
+			{"@validateByteRange 32-126", "hello", false},
+			{"@validateByteRange 32-126", "hello\x00", true},
+			{"@validateByteRange 9,10,13,32-126", "a\tb", false},
+			{"@validateUrlEncoding", "a%2fb", false},
+			{"@validateUrlEncoding", "a%zzb", true},
+			{"@validateUtf8Encoding", "h\xe9llo", true},
+		} {
+			op, err := parseOperator(c.operator)
+			assert.Nil(t, err, c.operator)
+			ok, _ := op.match(tx, c.value)
+			assert.Equal(t, c.expected, ok != op.Negated, c.operator+" "+c.value)
+		}
+	})
+
+	t.Run("not happy path: bad operators", func(t *testing.T) {
+		for _, operator := range []string{"@foo bar", "@rx (", "@pm", "@ipMatch foo", "@validateByteRange 1-300"} {
+			_, err := parseOperator(operator)
+			assert.NotNil(t, err, operator)
+		}
+	})
+}
+
+func TestTransformations(t *testing.T) {
+	t.Run("happy path: transformations", func(t *testing.T) {
+		for _, c := range []struct {
+			name     string
+			value    string
+			expected string
+		}{
+			{"lowercase", "SeLeCt", "select"},
+			{"urlDecode", "a%20b+c%zz", "a b c%zz"},
+			{"urlDecodeUni", "%u003cscript%3e", "<script>"},
+			{"htmlEntityDecode", "&lt;a&#x20;href&#61;&quot;", `<a href="`},
+			{"jsDecode", `\x3cscript>\n`, "<script>\n"},
+			{"base64Decode", "c2VsZWN0", "select"},
+			{"hexDecode", "73656c656374", "select"},
+			{"compressWhitespace", "a \t\n b", "a b"},
+			{"removeWhitespace", "sel ect\t", "select"},
+			{"removeNulls", "a\x00b", "ab"},
+			{"removeComments", "sel/* x */ect", "select"},
+			{"trim", "  a  ", "a"},
+			{"normalizePath", "/a/./b/../c//d", "/a/c/d"},
+			{"normalizePathWin", `\a\..\b`, "/b"},
+			{"cmdLine", `C^at "/etc/pass'wd" ;ls`, "cat/etc/passwd ls"},
+			{"length", "hello", "5"},
+		} {
+			f, ok := transformations[c.name]
+			assert.True(t, ok, c.name)
+			assert.Equal(t, c.expected, f(c.value), c.name)
+		}
+	})
+}
@@ -0,0 +1,204 @@
+package modsec
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/nzin/taxsi2/internal/com"
+)
+
+// the anomaly score of a (block) rule, by severity
+var severityScores = map[int]int{
+	0: 5, 1: 5, 2: 5, // EMERGENCY, ALERT, CRITICAL
+	3: 4, // ERROR
+	4: 3, // WARNING
+	5: 2, // NOTICE
+}
+
+var macroRegexp = regexp.MustCompile(`%\{([^}]+)\}`)
+
+/*
+transaction is the state of the evaluation of the rules for a request
+*/
+type transaction struct {
+	payload *com.TaxsiCom
+	// the TX collection (the keys are lowercase)
+	tx map[string]string
+	// the rule being evaluated
+	rule *Rule
+	// the last matched target, and all the targets matched by the rule
+	matchedVar target
+	matched    []target
+
+	// the anomaly score, and the last block rule that raised it
+	score     int
+	scoreRule *Rule
+}
+
+func newTransaction(payload *com.TaxsiCom) *transaction {
+	return &transaction{
+		payload: payload,
+		tx:      make(map[string]string),
+	}
+}
+
+func (t *transaction) bodyError() string {
+	if t.payload.Parse().BodyError != nil {
+		return "1"
+	}
+	return "0"
+}
+
+/*
+expand replaces the macros, like %{tx.threshold} or %{MATCHED_VAR}
+*/
+func (t *transaction) expand(s string) string {
+	if !strings.Contains(s, "%{") {
+		return s
+	}
+	return macroRegexp.ReplaceAllStringFunc(s, func(m string) string {
+		collection, key, hasKey := strings.Cut(m[2:len(m)-1], ".")
+		collection = strings.ToUpper(collection)
+		switch {
+		case collection == "TX" && hasKey:
+			return t.tx[strings.ToLower(key)]
+		case collection == "RULE" && t.rule != nil:
+			switch strings.ToLower(key) {
+			case "id":
+				return strconv.Itoa(t.rule.ID)
+			case "msg":
+				return t.rule.Msg
+			case "severity":
+				return strconv.Itoa(t.rule.Severity)
+			}
+		case !hasKey:
+			if f, ok := scalars[collection]; ok {
+				return f(t)
+			}
+		default:
+			if f, ok := collections[collection]; ok {
+				for _, target := range f(t) {
+					if strings.EqualFold(target.key, key) {
+						return target.value
+					}
+				}
+			}
+		}
+		return ""
+	})
+}
+
+/*
+setVar runs a setvar action: tx.name=value, tx.name=+value,
+tx.name=-value, tx.name (set to 1) or !tx.name (removed)
+*/
+func (t *transaction) setVar(action string) {
+	name, value, hasValue := strings.Cut(t.expand(action), "=")
+	remove := strings.HasPrefix(name, "!")
+	name = strings.ToLower(strings.TrimPrefix(name, "!"))
+	// (only the TX collection is supported, see parseSetVar)
+	key := strings.TrimPrefix(name, "tx.")
+
+	switch {
+	case remove:
+		delete(t.tx, key)
+	case !hasValue:
+		t.tx[key] = "1"
+	case strings.HasPrefix(value, "+") || strings.HasPrefix(value, "-"):
+		current, _ := strconv.Atoi(t.tx[key])
+		delta, _ := strconv.Atoi(value[1:])
+		if value[0] == '-' {
+			delta = -delta
+		}
+		t.tx[key] = strconv.Itoa(current + delta)
+	default:
+		t.tx[key] = value
+	}
+}
+
+/*
+evaluate checks a rule (without its chain) against the request
+*/
+func (t *transaction) evaluate(r *Rule) bool {
+	if r.unconditional {
+		return true
+	}
+	matched := false
+	captured := false
+	for _, target := range t.targets(r.Variables) {
+		value := target.value
+		for _, transform := range r.Transforms {
+			value = transform(value)
+		}
+		ok, captures := r.Operator.match(t, value)
+		if ok == r.Operator.Negated {
+			continue
+		}
+		matched = true
+		t.matchedVar = target
+		t.matched = append(t.matched, target)
+		if r.Capture && !captured && !r.Operator.Negated {
+			captured = true
+			for i := 0; i < 10; i++ {
+				if i < len(captures) {
+					t.tx[strconv.Itoa(i)] = captures[i]
+				} else {
+					delete(t.tx, strconv.Itoa(i))
+				}
+			}
+		}
+	}
+	return matched
+}
+
+/*
+run evaluates the rules of a phase. It returns the rule that
+decided (a deny or an allow rule), nil to continue
+*/
+func (t *transaction) run(rules []*Rule, phase int) *Rule {
+	skipTo := ""
+	for _, r := range rules {
+		if r.Marker != "" {
+			if r.Marker == skipTo {
+				skipTo = ""
+			}
+			continue
+		}
+		if skipTo != "" || r.Phase != phase {
+			continue
+		}
+
+		t.rule = r
+		t.matched = nil
+		matched := t.evaluate(r)
+		for c := r.Chain; matched && c != nil; c = c.Chain {
+			matched = t.evaluate(c)
+		}
+		if !matched {
+			continue
+		}
+
+		for c := r; c != nil; c = c.Chain {
+			for _, s := range c.SetVars {
+				t.setVar(s)
+			}
+		}
+		switch r.Disruptive {
+		case DISRUPTIVE_DENY, DISRUPTIVE_ALLOW:
+			return r
+		case DISRUPTIVE_BLOCK:
+			// (a block rule without severity is a critical one)
+			score, ok := severityScores[r.Severity]
+			if !ok && r.Severity < 0 {
+				score = severityScores[2]
+			}
+			t.score += score
+			t.scoreRule = r
+		}
+		if r.SkipAfter != "" {
+			skipTo = r.SkipAfter
+		}
+	}
+	return nil
+}
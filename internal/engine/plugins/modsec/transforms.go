@@ -0,0 +1,286 @@
+package modsec
+
+import (
+	"crypto/md5"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"html"
+	"path"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+/*
+transform is a SecLang transformation (t:), applied to the
+values before the operator
+*/
+type transform func(string) string
+
+var transformations = map[string]transform{
+	"lowercase":          strings.ToLower,
+	"uppercase":          strings.ToUpper,
+	"urlDecode":          func(s string) string { return urlDecode(s, false) },
+	"urlDecodeUni":       func(s string) string { return urlDecode(s, true) },
+	"htmlEntityDecode":   html.UnescapeString,
+	"jsDecode":           jsDecode,
+	"cssDecode":          cssDecode,
+	"base64Decode":       base64Decode,
+	"base64DecodeExt":    base64Decode,
+	"hexDecode":          hexDecode,
+	"compressWhitespace": compressWhitespace,
+	"removeWhitespace":   func(s string) string { return strings.Join(strings.Fields(s), "") },
+	"removeNulls":        func(s string) string { return strings.ReplaceAll(s, "\x00", "") },
+	"replaceNulls":       func(s string) string { return strings.ReplaceAll(s, "\x00", " ") },
+	"removeComments":     removeComments,
+	"trim":               strings.TrimSpace,
+	"trimLeft":           func(s string) string { return strings.TrimLeft(s, " \t\n\r\f\v") },
+	"trimRight":          func(s string) string { return strings.TrimRight(s, " \t\n\r\f\v") },
+	"normalizePath":      normalizePath,
+	"normalisePath":      normalizePath,
+	"normalizePathWin":   func(s string) string { return normalizePath(strings.ReplaceAll(s, `\`, "/")) },
+	"normalisePathWin":   func(s string) string { return normalizePath(strings.ReplaceAll(s, `\`, "/")) },
+	"cmdLine":            cmdLine,
+	"utf8toUnicode":      utf8toUnicode,
+	"length":             func(s string) string { return strconv.Itoa(len(s)) },
+	"md5":                func(s string) string { h := md5.Sum([]byte(s)); return string(h[:]) },
+	"sha1":               func(s string) string { h := sha1.Sum([]byte(s)); return string(h[:]) },
+}
+
+func isHex(c byte) bool {
+	return (c >= '0' && c <= '9') || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F')
+}
+
+func isHexString(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if !isHex(s[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+/*
+urlDecode decodes the %XX escapes and the + (and the IIS %uXXXX escapes
+if unicode is set), the invalid escapes are kept as is
+*/
+func urlDecode(s string, unicode bool) string {
+	if !strings.ContainsAny(s, "%+") {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		switch {
+		case s[i] == '+':
+			b.WriteByte(' ')
+		case s[i] == '%' && unicode && i+5 < len(s) && (s[i+1] == 'u' || s[i+1] == 'U') && isHexString(s[i+2:i+6]):
+			n, _ := strconv.ParseUint(s[i+2:i+6], 16, 32)
+			// (like ModSecurity, the fullwidth ASCII is mapped to ASCII)
+			if n >= 0xff01 && n <= 0xff5e {
+				n -= 0xfee0
+			}
+			b.WriteRune(rune(n))
+			i += 5
+		case s[i] == '%' && i+2 < len(s) && isHex(s[i+1]) && isHex(s[i+2]):
+			n, _ := strconv.ParseUint(s[i+1:i+3], 16, 8)
+			b.WriteByte(byte(n))
+			i += 2
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	return b.String()
+}
+
+/*
+jsDecode decodes the JavaScript escapes (\xHH, \uHHHH, \n...)
+*/
+func jsDecode(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i+1 >= len(s) {
+			b.WriteByte(s[i])
+			continue
+		}
+		c := s[i+1]
+		switch {
+		case c == 'x' && i+3 < len(s) && isHexString(s[i+2:i+4]):
+			n, _ := strconv.ParseUint(s[i+2:i+4], 16, 8)
+			b.WriteByte(byte(n))
+			i += 3
+		case c == 'u' && i+5 < len(s) && isHexString(s[i+2:i+6]):
+			n, _ := strconv.ParseUint(s[i+2:i+6], 16, 32)
+			b.WriteRune(rune(n))
+			i += 5
+		default:
+			escapes := map[byte]byte{'n': '\n', 't': '\t', 'r': '\r', 'b': '\b', 'f': '\f', 'v': '\v', '0': 0}
+			if e, ok := escapes[c]; ok {
+				b.WriteByte(e)
+			} else {
+				b.WriteByte(c)
+			}
+			i++
+		}
+	}
+	return b.String()
+}
+
+/*
+cssDecode decodes the CSS escapes (\HHHHHH, or \c)
+*/
+func cssDecode(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i+1 >= len(s) {
+			b.WriteByte(s[i])
+			continue
+		}
+		j := i + 1
+		for j < len(s) && j < i+7 && isHex(s[j]) {
+			j++
+		}
+		if j > i+1 {
+			n, _ := strconv.ParseUint(s[i+1:j], 16, 32)
+			if utf8.ValidRune(rune(n)) {
+				b.WriteRune(rune(n))
+			}
+			// a whitespace ends the escape
+			if j < len(s) && s[j] == ' ' {
+				j++
+			}
+			i = j - 1
+			continue
+		}
+		b.WriteByte(s[i+1])
+		i++
+	}
+	return b.String()
+}
+
+func base64Decode(s string) string {
+	s = strings.TrimRight(strings.TrimSpace(s), "=")
+	if d, err := base64.RawStdEncoding.DecodeString(s); err == nil {
+		return string(d)
+	}
+	if d, err := base64.RawURLEncoding.DecodeString(s); err == nil {
+		return string(d)
+	}
+	return s
+}
+
+func hexDecode(s string) string {
+	if d, err := hex.DecodeString(s); err == nil {
+		return string(d)
+	}
+	return s
+}
+
+func compressWhitespace(s string) string {
+	var b strings.Builder
+	space := false
+	for _, r := range s {
+		if r == ' ' || r == '\t' || r == '\n' || r == '\r' || r == '\f' || r == '\v' || r == 0xa0 {
+			space = true
+			continue
+		}
+		if space {
+			b.WriteByte(' ')
+			space = false
+		}
+		b.WriteRune(r)
+	}
+	if space {
+		b.WriteByte(' ')
+	}
+	return b.String()
+}
+
+/*
+removeComments removes the C style, SQL (--) and shell (#) comments
+*/
+func removeComments(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		switch {
+		case strings.HasPrefix(s[i:], "/*"):
+			end := strings.Index(s[i+2:], "*/")
+			if end < 0 {
+				return b.String()
+			}
+			i += end + 3
+		case strings.HasPrefix(s[i:], "--") || s[i] == '#':
+			end := strings.IndexAny(s[i:], "\r\n")
+			if end < 0 {
+				return b.String()
+			}
+			i += end - 1
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	return b.String()
+}
+
+func normalizePath(s string) string {
+	if s == "" {
+		return s
+	}
+	cleaned := path.Clean(s)
+	// (path.Clean removes the trailing slash)
+	if strings.HasSuffix(s, "/") && cleaned != "/" {
+		cleaned += "/"
+	}
+	return cleaned
+}
+
+/*
+cmdLine normalizes a command line like ModSecurity: the \ " ' ^
+are removed, the spaces before / and ( are removed, the , and ;
+are spaces, the spaces are compressed, and it is lowercased
+*/
+func cmdLine(s string) string {
+	var b strings.Builder
+	space := false
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch c {
+		case '\\', '"', '\'', '^':
+			continue
+		case ' ', '\t', '\n', '\r', ',', ';':
+			space = true
+			continue
+		}
+		if space && c != '/' && c != '(' && b.Len() > 0 {
+			b.WriteByte(' ')
+		}
+		space = false
+		if c >= 'A' && c <= 'Z' {
+			c += 'a' - 'A'
+		}
+		b.WriteByte(c)
+	}
+	return b.String()
+}
+
+/*
+utf8toUnicode escapes the non ASCII characters as %uHHHH
+*/
+func utf8toUnicode(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if r < 0x80 {
+			b.WriteRune(r)
+			continue
+		}
+		b.WriteString(fmt.Sprintf("%%u%04x", r))
+	}
+	return b.String()
+}
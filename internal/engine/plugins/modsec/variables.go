@@ -0,0 +1,302 @@
+package modsec
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/nzin/taxsi2/internal/com"
+)
+
+/*
+Variable is a SecRule variable, like ARGS, ARGS:id, !ARGS:/^utm_/ or &TX:score
+*/
+type Variable struct {
+	Collection string
+	// the key of the collection (lowercase, "" for all the keys)
+	Key    string
+	Regexp *regexp.Regexp
+	// the targets of the variable are removed from the other variables
+	Exclude bool
+	// the number of targets (instead of the targets)
+	Count bool
+}
+
+/*
+target is a value a rule is checked against
+*/
+type target struct {
+	// like ARGS:id
+	name  string
+	key   string
+	value string
+}
+
+// the sources of the ARGS, ARGS_GET and ARGS_POST fields
+var (
+	argsSources     = []string{com.SOURCE_QUERY, com.SOURCE_FORM, com.SOURCE_JSON, com.SOURCE_XML}
+	argsGetSources  = []string{com.SOURCE_QUERY}
+	argsPostSources = []string{com.SOURCE_FORM, com.SOURCE_JSON, com.SOURCE_XML}
+)
+
+// the collections (with a key)
+var collections = map[string]func(t *transaction) []target{
+	"ARGS": func(t *transaction) []target {
+		return t.fields("ARGS", argsSources...)
+	},
+	"ARGS_GET": func(t *transaction) []target {
+		return t.fields("ARGS_GET", argsGetSources...)
+	},
+	"ARGS_POST": func(t *transaction) []target {
+		return t.fields("ARGS_POST", argsPostSources...)
+	},
+	"ARGS_NAMES": func(t *transaction) []target {
+		return names("ARGS_NAMES", t.fields("ARGS", argsSources...))
+	},
+	"ARGS_GET_NAMES": func(t *transaction) []target {
+		return names("ARGS_GET_NAMES", t.fields("ARGS_GET", argsGetSources...))
+	},
+	"ARGS_POST_NAMES": func(t *transaction) []target {
+		return names("ARGS_POST_NAMES", t.fields("ARGS_POST", argsPostSources...))
+	},
+	"XML": func(t *transaction) []target {
+		return t.fields("XML", com.SOURCE_XML)
+	},
+	"REQUEST_COOKIES": func(t *transaction) []target {
+		return t.fields("REQUEST_COOKIES", com.SOURCE_COOKIE)
+	},
+	"REQUEST_COOKIES_NAMES": func(t *transaction) []target {
+		return names("REQUEST_COOKIES_NAMES", t.fields("REQUEST_COOKIES", com.SOURCE_COOKIE))
+	},
+	"REQUEST_HEADERS": func(t *transaction) []target {
+		targets := []target{}
+		for name, values := range t.payload.Headers {
+			for _, v := range values {
+				targets = append(targets, target{name: "REQUEST_HEADERS:" + name, key: name, value: v})
+			}
+		}
+		return targets
+	},
+	"REQUEST_HEADERS_NAMES": func(t *transaction) []target {
+		targets := []target{}
+		for name := range t.payload.Headers {
+			targets = append(targets, target{name: "REQUEST_HEADERS_NAMES:" + name, key: name, value: name})
+		}
+		return targets
+	},
+	"FILES": func(t *transaction) []target {
+		return t.files("FILES")
+	},
+	"FILES_NAMES": func(t *transaction) []target {
+		return names("FILES_NAMES", t.files("FILES"))
+	},
+	"TX": func(t *transaction) []target {
+		targets := []target{}
+		for k, v := range t.tx {
+			targets = append(targets, target{name: "TX:" + k, key: k, value: v})
+		}
+		return targets
+	},
+	"MATCHED_VARS": func(t *transaction) []target {
+		targets := []target{}
+		for _, m := range t.matched {
+			targets = append(targets, target{name: "MATCHED_VARS:" + m.name, key: m.name, value: m.value})
+		}
+		return targets
+	},
+	"MATCHED_VARS_NAMES": func(t *transaction) []target {
+		targets := []target{}
+		for _, m := range t.matched {
+			targets = append(targets, target{name: "MATCHED_VARS_NAMES:" + m.name, key: m.name, value: m.name})
+		}
+		return targets
+	},
+}
+
+// the variables without key
+var scalars = map[string]func(t *transaction) string{
+	"REQUEST_URI":      func(t *transaction) string { return t.payload.Url.RequestURI() },
+	"REQUEST_URI_RAW":  func(t *transaction) string { return t.payload.Url.String() },
+	"REQUEST_FILENAME": func(t *transaction) string { return t.payload.Url.Path },
+	"REQUEST_BASENAME": func(t *transaction) string { return path.Base(t.payload.Url.Path) },
+	"REQUEST_METHOD":   func(t *transaction) string { return t.payload.Method },
+	// (the protocol is not known)
+	"REQUEST_PROTOCOL": func(t *transaction) string { return "HTTP/1.1" },
+	"REQUEST_LINE": func(t *transaction) string {
+		return t.payload.Method + " " + t.payload.Url.RequestURI() + " HTTP/1.1"
+	},
+	"QUERY_STRING":        func(t *transaction) string { return t.payload.Url.RawQuery },
+	"REQUEST_BODY":        func(t *transaction) string { return string(t.payload.Body) },
+	"REQUEST_BODY_LENGTH": func(t *transaction) string { return strconv.Itoa(len(t.payload.Body)) },
+	"REMOTE_ADDR":         func(t *transaction) string { return t.payload.RemoteAddr },
+	"SERVER_NAME":         func(t *transaction) string { return t.payload.Url.Hostname() },
+	"MATCHED_VAR":         func(t *transaction) string { return t.matchedVar.value },
+	"MATCHED_VAR_NAME":    func(t *transaction) string { return t.matchedVar.name },
+	"REQBODY_ERROR":       func(t *transaction) string { return t.bodyError() },
+	"REQBODY_PROCESSOR_ERROR": func(t *transaction) string {
+		return t.bodyError()
+	},
+	"ARGS_COMBINED_SIZE": func(t *transaction) string {
+		size := 0
+		for _, a := range t.fields("ARGS", argsSources...) {
+			size += len(a.key) + len(a.value)
+		}
+		return strconv.Itoa(size)
+	},
+	"FILES_COMBINED_SIZE": func(t *transaction) string {
+		size := 0
+		for _, f := range t.payload.Parse().Files {
+			size += len(f.Content)
+		}
+		return strconv.Itoa(size)
+	},
+}
+
+/*
+fields returns the fields of some sources as the targets of a collection
+*/
+func (t *transaction) fields(collection string, sources ...string) []target {
+	targets := []target{}
+	for _, f := range t.payload.Parse().FieldsFrom(sources...) {
+		targets = append(targets, target{name: collection + ":" + f.Name, key: f.Name, value: f.Value})
+	}
+	return targets
+}
+
+/*
+files returns the names of the uploaded files (by form field name)
+*/
+func (t *transaction) files(collection string) []target {
+	targets := []target{}
+	for _, f := range t.payload.Parse().Files {
+		targets = append(targets, target{name: collection + ":" + f.Name, key: f.Name, value: f.Filename})
+	}
+	return targets
+}
+
+func names(collection string, targets []target) []target {
+	result := []target{}
+	for _, t := range targets {
+		result = append(result, target{name: collection + ":" + t.key, key: t.key, value: t.key})
+	}
+	return result
+}
+
+/*
+splitVariables splits a list of variables on the | that are not in
+a /regexp/ key
+*/
+func splitVariables(s string) []string {
+	parts := []string{}
+	start := 0
+	inRegexp := false
+	for i := 0; i < len(s); i++ {
+		switch {
+		case s[i] == '\\' && inRegexp:
+			i++
+		case s[i] == '/' && (inRegexp || (i > 0 && s[i-1] == ':')):
+			inRegexp = !inRegexp
+		case s[i] == '|' && !inRegexp:
+			parts = append(parts, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, s[start:])
+}
+
+/*
+parseVariables parses the variables of a SecRule
+*/
+func parseVariables(s string) ([]*Variable, error) {
+	variables := []*Variable{}
+	for _, part := range splitVariables(s) {
+		v := &Variable{}
+		part = strings.TrimSpace(part)
+		if strings.HasPrefix(part, "!") {
+			v.Exclude = true
+			part = part[1:]
+		}
+		if strings.HasPrefix(part, "&") {
+			v.Count = true
+			part = part[1:]
+		}
+		collection, key, hasKey := strings.Cut(part, ":")
+		v.Collection = strings.ToUpper(collection)
+
+		if _, ok := collections[v.Collection]; !ok {
+			if _, ok := scalars[v.Collection]; !ok || hasKey {
+				return nil, fmt.Errorf("unsupported variable %s", part)
+			}
+		}
+		if strings.HasPrefix(key, "/") && strings.HasSuffix(key, "/") && len(key) >= 2 {
+			re, err := regexp.Compile(key[1 : len(key)-1])
+			if err != nil {
+				return nil, fmt.Errorf("bad variable %s: %v", part, err)
+			}
+			v.Regexp = re
+		} else {
+			v.Key = strings.ToLower(strings.Trim(key, "'"))
+			// (XML:/* is all the XML values)
+			if v.Collection == "XML" {
+				v.Key = ""
+			}
+		}
+		if v.Exclude && v.Count {
+			return nil, fmt.Errorf("bad variable %s", part)
+		}
+		variables = append(variables, v)
+	}
+	return variables, nil
+}
+
+func (v *Variable) matches(t target) bool {
+	if v.Regexp != nil {
+		return v.Regexp.MatchString(t.key)
+	}
+	return v.Key == "" || strings.ToLower(t.key) == v.Key
+}
+
+/*
+targets returns the values of the variables of a rule
+*/
+func (t *transaction) targets(variables []*Variable) []target {
+	result := []target{}
+	for _, v := range variables {
+		if v.Exclude {
+			continue
+		}
+		var values []target
+		if f, ok := scalars[v.Collection]; ok {
+			values = []target{{name: v.Collection, value: f(t)}}
+		} else {
+			for _, target := range collections[v.Collection](t) {
+				if v.matches(target) {
+					values = append(values, target)
+				}
+			}
+		}
+		if v.Count {
+			name := v.Collection
+			if v.Key != "" {
+				name += ":" + v.Key
+			}
+			result = append(result, target{name: "&" + name, value: strconv.Itoa(len(values))})
+			continue
+		}
+		for _, value := range values {
+			excluded := false
+			for _, e := range variables {
+				if e.Exclude && e.Collection == v.Collection && e.matches(value) {
+					excluded = true
+					break
+				}
+			}
+			if !excluded {
+				result = append(result, value)
+			}
+		}
+	}
+	return result
+}
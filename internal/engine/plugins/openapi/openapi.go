@@ -0,0 +1,112 @@
+package openapi
+
+import (
+	"context"
+	"net"
+	"strings"
+	"sync"
+
+	"github.com/nzin/taxsi2/internal/com"
+	"github.com/nzin/taxsi2/internal/db"
+	"github.com/nzin/taxsi2/internal/engine"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// output variable
+	VARIABLE_VIOLATION = "openapi_violation"
+)
+
+/*
+OpenapiWafPlugin rejects the requests that don't match the OpenAPI
+spec of their host (the hosts without spec are not checked)
+*/
+type OpenapiWafPlugin struct {
+	ds    db.DbServiceOpenapi
+	mu    sync.RWMutex
+	specs map[string]*Spec
+}
+
+func NewOpenapiWafPlugin(ds db.DbServiceOpenapi) (engine.WafEnginePlugin, error) {
+	return newOpenapiWafPlugin(ds)
+}
+
+func newOpenapiWafPlugin(ds db.DbServiceOpenapi) (*OpenapiWafPlugin, error) {
+	o := &OpenapiWafPlugin{
+		ds:    ds,
+		specs: make(map[string]*Spec),
+	}
+	if err := o.loadSpecs(); err != nil {
+		return nil, err
+	}
+	ds.SubscribeChanges(db.CHANGELOG_TABLE_OPENAPI, o)
+	return o, nil
+}
+
+func (o *OpenapiWafPlugin) Name() string {
+	return "openapi"
+}
+
+func (o *OpenapiWafPlugin) loadSpecs() error {
+	rows, err := o.ds.GetOpenapiSpecs()
+	if err != nil {
+		return err
+	}
+
+	specs := make(map[string]*Spec)
+	for _, row := range rows {
+		s, err := LoadSpec(row.Spec)
+		if err != nil {
+			logrus.Errorf("openapi spec of %s: %v", row.Host, err)
+			continue
+		}
+		specs[strings.ToLower(row.Host)] = s
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.specs = specs
+	return nil
+}
+
+// NotifyDbChange is called when a spec changes
+func (o *OpenapiWafPlugin) NotifyDbChange(key string) {
+	if err := o.loadSpecs(); err != nil {
+		logrus.Errorf("Error reading openapi specs: %v", err)
+	}
+}
+
+/*
+specFor returns the spec of the host of a request (with or without its port)
+*/
+func (o *OpenapiWafPlugin) specFor(payload *com.TaxsiCom) *Spec {
+	host := ""
+	if hosts := payload.GetHeader("Host"); len(hosts) > 0 {
+		host = hosts[0]
+	} else if payload.Url != nil {
+		host = payload.Url.Host
+	}
+	host = strings.ToLower(host)
+
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+	if s, ok := o.specs[host]; ok {
+		return s
+	}
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		return o.specs[h]
+	}
+	return nil
+}
+
+func (o *OpenapiWafPlugin) Scan(ctx context.Context, payload *com.TaxsiCom) bool {
+	s := o.specFor(payload)
+	if s == nil {
+		return true
+	}
+	if violation := s.Validate(payload); violation != "" {
+		payload.SetVariable(VARIABLE_VIOLATION, violation)
+		return false
+	}
+	return true
+}
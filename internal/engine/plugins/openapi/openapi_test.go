@@ -0,0 +1,153 @@
+package openapi
+
+import (
+	"context"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/nzin/taxsi2/internal/com"
+	"github.com/nzin/taxsi2/internal/db"
+	"github.com/stretchr/testify/assert"
+)
+
+/*
+ * This is a mock implementation of the db.DbServiceOpenapi interface
+ */
+type DbServiceOpenapiMock struct {
+	specs     []db.OpenapiSpec
+	listeners []db.DbChangeListener
+}
+
+func (m *DbServiceOpenapiMock) SubscribeChanges(table int, listener db.DbChangeListener) {
+	m.listeners = append(m.listeners, listener)
+}
+func (m *DbServiceOpenapiMock) GetOpenapiSpecs() ([]db.OpenapiSpec, error) {
+	return m.specs, nil
+}
+func (m *DbServiceOpenapiMock) SetOpenapiSpec(host string, spec []byte) error {
+	return nil
+}
+func (m *DbServiceOpenapiMock) DeleteOpenapiSpec(host string) error {
+	return nil
+}
+
+func request(method string, rawurl string, headers map[string][]string, body string) *com.TaxsiCom {
+	u, _ := url.Parse(rawurl)
+	if headers == nil {
+		headers = map[string][]string{}
+	}
+	return &com.TaxsiCom{
+		Method:  method,
+		Url:     u,
+		Headers: headers,
+		Body:    []byte(body),
+	}
+}
+
+var JSON = map[string][]string{"Content-Type": {"application/json"}}
+
+func TestLoadSpec(t *testing.T) {
+	t.Run("happy path: JSON and YAML specs", func(t *testing.T) {
+		s, err := LoadSpec([]byte(`{"swagger": "2.0", "info": {"title": "t", "version": "1"}, "paths": {"/a": {"get": {}}}}`))
+		assert.Nil(t, err)
+		assert.Equal(t, 1, len(s.routes))
+
+		raw, err := os.ReadFile("testdata/petstore.yaml")
+		assert.Nil(t, err)
+		s, err = LoadSpec(raw)
+		assert.Nil(t, err)
+		assert.Equal(t, "/api/v1", s.basePath)
+		// the literal paths first
+		assert.Equal(t, "/pets/mine", s.routes[0].template)
+	})
+
+	t.Run("not happy path: bad specs", func(t *testing.T) {
+		_, err := LoadSpec([]byte(`{"openapi": "3.0.0"}`))
+		assert.NotNil(t, err)
+		_, err = LoadSpec([]byte(`{not a spec`))
+		assert.NotNil(t, err)
+	})
+}
+
+func TestOpenapiPlugin(t *testing.T) {
+	raw, err := os.ReadFile("testdata/petstore.yaml")
+	assert.Nil(t, err)
+	ds := &DbServiceOpenapiMock{
+		specs: []db.OpenapiSpec{
+			{Host: "api.example.com", Spec: raw},
+			{Host: "broken.example.com", Spec: []byte("{")},
+		},
+	}
+	plugin, err := newOpenapiWafPlugin(ds)
+	assert.Nil(t, err)
+
+	check := func(t *testing.T, payload *com.TaxsiCom, violation string) {
+		assert.False(t, plugin.Scan(context.Background(), payload))
+		assert.Contains(t, payload.Variables[VARIABLE_VIOLATION], violation)
+	}
+
+	t.Run("happy path: the hosts without spec are not checked", func(t *testing.T) {
+		assert.True(t, plugin.Scan(context.Background(), request("GET", "http://www.example.com/whatever", nil, "")))
+		assert.True(t, plugin.Scan(context.Background(), request("GET", "http://broken.example.com/whatever", nil, "")))
+	})
+
+	t.Run("happy path: requests matching the spec", func(t *testing.T) {
+		assert.True(t, plugin.Scan(context.Background(), request("GET", "http://api.example.com/api/v1/pets?limit=10&status=sold&tags=a,b", nil, "")))
+		assert.True(t, plugin.Scan(context.Background(), request("GET", "http://api.example.com:443/api/v1/pets/42", nil, "")))
+		assert.True(t, plugin.Scan(context.Background(), request("GET", "http://www.example.com/api/v1/pets/mine", map[string][]string{
+			"Host":      {"API.example.com"},
+			"X-Api-Key": {"secret"},
+		}, "")))
+		assert.True(t, plugin.Scan(context.Background(), request("POST", "http://api.example.com/api/v1/pets", JSON, `{"name": "rex", "email": "bob@example.com", "age": 3}`)))
+
+		body := strings.ReplaceAll("--XXX\nContent-Disposition: form-data; name=\"caption\"\n\nmy dog\n--XXX\nContent-Disposition: form-data; name=\"photo\"; filename=\"rex.png\"\nContent-Type: image/png\n\nPNG\n--XXX--\n", "\n", "\r\n")
+		assert.True(t, plugin.Scan(context.Background(), request("POST", "http://api.example.com/api/v1/pets/42/photo", map[string][]string{
+			"Content-Type": {"multipart/form-data; boundary=XXX"},
+		}, body)))
+	})
+
+	t.Run("not happy path: unknown paths and methods", func(t *testing.T) {
+		check(t, request("GET", "http://api.example.com/api/v1/users", nil, ""), "unknown path /api/v1/users")
+		check(t, request("GET", "http://api.example.com/admin", nil, ""), "unknown path /admin")
+		check(t, request("PUT", "http://api.example.com/api/v1/pets/42", nil, ""), "method PUT not allowed on /pets/{id}")
+	})
+
+	t.Run("not happy path: parameters", func(t *testing.T) {
+		check(t, request("GET", "http://api.example.com/api/v1/pets?debug=1", nil, ""), "unknown query parameter debug")
+		check(t, request("GET", "http://api.example.com/api/v1/pets?limit=ten", nil, ""), `query parameter limit: "ten" is not of type integer`)
+		check(t, request("GET", "http://api.example.com/api/v1/pets?limit=1000", nil, ""), "query parameter limit")
+		check(t, request("GET", "http://api.example.com/api/v1/pets?status=lost", nil, ""), "query parameter status")
+		check(t, request("GET", "http://api.example.com/api/v1/pets?tags=a,b,c,d", nil, ""), "query parameter tags")
+		check(t, request("GET", "http://api.example.com/api/v1/pets/1%20or%201=1", nil, ""), "path parameter id")
+		check(t, request("GET", "http://api.example.com/api/v1/pets/mine", nil, ""), "missing header parameter X-Api-Key")
+		check(t, request("DELETE", "http://api.example.com/api/v1/pets/42", JSON, `{"all": true}`), "unexpected body for DELETE /pets/{id}")
+	})
+
+	t.Run("not happy path: bodies", func(t *testing.T) {
+		check(t, request("POST", "http://api.example.com/api/v1/pets", JSON, ""), "missing body")
+		check(t, request("POST", "http://api.example.com/api/v1/pets", JSON, `{"age": 3}`), "body.name in body is required")
+		check(t, request("POST", "http://api.example.com/api/v1/pets", JSON, `{"name": "rex", "age": -1}`), "body.age in body should be greater than or equal to 0")
+		check(t, request("POST", "http://api.example.com/api/v1/pets", JSON, `{"name": "rex", "email": "bob"}`), "body.email in body must be of type email")
+		check(t, request("POST", "http://api.example.com/api/v1/pets", JSON, `{"name": "rex", "admin": true}`), "body")
+		check(t, request("POST", "http://api.example.com/api/v1/pets", map[string][]string{"Content-Type": {"text/plain"}}, "rex"), "unexpected body content type")
+
+		body := strings.ReplaceAll("--XXX\nContent-Disposition: form-data; name=\"caption\"\n\nmy dog\n--XXX--\n", "\n", "\r\n")
+		check(t, request("POST", "http://api.example.com/api/v1/pets/42/photo", map[string][]string{
+			"Content-Type": {"multipart/form-data; boundary=XXX"},
+		}, body), "missing file photo")
+		body = strings.ReplaceAll("--XXX\nContent-Disposition: form-data; name=\"role\"\n\nadmin\n--XXX--\n", "\n", "\r\n")
+		check(t, request("POST", "http://api.example.com/api/v1/pets/42/photo", map[string][]string{
+			"Content-Type": {"multipart/form-data; boundary=XXX"},
+		}, body), "unknown form parameter role")
+	})
+
+	t.Run("happy path: reload on changelog notification", func(t *testing.T) {
+		ds.specs = []db.OpenapiSpec{}
+		for _, l := range ds.listeners {
+			l.NotifyDbChange("api.example.com")
+		}
+		assert.True(t, plugin.Scan(context.Background(), request("GET", "http://api.example.com/admin", nil, "")))
+	})
+}
@@ -0,0 +1,145 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/go-openapi/loads"
+	"github.com/go-openapi/spec"
+)
+
+/*
+Spec is a loaded OpenAPI (swagger 2.0) spec, ready to match requests
+*/
+type Spec struct {
+	basePath string
+	// sorted by decreasing number of literal segments
+	routes []*route
+}
+
+/*
+route is a path template, like /users/{id}
+*/
+type route struct {
+	template string
+	// a segment is either a literal, or a {parameter}
+	segments   []string
+	literals   int
+	operations map[string]*operation
+}
+
+type operation struct {
+	// the path item parameters, overridden by the operation ones
+	parameters []spec.Parameter
+}
+
+/*
+LoadSpec loads (and expands) a JSON or YAML spec
+*/
+func LoadSpec(raw []byte) (*Spec, error) {
+	doc, err := loads.Analyzed(json.RawMessage(raw), "")
+	if err != nil {
+		return nil, fmt.Errorf("not able to load the spec: %v", err)
+	}
+	if doc.Version() != "2.0" {
+		return nil, fmt.Errorf("unsupported spec version %q (must be swagger 2.0)", doc.Version())
+	}
+	if doc, err = doc.Expanded(); err != nil {
+		return nil, fmt.Errorf("not able to expand the spec: %v", err)
+	}
+
+	s := &Spec{
+		basePath: strings.TrimRight(doc.BasePath(), "/"),
+		routes:   []*route{},
+	}
+	if doc.Spec().Paths == nil {
+		return s, nil
+	}
+	for template, item := range doc.Spec().Paths.Paths {
+		r := &route{
+			template:   template,
+			segments:   strings.Split(strings.Trim(template, "/"), "/"),
+			operations: make(map[string]*operation),
+		}
+		for _, segment := range r.segments {
+			if !isParameter(segment) {
+				r.literals++
+			}
+		}
+		for method, op := range map[string]*spec.Operation{
+			"GET": item.Get, "PUT": item.Put, "POST": item.Post, "DELETE": item.Delete,
+			"OPTIONS": item.Options, "HEAD": item.Head, "PATCH": item.Patch,
+		} {
+			if op != nil {
+				r.operations[method] = &operation{parameters: mergeParameters(item.Parameters, op.Parameters)}
+			}
+		}
+		s.routes = append(s.routes, r)
+	}
+	sort.SliceStable(s.routes, func(i, j int) bool {
+		if s.routes[i].literals != s.routes[j].literals {
+			return s.routes[i].literals > s.routes[j].literals
+		}
+		return s.routes[i].template < s.routes[j].template
+	})
+	return s, nil
+}
+
+func isParameter(segment string) bool {
+	return strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}")
+}
+
+func mergeParameters(common []spec.Parameter, specific []spec.Parameter) []spec.Parameter {
+	params := append([]spec.Parameter{}, specific...)
+	for _, c := range common {
+		overridden := false
+		for _, s := range specific {
+			if s.Name == c.Name && s.In == c.In {
+				overridden = true
+			}
+		}
+		if !overridden {
+			params = append(params, c)
+		}
+	}
+	return params
+}
+
+/*
+match returns the route of a path, and the values of its path parameters
+*/
+func (s *Spec) match(path string) (*route, map[string]string) {
+	if s.basePath != "" {
+		if path != s.basePath && !strings.HasPrefix(path, s.basePath+"/") {
+			return nil, nil
+		}
+		path = path[len(s.basePath):]
+	}
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+
+	for _, r := range s.routes {
+		if len(r.segments) != len(segments) {
+			continue
+		}
+		values := make(map[string]string)
+		matched := true
+		for i, segment := range r.segments {
+			if isParameter(segment) {
+				if segments[i] == "" {
+					matched = false
+					break
+				}
+				values[segment[1:len(segment)-1]] = segments[i]
+			} else if segment != segments[i] {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return r, values
+		}
+	}
+	return nil, nil
+}
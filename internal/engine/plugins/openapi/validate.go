@@ -0,0 +1,203 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/go-openapi/errors"
+	"github.com/go-openapi/spec"
+	"github.com/go-openapi/strfmt"
+	"github.com/go-openapi/swag"
+	"github.com/go-openapi/validate"
+	"github.com/nzin/taxsi2/internal/com"
+)
+
+/*
+Validate returns why a request doesn't match the spec ("" if it does)
+*/
+func (s *Spec) Validate(payload *com.TaxsiCom) string {
+	parsed := payload.Parse()
+	r, pathValues := s.match(parsed.Path)
+	if r == nil {
+		return fmt.Sprintf("unknown path %s", parsed.Path)
+	}
+	op := r.operations[parsed.Method]
+	if op == nil {
+		return fmt.Sprintf("method %s not allowed on %s", parsed.Method, r.template)
+	}
+
+	query := url.Values{}
+	if payload.Url != nil {
+		query, _ = url.ParseQuery(payload.Url.RawQuery)
+	}
+	form := url.Values{}
+	for _, f := range parsed.FieldsFrom(com.SOURCE_FORM) {
+		form.Add(f.Name, f.Value)
+	}
+	files := make(map[string]bool)
+	for _, f := range parsed.Files {
+		files[f.Name] = true
+	}
+
+	// the parameters that are not in the spec
+	known := map[string]map[string]bool{"query": {}, "formData": {}}
+	var body *spec.Parameter
+	for i, p := range op.parameters {
+		if known[p.In] != nil {
+			known[p.In][p.Name] = true
+		}
+		if p.In == "body" {
+			body = &op.parameters[i]
+		}
+	}
+	for name := range query {
+		if !known["query"][name] {
+			return fmt.Sprintf("unknown query parameter %s", name)
+		}
+	}
+	for name := range form {
+		if !known["formData"][name] {
+			return fmt.Sprintf("unknown form parameter %s", name)
+		}
+	}
+
+	for i := range op.parameters {
+		p := &op.parameters[i]
+		var values []string
+		switch p.In {
+		case "path":
+			if v, ok := pathValues[p.Name]; ok {
+				values = []string{v}
+			}
+		case "query":
+			values = query[p.Name]
+		case "header":
+			values = payload.GetHeader(p.Name)
+		case "formData":
+			if p.Type == "file" {
+				if p.Required && !files[p.Name] {
+					return fmt.Sprintf("missing file %s", p.Name)
+				}
+				continue
+			}
+			values = form[p.Name]
+		default:
+			continue
+		}
+
+		if len(values) == 0 {
+			if p.Required {
+				return fmt.Sprintf("missing %s parameter %s", p.In, p.Name)
+			}
+			continue
+		}
+		if violation := validateParameter(p, values); violation != "" {
+			return violation
+		}
+	}
+
+	if body != nil {
+		return validateBody(body, parsed, payload.Body)
+	}
+	if len(payload.Body) > 0 && len(known["formData"]) == 0 {
+		return fmt.Sprintf("unexpected body for %s %s", parsed.Method, r.template)
+	}
+	return ""
+}
+
+/*
+convert converts a raw value to the type of a parameter (or of its items)
+*/
+func convert(raw string, typ string) (interface{}, error) {
+	switch typ {
+	case "integer":
+		return swag.ConvertInt64(raw)
+	case "number":
+		return swag.ConvertFloat64(raw)
+	case "boolean":
+		return swag.ConvertBool(raw)
+	}
+	return raw, nil
+}
+
+func split(values []string, collectionFormat string) []string {
+	if collectionFormat == "multi" {
+		return values
+	}
+	sep := ","
+	switch collectionFormat {
+	case "ssv":
+		sep = " "
+	case "tsv":
+		sep = "\t"
+	case "pipes":
+		sep = "|"
+	}
+	return strings.Split(values[0], sep)
+}
+
+func validateParameter(p *spec.Parameter, values []string) string {
+	var data interface{}
+	if p.Type == "array" {
+		items := []interface{}{}
+		for _, raw := range split(values, p.CollectionFormat) {
+			typ := ""
+			if p.Items != nil {
+				typ = p.Items.Type
+			}
+			item, err := convert(raw, typ)
+			if err != nil {
+				return fmt.Sprintf("%s parameter %s: %q is not of type %s", p.In, p.Name, raw, typ)
+			}
+			items = append(items, item)
+		}
+		data = items
+	} else {
+		value, err := convert(values[0], p.Type)
+		if err != nil {
+			return fmt.Sprintf("%s parameter %s: %q is not of type %s", p.In, p.Name, values[0], p.Type)
+		}
+		data = value
+	}
+
+	if res := validate.NewParamValidator(p, strfmt.Default).Validate(data); res != nil && !res.IsValid() {
+		return fmt.Sprintf("%s parameter %s: %v", p.In, p.Name, firstError(res.AsError()))
+	}
+	return ""
+}
+
+func validateBody(p *spec.Parameter, parsed *com.ParsedRequest, body []byte) string {
+	if len(body) == 0 {
+		if p.Required {
+			return "missing body"
+		}
+		return ""
+	}
+	if parsed.ContentType != "application/json" && !strings.HasSuffix(parsed.ContentType, "+json") {
+		return fmt.Sprintf("unexpected body content type %q", parsed.ContentType)
+	}
+	var data interface{}
+	if err := json.Unmarshal(body, &data); err != nil {
+		return "invalid JSON body"
+	}
+	if p.Schema == nil {
+		return ""
+	}
+	if res := validate.NewSchemaValidator(p.Schema, nil, "body", strfmt.Default).Validate(data); !res.IsValid() {
+		return fmt.Sprintf("%v", firstError(res.AsError()))
+	}
+	return ""
+}
+
+/*
+firstError returns the first error of a composite error
+(the validation errors can be long)
+*/
+func firstError(err error) error {
+	if ce, ok := err.(*errors.CompositeError); ok && len(ce.Errors) > 0 {
+		return firstError(ce.Errors[0])
+	}
+	return err
+}
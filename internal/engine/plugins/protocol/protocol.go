@@ -0,0 +1,319 @@
+package protocol
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/textproto"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/nzin/taxsi2/internal/com"
+	"github.com/nzin/taxsi2/internal/db"
+	"github.com/nzin/taxsi2/internal/engine"
+	"golang.org/x/net/http/httpguts"
+)
+
+const (
+	// GlobalConfig keys
+	// maximum number of header lines
+	CONFIG_MAX_HEADERS = "protocol_max_headers"
+	// maximum size of a header line (name and value)
+	CONFIG_MAX_HEADER_SIZE = "protocol_max_header_size"
+	// comma separated list of extra methods (like PROPFIND,MKCOL)
+	CONFIG_EXTRA_METHODS = "protocol_extra_methods"
+
+	DEFAULT_MAX_HEADERS     = 100
+	DEFAULT_MAX_HEADER_SIZE = 8192
+
+	// output variable
+	VARIABLE_VIOLATION = "protocol_violation"
+)
+
+// the RFC 9110 (and RFC 5789) methods
+var knownMethods = []string{"GET", "HEAD", "POST", "PUT", "DELETE", "CONNECT", "OPTIONS", "TRACE", "PATCH"}
+
+/*
+ProtocolWafPlugin checks the HTTP protocol sanity of the requests
+(request smuggling, malformed headers, bad encodings)
+*/
+type ProtocolWafPlugin struct {
+	ds            db.DbServiceProtocol
+	mu            sync.RWMutex
+	maxHeaders    int
+	maxHeaderSize int
+	methods       map[string]bool
+}
+
+func NewProtocolWafPlugin(ds db.DbServiceProtocol) engine.WafEnginePlugin {
+	return newProtocolWafPlugin(ds)
+}
+
+func newProtocolWafPlugin(ds db.DbServiceProtocol) *ProtocolWafPlugin {
+	p := &ProtocolWafPlugin{
+		ds: ds,
+	}
+	p.loadConfig()
+
+	ds.SubscribeChanges(db.CHANGELOG_TABLE_CONFIG, &configListener{p: p})
+	return p
+}
+
+func (p *ProtocolWafPlugin) Name() string {
+	return "protocol"
+}
+
+/*
+ValidateConfig returns why a value can't be used for a protocol key
+(nil if it can, or if the key is not a protocol key)
+*/
+func ValidateConfig(key string, value string) error {
+	if key == CONFIG_MAX_HEADERS || key == CONFIG_MAX_HEADER_SIZE {
+		_, err := parseLimit(key, value)
+		return err
+	}
+	return nil
+}
+
+func parseLimit(key string, value string) (int, error) {
+	n, err := strconv.Atoi(value)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("bad %s %s (must be a positive integer)", key, value)
+	}
+	return n, nil
+}
+
+func (p *ProtocolWafPlugin) loadConfig() {
+	maxHeaders := DEFAULT_MAX_HEADERS
+	if v, err := p.ds.GetConfigValueForKey(CONFIG_MAX_HEADERS); err == nil {
+		if n, err := parseLimit(CONFIG_MAX_HEADERS, v); err == nil {
+			maxHeaders = n
+		}
+	}
+	maxHeaderSize := DEFAULT_MAX_HEADER_SIZE
+	if v, err := p.ds.GetConfigValueForKey(CONFIG_MAX_HEADER_SIZE); err == nil {
+		if n, err := parseLimit(CONFIG_MAX_HEADER_SIZE, v); err == nil {
+			maxHeaderSize = n
+		}
+	}
+	methods := make(map[string]bool)
+	for _, m := range knownMethods {
+		methods[m] = true
+	}
+	if extra, err := p.ds.GetConfigValueForKey(CONFIG_EXTRA_METHODS); err == nil {
+		for _, m := range strings.Split(extra, ",") {
+			if m = strings.TrimSpace(m); m != "" {
+				methods[m] = true
+			}
+		}
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.maxHeaders = maxHeaders
+	p.maxHeaderSize = maxHeaderSize
+	p.methods = methods
+}
+
+// configListener reloads the limits when the GlobalConfig changes
+type configListener struct {
+	p *ProtocolWafPlugin
+}
+
+func (l *configListener) NotifyDbChange(key string) {
+	if key == CONFIG_MAX_HEADERS || key == CONFIG_MAX_HEADER_SIZE || key == CONFIG_EXTRA_METHODS {
+		l.p.loadConfig()
+	}
+}
+
+func (p *ProtocolWafPlugin) Scan(ctx context.Context, payload *com.TaxsiCom) bool {
+	if violation := p.Check(payload); violation != "" {
+		payload.SetVariable(VARIABLE_VIOLATION, violation)
+		return false
+	}
+	return true
+}
+
+/*
+Check returns the first protocol violation of a request ("" if none)
+*/
+func (p *ProtocolWafPlugin) Check(payload *com.TaxsiCom) string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	// (the methods are case sensitive)
+	if !p.methods[payload.Method] {
+		return fmt.Sprintf("unknown method %q", payload.Method)
+	}
+
+	count := 0
+	for name, values := range payload.Headers {
+		if !httpguts.ValidHeaderFieldName(name) {
+			return fmt.Sprintf("invalid header name %q", name)
+		}
+		for _, v := range values {
+			count++
+			if !httpguts.ValidHeaderFieldValue(v) {
+				return fmt.Sprintf("invalid %s header value", name)
+			}
+			if len(name)+len(v) > p.maxHeaderSize {
+				return fmt.Sprintf("%s header bigger than %d bytes", name, p.maxHeaderSize)
+			}
+		}
+	}
+	if count > p.maxHeaders {
+		return fmt.Sprintf("more than %d headers", p.maxHeaders)
+	}
+
+	if violation := checkFraming(payload); violation != "" {
+		return violation
+	}
+	if violation := checkHost(payload); violation != "" {
+		return violation
+	}
+	return checkEncoding(payload.Url)
+}
+
+/*
+headerValues returns the values of a header, whatever the case
+of its name (several header lines with different cases can be sent)
+*/
+func headerValues(payload *com.TaxsiCom, name string) []string {
+	values := []string{}
+	for k, v := range payload.Headers {
+		if textproto.CanonicalMIMEHeaderKey(k) == name {
+			values = append(values, v...)
+		}
+	}
+	return values
+}
+
+/*
+checkFraming looks for the request smuggling attempts: a body whose
+length can be understood differently by a proxy and by the backend
+*/
+func checkFraming(payload *com.TaxsiCom) string {
+	lengths := headerValues(payload, "Content-Length")
+	encodings := headerValues(payload, "Transfer-Encoding")
+
+	if len(lengths) > 0 && len(encodings) > 0 {
+		return "both Content-Length and Transfer-Encoding"
+	}
+
+	length := ""
+	for _, l := range lengths {
+		for _, v := range strings.Split(l, ",") {
+			v = strings.TrimSpace(v)
+			if _, err := strconv.ParseUint(v, 10, 63); err != nil {
+				return fmt.Sprintf("invalid Content-Length %q", l)
+			}
+			if length != "" && v != length {
+				return "conflicting Content-Length"
+			}
+			length = v
+		}
+	}
+
+	if len(encodings) > 1 {
+		return "several Transfer-Encoding headers"
+	}
+	// chunked is the only encoding the backends have to understand,
+	// and it has to be the last (and here, the only) one
+	if len(encodings) == 1 && !strings.EqualFold(encodings[0], "chunked") {
+		return fmt.Sprintf("invalid Transfer-Encoding %q", encodings[0])
+	}
+	return ""
+}
+
+func checkHost(payload *com.TaxsiCom) string {
+	hosts := headerValues(payload, "Host")
+	if len(hosts) > 1 {
+		return "duplicate Host header"
+	}
+	if len(hosts) == 0 {
+		return ""
+	}
+	host := hosts[0]
+	if strings.ContainsAny(host, ", /@") {
+		return fmt.Sprintf("invalid Host %q", host)
+	}
+	if payload.Url != nil && payload.Url.Host != "" && !sameHost(payload.Url, host) {
+		return fmt.Sprintf("absolute URI host %s doesn't match Host %s", payload.Url.Host, host)
+	}
+	return ""
+}
+
+/*
+sameHost compares the host of an url and a Host header (the default
+ports are optional)
+*/
+func sameHost(u *url.URL, host string) bool {
+	if strings.EqualFold(u.Host, host) {
+		return true
+	}
+	defaultPort := "80"
+	if u.Scheme == "https" {
+		defaultPort = "443"
+	}
+	withPort := func(h string) string {
+		if _, _, err := net.SplitHostPort(h); err == nil {
+			return strings.ToLower(h)
+		}
+		return strings.ToLower(net.JoinHostPort(strings.Trim(h, "[]"), defaultPort))
+	}
+	return withPort(u.Host) == withPort(host)
+}
+
+/*
+checkEncoding checks the percent-encoding of the path and of the query
+(valid escapes, no overlong UTF-8 sequences, no null bytes)
+*/
+func checkEncoding(u *url.URL) string {
+	if u == nil {
+		return ""
+	}
+	for _, part := range []struct{ name, raw string }{{"path", u.EscapedPath()}, {"query", u.RawQuery}} {
+		for i := 0; i < len(part.raw); i++ {
+			if part.raw[i] != '%' {
+				continue
+			}
+			if i+2 >= len(part.raw) || !isHex(part.raw[i+1]) || !isHex(part.raw[i+2]) {
+				return fmt.Sprintf("bad percent-encoding in the %s", part.name)
+			}
+		}
+		decoded, err := url.PathUnescape(part.raw)
+		if err != nil {
+			return fmt.Sprintf("bad percent-encoding in the %s", part.name)
+		}
+		if isOverlong(decoded) {
+			return fmt.Sprintf("overlong UTF-8 encoding in the %s", part.name)
+		}
+		if strings.IndexByte(decoded, 0) >= 0 {
+			return fmt.Sprintf("null byte in the %s", part.name)
+		}
+	}
+	return ""
+}
+
+/*
+isOverlong looks for the overlong UTF-8 sequences (like %c0%af for '/'),
+the other invalid sequences can be legit latin-1 characters
+*/
+func isOverlong(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if i+1 >= len(s) || s[i+1] < 0x80 || s[i+1] > 0xbf {
+			continue
+		}
+		c, next := s[i], s[i+1]
+		if c == 0xc0 || c == 0xc1 || (c == 0xe0 && next < 0xa0) || (c == 0xf0 && next < 0x90) {
+			return true
+		}
+	}
+	return false
+}
+
+func isHex(c byte) bool {
+	return (c >= '0' && c <= '9') || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F')
+}
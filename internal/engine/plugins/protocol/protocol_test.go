@@ -0,0 +1,146 @@
+package protocol
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/nzin/taxsi2/internal/com"
+	"github.com/nzin/taxsi2/internal/db"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+/*
+ * This is a mock implementation of the db.DbServiceProtocol interface
+ */
+type DbServiceProtocolMock struct {
+	config    map[string]string
+	listeners []db.DbChangeListener
+}
+
+func (m *DbServiceProtocolMock) SubscribeChanges(table int, listener db.DbChangeListener) {
+	m.listeners = append(m.listeners, listener)
+}
+func (m *DbServiceProtocolMock) GetConfigValueForKey(key string) (string, error) {
+	value, ok := m.config[key]
+	if !ok {
+		return "", gorm.ErrRecordNotFound
+	}
+	return value, nil
+}
+
+func request(method string, rawurl string, headers map[string][]string) *com.TaxsiCom {
+	u, _ := url.Parse(rawurl)
+	if headers == nil {
+		headers = map[string][]string{}
+	}
+	return &com.TaxsiCom{
+		Method:  method,
+		Url:     u,
+		Headers: headers,
+	}
+}
+
+func TestProtocolPlugin(t *testing.T) {
+	ds := &DbServiceProtocolMock{config: map[string]string{}}
+	plugin := newProtocolWafPlugin(ds)
+
+	check := func(t *testing.T, payload *com.TaxsiCom, violation string) {
+		assert.False(t, plugin.Scan(context.Background(), payload))
+		assert.Contains(t, payload.Variables[VARIABLE_VIOLATION], violation)
+	}
+
+	t.Run("happy path: legit requests", func(t *testing.T) {
+		assert.True(t, plugin.Scan(context.Background(), request("GET", "http://www.example.com/search?q=caf%C3%A9&lang=fr", map[string][]string{
+			"Host":       {"www.example.com"},
+			"User-Agent": {"Mozilla/5.0"},
+		})))
+		assert.True(t, plugin.Scan(context.Background(), request("POST", "https://www.example.com/api", map[string][]string{
+			"Host":              {"www.example.com:443"},
+			"Transfer-Encoding": {"chunked"},
+		})))
+		assert.True(t, plugin.Scan(context.Background(), request("POST", "http://www.example.com/api", map[string][]string{
+			"Content-Length": {"42"},
+		})))
+		// latin-1 forms
+		assert.True(t, plugin.Scan(context.Background(), request("GET", "http://www.example.com/search?q=caf%E9", nil)))
+	})
+
+	t.Run("not happy path: request smuggling", func(t *testing.T) {
+		check(t, request("POST", "http://www.example.com/", map[string][]string{
+			"Content-Length":    {"42"},
+			"Transfer-Encoding": {"chunked"},
+		}), "both Content-Length and Transfer-Encoding")
+		check(t, request("POST", "http://www.example.com/", map[string][]string{
+			"Content-Length": {"42", "43"},
+		}), "conflicting Content-Length")
+		check(t, request("POST", "http://www.example.com/", map[string][]string{
+			"Content-Length": {"42"},
+			"content-length": {"0"},
+		}), "conflicting Content-Length")
+		check(t, request("POST", "http://www.example.com/", map[string][]string{
+			"Content-Length": {"+42"},
+		}), "invalid Content-Length")
+		check(t, request("POST", "http://www.example.com/", map[string][]string{
+			"Transfer-Encoding": {"chunked, identity"},
+		}), "invalid Transfer-Encoding")
+		check(t, request("POST", "http://www.example.com/", map[string][]string{
+			"Transfer-Encoding": {"chunked", "chunked"},
+		}), "several Transfer-Encoding headers")
+	})
+
+	t.Run("not happy path: hosts", func(t *testing.T) {
+		check(t, request("GET", "http://www.example.com/", map[string][]string{
+			"Host": {"www.example.com", "evil.com"},
+		}), "duplicate Host header")
+		check(t, request("GET", "http://www.example.com/", map[string][]string{
+			"Host": {"www.example.com, evil.com"},
+		}), "invalid Host")
+		check(t, request("GET", "http://www.example.com/", map[string][]string{
+			"Host": {"evil.com"},
+		}), "doesn't match Host evil.com")
+	})
+
+	t.Run("not happy path: headers", func(t *testing.T) {
+		check(t, request("GET", "http://www.example.com/", map[string][]string{
+			"X Forwarded": {"1"},
+		}), "invalid header name")
+		check(t, request("GET", "http://www.example.com/", map[string][]string{
+			"X-Foo": {"a\r\nX-Injected: 1"},
+		}), "invalid X-Foo header value")
+		check(t, request("GET", "http://www.example.com/", map[string][]string{
+			"Cookie": {strings.Repeat("a", DEFAULT_MAX_HEADER_SIZE)},
+		}), "Cookie header bigger than 8192 bytes")
+
+		headers := map[string][]string{}
+		for i := 0; i <= DEFAULT_MAX_HEADERS; i++ {
+			headers[fmt.Sprintf("X-Header-%d", i)] = []string{"1"}
+		}
+		check(t, request("GET", "http://www.example.com/", headers), "more than 100 headers")
+	})
+
+	t.Run("not happy path: methods and encodings", func(t *testing.T) {
+		check(t, request("PROPFIND", "http://www.example.com/", nil), `unknown method "PROPFIND"`)
+		check(t, request("get", "http://www.example.com/", nil), "unknown method")
+		check(t, request("GET", "http://www.example.com/?q=%zz", nil), "bad percent-encoding in the query")
+		check(t, request("GET", "http://www.example.com/?q=100%", nil), "bad percent-encoding in the query")
+		check(t, request("GET", "http://www.example.com/%c0%af..%c0%afetc/passwd", nil), "overlong UTF-8 encoding in the path")
+		check(t, request("GET", "http://www.example.com/index.php%00.jpg", nil), "null byte in the path")
+	})
+
+	t.Run("happy path: configuration", func(t *testing.T) {
+		ds.config[CONFIG_EXTRA_METHODS] = "PROPFIND, MKCOL"
+		ds.config[CONFIG_MAX_HEADERS] = "1"
+		for _, l := range ds.listeners {
+			l.NotifyDbChange(CONFIG_EXTRA_METHODS)
+		}
+		assert.True(t, plugin.Scan(context.Background(), request("PROPFIND", "http://www.example.com/", nil)))
+		check(t, request("GET", "http://www.example.com/", map[string][]string{
+			"Accept": {"*/*"},
+			"Host":   {"www.example.com"},
+		}), "more than 1 headers")
+	})
+}
@@ -0,0 +1,431 @@
+package ratelimiter
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"net/textproto"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nzin/taxsi2/internal/com"
+	"github.com/nzin/taxsi2/internal/db"
+	"github.com/nzin/taxsi2/internal/engine"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// counters are only kept on this node
+	MODE_MEMORY = "memory"
+	// counters are synced (every second) through the database
+	MODE_CLUSTER = "cluster"
+
+	// GlobalConfig key to choose the mode
+	CONFIG_MODE = "ratelimit_mode"
+)
+
+/*
+counter is the state of a (rule, key).
+Both algorithms count the hits per (fixed) window of rule.Period,
+which are the values shared between the nodes in cluster mode
+*/
+type counter struct {
+	windowStart time.Time
+	prev        int // hits during the previous window (all nodes)
+	cur         int // hits during the current window (all nodes, as far as we know)
+	pending     int // local hits not yet synced
+
+	// token-bucket
+	tokens float64
+	last   time.Time
+}
+
+type RatelimiterWafPlugin struct {
+	ds   db.DbServiceRatelimit
+	now  func() time.Time
+	mu   sync.Mutex
+	mode string
+	// sorted by decreasing path prefix length
+	rules    []db.RatelimitRule
+	counters map[uint]map[string]*counter
+}
+
+/*
+NewRatelimiterWafPlugin creates the plugin, and syncs its counters
+in the background until stopChannel is closed
+*/
+func NewRatelimiterWafPlugin(ds db.DbServiceRatelimit, stopChannel chan struct{}) (engine.WafEnginePlugin, error) {
+	r, err := newRatelimiterWafPlugin(ds)
+	if err != nil {
+		return nil, err
+	}
+	go r.Watch(stopChannel)
+	return r, nil
+}
+
+func newRatelimiterWafPlugin(ds db.DbServiceRatelimit) (*RatelimiterWafPlugin, error) {
+	r := &RatelimiterWafPlugin{
+		ds:       ds,
+		now:      time.Now,
+		mode:     MODE_MEMORY,
+		counters: make(map[uint]map[string]*counter),
+	}
+	if err := r.loadRules(); err != nil {
+		return nil, err
+	}
+	r.loadMode()
+
+	ds.SubscribeChanges(db.CHANGELOG_TABLE_RATELIMIT, r)
+	ds.SubscribeChanges(db.CHANGELOG_TABLE_CONFIG, &configListener{r: r})
+	return r, nil
+}
+
+/*
+ValidateRule returns why a rule can't be used (nil if it can)
+*/
+func ValidateRule(rule db.RatelimitRule) error {
+	for _, k := range strings.Split(rule.Key, ",") {
+		k = strings.TrimSpace(k)
+		if k != "ip" && k != "path" && !(strings.HasPrefix(k, "header:") && len(k) > len("header:")) &&
+			!(strings.HasPrefix(k, "claim:") && len(k) > len("claim:")) {
+			return fmt.Errorf("bad key %s (must be ip, path, header:<name> or claim:<name>)", k)
+		}
+	}
+	if rule.Algorithm != db.RATELIMIT_TOKEN_BUCKET && rule.Algorithm != db.RATELIMIT_SLIDING_WINDOW {
+		return fmt.Errorf("bad algorithm %s (must be %s or %s)", rule.Algorithm, db.RATELIMIT_TOKEN_BUCKET, db.RATELIMIT_SLIDING_WINDOW)
+	}
+	if rule.Limit <= 0 || rule.Period <= 0 || rule.Burst < 0 {
+		return fmt.Errorf("the limit and the period must be positive numbers")
+	}
+	return nil
+}
+
+func (r *RatelimiterWafPlugin) Name() string {
+	return "ratelimiter"
+}
+
+// DependsOn returns the jwt plugin, that sets the claims used as keys
+func (r *RatelimiterWafPlugin) DependsOn() []string {
+	return []string{"jwt"}
+}
+
+// Cacheable is false: the identical requests are counted
+func (r *RatelimiterWafPlugin) Cacheable() bool {
+	return false
+}
+
+// Interruptible is true: the counters are in memory (the cluster sync is in the background)
+func (r *RatelimiterWafPlugin) Interruptible() bool {
+	return true
+}
+
+func (r *RatelimiterWafPlugin) loadRules() error {
+	rules, err := r.ds.GetRatelimitRules()
+	if err != nil {
+		return err
+	}
+
+	valid := []db.RatelimitRule{}
+	for _, rule := range rules {
+		// (the rules are not always written through the API)
+		if err := ValidateRule(rule); err != nil {
+			logrus.Errorf("ratelimit rule %d: %v", rule.ID, err)
+			continue
+		}
+		valid = append(valid, rule)
+	}
+	sort.SliceStable(valid, func(i, j int) bool {
+		return len(valid[i].PathPrefix) > len(valid[j].PathPrefix)
+	})
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	// only the counters of the rules removed or changed start again
+	previous := make(map[uint]db.RatelimitRule)
+	for _, rule := range r.rules {
+		previous[rule.ID] = rule
+	}
+	counters := make(map[uint]map[string]*counter)
+	for _, rule := range valid {
+		if old, ok := previous[rule.ID]; ok && sameLimits(old, rule) {
+			counters[rule.ID] = r.counters[rule.ID]
+		}
+	}
+	r.rules = valid
+	r.counters = counters
+	return nil
+}
+
+/*
+sameLimits is true if the counters of a rule are still valid with the other one
+*/
+func sameLimits(a db.RatelimitRule, b db.RatelimitRule) bool {
+	return a.PathPrefix == b.PathPrefix && a.Key == b.Key && a.Algorithm == b.Algorithm &&
+		a.Limit == b.Limit && a.Period == b.Period && a.Burst == b.Burst
+}
+
+/*
+ValidateConfig returns why a value can't be used for a ratelimiter key
+(nil if it can, or if the key is not a ratelimiter key)
+*/
+func ValidateConfig(key string, value string) error {
+	if key == CONFIG_MODE && value != MODE_MEMORY && value != MODE_CLUSTER {
+		return fmt.Errorf("bad %s %s (must be %s or %s)", key, value, MODE_MEMORY, MODE_CLUSTER)
+	}
+	return nil
+}
+
+func (r *RatelimiterWafPlugin) loadMode() {
+	mode, err := r.ds.GetConfigValueForKey(CONFIG_MODE)
+	if err != nil || ValidateConfig(CONFIG_MODE, mode) != nil {
+		mode = MODE_MEMORY
+	}
+
+	r.mu.Lock()
+	r.mode = mode
+	r.mu.Unlock()
+}
+
+// NotifyDbChange is called when the rules change
+func (r *RatelimiterWafPlugin) NotifyDbChange(key string) {
+	if err := r.loadRules(); err != nil {
+		logrus.Errorf("Error reading ratelimit rules: %v", err)
+	}
+}
+
+// configListener reloads the mode when the GlobalConfig changes
+type configListener struct {
+	r *RatelimiterWafPlugin
+}
+
+func (l *configListener) NotifyDbChange(key string) {
+	if key == CONFIG_MODE {
+		l.r.loadMode()
+	}
+}
+
+/*
+matchRule returns the rule with the longest matching path prefix
+(must be called with the lock held)
+*/
+func (r *RatelimiterWafPlugin) matchRule(path string) *db.RatelimitRule {
+	for i := range r.rules {
+		if strings.HasPrefix(path, r.rules[i].PathPrefix) {
+			return &r.rules[i]
+		}
+	}
+	return nil
+}
+
+/*
+RequestKey computes the key of a request, for a rule key definition
+(comma separated list of "ip", "path", "header:<name>", "claim:<name>").
+A missing header (or claim) counts as an empty value
+*/
+func RequestKey(keyDef string, payload *com.TaxsiCom) string {
+	values := []string{}
+	for _, k := range strings.Split(keyDef, ",") {
+		k = strings.TrimSpace(k)
+		switch {
+		case k == "ip":
+			values = append(values, payload.RemoteAddr)
+		case k == "path":
+			values = append(values, payload.Url.Path)
+		case strings.HasPrefix(k, "header:"):
+			name := textproto.CanonicalMIMEHeaderKey(k[len("header:"):])
+			values = append(values, strings.Join(payload.Headers[name], ","))
+		case strings.HasPrefix(k, "claim:"):
+			// (the claims of the bearer token, checked by the jwt plugin)
+			values = append(values, payload.Claims[k[len("claim:"):]])
+		}
+	}
+	return strings.Join(values, "|")
+}
+
+func (r *RatelimiterWafPlugin) Scan(ctx context.Context, payload *com.TaxsiCom) bool {
+	now := r.now()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	rule := r.matchRule(payload.Url.Path)
+	if rule == nil {
+		return true
+	}
+
+	key := RequestKey(rule.Key, payload)
+	keys := r.counters[rule.ID]
+	if keys == nil {
+		keys = make(map[string]*counter)
+		r.counters[rule.ID] = keys
+	}
+	c := keys[key]
+	if c == nil {
+		c = &counter{
+			tokens: float64(burst(rule)),
+			last:   now,
+		}
+		keys[key] = c
+	}
+
+	period := time.Duration(rule.Period) * time.Second
+	c.roll(now, period)
+
+	if rule.Algorithm == db.RATELIMIT_TOKEN_BUCKET {
+		c.refill(now, rule)
+		if c.tokens < 1 {
+			return false
+		}
+		c.tokens--
+	} else {
+		// sliding window: the previous window is weighted by
+		// how much of it still overlaps the sliding window
+		elapsed := float64(now.Sub(c.windowStart)) / float64(period)
+		estimate := float64(c.prev)*(1-elapsed) + float64(c.cur)
+		if estimate >= float64(rule.Limit) {
+			return false
+		}
+	}
+	c.cur++
+	c.pending++
+	return true
+}
+
+func burst(rule *db.RatelimitRule) int {
+	if rule.Burst > 0 {
+		return rule.Burst
+	}
+	return rule.Limit
+}
+
+/*
+roll moves to the current window if needed
+*/
+func (c *counter) roll(now time.Time, period time.Duration) {
+	windowStart := now.Truncate(period)
+	if windowStart.Equal(c.windowStart) {
+		return
+	}
+	if windowStart.Equal(c.windowStart.Add(period)) {
+		c.prev = c.cur
+	} else {
+		c.prev = 0
+	}
+	c.cur = 0
+	// the hits not yet synced from the previous window are dropped
+	c.pending = 0
+	c.windowStart = windowStart
+}
+
+func (c *counter) refill(now time.Time, rule *db.RatelimitRule) {
+	rate := float64(rule.Limit) / float64(rule.Period)
+	c.tokens = math.Min(float64(burst(rule)), c.tokens+now.Sub(c.last).Seconds()*rate)
+	c.last = now
+}
+
+/*
+counterKey is the (fixed size) key of a counter in the database
+*/
+func counterKey(ruleID uint, key string) string {
+	h := sha1.Sum([]byte(key))
+	return fmt.Sprintf("%d-%s", ruleID, hex.EncodeToString(h[:]))
+}
+
+/*
+Sync pushes the local hits into the database (in cluster mode),
+fetches the hits of the other nodes, and forgets the idle counters
+*/
+func (r *RatelimiterWafPlugin) Sync() error {
+	now := r.now()
+
+	// hits per window
+	hits := make(map[time.Time]map[string]int)
+	refs := make(map[string]*counter)
+
+	var maxPeriod time.Duration
+	r.mu.Lock()
+	cluster := r.mode == MODE_CLUSTER
+	for i := range r.rules {
+		rule := &r.rules[i]
+		period := time.Duration(rule.Period) * time.Second
+		if period > maxPeriod {
+			maxPeriod = period
+		}
+		for key, c := range r.counters[rule.ID] {
+			c.roll(now, period)
+			if rule.Algorithm == db.RATELIMIT_TOKEN_BUCKET {
+				c.refill(now, rule)
+			}
+			// idle for 2 windows: nothing left to remember
+			if c.cur == 0 && c.prev == 0 && (rule.Algorithm == db.RATELIMIT_SLIDING_WINDOW || c.tokens >= float64(burst(rule))) {
+				delete(r.counters[rule.ID], key)
+				continue
+			}
+			if !cluster {
+				continue
+			}
+			if hits[c.windowStart] == nil {
+				hits[c.windowStart] = make(map[string]int)
+			}
+			ck := counterKey(rule.ID, key)
+			hits[c.windowStart][ck] = c.pending
+			refs[ck] = c
+			c.pending = 0
+		}
+	}
+	r.mu.Unlock()
+
+	if !cluster {
+		return nil
+	}
+
+	for windowStart, h := range hits {
+		totals, err := r.ds.AddRatelimitHits(windowStart, h)
+		if err != nil {
+			return err
+		}
+
+		r.mu.Lock()
+		for ck, total := range totals {
+			c := refs[ck]
+			// the window may have changed in the meantime
+			if !c.windowStart.Equal(windowStart) {
+				continue
+			}
+			// hits recorded since we released the lock are not yet in total
+			delta := total + c.pending - c.cur
+			if delta > 0 {
+				c.cur += delta
+				// the tokens consumed by the other nodes
+				c.tokens = math.Max(0, c.tokens-float64(delta))
+			}
+		}
+		r.mu.Unlock()
+	}
+
+	// the previous window is still needed by the sliding window
+	return r.ds.PurgeRatelimitCounters(now.Add(-2 * maxPeriod))
+}
+
+/*
+Watch periodically syncs the counters (until stopChannel is closed)
+*/
+func (r *RatelimiterWafPlugin) Watch(stopChannel chan struct{}) {
+	for {
+		select {
+		case _, ok := <-stopChannel:
+			if !ok {
+				// channel closed
+				return
+			}
+		case <-time.After(1 * time.Second):
+			if err := r.Sync(); err != nil {
+				logrus.Errorf("error syncing ratelimit counters: %v", err)
+			}
+		}
+	}
+}
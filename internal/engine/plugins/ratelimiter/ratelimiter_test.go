@@ -0,0 +1,259 @@
+package ratelimiter
+
+import (
+	"context"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/nzin/taxsi2/internal/com"
+	"github.com/nzin/taxsi2/internal/db"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+/*
+ * This is a mock implementation of the db.DbServiceRatelimit interface
+ * (the counters are shared, as if they were in the database)
+ */
+type DbServiceRatelimitMock struct {
+	config   map[string]string
+	rules    []db.RatelimitRule
+	counters map[time.Time]map[string]int
+}
+
+func newDbServiceRatelimitMock(rules ...db.RatelimitRule) *DbServiceRatelimitMock {
+	for i := range rules {
+		rules[i].ID = uint(i + 1)
+	}
+	return &DbServiceRatelimitMock{
+		config:   make(map[string]string),
+		rules:    rules,
+		counters: make(map[time.Time]map[string]int),
+	}
+}
+
+func (m *DbServiceRatelimitMock) SubscribeChanges(table int, listener db.DbChangeListener) {
+}
+func (m *DbServiceRatelimitMock) GetConfigValueForKey(key string) (string, error) {
+	value, ok := m.config[key]
+	if !ok {
+		return "", gorm.ErrRecordNotFound
+	}
+	return value, nil
+}
+func (m *DbServiceRatelimitMock) GetRatelimitRules() ([]db.RatelimitRule, error) {
+	return m.rules, nil
+}
+func (m *DbServiceRatelimitMock) SetRatelimitRule(rule *db.RatelimitRule) error {
+	return nil
+}
+func (m *DbServiceRatelimitMock) DeleteRatelimitRule(id uint) error {
+	return nil
+}
+func (m *DbServiceRatelimitMock) AddRatelimitHits(windowStart time.Time, hits map[string]int) (map[string]int, error) {
+	if m.counters[windowStart] == nil {
+		m.counters[windowStart] = make(map[string]int)
+	}
+	totals := make(map[string]int)
+	for key, n := range hits {
+		m.counters[windowStart][key] += n
+		totals[key] = m.counters[windowStart][key]
+	}
+	return totals, nil
+}
+func (m *DbServiceRatelimitMock) PurgeRatelimitCounters(before time.Time) error {
+	return nil
+}
+
+func request(remoteAddr string, path string, headers map[string][]string) *com.TaxsiCom {
+	u, _ := url.Parse("http://www.example.com" + path)
+	return &com.TaxsiCom{
+		RemoteAddr: remoteAddr,
+		Method:     "GET",
+		Url:        u,
+		Headers:    headers,
+	}
+}
+
+func TestRequestKey(t *testing.T) {
+	payload := request("1.2.3.4", "/api/users", map[string][]string{"X-Api-Key": {"secret"}})
+
+	assert.Equal(t, "1.2.3.4", RequestKey("ip", payload))
+	assert.Equal(t, "/api/users", RequestKey("path", payload))
+	assert.Equal(t, "secret", RequestKey("header:x-api-key", payload))
+	assert.Equal(t, "1.2.3.4|secret", RequestKey("ip, header:X-Api-Key", payload))
+	assert.Equal(t, "", RequestKey("header:Authorization", payload))
+
+	// the claims of the bearer token
+	assert.Equal(t, "", RequestKey("claim:sub", payload))
+	payload.Claims = map[string]string{"sub": "alice"}
+	assert.Equal(t, "alice|/api/users", RequestKey("claim:sub,path", payload))
+}
+
+func TestSlidingWindow(t *testing.T) {
+	t.Run("happy path: limit per ip on a path", func(t *testing.T) {
+		ds := newDbServiceRatelimitMock(
+			db.RatelimitRule{PathPrefix: "/login", Key: "ip", Algorithm: db.RATELIMIT_SLIDING_WINDOW, Limit: 3, Period: 60},
+			db.RatelimitRule{PathPrefix: "/", Key: "ip", Algorithm: db.RATELIMIT_SLIDING_WINDOW, Limit: 100, Period: 60},
+		)
+		r, err := newRatelimiterWafPlugin(ds)
+		assert.Nil(t, err)
+		now := time.Now().Truncate(time.Minute)
+		r.now = func() time.Time { return now }
+
+		for i := 0; i < 3; i++ {
+			assert.True(t, r.Scan(context.Background(), request("1.2.3.4", "/login", nil)))
+		}
+		assert.False(t, r.Scan(context.Background(), request("1.2.3.4", "/login", nil)))
+		// another ip, another path
+		assert.True(t, r.Scan(context.Background(), request("5.6.7.8", "/login", nil)))
+		assert.True(t, r.Scan(context.Background(), request("1.2.3.4", "/home", nil)))
+
+		// half of the previous window still counts: 3*0.5 = 1.5
+		now = now.Add(90 * time.Second)
+		assert.True(t, r.Scan(context.Background(), request("1.2.3.4", "/login", nil)))
+		assert.True(t, r.Scan(context.Background(), request("1.2.3.4", "/login", nil)))
+		assert.False(t, r.Scan(context.Background(), request("1.2.3.4", "/login", nil)))
+
+		// two windows later, everything is forgotten
+		now = now.Add(2 * time.Minute)
+		assert.True(t, r.Scan(context.Background(), request("1.2.3.4", "/login", nil)))
+	})
+
+	t.Run("happy path: cluster mode", func(t *testing.T) {
+		ds := newDbServiceRatelimitMock(
+			db.RatelimitRule{PathPrefix: "/", Key: "header:X-Api-Key", Algorithm: db.RATELIMIT_SLIDING_WINDOW, Limit: 4, Period: 60},
+		)
+		ds.config[CONFIG_MODE] = MODE_CLUSTER
+		now := time.Now().Truncate(time.Minute)
+
+		node1, err := newRatelimiterWafPlugin(ds)
+		assert.Nil(t, err)
+		node1.now = func() time.Time { return now }
+		node2, err := newRatelimiterWafPlugin(ds)
+		assert.Nil(t, err)
+		node2.now = func() time.Time { return now }
+
+		headers := map[string][]string{"X-Api-Key": {"key1"}}
+		assert.True(t, node1.Scan(context.Background(), request("1.2.3.4", "/", headers)))
+		assert.True(t, node1.Scan(context.Background(), request("1.2.3.4", "/", headers)))
+		assert.True(t, node2.Scan(context.Background(), request("5.6.7.8", "/", headers)))
+		assert.Nil(t, node1.Sync())
+		assert.Nil(t, node2.Sync())
+		assert.Nil(t, node1.Sync())
+
+		// 3 hits for key1 over the cluster
+		assert.True(t, node2.Scan(context.Background(), request("5.6.7.8", "/", headers)))
+		assert.False(t, node2.Scan(context.Background(), request("5.6.7.8", "/", headers)))
+		assert.Nil(t, node2.Sync())
+		assert.Nil(t, node1.Sync())
+		assert.False(t, node1.Scan(context.Background(), request("1.2.3.4", "/", headers)))
+	})
+
+	t.Run("happy path: memory mode doesn't share", func(t *testing.T) {
+		ds := newDbServiceRatelimitMock(
+			db.RatelimitRule{PathPrefix: "/", Key: "ip", Algorithm: db.RATELIMIT_SLIDING_WINDOW, Limit: 1, Period: 60},
+		)
+		node1, err := newRatelimiterWafPlugin(ds)
+		assert.Nil(t, err)
+		node2, err := newRatelimiterWafPlugin(ds)
+		assert.Nil(t, err)
+
+		assert.True(t, node1.Scan(context.Background(), request("1.2.3.4", "/", nil)))
+		assert.Nil(t, node1.Sync())
+		assert.True(t, node2.Scan(context.Background(), request("1.2.3.4", "/", nil)))
+		assert.Equal(t, 0, len(ds.counters))
+	})
+}
+
+func TestTokenBucket(t *testing.T) {
+	t.Run("happy path: burst then refill", func(t *testing.T) {
+		ds := newDbServiceRatelimitMock(
+			db.RatelimitRule{PathPrefix: "/api", Key: "ip", Algorithm: db.RATELIMIT_TOKEN_BUCKET, Limit: 1, Period: 1, Burst: 2},
+		)
+		r, err := newRatelimiterWafPlugin(ds)
+		assert.Nil(t, err)
+		now := time.Now()
+		r.now = func() time.Time { return now }
+
+		assert.True(t, r.Scan(context.Background(), request("1.2.3.4", "/api", nil)))
+		assert.True(t, r.Scan(context.Background(), request("1.2.3.4", "/api", nil)))
+		assert.False(t, r.Scan(context.Background(), request("1.2.3.4", "/api", nil)))
+
+		now = now.Add(1 * time.Second)
+		assert.True(t, r.Scan(context.Background(), request("1.2.3.4", "/api", nil)))
+		assert.False(t, r.Scan(context.Background(), request("1.2.3.4", "/api", nil)))
+
+		// no rule
+		assert.True(t, r.Scan(context.Background(), request("1.2.3.4", "/", nil)))
+	})
+
+	t.Run("happy path: idle counters are forgotten", func(t *testing.T) {
+		ds := newDbServiceRatelimitMock(
+			db.RatelimitRule{PathPrefix: "/", Key: "ip", Algorithm: db.RATELIMIT_TOKEN_BUCKET, Limit: 10, Period: 1},
+		)
+		r, err := newRatelimiterWafPlugin(ds)
+		assert.Nil(t, err)
+		now := time.Now()
+		r.now = func() time.Time { return now }
+
+		assert.True(t, r.Scan(context.Background(), request("1.2.3.4", "/", nil)))
+		assert.Nil(t, r.Sync())
+		assert.Equal(t, 1, len(r.counters[1]))
+
+		now = now.Add(3 * time.Second)
+		assert.Nil(t, r.Sync())
+		assert.Equal(t, 0, len(r.counters[1]))
+	})
+
+	t.Run("happy path: only the changed rules are reset", func(t *testing.T) {
+		ds := newDbServiceRatelimitMock(
+			db.RatelimitRule{PathPrefix: "/login", Key: "ip", Algorithm: db.RATELIMIT_SLIDING_WINDOW, Limit: 2, Period: 60},
+			db.RatelimitRule{PathPrefix: "/api", Key: "ip", Algorithm: db.RATELIMIT_SLIDING_WINDOW, Limit: 2, Period: 60},
+		)
+		r, err := newRatelimiterWafPlugin(ds)
+		assert.Nil(t, err)
+		now := time.Now().Truncate(time.Minute)
+		r.now = func() time.Time { return now }
+
+		for i := 0; i < 2; i++ {
+			assert.True(t, r.Scan(context.Background(), request("1.2.3.4", "/login", nil)))
+			assert.True(t, r.Scan(context.Background(), request("1.2.3.4", "/api", nil)))
+		}
+
+		ds.rules[1].Limit = 3
+		r.NotifyDbChange("2")
+		assert.False(t, r.Scan(context.Background(), request("1.2.3.4", "/login", nil)))
+		assert.True(t, r.Scan(context.Background(), request("1.2.3.4", "/api", nil)))
+
+		// removed
+		ds.rules = ds.rules[1:]
+		r.NotifyDbChange("1")
+		assert.Nil(t, r.counters[1])
+		assert.Equal(t, 1, len(r.counters[2]))
+	})
+
+	t.Run("happy path: the sync loop stops", func(t *testing.T) {
+		r, err := newRatelimiterWafPlugin(newDbServiceRatelimitMock())
+		assert.Nil(t, err)
+
+		stopChannel := make(chan struct{})
+		close(stopChannel)
+		// returns right away
+		r.Watch(stopChannel)
+	})
+
+	t.Run("not happy path: bad rules are ignored", func(t *testing.T) {
+		ds := newDbServiceRatelimitMock(
+			db.RatelimitRule{PathPrefix: "/", Key: "ip", Algorithm: "leaky", Limit: 1, Period: 1},
+			db.RatelimitRule{PathPrefix: "/", Key: "ip", Algorithm: db.RATELIMIT_TOKEN_BUCKET, Limit: 0, Period: 1},
+			// (would key all the requests on "")
+			db.RatelimitRule{PathPrefix: "/", Key: "header:", Algorithm: db.RATELIMIT_TOKEN_BUCKET, Limit: 1, Period: 1},
+			db.RatelimitRule{PathPrefix: "/", Key: "ip,foo", Algorithm: db.RATELIMIT_TOKEN_BUCKET, Limit: 1, Period: 1},
+		)
+		r, err := newRatelimiterWafPlugin(ds)
+		assert.Nil(t, err)
+		assert.Equal(t, 0, len(r.rules))
+	})
+}
@@ -0,0 +1,392 @@
+package rules
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/nzin/taxsi2/internal/com"
+	"github.com/nzin/taxsi2/internal/engine"
+)
+
+// the types of the values
+const (
+	TYPE_BOOL   = "bool"
+	TYPE_INT    = "int"
+	TYPE_STRING = "string"
+	// a list of strings, like all the args values
+	TYPE_LIST = "list"
+	TYPE_IP   = "ip"
+)
+
+type value struct {
+	typ  string
+	b    bool
+	i    int64
+	s    string
+	list []string
+	ip   net.IP
+}
+
+/*
+Expr is a compiled (and type checked) expression
+*/
+type Expr struct {
+	typ  string
+	eval func(r *request) value
+	// the value of a literal (nil if the expression depends on the request)
+	constant *value
+}
+
+/*
+Match evaluates a (boolean) expression against a request
+*/
+func (e *Expr) Match(payload *com.TaxsiCom, countries CountryLookup) bool {
+	return e.eval(newRequest(payload, countries)).b
+}
+
+func constant(v value) *Expr {
+	return &Expr{
+		typ:      v.typ,
+		eval:     func(r *request) value { return v },
+		constant: &v,
+	}
+}
+
+func boolean(f func(r *request) bool) *Expr {
+	return &Expr{typ: TYPE_BOOL, eval: func(r *request) value { return value{typ: TYPE_BOOL, b: f(r)} }}
+}
+
+func logical(op string, left *Expr, right *Expr) (*Expr, error) {
+	if left.typ != TYPE_BOOL || right.typ != TYPE_BOOL {
+		return nil, fmt.Errorf("%s expects bools, not %s and %s", op, left.typ, right.typ)
+	}
+	if op == "&&" {
+		return boolean(func(r *request) bool { return left.eval(r).b && right.eval(r).b }), nil
+	}
+	return boolean(func(r *request) bool { return left.eval(r).b || right.eval(r).b }), nil
+}
+
+func not(e *Expr) (*Expr, error) {
+	if e.typ != TYPE_BOOL {
+		return nil, fmt.Errorf("! expects a bool, not a %s", e.typ)
+	}
+	return boolean(func(r *request) bool { return !e.eval(r).b }), nil
+}
+
+func compare(op string, left *Expr, right *Expr) (*Expr, error) {
+	// an ip can be compared with a literal address
+	if left.typ == TYPE_IP && right.typ == TYPE_STRING && right.constant != nil {
+		ip := net.ParseIP(right.constant.s)
+		if ip == nil {
+			return nil, fmt.Errorf("bad ip %s", right.constant.s)
+		}
+		right = constant(value{typ: TYPE_IP, ip: ip})
+	}
+	if left.typ != right.typ {
+		return nil, fmt.Errorf("can't compare a %s with a %s", left.typ, right.typ)
+	}
+
+	var equal func(a, b value) bool
+	switch left.typ {
+	case TYPE_BOOL:
+		equal = func(a, b value) bool { return a.b == b.b }
+	case TYPE_INT:
+		equal = func(a, b value) bool { return a.i == b.i }
+	case TYPE_STRING:
+		equal = func(a, b value) bool { return a.s == b.s }
+	case TYPE_IP:
+		equal = func(a, b value) bool { return a.ip != nil && a.ip.Equal(b.ip) }
+	default:
+		return nil, fmt.Errorf("can't compare lists")
+	}
+
+	switch op {
+	case "==":
+		return boolean(func(r *request) bool { return equal(left.eval(r), right.eval(r)) }), nil
+	case "!=":
+		return boolean(func(r *request) bool { return !equal(left.eval(r), right.eval(r)) }), nil
+	}
+	if left.typ != TYPE_INT {
+		return nil, fmt.Errorf("%s expects ints, not %s", op, left.typ)
+	}
+	var less func(a, b int64) bool
+	switch op {
+	case "<":
+		less = func(a, b int64) bool { return a < b }
+	case "<=":
+		less = func(a, b int64) bool { return a <= b }
+	case ">":
+		less = func(a, b int64) bool { return a > b }
+	default:
+		less = func(a, b int64) bool { return a >= b }
+	}
+	return boolean(func(r *request) bool { return less(left.eval(r).i, right.eval(r).i) }), nil
+}
+
+/*
+literals returns the values of arguments that must be string literals
+*/
+func literals(name string, args []*Expr) ([]string, error) {
+	values := []string{}
+	for _, a := range args {
+		if a.constant == nil || a.typ != TYPE_STRING {
+			return nil, fmt.Errorf("%s expects string literals", name)
+		}
+		values = append(values, a.constant.s)
+	}
+	return values, nil
+}
+
+func checkArgs(name string, args []*Expr, types ...string) error {
+	if len(args) != len(types) {
+		return fmt.Errorf("%s expects %d argument(s)", name, len(types))
+	}
+	for i, a := range args {
+		if a.typ != types[i] {
+			return fmt.Errorf("%s expects a %s, not a %s", name, types[i], a.typ)
+		}
+	}
+	return nil
+}
+
+/*
+method applies a method to a string (or to each value of a list, a list
+matches when one of its values does), or to an ip
+*/
+func method(e *Expr, name string, args []*Expr) (*Expr, error) {
+	if e.typ == TYPE_IP {
+		if name != "in" {
+			return nil, fmt.Errorf("unknown ip method %s", name)
+		}
+		list, err := literals(name, args)
+		if err != nil {
+			return nil, err
+		}
+		for i, n := range list {
+			if !strings.Contains(n, "/") {
+				list[i] = n + "/32"
+				if strings.Contains(n, ":") {
+					list[i] = n + "/128"
+				}
+			}
+		}
+		networks, err := engine.ParseNetworks(strings.Join(list, ","))
+		if err != nil || len(list) == 0 {
+			return nil, fmt.Errorf("in expects networks (%v)", err)
+		}
+		return boolean(func(r *request) bool {
+			ip := e.eval(r).ip
+			return ip != nil && engine.IsIpInNetworks(ip, networks)
+		}), nil
+	}
+	if e.typ != TYPE_STRING && e.typ != TYPE_LIST {
+		return nil, fmt.Errorf("unknown %s method %s", e.typ, name)
+	}
+
+	// the transformations
+	var transform func(string) string
+	switch name {
+	case "lower":
+		transform = strings.ToLower
+	case "urldecode":
+		transform = func(s string) string {
+			if d, err := url.QueryUnescape(s); err == nil {
+				return d
+			}
+			return s
+		}
+	case "length":
+		if err := checkArgs(name, args); err != nil {
+			return nil, err
+		}
+		return &Expr{typ: TYPE_INT, eval: func(r *request) value {
+			v := e.eval(r)
+			if v.typ == TYPE_LIST {
+				return value{typ: TYPE_INT, i: int64(len(v.list))}
+			}
+			return value{typ: TYPE_INT, i: int64(len(v.s))}
+		}}, nil
+	}
+	if transform != nil {
+		if err := checkArgs(name, args); err != nil {
+			return nil, err
+		}
+		return &Expr{typ: e.typ, eval: func(r *request) value {
+			v := e.eval(r)
+			if v.typ == TYPE_LIST {
+				list := make([]string, len(v.list))
+				for i, s := range v.list {
+					list[i] = transform(s)
+				}
+				return value{typ: TYPE_LIST, list: list}
+			}
+			return value{typ: TYPE_STRING, s: transform(v.s)}
+		}}, nil
+	}
+
+	// the predicates
+	var predicate func(s string, arg string) bool
+	switch name {
+	case "startsWith":
+		predicate = strings.HasPrefix
+	case "endsWith":
+		predicate = strings.HasSuffix
+	case "contains":
+		predicate = strings.Contains
+	case "matches":
+		list, err := literals(name, args)
+		if err != nil || len(list) != 1 {
+			return nil, fmt.Errorf("matches expects a regexp literal")
+		}
+		re, err := regexp.Compile(list[0])
+		if err != nil {
+			return nil, fmt.Errorf("bad regexp: %v", err)
+		}
+		return boolean(func(r *request) bool { return matchAny(e.eval(r), re.MatchString) }), nil
+	case "in":
+		list, err := literals(name, args)
+		if err != nil {
+			return nil, err
+		}
+		set := map[string]bool{}
+		for _, s := range list {
+			set[s] = true
+		}
+		return boolean(func(r *request) bool {
+			return matchAny(e.eval(r), func(s string) bool { return set[s] })
+		}), nil
+	default:
+		return nil, fmt.Errorf("unknown %s method %s", e.typ, name)
+	}
+	if err := checkArgs(name, args, TYPE_STRING); err != nil {
+		return nil, err
+	}
+	arg := args[0]
+	return boolean(func(r *request) bool {
+		a := arg.eval(r).s
+		return matchAny(e.eval(r), func(s string) bool { return predicate(s, a) })
+	}), nil
+}
+
+/*
+matchAny is true when a string (or one of the values of a list) matches
+*/
+func matchAny(v value, match func(string) bool) bool {
+	if v.typ != TYPE_LIST {
+		return match(v.s)
+	}
+	for _, s := range v.list {
+		if match(s) {
+			return true
+		}
+	}
+	return false
+}
+
+// the functions, taking a name (header, arg, cookie, claim)
+var functions = map[string]struct {
+	typ  string
+	eval func(r *request, name string) value
+}{
+	"header": {TYPE_STRING, func(r *request, name string) value {
+		return value{typ: TYPE_STRING, s: first(r.payload.GetHeader(name))}
+	}},
+	"arg": {TYPE_STRING, func(r *request, name string) value {
+		return value{typ: TYPE_STRING, s: first(r.args()[name])}
+	}},
+	"cookie": {TYPE_STRING, func(r *request, name string) value {
+		return value{typ: TYPE_STRING, s: first(r.cookies()[name])}
+	}},
+	"claim": {TYPE_STRING, func(r *request, name string) value {
+		return value{typ: TYPE_STRING, s: r.payload.Claims[name]}
+	}},
+	"has_header": {TYPE_BOOL, func(r *request, name string) value {
+		return value{typ: TYPE_BOOL, b: len(r.payload.GetHeader(name)) > 0}
+	}},
+	"has_arg": {TYPE_BOOL, func(r *request, name string) value {
+		_, ok := r.args()[name]
+		return value{typ: TYPE_BOOL, b: ok}
+	}},
+	"has_cookie": {TYPE_BOOL, func(r *request, name string) value {
+		_, ok := r.cookies()[name]
+		return value{typ: TYPE_BOOL, b: ok}
+	}},
+	"has_claim": {TYPE_BOOL, func(r *request, name string) value {
+		_, ok := r.payload.Claims[name]
+		return value{typ: TYPE_BOOL, b: ok}
+	}},
+}
+
+func function(name string, args []*Expr) (*Expr, error) {
+	f, ok := functions[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown function %s", name)
+	}
+	if err := checkArgs(name, args, TYPE_STRING); err != nil {
+		return nil, err
+	}
+	arg := args[0]
+	return &Expr{typ: f.typ, eval: func(r *request) value { return f.eval(r, arg.eval(r).s) }}, nil
+}
+
+func first(values []string) string {
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
+
+func field(typ string, eval func(r *request) value) *Expr {
+	return &Expr{typ: typ, eval: eval}
+}
+
+func str(f func(r *request) string) *Expr {
+	return field(TYPE_STRING, func(r *request) value { return value{typ: TYPE_STRING, s: f(r)} })
+}
+
+func list(f func(r *request) []string) *Expr {
+	return field(TYPE_LIST, func(r *request) value { return value{typ: TYPE_LIST, list: f(r)} })
+}
+
+// the request fields
+var variables = map[string]*Expr{
+	"method": str(func(r *request) string { return r.payload.Method }),
+	// decoded, with the dot-segments resolved
+	"path":         str(func(r *request) string { return r.parsed().Path }),
+	"query":        str(func(r *request) string { return r.payload.Url.RawQuery }),
+	"host":         str(func(r *request) string { return r.host() }),
+	"content_type": str(func(r *request) string { return r.parsed().ContentType }),
+	"body":         str(func(r *request) string { return string(r.payload.Body) }),
+	"country":      str(func(r *request) string { return r.country() }),
+	"ip": field(TYPE_IP, func(r *request) value {
+		return value{typ: TYPE_IP, ip: net.ParseIP(r.payload.RemoteAddr)}
+	}),
+	// the values of the query, form, json and xml fields
+	"args":      list(func(r *request) []string { return r.argValues() }),
+	"arg_names": list(func(r *request) []string { return r.argNames() }),
+	"headers": list(func(r *request) []string {
+		values := []string{}
+		for _, v := range r.payload.Headers {
+			values = append(values, v...)
+		}
+		return values
+	}),
+	// lowercase
+	"header_names": list(func(r *request) []string {
+		names := []string{}
+		for name := range r.payload.Headers {
+			names = append(names, strings.ToLower(name))
+		}
+		return names
+	}),
+	"cookies": list(func(r *request) []string {
+		values := []string{}
+		for _, v := range r.cookies() {
+			values = append(values, v...)
+		}
+		return values
+	}),
+}
@@ -0,0 +1,135 @@
+package rules
+
+import (
+	"net"
+	"net/url"
+	"testing"
+
+	"github.com/nzin/taxsi2/internal/com"
+	"github.com/stretchr/testify/assert"
+)
+
+/*
+ * This is a mock implementation of the CountryLookup interface
+ */
+type CountryLookupMock struct {
+	countries map[string]string
+}
+
+func (c *CountryLookupMock) Country(ip net.IP) string {
+	return c.countries[ip.String()]
+}
+
+func newPayload(method string, rawurl string, headers map[string][]string, body string) *com.TaxsiCom {
+	u, _ := url.Parse(rawurl)
+	if headers == nil {
+		headers = map[string][]string{}
+	}
+	return &com.TaxsiCom{
+		Method:     method,
+		Url:        u,
+		Headers:    headers,
+		Body:       []byte(body),
+		RemoteAddr: "192.168.1.10",
+	}
+}
+
+func TestCompile(t *testing.T) {
+	countries := &CountryLookupMock{countries: map[string]string{"192.168.1.10": "FR"}}
+	post := newPayload("POST", "http://www.example.com/admin/users?id=12&debug=1", map[string][]string{
+		"Content-Type": {"application/x-www-form-urlencoded"},
+		"User-Agent":   {"curl/8.0"},
+		"Cookie":       {"session=abc"},
+	}, "name=Bob%20Smith&role=admin")
+	post.Claims = map[string]string{"scope": "read write"}
+
+	t.Run("happy path: matching expressions", func(t *testing.T) {
+		for expression, expected := range map[string]bool{
+			`method == "POST" && path.startsWith("/admin") && !ip.in("10.0.0.0/8")`:     true,
+			`method == "POST" && path.startsWith("/admin") && !ip.in("192.168.0.0/16")`: false,
+			`method == "GET" || path.endsWith("/users")`:                                true,
+			`!(method == "GET")`: true,
+			`ip == "192.168.1.10" && ip.in("192.168.1.10", "::1")`:                         true,
+			`header("user-agent").lower().startsWith("curl/")`:                             true,
+			`has_header("X-Forwarded-For")`:                                                false,
+			`arg("id") == "12" && arg("role") == "admin" && arg("missing") == ""`:          true,
+			`arg("name").matches("^bob ") || arg("name").lower().matches("^bob ")`:         true,
+			`args.contains("admin") && args.length() == 4`:                                 true,
+			`arg_names.in("debug", "test")`:                                                true,
+			`headers.matches("(?i)CURL")`:                                                  true,
+			`header_names.in("cookie")`:                                                    true,
+			`cookie("session") == "abc" && has_cookie("session") && cookies.length() == 1`: true,
+			`claim("scope").contains("write") && has_claim("scope") && !has_claim("sub")`:  true,
+			`country.in("FR", "DE") && country != "US"`:                                    true,
+			`host == "www.example.com" && query.contains("debug=1")`:                       true,
+			`content_type == "application/x-www-form-urlencoded" && body.length() > 10`:    true,
+			`body.urldecode().contains("Bob Smith") && body.length() <= 27`:                true,
+			`true && !false`: true,
+		} {
+			e, err := Compile(expression)
+			assert.Nil(t, err, expression)
+			if err == nil {
+				assert.Equal(t, expected, e.Match(post, countries), expression)
+			}
+		}
+	})
+
+	t.Run("happy path: without country lookup", func(t *testing.T) {
+		e, err := Compile(`country == ""`)
+		assert.Nil(t, err)
+		assert.True(t, e.Match(post, nil))
+	})
+
+	t.Run("not happy path: invalid expressions", func(t *testing.T) {
+		for _, expression := range []string{
+			``,
+			`method`,
+			`method == `,
+			`method == "POST" &&`,
+			`method = "POST"`,
+			`(method == "POST"`,
+			`method == "POST")`,
+			`method == 1`,
+			`method > "A"`,
+			`args == "a"`,
+			`foo == "bar"`,
+			`header("a", "b") == ""`,
+			`header(1) == ""`,
+			`unknown("a")`,
+			`path.foo("a")`,
+			`path.matches("(")`,
+			`path.matches(method)`,
+			`ip.in("10.0.0.0/33")`,
+			`ip.in()`,
+			`ip == "not an ip"`,
+			`ip.startsWith("10.")`,
+			`!method`,
+			`"unterminated == method`,
+			`method == "POST" # comment`,
+			`path.length() > 99999999999999999999`,
+		} {
+			_, err := Compile(expression)
+			assert.NotNil(t, err, expression)
+		}
+	})
+
+	t.Run("not happy path: too deep expressions", func(t *testing.T) {
+		expression := ""
+		for i := 0; i < MAX_PARSE_DEPTH+1; i++ {
+			expression += "("
+		}
+		expression += "true"
+		for i := 0; i < MAX_PARSE_DEPTH+1; i++ {
+			expression += ")"
+		}
+		_, err := Compile(expression)
+		assert.NotNil(t, err)
+
+		expression = ""
+		for i := 0; i < MAX_PARSE_DEPTH+1; i++ {
+			expression += "!"
+		}
+		_, err = Compile(expression + "true")
+		assert.NotNil(t, err)
+	})
+}
@@ -0,0 +1,341 @@
+package rules
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	// the maximum size of an expression
+	MAX_EXPRESSION_SIZE = 4096
+	// the maximum nesting of the sub expressions
+	MAX_PARSE_DEPTH = 64
+)
+
+type token struct {
+	kind  byte
+	value string
+	pos   int
+}
+
+// token kinds (the operators and the punctuators are TOKEN_OP)
+const (
+	TOKEN_IDENT  = 'i'
+	TOKEN_INT    = 'n'
+	TOKEN_STRING = 's'
+	TOKEN_OP     = 'o'
+	TOKEN_EOF    = 0
+)
+
+// the operators, the longest first
+var operators = []string{"&&", "||", "==", "!=", "<=", ">=", "<", ">", "!", "(", ")", ",", "."}
+
+func isIdentStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+/*
+tokenize splits an expression in tokens
+*/
+func tokenize(s string) ([]token, error) {
+	tokens := []token{}
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case isIdentStart(c):
+			j := i + 1
+			for j < len(s) && (isIdentStart(s[j]) || isDigit(s[j])) {
+				j++
+			}
+			tokens = append(tokens, token{kind: TOKEN_IDENT, value: s[i:j], pos: i})
+			i = j
+		case isDigit(c):
+			j := i + 1
+			for j < len(s) && isDigit(s[j]) {
+				j++
+			}
+			tokens = append(tokens, token{kind: TOKEN_INT, value: s[i:j], pos: i})
+			i = j
+		case c == '"':
+			j := i + 1
+			for j < len(s) && s[j] != '"' {
+				if s[j] == '\\' {
+					j++
+				}
+				j++
+			}
+			if j >= len(s) {
+				return nil, fmt.Errorf("unterminated string at %d", i)
+			}
+			value, err := strconv.Unquote(s[i : j+1])
+			if err != nil {
+				return nil, fmt.Errorf("bad string at %d: %v", i, err)
+			}
+			tokens = append(tokens, token{kind: TOKEN_STRING, value: value, pos: i})
+			i = j + 1
+		default:
+			found := false
+			for _, op := range operators {
+				if strings.HasPrefix(s[i:], op) {
+					tokens = append(tokens, token{kind: TOKEN_OP, value: op, pos: i})
+					i += len(op)
+					found = true
+					break
+				}
+			}
+			if !found {
+				return nil, fmt.Errorf("unexpected %q at %d", c, i)
+			}
+		}
+	}
+	return append(tokens, token{kind: TOKEN_EOF, pos: len(s)}), nil
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+	depth  int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != TOKEN_EOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *parser) isOp(op string) bool {
+	t := p.peek()
+	return t.kind == TOKEN_OP && t.value == op
+}
+
+func (p *parser) expect(op string) error {
+	t := p.next()
+	if t.kind != TOKEN_OP || t.value != op {
+		return unexpected(t, op)
+	}
+	return nil
+}
+
+func unexpected(t token, expected string) error {
+	if t.kind == TOKEN_EOF {
+		return fmt.Errorf("unexpected end of expression (expected %s)", expected)
+	}
+	return fmt.Errorf("unexpected %q at %d (expected %s)", t.value, t.pos, expected)
+}
+
+/*
+Compile parses and type checks an expression. The expression must be
+a boolean, like:
+
+	method == "POST" && path.startsWith("/admin") && !ip.in("10.0.0.0/8")
+
+The regexps and the networks are compiled once, here
+*/
+func Compile(expression string) (*Expr, error) {
+	if len(expression) > MAX_EXPRESSION_SIZE {
+		return nil, fmt.Errorf("expression bigger than %d bytes", MAX_EXPRESSION_SIZE)
+	}
+	tokens, err := tokenize(expression)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens}
+	e, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != TOKEN_EOF {
+		return nil, unexpected(t, "an operator")
+	}
+	if e.typ != TYPE_BOOL {
+		return nil, fmt.Errorf("the expression is a %s, not a bool", e.typ)
+	}
+	return e, nil
+}
+
+func (p *parser) parseOr() (*Expr, error) {
+	p.depth++
+	defer func() { p.depth-- }()
+	if p.depth > MAX_PARSE_DEPTH {
+		return nil, fmt.Errorf("expression nested more than %d times", MAX_PARSE_DEPTH)
+	}
+
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.isOp("||") {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		if left, err = logical("||", left, right); err != nil {
+			return nil, err
+		}
+	}
+	return left, nil
+}
+
+func (p *parser) parseAnd() (*Expr, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.isOp("&&") {
+		p.next()
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		if left, err = logical("&&", left, right); err != nil {
+			return nil, err
+		}
+	}
+	return left, nil
+}
+
+func (p *parser) parseUnary() (*Expr, error) {
+	if p.isOp("!") {
+		p.next()
+		p.depth++
+		defer func() { p.depth-- }()
+		if p.depth > MAX_PARSE_DEPTH {
+			return nil, fmt.Errorf("expression nested more than %d times", MAX_PARSE_DEPTH)
+		}
+		e, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return not(e)
+	}
+	return p.parseComparison()
+}
+
+func (p *parser) parseComparison() (*Expr, error) {
+	left, err := p.parsePostfix()
+	if err != nil {
+		return nil, err
+	}
+	t := p.peek()
+	if t.kind == TOKEN_OP {
+		switch t.value {
+		case "==", "!=", "<", "<=", ">", ">=":
+			p.next()
+			right, err := p.parsePostfix()
+			if err != nil {
+				return nil, err
+			}
+			return compare(t.value, left, right)
+		}
+	}
+	return left, nil
+}
+
+func (p *parser) parsePostfix() (*Expr, error) {
+	e, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+	for p.isOp(".") {
+		p.next()
+		t := p.next()
+		if t.kind != TOKEN_IDENT {
+			return nil, unexpected(t, "a method name")
+		}
+		args, err := p.parseArguments()
+		if err != nil {
+			return nil, err
+		}
+		if e, err = method(e, t.value, args); err != nil {
+			return nil, fmt.Errorf("%s at %d", err, t.pos)
+		}
+	}
+	return e, nil
+}
+
+func (p *parser) parseArguments() ([]*Expr, error) {
+	if err := p.expect("("); err != nil {
+		return nil, err
+	}
+	args := []*Expr{}
+	if p.isOp(")") {
+		p.next()
+		return args, nil
+	}
+	for {
+		arg, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, arg)
+		if p.isOp(",") {
+			p.next()
+			continue
+		}
+		if err := p.expect(")"); err != nil {
+			return nil, err
+		}
+		return args, nil
+	}
+}
+
+func (p *parser) parsePrimary() (*Expr, error) {
+	t := p.next()
+	switch t.kind {
+	case TOKEN_STRING:
+		return constant(value{typ: TYPE_STRING, s: t.value}), nil
+	case TOKEN_INT:
+		n, err := strconv.ParseInt(t.value, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("bad number %s at %d", t.value, t.pos)
+		}
+		return constant(value{typ: TYPE_INT, i: n}), nil
+	case TOKEN_IDENT:
+		switch t.value {
+		case "true", "false":
+			return constant(value{typ: TYPE_BOOL, b: t.value == "true"}), nil
+		}
+		if p.isOp("(") {
+			args, err := p.parseArguments()
+			if err != nil {
+				return nil, err
+			}
+			e, err := function(t.value, args)
+			if err != nil {
+				return nil, fmt.Errorf("%s at %d", err, t.pos)
+			}
+			return e, nil
+		}
+		e, ok := variables[t.value]
+		if !ok {
+			return nil, fmt.Errorf("unknown field %s at %d", t.value, t.pos)
+		}
+		return e, nil
+	case TOKEN_OP:
+		if t.value == "(" {
+			e, err := p.parseOr()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect(")"); err != nil {
+				return nil, err
+			}
+			return e, nil
+		}
+	}
+	return nil, unexpected(t, "a value")
+}
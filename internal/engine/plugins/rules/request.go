@@ -0,0 +1,104 @@
+package rules
+
+import (
+	"net"
+	"strings"
+
+	"github.com/nzin/taxsi2/internal/com"
+)
+
+/*
+CountryLookup returns the (ISO) country of an IP, "" if unknown
+*/
+type CountryLookup interface {
+	Country(ip net.IP) string
+}
+
+/*
+request is the view of a request the expressions are evaluated on,
+the fields are computed once, when an expression uses them
+*/
+type request struct {
+	payload   *com.TaxsiCom
+	countries CountryLookup
+
+	argsByName    map[string][]string
+	values        []string
+	names         []string
+	cookiesByName map[string][]string
+	countryCode   *string
+}
+
+func newRequest(payload *com.TaxsiCom, countries CountryLookup) *request {
+	return &request{
+		payload:   payload,
+		countries: countries,
+	}
+}
+
+func (r *request) parsed() *com.ParsedRequest {
+	return r.payload.Parse()
+}
+
+func (r *request) host() string {
+	host := r.payload.Url.Host
+	if host == "" {
+		host = first(r.payload.GetHeader("Host"))
+	}
+	return strings.ToLower(host)
+}
+
+func (r *request) country() string {
+	if r.countryCode == nil {
+		code := ""
+		if ip := net.ParseIP(r.payload.RemoteAddr); ip != nil && r.countries != nil {
+			code = r.countries.Country(ip)
+		}
+		r.countryCode = &code
+	}
+	return *r.countryCode
+}
+
+/*
+args returns the query, form, json and xml fields by name
+*/
+func (r *request) args() map[string][]string {
+	if r.argsByName == nil {
+		r.argsByName = make(map[string][]string)
+		r.values = []string{}
+		r.names = []string{}
+		for _, f := range r.parsed().Fields {
+			switch f.Source {
+			case com.SOURCE_QUERY, com.SOURCE_FORM, com.SOURCE_JSON, com.SOURCE_XML:
+				if _, ok := r.argsByName[f.Name]; !ok {
+					r.names = append(r.names, f.Name)
+				}
+				r.argsByName[f.Name] = append(r.argsByName[f.Name], f.Value)
+				r.values = append(r.values, f.Value)
+			}
+		}
+	}
+	return r.argsByName
+}
+
+func (r *request) argValues() []string {
+	r.args()
+	return r.values
+}
+
+func (r *request) argNames() []string {
+	r.args()
+	return r.names
+}
+
+func (r *request) cookies() map[string][]string {
+	if r.cookiesByName == nil {
+		r.cookiesByName = make(map[string][]string)
+		for _, f := range r.parsed().Fields {
+			if f.Source == com.SOURCE_COOKIE {
+				r.cookiesByName[f.Name] = append(r.cookiesByName[f.Name], f.Value)
+			}
+		}
+	}
+	return r.cookiesByName
+}
@@ -0,0 +1,183 @@
+package rules
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/nzin/taxsi2/internal/com"
+	"github.com/nzin/taxsi2/internal/db"
+	"github.com/nzin/taxsi2/internal/engine"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// the actions of the rules
+	ACTION_BLOCK     = "block"
+	ACTION_CHALLENGE = "challenge"
+	// the match is only reported (see VARIABLE_LOGGED)
+	ACTION_LOG = "log"
+
+	// output variables
+	// the rule that blocked (or challenged) the request
+	VARIABLE_RULE = "rule_id"
+	// comma separated list of the matching log rules
+	VARIABLE_LOGGED = "rule_logged"
+)
+
+type rule struct {
+	id     string
+	action string
+	expr   *Expr
+}
+
+/*
+compileRule checks the action and compiles the expression of a rule
+*/
+func compileRule(r db.Rule) (*rule, error) {
+	switch r.Action {
+	case ACTION_BLOCK, ACTION_CHALLENGE, ACTION_LOG:
+	default:
+		return nil, fmt.Errorf("bad action %s (must be block, challenge or log)", r.Action)
+	}
+	expr, err := Compile(r.Expression)
+	if err != nil {
+		return nil, err
+	}
+	return &rule{id: r.ID, action: r.Action, expr: expr}, nil
+}
+
+/*
+ValidateRule returns why a rule can't be used (nil if it can)
+*/
+func ValidateRule(r db.Rule) error {
+	_, err := compileRule(r)
+	return err
+}
+
+/*
+lessId sorts the rule IDs, numerically when both are numbers
+*/
+func lessId(a string, b string) bool {
+	na, errA := strconv.ParseInt(a, 10, 64)
+	nb, errB := strconv.ParseInt(b, 10, 64)
+	if errA == nil && errB == nil {
+		return na < nb
+	}
+	return a < b
+}
+
+/*
+Sort returns the rules in the order they are evaluated
+*/
+func Sort(rules []db.Rule) []db.Rule {
+	sorted := append([]db.Rule{}, rules...)
+	sort.SliceStable(sorted, func(a, b int) bool {
+		return lessId(sorted[a].ID, sorted[b].ID)
+	})
+	return sorted
+}
+
+/*
+RulesWafPlugin evaluates the custom rules (see Compile for the syntax),
+in the order of their ID: the first block (or challenge) rule that
+matches decides
+*/
+type RulesWafPlugin struct {
+	ds        db.DbServiceRules
+	countries CountryLookup
+	mu        sync.RWMutex
+	rules     []*rule
+}
+
+/*
+NewRulesWafPlugin creates the plugin, countries is used by the
+country field (it can be nil, then the country is always "")
+*/
+func NewRulesWafPlugin(ds db.DbServiceRules, countries CountryLookup) (engine.WafEnginePlugin, error) {
+	return newRulesWafPlugin(ds, countries)
+}
+
+func newRulesWafPlugin(ds db.DbServiceRules, countries CountryLookup) (*RulesWafPlugin, error) {
+	r := &RulesWafPlugin{
+		ds:        ds,
+		countries: countries,
+	}
+	if err := r.load(); err != nil {
+		return nil, err
+	}
+	ds.SubscribeChanges(db.CHANGELOG_TABLE_RULE, r)
+	return r, nil
+}
+
+func (p *RulesWafPlugin) Name() string {
+	return "rules"
+}
+
+/*
+load compiles the rules, an invalid rule is logged and skipped
+*/
+func (p *RulesWafPlugin) load() error {
+	rows, err := p.ds.GetRules()
+	if err != nil {
+		return err
+	}
+	rules := []*rule{}
+	for _, row := range Sort(rows) {
+		r, err := compileRule(row)
+		if err != nil {
+			logrus.Errorf("rule %s: %v", row.ID, err)
+			continue
+		}
+		rules = append(rules, r)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.rules = rules
+	return nil
+}
+
+// NotifyDbChange is called when the rules change
+func (p *RulesWafPlugin) NotifyDbChange(key string) {
+	if err := p.load(); err != nil {
+		logrus.Errorf("Error reading rules: %v", err)
+	}
+}
+
+func (p *RulesWafPlugin) Scan(ctx context.Context, payload *com.TaxsiCom) bool {
+	return p.ScanVerdict(ctx, payload) == engine.VERDICT_PASS
+}
+
+func (p *RulesWafPlugin) ScanVerdict(ctx context.Context, payload *com.TaxsiCom) engine.Verdict {
+	p.mu.RLock()
+	rules := p.rules
+	p.mu.RUnlock()
+
+	r := newRequest(payload, p.countries)
+	logged := []string{}
+	defer func() {
+		if len(logged) > 0 {
+			payload.SetVariable(VARIABLE_LOGGED, strings.Join(logged, ","))
+		}
+	}()
+	for _, rule := range rules {
+		if !rule.expr.eval(r).b {
+			continue
+		}
+		switch rule.action {
+		case ACTION_LOG:
+			logged = append(logged, rule.id)
+		case ACTION_BLOCK:
+			payload.SetVariable(VARIABLE_RULE, rule.id)
+			return engine.VERDICT_BLOCK
+		case ACTION_CHALLENGE:
+			payload.SetVariable(VARIABLE_RULE, rule.id)
+			return engine.VERDICT_CHALLENGE
+		}
+	}
+	return engine.VERDICT_PASS
+}
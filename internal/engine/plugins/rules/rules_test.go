@@ -0,0 +1,93 @@
+package rules
+
+import (
+	"context"
+	"testing"
+
+	"github.com/nzin/taxsi2/internal/db"
+	"github.com/nzin/taxsi2/internal/engine"
+	"github.com/stretchr/testify/assert"
+)
+
+/*
+ * This is a mock implementation of the db.DbServiceRules interface
+ */
+type DbServiceRulesMock struct {
+	rules     map[string]db.Rule
+	listeners []db.DbChangeListener
+}
+
+func (m *DbServiceRulesMock) SubscribeChanges(table int, listener db.DbChangeListener) {
+	m.listeners = append(m.listeners, listener)
+}
+func (m *DbServiceRulesMock) GetRules() ([]db.Rule, error) {
+	rules := []db.Rule{}
+	for _, r := range m.rules {
+		rules = append(rules, r)
+	}
+	return rules, nil
+}
+func (m *DbServiceRulesMock) SetRule(rule *db.Rule) error {
+	m.rules[rule.ID] = *rule
+	for _, l := range m.listeners {
+		l.NotifyDbChange(rule.ID)
+	}
+	return nil
+}
+func (m *DbServiceRulesMock) DeleteRule(id string) error {
+	delete(m.rules, id)
+	for _, l := range m.listeners {
+		l.NotifyDbChange(id)
+	}
+	return nil
+}
+
+func TestRulesPlugin(t *testing.T) {
+	t.Run("happy path: rules are evaluated in the order of their ID", func(t *testing.T) {
+		ds := &DbServiceRulesMock{rules: map[string]db.Rule{
+			"9":   {ID: "9", Expression: `path.startsWith("/admin")`, Action: ACTION_LOG},
+			"10":  {ID: "10", Expression: `path.startsWith("/admin") && method == "POST"`, Action: ACTION_CHALLENGE},
+			"100": {ID: "100", Expression: `path.startsWith("/admin")`, Action: ACTION_BLOCK},
+			// invalid rules are skipped
+			"bad":  {ID: "bad", Expression: `path ==`, Action: ACTION_BLOCK},
+			"bad2": {ID: "bad2", Expression: `true`, Action: "drop"},
+		}}
+		plugin, err := newRulesWafPlugin(ds, nil)
+		assert.Nil(t, err)
+		assert.Equal(t, "rules", plugin.Name())
+		assert.Equal(t, 3, len(plugin.rules))
+
+		payload := newPayload("POST", "http://www.example.com/admin/users", nil, "")
+		assert.Equal(t, engine.VERDICT_CHALLENGE, plugin.ScanVerdict(context.Background(), payload))
+		assert.Equal(t, "10", payload.Variables[VARIABLE_RULE])
+		assert.Equal(t, "9", payload.Variables[VARIABLE_LOGGED])
+
+		payload = newPayload("GET", "http://www.example.com/admin/users", nil, "")
+		assert.False(t, plugin.Scan(context.Background(), payload))
+		assert.Equal(t, "100", payload.Variables[VARIABLE_RULE])
+
+		payload = newPayload("GET", "http://www.example.com/", nil, "")
+		assert.True(t, plugin.Scan(context.Background(), payload))
+		assert.Equal(t, "", payload.Variables[VARIABLE_RULE])
+		assert.Equal(t, "", payload.Variables[VARIABLE_LOGGED])
+	})
+
+	t.Run("happy path: rules are hot reloaded", func(t *testing.T) {
+		ds := &DbServiceRulesMock{rules: map[string]db.Rule{}}
+		plugin, err := newRulesWafPlugin(ds, nil)
+		assert.Nil(t, err)
+		assert.True(t, plugin.Scan(context.Background(), newPayload("TRACE", "http://www.example.com/", nil, "")))
+
+		ds.SetRule(&db.Rule{ID: "block-trace", Expression: `method.in("TRACE", "TRACK")`, Action: ACTION_BLOCK})
+		assert.False(t, plugin.Scan(context.Background(), newPayload("TRACE", "http://www.example.com/", nil, "")))
+
+		ds.DeleteRule("block-trace")
+		assert.True(t, plugin.Scan(context.Background(), newPayload("TRACE", "http://www.example.com/", nil, "")))
+	})
+
+	t.Run("not happy path: validating rules", func(t *testing.T) {
+		assert.Nil(t, ValidateRule(db.Rule{ID: "1", Expression: `method == "TRACE"`, Action: ACTION_BLOCK}))
+		assert.NotNil(t, ValidateRule(db.Rule{ID: "1", Expression: `method == "TRACE"`, Action: "allow"}))
+		assert.NotNil(t, ValidateRule(db.Rule{ID: "1", Expression: `method`, Action: ACTION_LOG}))
+	})
+}
@@ -0,0 +1,137 @@
+package sqli
+
+/*
+The built-in fingerprint set is generated from the ways an injection
+breaks out of a value (the prefixes: a string, a number, closing
+parenthesis...) combined with what it does next (the tails: a
+tautology, a union, a stacked query...).
+A fingerprint matches when it starts with one of the generated
+patterns (the patterns are truncated to FINGERPRINT_SIZE tokens).
+*/
+
+// the value the injection breaks out of
+var (
+	stringPrefixes = []string{"s", "s)", "s))"}
+	numberPrefixes = []string{"1", "1)", "1))"}
+	otherPrefixes  = []string{"n)", "n))", "v", "v)"}
+)
+
+// any value
+var values = []string{"1", "s", "n", "v", "t", "f("}
+
+type family struct {
+	prefixes [][]string
+	tails    []string
+}
+
+func tautologies() []string {
+	// and sleep(5), or (select...), or 1=1--, or true
+	tails := []string{"&f(", "&(", "&1c", "&sc", "&vc", "&1;", "&s;", "&t"}
+	for _, v1 := range values {
+		for _, v2 := range append(values, "(") {
+			// or 1=1, and 'a'='a', or user=user...
+			tails = append(tails, "&"+v1+"o"+v2)
+		}
+		// or 1 limit 1
+		tails = append(tails, "&"+v1+"k")
+	}
+	return tails
+}
+
+func conditions() []string {
+	tails := []string{}
+	for _, v1 := range values {
+		for _, v2 := range values {
+			// having 1=1, where 'a'='a'
+			tails = append(tails, "k"+v1+"o"+v2)
+		}
+	}
+	return tails
+}
+
+func unions() []string {
+	// union select..., union (select...
+	return []string{"UE", "U(E"}
+}
+
+func stackedQueries() []string {
+	// ; drop table users...
+	return []string{";E"}
+}
+
+func subQueries() []string {
+	// =(select ...), in (select...), =sleep(...)
+	return []string{"o(E", "of("}
+}
+
+func groups() []string {
+	// order by 5--, group by 1,2
+	return []string{"B1", "Bn", "Bf("}
+}
+
+func timeDelays() []string {
+	// waitfor delay '0:0:5'
+	return []string{"Eks", "Ek1"}
+}
+
+func stringBreaks() []string {
+	// admin'--, ' or ', '=' or '
+	return []string{"c", "&s", "os"}
+}
+
+/*
+statements are SQL fragments (without any break out)
+*/
+var statements = []string{
+	"Eokn", "Eokv", "Enknk", "Enkv", "En,n,", "En,nk", "En,1,", "Ef(",
+	"Eknk", "Ekn(", "Ekn;", "Ekv", "E1,1,", "E1,n", "E1U", "E1;", "Ev,", "E(E",
+}
+
+func families() []family {
+	return []family{
+		{prefixes: [][]string{stringPrefixes, numberPrefixes, otherPrefixes}, tails: tautologies()},
+		{prefixes: [][]string{stringPrefixes}, tails: conditions()},
+		{prefixes: [][]string{stringPrefixes, numberPrefixes, otherPrefixes, {"n"}}, tails: unions()},
+		{prefixes: [][]string{stringPrefixes, numberPrefixes, otherPrefixes}, tails: stackedQueries()},
+		{prefixes: [][]string{stringPrefixes, numberPrefixes}, tails: subQueries()},
+		{prefixes: [][]string{stringPrefixes, numberPrefixes, otherPrefixes}, tails: groups()},
+		{prefixes: [][]string{stringPrefixes, numberPrefixes, otherPrefixes}, tails: timeDelays()},
+		{prefixes: [][]string{stringPrefixes}, tails: stringBreaks()},
+		{prefixes: [][]string{{"1)", "1))"}}, tails: []string{"c"}},
+	}
+}
+
+var fingerprints = buildFingerprints()
+
+func buildFingerprints() map[string]bool {
+	set := make(map[string]bool)
+	for _, fam := range families() {
+		for _, prefixes := range fam.prefixes {
+			for _, p := range prefixes {
+				for _, t := range fam.tails {
+					fp := p + t
+					if len(fp) > FINGERPRINT_SIZE {
+						fp = fp[:FINGERPRINT_SIZE]
+					}
+					set[fp] = true
+				}
+			}
+		}
+	}
+	for _, s := range statements {
+		set[s] = true
+	}
+	return set
+}
+
+/*
+IsFingerprint tells if a fingerprint is a known SQL injection
+*/
+func IsFingerprint(fp string) bool {
+	for i := len(fp); i > 0; i-- {
+		if fingerprints[fp[:i]] {
+			return true
+		}
+	}
+	return false
+}
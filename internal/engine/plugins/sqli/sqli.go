@@ -0,0 +1,90 @@
+package sqli
+
+import (
+	"context"
+	"strings"
+
+	"github.com/nzin/taxsi2/internal/com"
+	"github.com/nzin/taxsi2/internal/engine"
+)
+
+const (
+	// output variable
+	VARIABLE_FINGERPRINT = "sqli_fingerprint"
+)
+
+// the headers that are commonly injected (the others carry urls, lists...)
+var scannedHeaders = map[string]bool{
+	"User-Agent":      true,
+	"Referer":         true,
+	"X-Forwarded-For": true,
+}
+
+/*
+SqliWafPlugin detects the SQL injections in the query args,
+the form (and multipart) fields, the uploaded file names, the JSON
+and XML values, the cookies and some headers (see scannedHeaders)
+*/
+type SqliWafPlugin struct {
+}
+
+func NewSqliWafPlugin() engine.WafEnginePlugin {
+	return &SqliWafPlugin{}
+}
+
+func (s *SqliWafPlugin) Name() string {
+	return "sqli"
+}
+
+// Interruptible is true: the scan stops when the context is done
+func (s *SqliWafPlugin) Interruptible() bool {
+	return true
+}
+
+func (s *SqliWafPlugin) Scan(ctx context.Context, payload *com.TaxsiCom) bool {
+	for _, input := range Inputs(payload) {
+		if ctx.Err() != nil {
+			// (the verdict is ignored)
+			return true
+		}
+		if fp, ok := IsSQLi(input); ok {
+			payload.SetVariable(VARIABLE_FINGERPRINT, fp)
+			return false
+		}
+	}
+	return true
+}
+
+/*
+IsSQLi checks an input as if it was injected outside of a string,
+in a single quoted string, and in a double quoted string.
+It returns the matching fingerprint
+*/
+func IsSQLi(input string) (string, bool) {
+	for _, delim := range []byte{0, '\'', '"'} {
+		if delim != 0 && strings.IndexByte(input, delim) < 0 {
+			continue
+		}
+		fp := Fingerprint(input, delim)
+		if IsFingerprint(fp) {
+			return fp, true
+		}
+	}
+	return "", false
+}
+
+/*
+Inputs returns the user controlled values of a request
+(with their decoded variants)
+*/
+func Inputs(payload *com.TaxsiCom) []string {
+	inputs := []string{}
+	parsed := payload.Parse()
+	for _, f := range parsed.FieldsFrom(com.SOURCE_QUERY, com.SOURCE_FORM, com.SOURCE_FILE, com.SOURCE_COOKIE, com.SOURCE_JSON, com.SOURCE_XML, com.SOURCE_HEADER) {
+		if f.Source == com.SOURCE_HEADER && !scannedHeaders[f.Name] {
+			continue
+		}
+		inputs = append(inputs, f.Decoded...)
+	}
+	return inputs
+}
@@ -0,0 +1,142 @@
+package sqli
+
+import (
+	"bufio"
+	"context"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/nzin/taxsi2/internal/com"
+	"github.com/nzin/taxsi2/internal/engine"
+	"github.com/stretchr/testify/assert"
+)
+
+func readCorpus(t *testing.T, path string) []string {
+	f, err := os.Open(path)
+	assert.Nil(t, err)
+	defer f.Close()
+
+	lines := []string{}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" || strings.HasPrefix(line, "# ") {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	assert.Nil(t, scanner.Err())
+	return lines
+}
+
+func TestFingerprint(t *testing.T) {
+	assert.Equal(t, "s&sos", Fingerprint("' or '1'='1", '\''))
+	assert.Equal(t, "s&1o1", Fingerprint("' or 1=1--", '\''))
+	assert.Equal(t, "1&1o1", Fingerprint("1 or 1=1", 0))
+	assert.Equal(t, "sc", Fingerprint("admin'--", '\''))
+	// comments and unary operators are folded
+	assert.Equal(t, "1UE1,", Fingerprint("-1/**/union/**/all/**/select 1,2", 0))
+	assert.Equal(t, "1B1c", Fingerprint("1 ORDER BY 1--", 0))
+	// MySQL executable comments are code
+	assert.Equal(t, "1UE1,", Fingerprint("1 /*!50000union*/ /*!select*/ 1,2", 0))
+	// functions need parenthesis
+	assert.Equal(t, "1&f(1", Fingerprint("1 and sleep(5)", 0))
+	assert.Equal(t, "n", Fingerprint("sleep", 0))
+	// time based
+	assert.Equal(t, "sEksc", Fingerprint("1' waitfor delay '0:0:5'--", '\''))
+	assert.Equal(t, "1&t", Fingerprint("1 or true", 0))
+}
+
+func TestCorpus(t *testing.T) {
+	t.Run("happy path: attacks are detected", func(t *testing.T) {
+		attacks := readCorpus(t, "testdata/attacks.txt")
+		assert.Greater(t, len(attacks), 50)
+		for _, a := range attacks {
+			_, ok := IsSQLi(a)
+			assert.True(t, ok, "not detected: %s (%s, %s, %s)", a, Fingerprint(a, 0), Fingerprint(a, '\''), Fingerprint(a, '"'))
+		}
+	})
+
+	t.Run("happy path: benign inputs are not detected", func(t *testing.T) {
+		benign := readCorpus(t, "testdata/benign.txt")
+		assert.Greater(t, len(benign), 50)
+		for _, b := range benign {
+			fp, ok := IsSQLi(b)
+			assert.False(t, ok, "false positive: %s (%s)", b, fp)
+		}
+	})
+}
+
+func TestSqliPlugin(t *testing.T) {
+	plugin := NewSqliWafPlugin()
+
+	request := func(rawurl string, contentType string, body string, cookie string) *com.TaxsiCom {
+		u, _ := url.Parse(rawurl)
+		payload := &com.TaxsiCom{
+			Method:  "POST",
+			Url:     u,
+			Headers: map[string][]string{},
+			Body:    []byte(body),
+		}
+		if contentType != "" {
+			payload.Headers["Content-Type"] = []string{contentType}
+		}
+		if cookie != "" {
+			payload.Headers["Cookie"] = []string{cookie}
+		}
+		return payload
+	}
+
+	t.Run("happy path: legit requests", func(t *testing.T) {
+		assert.True(t, plugin.Scan(context.Background(), request("http://www.example.com/?name=O%27Reilly&page=2", "", "", "")))
+		assert.True(t, plugin.Scan(context.Background(), request("http://www.example.com/", "application/json", `{"query": "tom and jerry", "ids": [1, 2]}`, "session=abc123")))
+	})
+
+	t.Run("happy path: query args", func(t *testing.T) {
+		payload := request("http://www.example.com/?id=1%27%20or%20%271%27%3D%271", "", "", "")
+		assert.False(t, plugin.Scan(context.Background(), payload))
+		assert.Equal(t, "s&sos", payload.Variables[VARIABLE_FINGERPRINT])
+	})
+
+	t.Run("happy path: form fields", func(t *testing.T) {
+		assert.False(t, plugin.Scan(context.Background(), request("http://www.example.com/login", "application/x-www-form-urlencoded; charset=utf-8", "user=admin%27--&password=x", "")))
+	})
+
+	t.Run("happy path: JSON values", func(t *testing.T) {
+		assert.False(t, plugin.Scan(context.Background(), request("http://www.example.com/api", "application/json", `{"filter": {"ids": ["1 union select 1,2"]}}`, "")))
+	})
+
+	t.Run("happy path: cookies", func(t *testing.T) {
+		assert.False(t, plugin.Scan(context.Background(), request("http://www.example.com/", "", "", "lang=en; id=1%20or%201%3D1")))
+	})
+
+	t.Run("happy path: multipart fields and file names", func(t *testing.T) {
+		body := "--XX\r\nContent-Disposition: form-data; name=\"id\"\r\n\r\n1' waitfor delay '0:0:5'--\r\n--XX--\r\n"
+		assert.False(t, plugin.Scan(context.Background(), request("http://www.example.com/upload", "multipart/form-data; boundary=XX", body, "")))
+
+		body = "--XX\r\nContent-Disposition: form-data; name=\"file\"; filename=\"a' or 1=1--.png\"\r\n\r\nPNG\r\n--XX--\r\n"
+		assert.False(t, plugin.Scan(context.Background(), request("http://www.example.com/upload", "multipart/form-data; boundary=XX", body, "")))
+	})
+
+	t.Run("happy path: headers", func(t *testing.T) {
+		for _, name := range []string{"User-Agent", "Referer", "X-Forwarded-For"} {
+			payload := request("http://www.example.com/", "", "", "")
+			payload.Headers[name] = []string{"1' or '1'='1"}
+			assert.False(t, plugin.Scan(context.Background(), payload), name)
+		}
+
+		// the other headers are not scanned
+		payload := request("http://www.example.com/", "", "", "")
+		payload.Headers["Accept"] = []string{"1' or '1'='1"}
+		payload.Headers["User-Agent"] = []string{"Mozilla/5.0 (X11; Linux x86_64; rv:121.0) Gecko/20100101 Firefox/121.0"}
+		assert.True(t, plugin.Scan(context.Background(), payload))
+	})
+	t.Run("happy path: the scan stops when the context is done", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		assert.True(t, plugin.(engine.WafEngineInterruptiblePlugin).Interruptible())
+		assert.True(t, plugin.Scan(ctx, request("http://www.example.com/?id=1%20or%201%3D1", "", "", "")))
+	})
+}
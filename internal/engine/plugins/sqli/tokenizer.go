@@ -0,0 +1,382 @@
+package sqli
+
+import (
+	"strings"
+)
+
+/*
+token types (the fingerprint of an input is the list
+of the types of its first tokens, like libinjection)
+*/
+const (
+	TYPE_STRING      = 's'
+	TYPE_NUMBER      = '1'
+	TYPE_BOOLEAN     = 't'
+	TYPE_BAREWORD    = 'n'
+	TYPE_VARIABLE    = 'v'
+	TYPE_KEYWORD     = 'k'
+	TYPE_UNION       = 'U'
+	TYPE_GROUP       = 'B' // order by, group by
+	TYPE_EXPRESSION  = 'E' // select, drop, exec...
+	TYPE_FUNCTION    = 'f'
+	TYPE_OPERATOR    = 'o'
+	TYPE_LOGIC       = '&' // and, or, &&, ||
+	TYPE_COMMENT     = 'c'
+	TYPE_LEFTPARENS  = '('
+	TYPE_RIGHTPARENS = ')'
+	TYPE_COMMA       = ','
+	TYPE_SEMICOLON   = ';'
+	TYPE_UNKNOWN     = '?'
+
+	// the number of tokens of a fingerprint
+	FINGERPRINT_SIZE = 5
+)
+
+type token struct {
+	kind  byte
+	value string
+}
+
+var keywords = map[string]byte{
+	"select":    TYPE_EXPRESSION,
+	"insert":    TYPE_EXPRESSION,
+	"update":    TYPE_EXPRESSION,
+	"delete":    TYPE_EXPRESSION,
+	"drop":      TYPE_EXPRESSION,
+	"create":    TYPE_EXPRESSION,
+	"alter":     TYPE_EXPRESSION,
+	"truncate":  TYPE_EXPRESSION,
+	"exec":      TYPE_EXPRESSION,
+	"execute":   TYPE_EXPRESSION,
+	"declare":   TYPE_EXPRESSION,
+	"shutdown":  TYPE_EXPRESSION,
+	"waitfor":   TYPE_EXPRESSION,
+	"union":     TYPE_UNION,
+	"intersect": TYPE_UNION,
+	"except":    TYPE_UNION,
+
+	"and": TYPE_LOGIC,
+	"or":  TYPE_LOGIC,
+	"xor": TYPE_LOGIC,
+
+	"not":     TYPE_OPERATOR,
+	"like":    TYPE_OPERATOR,
+	"rlike":   TYPE_OPERATOR,
+	"regexp":  TYPE_OPERATOR,
+	"in":      TYPE_OPERATOR,
+	"is":      TYPE_OPERATOR,
+	"between": TYPE_OPERATOR,
+	"div":     TYPE_OPERATOR,
+	"mod":     TYPE_OPERATOR,
+	"sounds":  TYPE_OPERATOR,
+	"collate": TYPE_OPERATOR,
+
+	"null":  TYPE_NUMBER,
+	"true":  TYPE_BOOLEAN,
+	"false": TYPE_BOOLEAN,
+
+	"from":      TYPE_KEYWORD,
+	"where":     TYPE_KEYWORD,
+	"order":     TYPE_KEYWORD,
+	"group":     TYPE_KEYWORD,
+	"by":        TYPE_KEYWORD,
+	"having":    TYPE_KEYWORD,
+	"limit":     TYPE_KEYWORD,
+	"offset":    TYPE_KEYWORD,
+	"into":      TYPE_KEYWORD,
+	"values":    TYPE_KEYWORD,
+	"set":       TYPE_KEYWORD,
+	"table":     TYPE_KEYWORD,
+	"database":  TYPE_KEYWORD,
+	"case":      TYPE_KEYWORD,
+	"when":      TYPE_KEYWORD,
+	"then":      TYPE_KEYWORD,
+	"else":      TYPE_KEYWORD,
+	"end":       TYPE_KEYWORD,
+	"as":        TYPE_KEYWORD,
+	"join":      TYPE_KEYWORD,
+	"on":        TYPE_KEYWORD,
+	"all":       TYPE_KEYWORD,
+	"distinct":  TYPE_KEYWORD,
+	"top":       TYPE_KEYWORD,
+	"procedure": TYPE_KEYWORD,
+	"outfile":   TYPE_KEYWORD,
+	"dumpfile":  TYPE_KEYWORD,
+	"delay":     TYPE_KEYWORD,
+	"exists":    TYPE_KEYWORD,
+}
+
+// known functions (a function is a bareword when not followed by '(')
+var functions = map[string]bool{
+	"sleep": true, "benchmark": true, "pg_sleep": true, "waitfor": true,
+	"char": true, "chr": true, "nchar": true, "concat": true, "concat_ws": true,
+	"group_concat": true, "version": true, "database": true, "user": true,
+	"current_user": true, "system_user": true, "schema": true,
+	"substring": true, "substr": true, "mid": true, "ascii": true, "ord": true,
+	"hex": true, "unhex": true, "length": true, "char_length": true,
+	"count": true, "cast": true, "convert": true, "if": true, "ifnull": true,
+	"iif": true, "isnull": true, "coalesce": true, "md5": true, "sha1": true,
+	"load_file": true, "extractvalue": true, "updatexml": true,
+	"xp_cmdshell": true, "exists": true, "dbms_pipe.receive_message": true,
+	"utl_inaddr.get_host_name": true, "make_set": true, "elt": true,
+	"floor": true, "rand": true, "randomblob": true, "sqlite_version": true,
+}
+
+/*
+tokenizer splits an input as if it was a SQL statement, starting
+outside of a string (delim 0) or inside of a string opened by delim
+*/
+type tokenizer struct {
+	s     string
+	pos   int
+	delim byte
+}
+
+func newTokenizer(s string, delim byte) *tokenizer {
+	return &tokenizer{s: s, delim: delim}
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\v' || c == '\f' || c == 0xa0
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isWordChar(c byte) bool {
+	return c == '_' || c == '$' || c == '.' || isDigit(c) ||
+		(c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || c >= 0x80
+}
+
+/*
+next returns the next token, false at the end of the input
+*/
+func (t *tokenizer) next() (token, bool) {
+	if t.pos == 0 && t.delim != 0 {
+		return t.parseString(0, t.delim), true
+	}
+
+	for t.pos < len(t.s) {
+		c := t.s[t.pos]
+		switch {
+		case isSpace(c):
+			t.pos++
+		case c == '\'' || c == '"':
+			return t.parseString(t.pos+1, c), true
+		case c == '`':
+			tok := t.parseString(t.pos+1, c)
+			tok.kind = TYPE_BAREWORD
+			return tok, true
+		case isDigit(c) || (c == '.' && t.pos+1 < len(t.s) && isDigit(t.s[t.pos+1])):
+			return t.parseNumber(), true
+		case c == '#' || strings.HasPrefix(t.s[t.pos:], "--"):
+			return t.parseUntil("\n", TYPE_COMMENT), true
+		case strings.HasPrefix(t.s[t.pos:], "/*!"):
+			// MySQL executable comment: the content is code
+			t.pos += 3
+			for t.pos < len(t.s) && isDigit(t.s[t.pos]) {
+				t.pos++
+			}
+		case strings.HasPrefix(t.s[t.pos:], "/*"):
+			return t.parseUntil("*/", TYPE_COMMENT), true
+		case strings.HasPrefix(t.s[t.pos:], "*/"):
+			// end of a MySQL executable comment
+			t.pos += 2
+		case c == '(' || c == ')' || c == ',' || c == ';':
+			t.pos++
+			return token{kind: c, value: string(c)}, true
+		case c == '@':
+			start := t.pos
+			t.pos++
+			for t.pos < len(t.s) && (t.s[t.pos] == '@' || isWordChar(t.s[t.pos])) {
+				t.pos++
+			}
+			return token{kind: TYPE_VARIABLE, value: t.s[start:t.pos]}, true
+		case isWordChar(c):
+			return t.parseWord(), true
+		default:
+			return t.parseOperator(), true
+		}
+	}
+	return token{}, false
+}
+
+/*
+parseString parses a string (starting after its opening quote),
+an unterminated string ends with the input
+*/
+func (t *tokenizer) parseString(start int, delim byte) token {
+	i := start
+	for i < len(t.s) {
+		if t.s[i] == '\\' && delim != '`' {
+			i += 2
+			continue
+		}
+		if t.s[i] == delim {
+			// doubled quote
+			if i+1 < len(t.s) && t.s[i+1] == delim {
+				i += 2
+				continue
+			}
+			t.pos = i + 1
+			return token{kind: TYPE_STRING, value: t.s[start:i]}
+		}
+		i++
+	}
+	t.pos = len(t.s)
+	return token{kind: TYPE_STRING, value: t.s[start:]}
+}
+
+func (t *tokenizer) parseUntil(end string, kind byte) token {
+	start := t.pos
+	i := strings.Index(t.s[t.pos:], end)
+	if i < 0 {
+		t.pos = len(t.s)
+	} else {
+		t.pos += i + len(end)
+	}
+	return token{kind: kind, value: t.s[start:t.pos]}
+}
+
+func (t *tokenizer) parseNumber() token {
+	start := t.pos
+	if strings.HasPrefix(t.s[t.pos:], "0x") || strings.HasPrefix(t.s[t.pos:], "0X") {
+		t.pos += 2
+		for t.pos < len(t.s) && strings.IndexByte("0123456789abcdefABCDEF", t.s[t.pos]) >= 0 {
+			t.pos++
+		}
+		return token{kind: TYPE_NUMBER, value: t.s[start:t.pos]}
+	}
+	for t.pos < len(t.s) && (isDigit(t.s[t.pos]) || t.s[t.pos] == '.') {
+		t.pos++
+	}
+	// exponent
+	if t.pos < len(t.s) && (t.s[t.pos] == 'e' || t.s[t.pos] == 'E') {
+		i := t.pos + 1
+		if i < len(t.s) && (t.s[i] == '+' || t.s[i] == '-') {
+			i++
+		}
+		if i < len(t.s) && isDigit(t.s[i]) {
+			t.pos = i
+			for t.pos < len(t.s) && isDigit(t.s[t.pos]) {
+				t.pos++
+			}
+		}
+	}
+	// 1abc is a bareword
+	if t.pos < len(t.s) && isWordChar(t.s[t.pos]) {
+		for t.pos < len(t.s) && isWordChar(t.s[t.pos]) {
+			t.pos++
+		}
+		return token{kind: TYPE_BAREWORD, value: t.s[start:t.pos]}
+	}
+	return token{kind: TYPE_NUMBER, value: t.s[start:t.pos]}
+}
+
+func (t *tokenizer) parseWord() token {
+	start := t.pos
+	for t.pos < len(t.s) && isWordChar(t.s[t.pos]) {
+		t.pos++
+	}
+	word := t.s[start:t.pos]
+	lower := strings.ToLower(word)
+
+	if functions[lower] && t.nextNonSpace() == '(' {
+		return token{kind: TYPE_FUNCTION, value: word}
+	}
+	if kind, ok := keywords[lower]; ok {
+		return token{kind: kind, value: word}
+	}
+	return token{kind: TYPE_BAREWORD, value: word}
+}
+
+func (t *tokenizer) nextNonSpace() byte {
+	for i := t.pos; i < len(t.s); i++ {
+		if !isSpace(t.s[i]) {
+			return t.s[i]
+		}
+	}
+	return 0
+}
+
+var operators = []string{"<=>", "!=", "<>", "<=", ">=", "::", ":=", "<<", ">>", "&&", "||"}
+
+func (t *tokenizer) parseOperator() token {
+	for _, op := range operators {
+		if strings.HasPrefix(t.s[t.pos:], op) {
+			t.pos += len(op)
+			if op == "&&" || op == "||" {
+				return token{kind: TYPE_LOGIC, value: op}
+			}
+			return token{kind: TYPE_OPERATOR, value: op}
+		}
+	}
+	c := t.s[t.pos]
+	t.pos++
+	if strings.IndexByte("=<>!+-*/%^|&~:", c) >= 0 {
+		return token{kind: TYPE_OPERATOR, value: string(c)}
+	}
+	return token{kind: TYPE_UNKNOWN, value: string(c)}
+}
+
+/*
+Fingerprint tokenizes an input (in a given context) and folds
+the tokens that don't change the meaning of the statement:
+- the comments (except a trailing one)
+- the unary operators
+- the string concatenations ('a' 'b')
+- "union all", "union distinct", "order by", "group by"
+*/
+func Fingerprint(s string, delim byte) string {
+	t := newTokenizer(s, delim)
+	tokens := []token{}
+	trailingComment := false
+
+	for len(tokens) <= FINGERPRINT_SIZE {
+		tok, ok := t.next()
+		if !ok {
+			break
+		}
+		if tok.kind == TYPE_COMMENT {
+			trailingComment = true
+			continue
+		}
+		trailingComment = false
+
+		var last *token
+		if len(tokens) > 0 {
+			last = &tokens[len(tokens)-1]
+		}
+
+		// unary operators
+		if tok.kind == TYPE_OPERATOR && (tok.value == "+" || tok.value == "-" || tok.value == "!" || tok.value == "~" || strings.EqualFold(tok.value, "not")) &&
+			(last == nil || strings.IndexByte("o&(,;EkBU", last.kind) >= 0) {
+			continue
+		}
+		if last != nil {
+			if tok.kind == TYPE_STRING && last.kind == TYPE_STRING {
+				continue
+			}
+			if last.kind == TYPE_UNION && tok.kind == TYPE_KEYWORD &&
+				(strings.EqualFold(tok.value, "all") || strings.EqualFold(tok.value, "distinct")) {
+				continue
+			}
+			if last.kind == TYPE_KEYWORD && tok.kind == TYPE_KEYWORD && strings.EqualFold(tok.value, "by") &&
+				(strings.EqualFold(last.value, "order") || strings.EqualFold(last.value, "group")) {
+				last.kind = TYPE_GROUP
+				continue
+			}
+		}
+		tokens = append(tokens, tok)
+	}
+
+	fp := []byte{}
+	for i := 0; i < len(tokens) && i < FINGERPRINT_SIZE; i++ {
+		fp = append(fp, tokens[i].kind)
+	}
+	if trailingComment && len(fp) < FINGERPRINT_SIZE {
+		fp = append(fp, TYPE_COMMENT)
+	}
+	return string(fp)
+}
@@ -0,0 +1,135 @@
+package ssrf
+
+import (
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// a URL (or a scheme relative //host), with its scheme and its authority.
+// The backslashes are accepted as slashes, like the browsers do
+var urlRegexp = regexp.MustCompile(`^\s*(?:([a-z][a-z0-9+.\-]*):)?[/\\]{2}([^/\\?#]*)`)
+
+/*
+hostOf returns the host of a URL authority, without the user info,
+the port, the IPv6 brackets and the trailing dot
+*/
+func hostOf(authority string) string {
+	if i := strings.LastIndex(authority, "@"); i >= 0 {
+		authority = authority[i+1:]
+	}
+	if strings.HasPrefix(authority, "[") {
+		if i := strings.Index(authority, "]"); i > 0 {
+			return authority[1:i]
+		}
+		return ""
+	}
+	if i := strings.LastIndex(authority, ":"); i >= 0 {
+		authority = authority[:i]
+	}
+	return strings.TrimRight(authority, ".")
+}
+
+/*
+parseIP parses a host that is an IP address, including the forms that
+the resolvers accept but that don't look like an IP: decimal (2130706433),
+hexadecimal (0x7f.0x0.0x0.0x1), octal (0177.0.0.01) and short (127.1)
+IPv4 addresses. It returns nil if the host is a name
+*/
+func parseIP(host string) net.IP {
+	if strings.Contains(host, ":") {
+		// an IPv6 address, maybe with a zone
+		if i := strings.Index(host, "%"); i >= 0 {
+			host = host[:i]
+		}
+		return net.ParseIP(host)
+	}
+
+	parts := strings.Split(host, ".")
+	if len(parts) > 4 {
+		return nil
+	}
+	numbers := []uint64{}
+	for _, p := range parts {
+		n, ok := parseNumber(p)
+		if !ok {
+			return nil
+		}
+		numbers = append(numbers, n)
+	}
+	// the last part fills the remaining bytes (127.1 is 127.0.0.1)
+	last := numbers[len(numbers)-1]
+	if last >= 1<<(8*(5-len(numbers))) {
+		return nil
+	}
+	ip := make([]byte, 4)
+	for i, n := range numbers[:len(numbers)-1] {
+		if n > 255 {
+			return nil
+		}
+		ip[i] = byte(n)
+	}
+	for i := 3; i >= len(numbers)-1; i-- {
+		ip[i] = byte(last)
+		last >>= 8
+	}
+	return net.IPv4(ip[0], ip[1], ip[2], ip[3])
+}
+
+/*
+parseNumber parses a part of an IPv4 address, in decimal, in
+hexadecimal (0x) or in octal (leading 0)
+*/
+func parseNumber(s string) (uint64, bool) {
+	base := 10
+	if strings.HasPrefix(s, "0x") {
+		s = s[2:]
+		base = 16
+		if s == "" {
+			// 0x alone is 0
+			return 0, true
+		}
+	} else if len(s) > 1 && s[0] == '0' {
+		s = s[1:]
+		base = 8
+	}
+	if s == "" || strings.ContainsAny(s, "+-") {
+		return 0, false
+	}
+	n, err := strconv.ParseUint(s, base, 32)
+	return n, err == nil
+}
+
+/*
+embeddedIPv4 returns the IPv4 address carried by an IPv6 address
+(IPv4-mapped, IPv4-compatible, NAT64 and 6to4), or nil
+*/
+func embeddedIPv4(ip net.IP) net.IP {
+	if v4 := ip.To4(); v4 != nil {
+		return v4
+	}
+	if len(ip) != net.IPv6len {
+		return nil
+	}
+	isZero := func(b []byte) bool {
+		for _, c := range b {
+			if c != 0 {
+				return false
+			}
+		}
+		return true
+	}
+	switch {
+	case isZero(ip[:12]):
+		// ::a.b.c.d
+		return net.IPv4(ip[12], ip[13], ip[14], ip[15])
+	case ip[0] == 0x00 && ip[1] == 0x64 && ip[2] == 0xff && ip[3] == 0x9b && isZero(ip[4:12]):
+		// 64:ff9b::a.b.c.d
+		return net.IPv4(ip[12], ip[13], ip[14], ip[15])
+	case ip[0] == 0x20 && ip[1] == 0x02:
+		// 2002:AABB:CCDD::
+		return net.IPv4(ip[2], ip[3], ip[4], ip[5])
+	}
+	return nil
+}
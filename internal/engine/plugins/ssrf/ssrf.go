@@ -0,0 +1,314 @@
+package ssrf
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nzin/taxsi2/internal/com"
+	"github.com/nzin/taxsi2/internal/db"
+	"github.com/nzin/taxsi2/internal/engine"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// GlobalConfig keys
+	// comma separated list of the networks the URLs can't target
+	CONFIG_BLOCKED_NETWORKS = "ssrf_blocked_networks"
+	// resolve the host names of the URLs (enabled, disabled)
+	CONFIG_RESOLVE = "ssrf_resolve"
+	// what to do when a host name can't be checked, i.e. the lookup
+	// fails or times out, or there are too many host names to resolve
+	// (block, or allow with the VARIABLE_UNRESOLVED variable set)
+	CONFIG_UNRESOLVED_ACTION = "ssrf_unresolved_action"
+
+	// loopback, private, shared, link-local (with the cloud metadata
+	// 169.254.169.254), benchmark, multicast and reserved ranges
+	DEFAULT_BLOCKED_NETWORKS = "0.0.0.0/8,10.0.0.0/8,100.64.0.0/10,127.0.0.0/8,169.254.0.0/16," +
+		"172.16.0.0/12,192.0.0.0/24,192.168.0.0/16,198.18.0.0/15,224.0.0.0/4,240.0.0.0/4," +
+		"::/128,::1/128,fc00::/7,fe80::/10,ff00::/8"
+	DEFAULT_RESOLVE           = "enabled"
+	DEFAULT_UNRESOLVED_ACTION = "block"
+
+	// output variables
+	VARIABLE_REASON = "ssrf_reason"
+	// where the URL was found, like "query:webhook"
+	VARIABLE_LOCATION = "ssrf_location"
+	VARIABLE_URL      = "ssrf_url"
+	// why a host name was not checked (when the request is allowed)
+	VARIABLE_UNRESOLVED = "ssrf_unresolved"
+
+	// the maximum size of a reported URL
+	URL_SIZE = 128
+
+	RESOLVE_TIMEOUT = 500 * time.Millisecond
+	// the maximum number of host names resolved for a request
+	MAX_RESOLUTIONS    = 5
+	RESOLVE_CACHE_TTL  = 1 * time.Minute
+	RESOLVE_CACHE_SIZE = 10000
+)
+
+// the schemes only used to talk to internal services
+var blockedSchemes = map[string]bool{
+	"gopher": true, "dict": true, "ldap": true, "ldaps": true, "tftp": true, "netdoc": true, "jar": true,
+}
+
+// the host names of the cloud metadata services, and of the local host
+var blockedHosts = map[string]bool{
+	"localhost": true, "metadata": true, "metadata.google.internal": true,
+	"instance-data": true, "instance-data.ec2.internal": true,
+}
+
+/*
+Resolver resolves the host names of the URLs
+*/
+type Resolver interface {
+	LookupIP(ctx context.Context, host string) ([]net.IP, error)
+}
+
+type dnsResolver struct{}
+
+func (dnsResolver) LookupIP(ctx context.Context, host string) ([]net.IP, error) {
+	return net.DefaultResolver.LookupIP(ctx, "ip", host)
+}
+
+type cacheEntry struct {
+	ips     []net.IP
+	expires time.Time
+}
+
+/*
+SsrfWafPlugin blocks the requests carrying a URL (in the query or in
+the body) that targets an internal network, like a webhook pointing
+to the cloud metadata service
+*/
+type SsrfWafPlugin struct {
+	ds       db.DbServiceSsrf
+	resolver Resolver
+	mu       sync.RWMutex
+	networks []*net.IPNet
+	resolve  bool
+	// block the requests whose host names can't be checked
+	blockUnresolved bool
+
+	cacheMu sync.Mutex
+	cache   map[string]cacheEntry
+	now     func() time.Time
+}
+
+func NewSsrfWafPlugin(ds db.DbServiceSsrf) engine.WafEnginePlugin {
+	return newSsrfWafPlugin(ds, dnsResolver{})
+}
+
+func newSsrfWafPlugin(ds db.DbServiceSsrf, resolver Resolver) *SsrfWafPlugin {
+	s := &SsrfWafPlugin{
+		ds:       ds,
+		resolver: resolver,
+		cache:    make(map[string]cacheEntry),
+		now:      time.Now,
+	}
+	s.loadConfig()
+
+	ds.SubscribeChanges(db.CHANGELOG_TABLE_CONFIG, &configListener{s: s})
+	return s
+}
+
+func (s *SsrfWafPlugin) Name() string {
+	return "ssrf"
+}
+
+// Interruptible is true: the DNS lookups stop when the context is done
+func (s *SsrfWafPlugin) Interruptible() bool {
+	return true
+}
+
+/*
+ValidateConfig returns why a value can't be used for a ssrf key
+(nil if it can, or if the key is not a ssrf key)
+*/
+func ValidateConfig(key string, value string) error {
+	if key == CONFIG_BLOCKED_NETWORKS {
+		if _, err := engine.ParseNetworks(value); err != nil {
+			return fmt.Errorf("%s: %v", key, err)
+		}
+	}
+	if key == CONFIG_RESOLVE && value != "enabled" && value != "disabled" {
+		return fmt.Errorf("bad %s %s (must be enabled or disabled)", key, value)
+	}
+	if key == CONFIG_UNRESOLVED_ACTION && value != "allow" && value != "block" {
+		return fmt.Errorf("bad %s %s (must be allow or block)", key, value)
+	}
+	return nil
+}
+
+func (s *SsrfWafPlugin) loadConfig() {
+	list := DEFAULT_BLOCKED_NETWORKS
+	if v, err := s.ds.GetConfigValueForKey(CONFIG_BLOCKED_NETWORKS); err == nil {
+		list = v
+	}
+	networks, err := engine.ParseNetworks(list)
+	if err != nil {
+		logrus.Errorf("%s: %v", CONFIG_BLOCKED_NETWORKS, err)
+	}
+	resolve := DEFAULT_RESOLVE
+	if v, err := s.ds.GetConfigValueForKey(CONFIG_RESOLVE); err == nil {
+		resolve = v
+	}
+	unresolvedAction := DEFAULT_UNRESOLVED_ACTION
+	if v, err := s.ds.GetConfigValueForKey(CONFIG_UNRESOLVED_ACTION); err == nil {
+		if err := ValidateConfig(CONFIG_UNRESOLVED_ACTION, v); err != nil {
+			logrus.Errorf("%v", err)
+		} else {
+			unresolvedAction = v
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.networks = networks
+	s.resolve = resolve != "disabled"
+	s.blockUnresolved = unresolvedAction == "block"
+}
+
+// configListener reloads the config when the GlobalConfig changes
+type configListener struct {
+	s *SsrfWafPlugin
+}
+
+func (l *configListener) NotifyDbChange(key string) {
+	if key == CONFIG_BLOCKED_NETWORKS || key == CONFIG_RESOLVE || key == CONFIG_UNRESOLVED_ACTION {
+		l.s.loadConfig()
+	}
+}
+
+func (s *SsrfWafPlugin) Scan(ctx context.Context, payload *com.TaxsiCom) bool {
+	s.mu.RLock()
+	networks := s.networks
+	resolve := s.resolve
+	blockUnresolved := s.blockUnresolved
+	s.mu.RUnlock()
+
+	// the host names resolved for this request
+	resolved := make(map[string]bool)
+	// the first host name that could not be checked
+	unresolved := ""
+	for _, f := range payload.Parse().Fields {
+		switch f.Source {
+		case com.SOURCE_QUERY, com.SOURCE_FORM, com.SOURCE_JSON, com.SOURCE_XML:
+		default:
+			continue
+		}
+		for _, v := range f.Decoded {
+			m := urlRegexp.FindStringSubmatch(v)
+			if m == nil {
+				continue
+			}
+			reason := ""
+			// the host name could not be checked
+			unchecked := false
+			host := hostOf(m[2])
+			if blockedSchemes[m[1]] {
+				reason = fmt.Sprintf("blocked scheme %s", m[1])
+			} else if host == "" {
+				continue
+			} else if ip := parseIP(host); ip != nil {
+				if isBlocked(ip, networks) {
+					reason = fmt.Sprintf("%s is in a blocked network", ip)
+				}
+			} else if blockedHosts[host] || strings.HasSuffix(host, ".localhost") {
+				reason = fmt.Sprintf("blocked host %s", host)
+			} else if resolve && !resolved[host] && len(resolved) >= MAX_RESOLUTIONS {
+				reason = fmt.Sprintf("%s not resolved (more than %d host names)", host, MAX_RESOLUTIONS)
+				unchecked = true
+			} else if resolve {
+				resolved[host] = true
+				ips, err := s.lookup(ctx, host)
+				if err != nil {
+					reason = fmt.Sprintf("%s not resolved (%v)", host, err)
+					unchecked = true
+				}
+				for _, ip := range ips {
+					if isBlocked(ip, networks) {
+						reason = fmt.Sprintf("%s resolves to %s (blocked network)", host, ip)
+						break
+					}
+				}
+			}
+			if unchecked && !blockUnresolved {
+				if unresolved == "" {
+					unresolved = reason
+				}
+				continue
+			}
+			if reason == "" {
+				continue
+			}
+
+			location := f.Source
+			if f.Name != "" {
+				location += ":" + f.Name
+			}
+			if len(v) > URL_SIZE {
+				v = v[:URL_SIZE]
+			}
+			payload.SetVariable(VARIABLE_REASON, reason)
+			payload.SetVariable(VARIABLE_LOCATION, location)
+			payload.SetVariable(VARIABLE_URL, strings.TrimSpace(v))
+			return false
+		}
+	}
+	if unresolved != "" {
+		payload.SetVariable(VARIABLE_UNRESOLVED, unresolved)
+	}
+	return true
+}
+
+/*
+isBlocked tells if an IP (or the IPv4 address it embeds) is in a
+blocked network
+*/
+func isBlocked(ip net.IP, networks []*net.IPNet) bool {
+	if engine.IsIpInNetworks(ip, networks) {
+		return true
+	}
+	if v4 := embeddedIPv4(ip); v4 != nil {
+		return engine.IsIpInNetworks(v4, networks)
+	}
+	return false
+}
+
+/*
+lookup resolves a host name (with a cache). A host that doesn't exist
+can't be reached either, so it has no IP. The other errors (like a
+timeout) are returned, and not cached
+*/
+func (s *SsrfWafPlugin) lookup(ctx context.Context, host string) ([]net.IP, error) {
+	now := s.now()
+	s.cacheMu.Lock()
+	entry, ok := s.cache[host]
+	s.cacheMu.Unlock()
+	if ok && now.Before(entry.expires) {
+		return entry.ips, nil
+	}
+
+	lookupCtx, cancel := context.WithTimeout(ctx, RESOLVE_TIMEOUT)
+	defer cancel()
+	ips, err := s.resolver.LookupIP(lookupCtx, host)
+	var dnsErr *net.DNSError
+	if err != nil && !(errors.As(err, &dnsErr) && dnsErr.IsNotFound) {
+		logrus.Debugf("ssrf: unable to resolve %s: %v", host, err)
+		return nil, err
+	}
+
+	s.cacheMu.Lock()
+	defer s.cacheMu.Unlock()
+	if len(s.cache) >= RESOLVE_CACHE_SIZE {
+		s.cache = make(map[string]cacheEntry)
+	}
+	s.cache[host] = cacheEntry{ips: ips, expires: now.Add(RESOLVE_CACHE_TTL)}
+	return ips, nil
+}
@@ -0,0 +1,102 @@
+package upload
+
+import (
+	"bytes"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+const (
+	TYPE_EXECUTABLE = "application/x-executable"
+	TYPE_SVG        = "image/svg+xml"
+	// what http.DetectContentType returns when it doesn't know
+	TYPE_UNKNOWN = "application/octet-stream"
+	// how many bytes are sniffed
+	SNIFF_SIZE = 512
+)
+
+// the content type of the usual extensions
+var extensionTypes = map[string]string{
+	"jpg": "image/jpeg", "jpeg": "image/jpeg", "png": "image/png",
+	"gif": "image/gif", "webp": "image/webp", "bmp": "image/bmp",
+	"ico": "image/x-icon", "svg": TYPE_SVG, "pdf": "application/pdf",
+	"zip": "application/zip", "mp4": "video/mp4", "mp3": "audio/mpeg",
+	"html": "text/html", "htm": "text/html",
+}
+
+// the types that always start with the same magic bytes
+var magicTypes = map[string]bool{
+	"image/jpeg": true, "image/png": true, "image/gif": true, "image/webp": true,
+	"image/bmp": true, "application/pdf": true, "application/zip": true,
+}
+
+// the types that don't say much about a content
+var genericTypes = map[string]bool{
+	TYPE_UNKNOWN: true, "text/plain": true,
+}
+
+// the extensions run by the web servers (or by the OS)
+var executableExtensions = map[string]bool{}
+
+func init() {
+	for _, e := range []string{
+		"php", "php3", "php4", "php5", "php7", "phtml", "phar", "pht", "phps",
+		"asp", "aspx", "ascx", "ashx", "asmx", "cer", "jsp", "jspx", "jsw", "jsv",
+		"cgi", "pl", "py", "rb", "sh", "bash", "shtml", "htaccess",
+		"exe", "dll", "bat", "cmd", "com", "msi", "vbs", "ps1", "jar", "war",
+	} {
+		executableExtensions[e] = true
+	}
+}
+
+// the markers of a script hidden in a file (for example in the EXIF comment of an image)
+var scriptMarkers = [][]byte{
+	[]byte("<?php"), []byte("<?="), []byte("<script"), []byte("<%@"), []byte("<%="),
+}
+
+// an archive hidden in a file (like a JPEG/JAR polyglot)
+var zipMarker = []byte("PK\x03\x04")
+
+/*
+Sniff returns the content type of a file, from its first bytes
+*/
+func Sniff(content []byte) string {
+	if bytes.HasPrefix(content, []byte("\x7fELF")) || bytes.HasPrefix(content, []byte("MZ")) ||
+		bytes.HasPrefix(content, []byte("#!")) {
+		return TYPE_EXECUTABLE
+	}
+
+	head := content
+	if len(head) > SNIFF_SIZE {
+		head = head[:SNIFF_SIZE]
+	}
+	head = bytes.ToLower(bytes.TrimLeft(bytes.TrimPrefix(head, []byte("\xef\xbb\xbf")), " \t\r\n"))
+	// (after an optional xml declaration, doctype or comment)
+	if bytes.HasPrefix(head, []byte("<")) && bytes.Contains(head, []byte("<svg")) && !bytes.Contains(head, []byte("<html")) {
+		return TYPE_SVG
+	}
+
+	return mediaType(http.DetectContentType(content))
+}
+
+/*
+mediaType returns a content type without its parameters
+*/
+func mediaType(contentType string) string {
+	t, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return strings.ToLower(strings.TrimSpace(contentType))
+	}
+	return t
+}
+
+func hasScript(content []byte) bool {
+	lower := bytes.ToLower(content)
+	for _, m := range scriptMarkers {
+		if bytes.Contains(lower, m) {
+			return true
+		}
+	}
+	return false
+}
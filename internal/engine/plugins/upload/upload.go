@@ -0,0 +1,229 @@
+package upload
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/nzin/taxsi2/internal/com"
+	"github.com/nzin/taxsi2/internal/db"
+	"github.com/nzin/taxsi2/internal/engine"
+	"github.com/nzin/taxsi2/internal/engine/plugins/xss"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// output variable
+	VARIABLE_REASON = "upload_reason"
+)
+
+/*
+policy is a db.UploadPolicy with its lists parsed
+*/
+type policy struct {
+	db.UploadPolicy
+	allowedExtensions map[string]bool
+	deniedExtensions  map[string]bool
+	allowedTypes      map[string]bool
+}
+
+func parseList(list string) map[string]bool {
+	values := make(map[string]bool)
+	for _, v := range strings.Split(list, ",") {
+		v = strings.TrimPrefix(strings.ToLower(strings.TrimSpace(v)), ".")
+		if v != "" {
+			values[v] = true
+		}
+	}
+	return values
+}
+
+/*
+UploadWafPlugin inspects the files uploaded with multipart/form-data
+requests, according to the upload policy of the path
+*/
+type UploadWafPlugin struct {
+	ds db.DbServiceUpload
+	mu sync.RWMutex
+	// sorted by decreasing path prefix length
+	policies []policy
+}
+
+func NewUploadWafPlugin(ds db.DbServiceUpload) (engine.WafEnginePlugin, error) {
+	return newUploadWafPlugin(ds)
+}
+
+func newUploadWafPlugin(ds db.DbServiceUpload) (*UploadWafPlugin, error) {
+	u := &UploadWafPlugin{
+		ds: ds,
+	}
+	if err := u.loadPolicies(); err != nil {
+		return nil, err
+	}
+	ds.SubscribeChanges(db.CHANGELOG_TABLE_UPLOAD, u)
+	return u, nil
+}
+
+func (u *UploadWafPlugin) Name() string {
+	return "upload"
+}
+
+func (u *UploadWafPlugin) loadPolicies() error {
+	policies, err := u.ds.GetUploadPolicies()
+	if err != nil {
+		return err
+	}
+
+	parsed := []policy{}
+	for _, p := range policies {
+		parsed = append(parsed, policy{
+			UploadPolicy:      p,
+			allowedExtensions: parseList(p.AllowedExtensions),
+			deniedExtensions:  parseList(p.DeniedExtensions),
+			allowedTypes:      parseList(p.AllowedTypes),
+		})
+	}
+	sort.SliceStable(parsed, func(i, j int) bool {
+		return len(parsed[i].PathPrefix) > len(parsed[j].PathPrefix)
+	})
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.policies = parsed
+	return nil
+}
+
+// NotifyDbChange is called when the policies change
+func (u *UploadWafPlugin) NotifyDbChange(key string) {
+	if err := u.loadPolicies(); err != nil {
+		logrus.Errorf("Error reading upload policies: %v", err)
+	}
+}
+
+/*
+matchPolicy returns the policy with the longest matching path prefix
+*/
+func (u *UploadWafPlugin) matchPolicy(path string) *policy {
+	u.mu.RLock()
+	defer u.mu.RUnlock()
+	for i := range u.policies {
+		if strings.HasPrefix(path, u.policies[i].PathPrefix) {
+			return &u.policies[i]
+		}
+	}
+	return nil
+}
+
+func (u *UploadWafPlugin) Scan(ctx context.Context, payload *com.TaxsiCom) bool {
+	parsed := payload.Parse()
+	if len(parsed.Files) == 0 {
+		return true
+	}
+
+	p := u.matchPolicy(parsed.Path)
+	for _, f := range parsed.Files {
+		if reason := inspect(f, p); reason != "" {
+			payload.SetVariable(VARIABLE_REASON, reason)
+			return false
+		}
+	}
+	return true
+}
+
+/*
+extensions returns the (lowercase) extensions of a file name,
+"shell.php.jpg" returns [php jpg]
+*/
+func extensions(filename string) []string {
+	// some clients send the full path
+	name := path.Base(strings.ReplaceAll(filename, "\\", "/"))
+	// windows ignores the trailing dots and spaces ("shell.php.")
+	name = strings.TrimRight(strings.ToLower(name), ". ")
+	parts := strings.Split(name, ".")
+	return parts[1:]
+}
+
+/*
+inspect returns why a file is rejected ("" if it is not).
+The policy can be nil (no policy for the path)
+*/
+func inspect(f com.File, p *policy) string {
+	if p != nil && p.Deny {
+		return "uploads are denied"
+	}
+	if p != nil && p.MaxFileSize > 0 && len(f.Content) > p.MaxFileSize {
+		return fmt.Sprintf("%s is bigger than %d bytes", f.Filename, p.MaxFileSize)
+	}
+	if strings.IndexFunc(f.Filename, func(r rune) bool { return r < ' ' }) >= 0 {
+		return "invalid file name"
+	}
+
+	// extensions
+	exts := extensions(f.Filename)
+	ext := ""
+	if len(exts) > 0 {
+		ext = exts[len(exts)-1]
+		exts = exts[:len(exts)-1]
+	}
+	if p != nil && p.deniedExtensions[ext] {
+		return fmt.Sprintf("denied extension %s", ext)
+	}
+	if p != nil && len(p.allowedExtensions) > 0 && !p.allowedExtensions[ext] {
+		return fmt.Sprintf("extension %s not allowed", ext)
+	}
+	for _, e := range exts {
+		if executableExtensions[e] {
+			return fmt.Sprintf("double extension %s", f.Filename)
+		}
+	}
+
+	// content types
+	declared := mediaType(f.ContentType)
+	sniffed := Sniff(f.Content)
+	if p != nil && len(p.allowedTypes) > 0 && (!p.allowedTypes[declared] || !p.allowedTypes[sniffed]) {
+		return fmt.Sprintf("content type %s (sniffed %s) not allowed", declared, sniffed)
+	}
+	if expected, ok := extensionTypes[ext]; ok && mismatch(expected, sniffed) {
+		return fmt.Sprintf("%s content is %s", f.Filename, sniffed)
+	}
+	if _, ok := extensionTypes[ext]; !ok && magicTypes[declared] && mismatch(declared, sniffed) {
+		return fmt.Sprintf("declared %s, content is %s", declared, sniffed)
+	}
+	if sniffed == TYPE_EXECUTABLE && !executableExtensions[ext] {
+		return fmt.Sprintf("%s is an executable", f.Filename)
+	}
+
+	// embedded scripts and polyglots
+	if sniffed == TYPE_SVG || ext == "svg" {
+		if s, ok := xss.Detect(string(f.Content)); ok {
+			return fmt.Sprintf("script in svg: %s", s)
+		}
+	}
+	if magicTypes[sniffed] && len(f.Content) > 0 {
+		if hasScript(f.Content) {
+			return fmt.Sprintf("script embedded in %s", f.Filename)
+		}
+		if sniffed != "application/zip" && bytes.Contains(f.Content[1:], zipMarker) {
+			return fmt.Sprintf("archive embedded in %s", f.Filename)
+		}
+	}
+	return ""
+}
+
+/*
+mismatch tells if the sniffed type contradicts the expected one
+*/
+func mismatch(expected string, sniffed string) bool {
+	if expected == sniffed {
+		return false
+	}
+	// the magic bytes must be there
+	if magicTypes[expected] {
+		return true
+	}
+	return !genericTypes[sniffed]
+}
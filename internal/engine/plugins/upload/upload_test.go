@@ -0,0 +1,160 @@
+package upload
+
+import (
+	"bytes"
+	"context"
+	"mime/multipart"
+	"net/textproto"
+	"net/url"
+	"testing"
+
+	"github.com/nzin/taxsi2/internal/com"
+	"github.com/nzin/taxsi2/internal/db"
+	"github.com/stretchr/testify/assert"
+)
+
+/*
+ * This is a mock implementation of the db.DbServiceUpload interface
+ */
+type DbServiceUploadMock struct {
+	policies  []db.UploadPolicy
+	listeners []db.DbChangeListener
+}
+
+func (m *DbServiceUploadMock) SubscribeChanges(table int, listener db.DbChangeListener) {
+	m.listeners = append(m.listeners, listener)
+}
+func (m *DbServiceUploadMock) GetUploadPolicies() ([]db.UploadPolicy, error) {
+	return m.policies, nil
+}
+func (m *DbServiceUploadMock) SetUploadPolicy(policy *db.UploadPolicy) error {
+	return nil
+}
+func (m *DbServiceUploadMock) DeleteUploadPolicy(id uint) error {
+	return nil
+}
+
+var (
+	PNG  = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR\x00\x00\x00\x01\x00\x00\x00\x01")
+	JPEG = []byte("\xff\xd8\xff\xe0\x00\x10JFIF\x00\x01\x01\x00\x00\x01\x00\x01\x00\x00")
+	GIF  = []byte("GIF89a\x01\x00\x01\x00\x80\x00\x00")
+	ELF  = []byte("\x7fELF\x02\x01\x01\x00\x00\x00\x00\x00")
+	SVG  = []byte(`<?xml version="1.0"?><svg xmlns="http://www.w3.org/2000/svg"><circle r="4"/></svg>`)
+)
+
+type file struct {
+	filename    string
+	contentType string
+	content     []byte
+}
+
+func uploadRequest(t *testing.T, path string, files ...file) *com.TaxsiCom {
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	assert.Nil(t, w.WriteField("title", "hello"))
+	for _, f := range files {
+		h := make(textproto.MIMEHeader)
+		h.Set("Content-Disposition", `form-data; name="file"; filename="`+f.filename+`"`)
+		h.Set("Content-Type", f.contentType)
+		part, err := w.CreatePart(h)
+		assert.Nil(t, err)
+		_, err = part.Write(f.content)
+		assert.Nil(t, err)
+	}
+	assert.Nil(t, w.Close())
+
+	u, _ := url.Parse("http://www.example.com" + path)
+	return &com.TaxsiCom{
+		Method:  "POST",
+		Url:     u,
+		Headers: map[string][]string{"Content-Type": {w.FormDataContentType()}},
+		Body:    body.Bytes(),
+	}
+}
+
+func TestSniff(t *testing.T) {
+	assert.Equal(t, "image/png", Sniff(PNG))
+	assert.Equal(t, "image/jpeg", Sniff(JPEG))
+	assert.Equal(t, TYPE_EXECUTABLE, Sniff(ELF))
+	assert.Equal(t, TYPE_EXECUTABLE, Sniff([]byte("#!/bin/sh\nid\n")))
+	assert.Equal(t, TYPE_SVG, Sniff(SVG))
+	assert.Equal(t, TYPE_SVG, Sniff([]byte("\n<!-- logo -->\n<svg></svg>")))
+	assert.Equal(t, "text/html", Sniff([]byte("<html><body><svg></svg></body></html>")))
+	assert.Equal(t, "text/plain", Sniff([]byte("hello world")))
+}
+
+func TestExtensions(t *testing.T) {
+	assert.Equal(t, []string{"php", "jpg"}, extensions("shell.PHP.jpg"))
+	assert.Equal(t, []string{"php"}, extensions("shell.php. . "))
+	assert.Equal(t, []string{"png"}, extensions(`C:\Users\bob\cat.png`))
+	assert.Equal(t, []string{}, extensions("README"))
+}
+
+func TestUploadPlugin(t *testing.T) {
+	ds := &DbServiceUploadMock{
+		policies: []db.UploadPolicy{
+			{PathPrefix: "/", Deny: true},
+			{PathPrefix: "/cms/media", AllowedExtensions: "jpg,jpeg,png,gif,svg", AllowedTypes: "image/jpeg,image/png,image/gif,image/svg+xml", MaxFileSize: 1024},
+			{PathPrefix: "/cms/documents", DeniedExtensions: "exe,.html", MaxFileSize: 0},
+		},
+	}
+	plugin, err := newUploadWafPlugin(ds)
+	assert.Nil(t, err)
+
+	t.Run("happy path: requests without files", func(t *testing.T) {
+		assert.True(t, plugin.Scan(context.Background(), uploadRequest(t, "/api/users")))
+	})
+
+	t.Run("happy path: legit uploads", func(t *testing.T) {
+		assert.True(t, plugin.Scan(context.Background(), uploadRequest(t, "/cms/media/upload",
+			file{"cat.png", "image/png", PNG},
+			file{"dog.JPG", "image/jpeg", JPEG},
+			file{"logo.svg", "image/svg+xml", SVG},
+		)))
+		assert.True(t, plugin.Scan(context.Background(), uploadRequest(t, "/cms/documents/upload",
+			file{"report.v2.txt", "text/plain", []byte("hello")},
+			file{"archive.tar.gz", "application/gzip", []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00")},
+		)))
+	})
+
+	check := func(t *testing.T, path string, f file, reason string) {
+		payload := uploadRequest(t, path, f)
+		assert.False(t, plugin.Scan(context.Background(), payload), f.filename)
+		assert.Contains(t, payload.Variables[VARIABLE_REASON], reason)
+	}
+
+	t.Run("not happy path: policies", func(t *testing.T) {
+		check(t, "/api/users", file{"cat.png", "image/png", PNG}, "uploads are denied")
+		check(t, "/cms/media", file{"big.png", "image/png", append(PNG, make([]byte, 1024)...)}, "bigger than 1024 bytes")
+		check(t, "/cms/media", file{"doc.pdf", "application/pdf", []byte("%PDF-1.4")}, "extension pdf not allowed")
+		check(t, "/cms/media", file{"cat.png", "application/octet-stream", PNG}, "not allowed")
+		check(t, "/cms/documents", file{"page.HTML", "text/html", []byte("<p>")}, "denied extension html")
+	})
+
+	t.Run("not happy path: file names", func(t *testing.T) {
+		check(t, "/cms/documents", file{"shell.php.txt", "text/plain", []byte("hello")}, "double extension")
+		assert.Equal(t, "invalid file name", inspect(com.File{Filename: "shell.php\x00.jpg", Content: JPEG}, nil))
+	})
+
+	t.Run("not happy path: content mismatches", func(t *testing.T) {
+		check(t, "/cms/media", file{"cat.png", "image/png", JPEG}, "content is image/jpeg")
+		check(t, "/cms/documents", file{"cat.png", "image/png", []byte("<?php system($_GET['c']); ?>")}, "content is text/plain")
+		check(t, "/cms/documents", file{"picture", "image/png", []byte("<html><script>")}, "declared image/png, content is text/html")
+		check(t, "/cms/documents", file{"notes.txt", "text/plain", ELF}, "is an executable")
+	})
+
+	t.Run("not happy path: embedded scripts and polyglots", func(t *testing.T) {
+		check(t, "/cms/media", file{"cat.gif", "image/gif", append(GIF, []byte("<?php system($_GET['c']); ?>")...)}, "script embedded in cat.gif")
+		check(t, "/cms/media", file{"cat.jpg", "image/jpeg", append(JPEG, []byte("PK\x03\x04\x14\x00")...)}, "archive embedded in cat.jpg")
+		check(t, "/cms/media", file{"logo.svg", "image/svg+xml", []byte(`<svg onload="alert(1)"></svg>`)}, `script in svg: onload="alert(1)"`)
+		check(t, "/cms/media", file{"logo.svg", "image/svg+xml", []byte(`<svg><script>alert(1)</script></svg>`)}, "script in svg: <script>")
+	})
+
+	t.Run("happy path: reload on changelog notification", func(t *testing.T) {
+		ds.policies = []db.UploadPolicy{}
+		for _, l := range ds.listeners {
+			l.NotifyDbChange("1")
+		}
+		assert.True(t, plugin.Scan(context.Background(), uploadRequest(t, "/api/users", file{"cat.png", "image/png", PNG})))
+	})
+}
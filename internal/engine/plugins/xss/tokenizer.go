@@ -0,0 +1,314 @@
+package xss
+
+import (
+	"html"
+	"strings"
+)
+
+const (
+	// length of the reported snippets
+	SNIPPET_SIZE = 64
+)
+
+// the tags that can run scripts by themselves
+var dangerousTags = map[string]bool{
+	"script": true, "iframe": true, "frame": true, "frameset": true,
+	"object": true, "embed": true, "applet": true, "base": true,
+	"import": true, "xss": true, "vmlframe": true, "xml": true,
+}
+
+// the attributes whose value is an url
+var urlAttributes = map[string]bool{
+	"href": true, "src": true, "action": true, "formaction": true,
+	"data": true, "xlink:href": true, "background": true, "lowsrc": true,
+	"dynsrc": true, "poster": true, "codebase": true,
+	"to": true, "values": true, "from": true, "by": true, "icon": true,
+	"manifest": true, "ping": true, "cite": true, "longdesc": true,
+}
+
+// the schemes that run scripts
+var dangerousSchemes = []string{
+	"javascript:", "vbscript:", "livescript:", "data:text/html", "data:text/javascript",
+	"data:image/svg+xml", "data:application/javascript", "data:application/x-javascript",
+}
+
+// event handler attributes
+var eventHandlers = map[string]bool{}
+
+func init() {
+	for _, e := range []string{
+		"abort", "activate", "afterprint", "animationend", "animationiteration",
+		"animationstart", "auxclick", "beforecopy", "beforecut", "beforeinput",
+		"beforepaste", "beforeprint", "beforeunload", "begin", "blur", "bounce",
+		"canplay", "canplaythrough", "change", "click", "close", "contextmenu",
+		"copy", "cuechange", "cut", "dblclick", "drag", "dragend", "dragenter",
+		"dragleave", "dragover", "dragstart", "drop", "durationchange", "end",
+		"ended", "error", "finish", "focus", "focusin", "focusout",
+		"formdata", "fullscreenchange", "hashchange", "input", "invalid",
+		"keydown", "keypress", "keyup", "load", "loadeddata", "loadedmetadata",
+		"loadstart", "message", "mousedown", "mouseenter", "mouseleave",
+		"mousemove", "mouseout", "mouseover", "mouseup", "mousewheel",
+		"offline", "online", "pagehide", "pageshow", "paste", "pause", "play",
+		"playing", "pointerdown", "pointerenter", "pointerleave",
+		"pointermove", "pointerout", "pointerover", "pointerup", "popstate",
+		"progress", "ratechange", "readystatechange", "repeat", "reset",
+		"resize", "scroll", "scrollend", "search", "seeked", "seeking",
+		"select", "selectionchange", "selectstart", "show", "start", "stalled",
+		"storage", "submit", "suspend", "timeupdate", "toggle", "touchend",
+		"touchmove", "touchstart", "transitioncancel", "transitionend",
+		"transitionrun", "transitionstart", "unload", "volumechange",
+		"waiting", "webkitanimationend", "webkittransitionend", "wheel",
+	} {
+		eventHandlers["on"+e] = true
+	}
+}
+
+type attribute struct {
+	name     string
+	value    string
+	hasValue bool
+	raw      string
+}
+
+type tag struct {
+	name       string
+	attributes []attribute
+	raw        string
+}
+
+/*
+parser is a (simplified) HTML5 tokenizer, only interested in the tags
+*/
+type parser struct {
+	s   string
+	pos int
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f' || c == '\v' || c == 0
+}
+
+func isLetter(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+/*
+nextTag returns the next opening tag, false at the end of the input.
+The tags are searched for inside of the tags too (like "<scr<script>ipt>"
+or srcdoc="<script>")
+*/
+func (p *parser) nextTag() (*tag, bool) {
+	for {
+		i := strings.IndexByte(p.s[p.pos:], '<')
+		if i < 0 {
+			p.pos = len(p.s)
+			return nil, false
+		}
+		p.pos += i + 1
+		if strings.HasPrefix(p.s[p.pos:], "!--") {
+			end := strings.Index(p.s[p.pos:], "-->")
+			if end < 0 {
+				p.pos = len(p.s)
+				return nil, false
+			}
+			p.pos += end + 3
+			continue
+		}
+		if p.pos < len(p.s) && isLetter(p.s[p.pos]) {
+			return p.parseTag(p.pos - 1), true
+		}
+	}
+}
+
+func (p *parser) parseTag(start int) *tag {
+	nameStart := p.pos
+	for p.pos < len(p.s) && !isSpace(p.s[p.pos]) && p.s[p.pos] != '/' && p.s[p.pos] != '>' {
+		p.pos++
+	}
+	t := &tag{name: strings.ToLower(p.s[nameStart:p.pos])}
+	p.parseAttributes(t)
+	t.raw = p.s[start:p.pos]
+	p.pos = start + 1
+	return t
+}
+
+/*
+parseAttributes parses the attributes until the end of the tag
+*/
+func (p *parser) parseAttributes(t *tag) {
+	for p.pos < len(p.s) {
+		c := p.s[p.pos]
+		if isSpace(c) || c == '/' {
+			p.pos++
+			continue
+		}
+		if c == '>' {
+			p.pos++
+			return
+		}
+
+		start := p.pos
+		for p.pos < len(p.s) && !isSpace(p.s[p.pos]) && p.s[p.pos] != '/' && p.s[p.pos] != '>' && p.s[p.pos] != '=' {
+			p.pos++
+		}
+		// an attribute name can start with '='
+		if p.pos == start {
+			p.pos++
+		}
+		a := attribute{name: strings.ToLower(p.s[start:p.pos])}
+
+		for p.pos < len(p.s) && isSpace(p.s[p.pos]) {
+			p.pos++
+		}
+		if p.pos < len(p.s) && p.s[p.pos] == '=' {
+			p.pos++
+			for p.pos < len(p.s) && isSpace(p.s[p.pos]) {
+				p.pos++
+			}
+			a.value = p.parseValue()
+			a.hasValue = true
+		}
+		a.raw = p.s[start:p.pos]
+		t.attributes = append(t.attributes, a)
+	}
+}
+
+func (p *parser) parseValue() string {
+	if p.pos >= len(p.s) {
+		return ""
+	}
+	c := p.s[p.pos]
+	if c == '"' || c == '\'' || c == '`' {
+		p.pos++
+		end := strings.IndexByte(p.s[p.pos:], c)
+		if end < 0 {
+			value := p.s[p.pos:]
+			p.pos = len(p.s)
+			return value
+		}
+		value := p.s[p.pos : p.pos+end]
+		p.pos += end + 1
+		return value
+	}
+	start := p.pos
+	for p.pos < len(p.s) && !isSpace(p.s[p.pos]) && p.s[p.pos] != '>' {
+		p.pos++
+	}
+	return p.s[start:p.pos]
+}
+
+/*
+normalizeUrl decodes an url attribute value the way a browser
+does before looking at the scheme
+*/
+func normalizeUrl(value string) string {
+	value = html.UnescapeString(value)
+	var b strings.Builder
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		// browsers ignore the control characters and the spaces in the scheme
+		if c <= ' ' {
+			continue
+		}
+		b.WriteByte(c)
+	}
+	return strings.ToLower(b.String())
+}
+
+func isDangerousUrl(value string) bool {
+	u := normalizeUrl(value)
+	for _, scheme := range dangerousSchemes {
+		if strings.HasPrefix(u, scheme) {
+			return true
+		}
+	}
+	return false
+}
+
+/*
+isDangerousRefresh checks a <meta http-equiv="refresh" content="0;url=...">
+*/
+func isDangerousRefresh(value string) bool {
+	u := normalizeUrl(value)
+	i := strings.Index(u, "url=")
+	return i >= 0 && isDangerousUrl(u[i+len("url="):])
+}
+
+func isDangerousStyle(value string) bool {
+	s := normalizeUrl(value)
+	return strings.Contains(s, "expression(") || strings.Contains(s, "javascript:") ||
+		strings.Contains(s, "-moz-binding") || strings.Contains(s, "behavior:")
+}
+
+/*
+checkTag returns the script-capable part of a tag (or "")
+*/
+func checkTag(t *tag) string {
+	if dangerousTags[t.name] {
+		return t.raw
+	}
+	for _, a := range t.attributes {
+		switch {
+		case eventHandlers[a.name] && a.hasValue:
+			return a.raw
+		case a.name == "srcdoc":
+			return a.raw
+		case urlAttributes[a.name] && isDangerousUrl(a.value):
+			return a.raw
+		case a.name == "style" && isDangerousStyle(a.value):
+			return a.raw
+		case a.name == "content" && isDangerousRefresh(a.value):
+			return a.raw
+		}
+	}
+	return ""
+}
+
+// the contexts the input can be reflected into
+var contexts = []string{
+	"",       // html content
+	`<x a="`, // double quoted attribute value
+	`<x a='`, // single quoted attribute value
+	`<x a=`,  // unquoted attribute value
+}
+
+/*
+Detect parses an input as a HTML fragment (in the different
+contexts it can be reflected into), and returns the first
+script-capable construct
+*/
+func Detect(input string) (string, bool) {
+	// an url used as is (in a href)
+	if isDangerousUrl(input) {
+		return snippet(strings.TrimSpace(input)), true
+	}
+
+	for _, ctx := range contexts {
+		if ctx != "" && strings.IndexAny(input, `"'`+"` ") < 0 {
+			// no way to break out of the attribute
+			continue
+		}
+		p := &parser{s: ctx + input}
+		for first := true; ; first = false {
+			t, ok := p.nextTag()
+			if !ok {
+				break
+			}
+			if ctx != "" && first {
+				// the context tag: only the attributes coming from the input count
+				t.attributes = t.attributes[1:]
+			}
+			if s := checkTag(t); s != "" {
+				return snippet(s), true
+			}
+		}
+	}
+	return "", false
+}
+
+func snippet(s string) string {
+	if len(s) > SNIPPET_SIZE {
+		return s[:SNIPPET_SIZE]
+	}
+	return s
+}
@@ -0,0 +1,84 @@
+package xss
+
+import (
+	"context"
+	"strings"
+
+	"github.com/nzin/taxsi2/internal/com"
+	"github.com/nzin/taxsi2/internal/engine"
+)
+
+const (
+	// output variable
+	VARIABLE_SNIPPET = "xss_snippet"
+)
+
+/*
+XssWafPlugin detects the cross-site scripting attempts in the query args,
+the headers, the form fields, the JSON values and the text bodies
+*/
+type XssWafPlugin struct {
+}
+
+func NewXssWafPlugin() engine.WafEnginePlugin {
+	return &XssWafPlugin{}
+}
+
+func (x *XssWafPlugin) Name() string {
+	return "xss"
+}
+
+// Interruptible is true: the scan stops when the context is done
+func (x *XssWafPlugin) Interruptible() bool {
+	return true
+}
+
+func (x *XssWafPlugin) Scan(ctx context.Context, payload *com.TaxsiCom) bool {
+	for _, input := range Inputs(payload) {
+		if ctx.Err() != nil {
+			// (the verdict is ignored)
+			return true
+		}
+		if s, ok := Detect(input); ok {
+			payload.SetVariable(VARIABLE_SNIPPET, s)
+			return false
+		}
+	}
+	return true
+}
+
+/*
+IsXSS checks an input, and its decoded variants (url, html
+entities, javascript escapes, base64). It returns the matched snippet
+*/
+func IsXSS(input string) (string, bool) {
+	for _, v := range com.Decode(input) {
+		if s, ok := Detect(v); ok {
+			return s, true
+		}
+	}
+	return "", false
+}
+
+/*
+Inputs returns the user controlled values of a request
+(with their decoded variants)
+*/
+func Inputs(payload *com.TaxsiCom) []string {
+	inputs := []string{}
+	parsed := payload.Parse()
+	for _, f := range parsed.Fields {
+		switch f.Source {
+		case com.SOURCE_QUERY, com.SOURCE_FORM, com.SOURCE_JSON:
+			// the names are user controlled too
+			inputs = append(inputs, com.Decode(f.Name)...)
+		}
+		inputs = append(inputs, f.Decoded...)
+	}
+
+	// the tags of a text body
+	if strings.HasPrefix(parsed.ContentType, "text/") || strings.HasSuffix(parsed.ContentType, "xml") {
+		inputs = append(inputs, com.Decode(string(payload.Body))...)
+	}
+	return inputs
+}
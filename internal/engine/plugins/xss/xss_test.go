@@ -0,0 +1,129 @@
+package xss
+
+import (
+	"bufio"
+	"context"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/nzin/taxsi2/internal/com"
+	"github.com/stretchr/testify/assert"
+)
+
+func readCorpus(t *testing.T, path string) []string {
+	f, err := os.Open(path)
+	assert.Nil(t, err)
+	defer f.Close()
+
+	lines := []string{}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" || strings.HasPrefix(line, "# ") {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	assert.Nil(t, scanner.Err())
+	return lines
+}
+
+func TestDetect(t *testing.T) {
+	s, ok := Detect(`hello <img src=x onerror=alert(1)> world`)
+	assert.True(t, ok)
+	assert.Equal(t, "onerror=alert(1)", s)
+
+	s, ok = Detect(`<script>alert(1)</script>`)
+	assert.True(t, ok)
+	assert.Equal(t, "<script>", s)
+
+	// attribute context
+	s, ok = Detect(`" onmouseover="alert(1)`)
+	assert.True(t, ok)
+	assert.Equal(t, `onmouseover="alert(1)`, s)
+
+	// an event handler needs a value
+	_, ok = Detect(`<x onclick>`)
+	assert.False(t, ok)
+
+	// snippets are truncated
+	s, ok = Detect(`<script src="https://evil.com/` + strings.Repeat("a", 100) + `">`)
+	assert.True(t, ok)
+	assert.Equal(t, SNIPPET_SIZE, len(s))
+}
+
+func TestCorpus(t *testing.T) {
+	t.Run("happy path: attacks are detected", func(t *testing.T) {
+		attacks := readCorpus(t, "testdata/attacks.txt")
+		assert.Greater(t, len(attacks), 50)
+		for _, a := range attacks {
+			_, ok := IsXSS(a)
+			assert.True(t, ok, "not detected: %s", a)
+		}
+	})
+
+	t.Run("happy path: benign inputs are not detected", func(t *testing.T) {
+		benign := readCorpus(t, "testdata/benign.txt")
+		assert.Greater(t, len(benign), 50)
+		for _, b := range benign {
+			s, ok := IsXSS(b)
+			assert.False(t, ok, "false positive: %s (%s)", b, s)
+		}
+	})
+}
+
+func TestXssPlugin(t *testing.T) {
+	plugin := NewXssWafPlugin()
+
+	request := func(rawurl string, headers map[string][]string, body string) *com.TaxsiCom {
+		u, _ := url.Parse(rawurl)
+		if headers == nil {
+			headers = map[string][]string{}
+		}
+		return &com.TaxsiCom{
+			Method:  "POST",
+			Url:     u,
+			Headers: headers,
+			Body:    []byte(body),
+		}
+	}
+
+	t.Run("happy path: legit requests", func(t *testing.T) {
+		assert.True(t, plugin.Scan(context.Background(), request("http://www.example.com/search?q=%3Cb%3Ebold%3C%2Fb%3E", map[string][]string{
+			"User-Agent": {"Mozilla/5.0"},
+			"Cookie":     {"session=abc; theme=dark"},
+		}, "")))
+		assert.True(t, plugin.Scan(context.Background(), request("http://www.example.com/", map[string][]string{
+			"Content-Type": {"application/json"},
+		}, `{"comment": "I <3 this"}`)))
+	})
+
+	t.Run("happy path: query args", func(t *testing.T) {
+		payload := request("http://www.example.com/?q=%3Csvg%2Fonload%3Dalert(1)%3E", nil, "")
+		assert.False(t, plugin.Scan(context.Background(), payload))
+		assert.Equal(t, "onload=alert(1)", payload.Variables[VARIABLE_SNIPPET])
+	})
+
+	t.Run("happy path: headers and cookies", func(t *testing.T) {
+		assert.False(t, plugin.Scan(context.Background(), request("http://www.example.com/", map[string][]string{
+			"Referer": {"javascript:alert(1)"},
+		}, "")))
+		assert.False(t, plugin.Scan(context.Background(), request("http://www.example.com/", map[string][]string{
+			"Cookie": {"name=%3Cscript%3Ealert(1)%3C%2Fscript%3E"},
+		}, "")))
+	})
+
+	t.Run("happy path: bodies", func(t *testing.T) {
+		assert.False(t, plugin.Scan(context.Background(), request("http://www.example.com/", map[string][]string{
+			"Content-Type": {"application/x-www-form-urlencoded"},
+		}, "comment=%3Cimg+src%3Dx+onerror%3Dalert(1)%3E")))
+		assert.False(t, plugin.Scan(context.Background(), request("http://www.example.com/", map[string][]string{
+			"Content-Type": {"application/json; charset=utf-8"},
+		}, `{"user": {"bio": "<script>alert(1)</script>"}}`)))
+		assert.False(t, plugin.Scan(context.Background(), request("http://www.example.com/", map[string][]string{
+			"Content-Type": {"text/plain"},
+		}, `<iframe src="https://evil.com">`)))
+	})
+}
@@ -0,0 +1,194 @@
+package engine
+
+import (
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/nzin/taxsi2/internal/db"
+	"github.com/sirupsen/logrus"
+)
+
+/*
+WafAutoban bans (fail2ban-style) the IPs triggering too many blocked
+verdicts within a (fixed) window.
+
+Hits are counted locally, and periodically synced into the database
+(see Sync()), so the counters are shared (approximately, i.e. with a
+sync delay) between the nodes.
+
+The bans are enforced locally right away, but written into the database
+by Sync() (never on the request path).
+*/
+type WafAutoban struct {
+	ds     db.DbServiceAutoban
+	config *WafConfig
+	bans   *WafBans
+	now    func() time.Time
+
+	mu          sync.Mutex
+	windowStart time.Time
+	pending     map[string]int  // local hits not yet synced
+	totals      map[string]int  // hits of all the nodes (as far as we know)
+	banned      map[string]bool // already banned during this window
+	queued      []string        // banned locally, not yet written into the database
+}
+
+func NewWafAutoban(ds db.DbServiceAutoban, config *WafConfig, bans *WafBans) *WafAutoban {
+	return &WafAutoban{
+		ds:      ds,
+		config:  config,
+		bans:    bans,
+		now:     time.Now,
+		pending: make(map[string]int),
+		totals:  make(map[string]int),
+		banned:  make(map[string]bool),
+	}
+}
+
+/*
+rollWindow resets the counters if the window is over
+(must be called with the lock held).
+The hits not yet synced from the previous window are dropped
+*/
+func (wa *WafAutoban) rollWindow() {
+	windowStart := wa.now().Truncate(wa.config.Settings().AutobanWindow)
+	if windowStart.Equal(wa.windowStart) {
+		return
+	}
+	wa.windowStart = windowStart
+	wa.pending = make(map[string]int)
+	wa.totals = make(map[string]int)
+	wa.banned = make(map[string]bool)
+}
+
+/*
+RecordBlocked counts a blocked verdict for an IP, and bans it
+(on this node, see Sync()) if the threshold is reached
+*/
+func (wa *WafAutoban) RecordBlocked(ip net.IP) {
+	threshold := wa.config.Settings().AutobanThreshold
+	if threshold <= 0 {
+		return
+	}
+
+	wa.mu.Lock()
+	wa.rollWindow()
+	key := ip.String()
+	wa.pending[key]++
+	wa.totals[key]++
+	toBan := wa.checkThreshold(key, threshold)
+	wa.queued = append(wa.queued, toBan...)
+	wa.mu.Unlock()
+
+	duration := wa.config.Settings().AutobanDuration
+	for _, ip := range toBan {
+		wa.bans.enforce(autobanNetwork(ip), time.Now().Add(duration))
+	}
+}
+
+/*
+autobanNetwork returns the (single IP) network to ban
+*/
+func autobanNetwork(ip string) *net.IPNet {
+	addr := net.ParseIP(ip)
+	if addr.To4() != nil {
+		return &net.IPNet{IP: addr.To4(), Mask: net.CIDRMask(32, 32)}
+	}
+	return &net.IPNet{IP: addr, Mask: net.CIDRMask(128, 128)}
+}
+
+/*
+checkThreshold returns the IPs to ban
+(must be called with the lock held)
+*/
+func (wa *WafAutoban) checkThreshold(key string, threshold int) []string {
+	if wa.totals[key] >= threshold && !wa.banned[key] {
+		wa.banned[key] = true
+		return []string{key}
+	}
+	return []string{}
+}
+
+func (wa *WafAutoban) ban(ips []string) {
+	config := wa.config.Settings()
+	for _, ip := range ips {
+		cidr := autobanNetwork(ip).String()
+		reason := fmt.Sprintf("%d blocked requests within %s", config.AutobanThreshold, config.AutobanWindow)
+		err := wa.bans.Ban(cidr, reason, db.AUTOBAN_CREATOR, config.AutobanDuration)
+		if err != nil {
+			logrus.Errorf("not able to autoban %s: %v", ip, err)
+		} else {
+			logrus.Infof("autoban %s for %s: %s", ip, config.AutobanDuration, reason)
+		}
+	}
+}
+
+/*
+Sync pushes the local hits (and bans) into the database, and fetches
+the hits of the other nodes
+*/
+func (wa *WafAutoban) Sync() error {
+	wa.mu.Lock()
+	queued := wa.queued
+	wa.queued = nil
+	wa.mu.Unlock()
+	wa.ban(queued)
+
+	config := wa.config.Settings()
+	threshold := config.AutobanThreshold
+	if threshold <= 0 {
+		return nil
+	}
+
+	wa.mu.Lock()
+	wa.rollWindow()
+	windowStart := wa.windowStart
+	pending := wa.pending
+	wa.pending = make(map[string]int)
+	wa.mu.Unlock()
+
+	totals, err := wa.ds.AddAutobanHits(windowStart, pending)
+	if err != nil {
+		return err
+	}
+
+	toBan := []string{}
+	wa.mu.Lock()
+	// the window may have changed in the meantime
+	if windowStart.Equal(wa.windowStart) {
+		for ip, total := range totals {
+			// hits recorded since we released the lock are not yet in total
+			total += wa.pending[ip]
+			if total > wa.totals[ip] {
+				wa.totals[ip] = total
+			}
+			toBan = append(toBan, wa.checkThreshold(ip, threshold)...)
+		}
+	}
+	wa.mu.Unlock()
+
+	wa.ban(toBan)
+
+	return wa.ds.PurgeAutobanCounters(windowStart.Add(-config.AutobanWindow))
+}
+
+/*
+Watch periodically syncs the counters (until stopChannel is closed)
+*/
+func (wa *WafAutoban) Watch(stopChannel chan struct{}) {
+	for {
+		select {
+		case _, ok := <-stopChannel:
+			if !ok {
+				// channel closed
+				return
+			}
+		case <-time.After(1 * time.Second):
+			if err := wa.Sync(); err != nil {
+				logrus.Errorf("error syncing autoban counters: %v", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,192 @@
+package engine
+
+import (
+	"context"
+	"html/template"
+	"net"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/nzin/taxsi2/internal/com"
+	"github.com/nzin/taxsi2/internal/db"
+	"github.com/stretchr/testify/assert"
+)
+
+// shared counters, as if they were in the database
+type DbServiceAutobanMock struct {
+	counters map[time.Time]map[string]int
+}
+
+func (c *DbServiceAutobanMock) AddAutobanHits(windowStart time.Time, hits map[string]int) (map[string]int, error) {
+	if c.counters[windowStart] == nil {
+		c.counters[windowStart] = make(map[string]int)
+	}
+	totals := make(map[string]int)
+	for ip, n := range hits {
+		c.counters[windowStart][ip] += n
+		totals[ip] = c.counters[windowStart][ip]
+	}
+	return totals, nil
+}
+func (c *DbServiceAutobanMock) PurgeAutobanCounters(before time.Time) error {
+	return nil
+}
+
+type blockingPlugin struct{}
+
+func (p *blockingPlugin) Name() string {
+	return "blocking"
+}
+func (p *blockingPlugin) Scan(ctx context.Context, payload *com.TaxsiCom) bool {
+	return false
+}
+
+func newTestAutoban(t *testing.T, counters *DbServiceAutobanMock, bansMock *DbServiceBanMock) (*WafAutoban, *WafBans) {
+	config, err := NewWafConfig(&DbServiceConfigMock{
+		config: map[string]string{
+			"autoban_threshold": "3",
+			"autoban_window":    "1m",
+			"autoban_duration":  "2h",
+		},
+	})
+	assert.Nil(t, err)
+
+	bans, err := NewWafBans(bansMock)
+	assert.Nil(t, err)
+
+	return NewWafAutoban(counters, config, bans), bans
+}
+
+func TestWafAutoban(t *testing.T) {
+	t.Run("happy path: ban after N blocked verdicts", func(t *testing.T) {
+		bansMock := DbServiceBanMock{}
+		autoban, bans := newTestAutoban(t, &DbServiceAutobanMock{counters: make(map[time.Time]map[string]int)}, &bansMock)
+		ip := net.ParseIP("1.2.3.4")
+
+		autoban.RecordBlocked(ip)
+		autoban.RecordBlocked(ip)
+		assert.False(t, bans.IsIpBanned(ip))
+
+		autoban.RecordBlocked(ip)
+		assert.True(t, bans.IsIpBanned(ip))
+		// written into the database by the background sync
+		assert.Equal(t, 0, len(bansMock.bans))
+		assert.Nil(t, autoban.Sync())
+		assert.Equal(t, 1, len(bansMock.bans))
+		assert.Equal(t, "1.2.3.4/32", bansMock.bans[0].Cidr)
+		assert.Equal(t, db.AUTOBAN_CREATOR, bansMock.bans[0].Creator)
+		assert.WithinDuration(t, time.Now().Add(2*time.Hour), bansMock.bans[0].ExpiresAt, time.Minute)
+
+		// no double ban
+		autoban.RecordBlocked(ip)
+		assert.Nil(t, autoban.Sync())
+		assert.Equal(t, 1, len(bansMock.bans))
+	})
+
+	t.Run("happy path: a single ban when several nodes reach the threshold", func(t *testing.T) {
+		counters := DbServiceAutobanMock{counters: make(map[time.Time]map[string]int)}
+		bansMock := DbServiceBanMock{}
+		node1, bans1 := newTestAutoban(t, &counters, &bansMock)
+		node2, bans2 := newTestAutoban(t, &counters, &bansMock)
+		ip := net.ParseIP("1.2.3.4")
+
+		for i := 0; i < 3; i++ {
+			node1.RecordBlocked(ip)
+			node2.RecordBlocked(ip)
+		}
+		assert.True(t, bans1.IsIpBanned(ip))
+		assert.True(t, bans2.IsIpBanned(ip))
+
+		assert.Nil(t, node1.Sync())
+		assert.Nil(t, node2.Sync())
+		assert.Equal(t, 1, len(bansMock.bans))
+	})
+
+	t.Run("happy path: counters are reset with the window", func(t *testing.T) {
+		bansMock := DbServiceBanMock{}
+		autoban, bans := newTestAutoban(t, &DbServiceAutobanMock{counters: make(map[time.Time]map[string]int)}, &bansMock)
+		ip := net.ParseIP("1.2.3.4")
+
+		now := time.Now().Truncate(time.Minute)
+		autoban.now = func() time.Time { return now }
+		autoban.RecordBlocked(ip)
+		autoban.RecordBlocked(ip)
+
+		now = now.Add(time.Minute)
+		autoban.RecordBlocked(ip)
+		assert.False(t, bans.IsIpBanned(ip))
+	})
+
+	t.Run("happy path: counters are shared between nodes", func(t *testing.T) {
+		counters := DbServiceAutobanMock{counters: make(map[time.Time]map[string]int)}
+		bansMock := DbServiceBanMock{}
+		node1, bans1 := newTestAutoban(t, &counters, &bansMock)
+		node2, _ := newTestAutoban(t, &counters, &bansMock)
+		ip := net.ParseIP("1.2.3.4")
+
+		node1.RecordBlocked(ip)
+		node2.RecordBlocked(ip)
+		assert.Nil(t, node2.Sync())
+		node1.RecordBlocked(ip)
+		assert.False(t, bans1.IsIpBanned(ip))
+
+		// node1 learns about node2's hit
+		assert.Nil(t, node1.Sync())
+		assert.True(t, bans1.IsIpBanned(ip))
+		assert.Equal(t, 1, len(bansMock.bans))
+	})
+
+	t.Run("happy path: disabled by default", func(t *testing.T) {
+		config, err := NewWafConfig(&DbServiceConfigMock{config: make(map[string]string)})
+		assert.Nil(t, err)
+		bansMock := DbServiceBanMock{}
+		bans, err := NewWafBans(&bansMock)
+		assert.Nil(t, err)
+		autoban := NewWafAutoban(&DbServiceAutobanMock{counters: make(map[time.Time]map[string]int)}, config, bans)
+
+		ip := net.ParseIP("1.2.3.4")
+		for i := 0; i < 10; i++ {
+			autoban.RecordBlocked(ip)
+		}
+		assert.Nil(t, autoban.Sync())
+		assert.Equal(t, 0, len(bansMock.bans))
+	})
+
+	t.Run("happy path: the sync loop stops", func(t *testing.T) {
+		autoban, _ := newTestAutoban(t, &DbServiceAutobanMock{counters: make(map[time.Time]map[string]int)}, &DbServiceBanMock{})
+
+		stopChannel := make(chan struct{})
+		close(stopChannel)
+		// returns right away
+		autoban.Watch(stopChannel)
+	})
+
+	t.Run("happy path: engine bans at the deny stage", func(t *testing.T) {
+		bansMock := DbServiceBanMock{}
+		autoban, bans := newTestAutoban(t, &DbServiceAutobanMock{counters: make(map[time.Time]map[string]int)}, &bansMock)
+		autoban.config.EnabledPlugin["blocking"] = true
+
+		tmpl, err := template.New("outputformat").Parse("")
+		assert.Nil(t, err)
+		we := WafEngineImpl{
+			analysisOutputTemplate: tmpl,
+			config:                 autoban.config,
+			bans:                   bans,
+			autoban:                autoban,
+			plugins:                map[string]WafEnginePlugin{},
+		}
+		we.RegisterPlugin(&blockingPlugin{})
+
+		u, _ := url.Parse("http://www.example.com/")
+		payload := com.TaxsiCom{RemoteAddr: "1.2.3.4", Url: u}
+		for i := 0; i < 3; i++ {
+			assert.Equal(t, VERDICT_BLOCK, we.Scan(context.Background(), &payload))
+		}
+		assert.True(t, bans.IsIpBanned(net.ParseIP("1.2.3.4")))
+
+		// the plugin is not even called anymore
+		delete(we.plugins, "blocking")
+		assert.Equal(t, VERDICT_BLOCK, we.Scan(context.Background(), &payload))
+	})
+}
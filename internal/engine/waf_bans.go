@@ -0,0 +1,148 @@
+package engine
+
+import (
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/nzin/taxsi2/internal/db"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// how often the expired bans are removed from the database
+	BAN_PURGE_INTERVAL = 1 * time.Minute
+)
+
+type wafBan struct {
+	network   *net.IPNet
+	expiresAt time.Time
+}
+
+/*
+WafBans keeps in memory the active (time-boxed) bans.
+It is reloaded each time a ban is added/removed/purged on any node
+*/
+type WafBans struct {
+	ds   db.DbServiceBan
+	mu   sync.RWMutex
+	bans []wafBan
+}
+
+func NewWafBans(ds db.DbServiceBan) (*WafBans, error) {
+	wb := WafBans{
+		ds:   ds,
+		bans: []wafBan{},
+	}
+
+	if err := wb.loadBans(); err != nil {
+		return nil, err
+	}
+	ds.SubscribeChanges(db.CHANGELOG_TABLE_BAN, &wb)
+	return &wb, nil
+}
+
+func (wb *WafBans) loadBans() error {
+	list, err := wb.ds.GetActiveBans()
+	if err != nil {
+		return err
+	}
+
+	bans := []wafBan{}
+	for _, b := range list {
+		_, network, err := net.ParseCIDR(b.Cidr)
+		if err != nil {
+			logrus.Errorf("not able to parse ban net %s: %v", b.Cidr, err)
+			continue
+		}
+		bans = append(bans, wafBan{
+			network:   network,
+			expiresAt: b.ExpiresAt,
+		})
+	}
+
+	wb.mu.Lock()
+	wb.bans = bans
+	wb.mu.Unlock()
+	return nil
+}
+
+func (wb *WafBans) NotifyDbChange(key string) {
+	if err := wb.loadBans(); err != nil {
+		logrus.Errorf("Error reading bans: %v", err)
+	}
+}
+
+/*
+IsIpBanned to know if an IP is currently banned.
+The expiration is checked here, so a ban stops as soon as it
+expires, even before the purge is propagated
+*/
+func (wb *WafBans) IsIpBanned(remoteAddr net.IP) bool {
+	now := time.Now()
+
+	wb.mu.RLock()
+	defer wb.mu.RUnlock()
+	for _, b := range wb.bans {
+		if b.network.Contains(remoteAddr) && now.Before(b.expiresAt) {
+			return true
+		}
+	}
+	return false
+}
+
+/*
+Ban adds a ban in the database (unless the CIDR is already banned,
+i.e. by another node), and enforces it right away on this node
+(the other nodes will get it through the changelog)
+*/
+func (wb *WafBans) Ban(cidr string, reason string, creator string, duration time.Duration) error {
+	_, network, err := net.ParseCIDR(cidr)
+	if err != nil {
+		return fmt.Errorf("not able to parse ban net %s: %v", cidr, err)
+	}
+
+	ban, _, err := wb.ds.AddBanIfNotBanned(network.String(), reason, creator, time.Now().Add(duration))
+	if err != nil {
+		return err
+	}
+
+	wb.enforce(network, ban.ExpiresAt)
+	return nil
+}
+
+/*
+enforce bans a network on this node only (i.e. until the ban
+is written in the database)
+*/
+func (wb *WafBans) enforce(network *net.IPNet, expiresAt time.Time) {
+	wb.mu.Lock()
+	wb.bans = append(wb.bans, wafBan{
+		network:   network,
+		expiresAt: expiresAt,
+	})
+	wb.mu.Unlock()
+}
+
+/*
+Watch periodically purges the expired bans (until stopChannel is closed).
+It is the only place where the bans are purged: several nodes
+purging at the same time is harmless (only the one which actually
+removes the bans notifies the changelog)
+*/
+func (wb *WafBans) Watch(stopChannel chan struct{}) {
+	for {
+		select {
+		case _, ok := <-stopChannel:
+			if !ok {
+				// channel closed
+				return
+			}
+		case <-time.After(BAN_PURGE_INTERVAL):
+			if err := wb.ds.PurgeExpiredBans(); err != nil {
+				logrus.Errorf("error purging expired bans: %v", err)
+			}
+		}
+	}
+}
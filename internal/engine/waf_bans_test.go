@@ -0,0 +1,96 @@
+package engine
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/nzin/taxsi2/internal/db"
+	"github.com/stretchr/testify/assert"
+)
+
+type DbServiceBanMock struct {
+	bans []db.Ban
+}
+
+func (c *DbServiceBanMock) SubscribeChanges(table int, listener db.DbChangeListener) {
+
+}
+func (c *DbServiceBanMock) AddBan(cidr string, reason string, creator string, expiresAt time.Time) (*db.Ban, error) {
+	ban := db.Ban{
+		Cidr:      cidr,
+		Reason:    reason,
+		Creator:   creator,
+		ExpiresAt: expiresAt,
+	}
+	c.bans = append(c.bans, ban)
+	return &ban, nil
+}
+func (c *DbServiceBanMock) AddBanIfNotBanned(cidr string, reason string, creator string, expiresAt time.Time) (*db.Ban, bool, error) {
+	for _, b := range c.bans {
+		if b.Cidr == cidr && b.ExpiresAt.After(time.Now()) {
+			return &b, false, nil
+		}
+	}
+	ban, err := c.AddBan(cidr, reason, creator, expiresAt)
+	return ban, err == nil, err
+}
+func (c *DbServiceBanMock) GetActiveBans() ([]db.Ban, error) {
+	return c.bans, nil
+}
+func (c *DbServiceBanMock) RemoveBan(id uint) error {
+	return nil
+}
+func (c *DbServiceBanMock) PurgeExpiredBans() error {
+	return nil
+}
+
+func TestWafBans(t *testing.T) {
+	t.Run("happy path: no ban", func(t *testing.T) {
+		c := DbServiceBanMock{}
+
+		wb, err := NewWafBans(&c)
+		assert.Nil(t, err)
+		assert.False(t, wb.IsIpBanned(net.ParseIP("1.2.3.4")))
+	})
+
+	t.Run("happy path: bans are enforced until they expire", func(t *testing.T) {
+		c := DbServiceBanMock{}
+		c.AddBan("1.2.3.4/32", "scanner", "alice", time.Now().Add(1*time.Hour))
+		c.AddBan("5.6.0.0/16", "ddos", "bob", time.Now().Add(-1*time.Second))
+		c.AddBan("not a cidr", "", "bob", time.Now().Add(1*time.Hour))
+
+		wb, err := NewWafBans(&c)
+		assert.Nil(t, err)
+		assert.True(t, wb.IsIpBanned(net.ParseIP("1.2.3.4")))
+		assert.False(t, wb.IsIpBanned(net.ParseIP("1.2.3.5")))
+		// expired, but not yet purged
+		assert.False(t, wb.IsIpBanned(net.ParseIP("5.6.7.8")))
+	})
+
+	t.Run("happy path: reload on changelog notification", func(t *testing.T) {
+		c := DbServiceBanMock{}
+
+		wb, err := NewWafBans(&c)
+		assert.Nil(t, err)
+		assert.False(t, wb.IsIpBanned(net.ParseIP("1.2.3.4")))
+
+		c.AddBan("1.2.3.0/24", "scanner", "alice", time.Now().Add(1*time.Hour))
+		wb.NotifyDbChange("1")
+		assert.True(t, wb.IsIpBanned(net.ParseIP("1.2.3.4")))
+
+		c.bans = []db.Ban{}
+		wb.NotifyDbChange("")
+		assert.False(t, wb.IsIpBanned(net.ParseIP("1.2.3.4")))
+	})
+
+	t.Run("happy path: the purge loop stops", func(t *testing.T) {
+		wb, err := NewWafBans(&DbServiceBanMock{})
+		assert.Nil(t, err)
+
+		stopChannel := make(chan struct{})
+		close(stopChannel)
+		// returns right away
+		wb.Watch(stopChannel)
+	})
+}
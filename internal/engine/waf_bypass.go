@@ -0,0 +1,142 @@
+package engine
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/nzin/taxsi2/internal/com"
+)
+
+const (
+	// reason of the verdict when a bypass rule matches
+	REASON_BYPASS = "bypass"
+	// output variable (the bypass rule that matched)
+	VARIABLE_BYPASS_RULE = "bypass_rule"
+)
+
+var methodsRegexp = regexp.MustCompile(`^[A-Z]+(\|[A-Z]+)*$`)
+
+/*
+BypassRule skips the plugins for the requests of some methods and paths
+(like the static assets): "[METHOD[|METHOD...]] [host]/path", where the
+path can use * (any characters of a path segment) and ** (any characters,
+/ included), for example "GET|HEAD /static/*.css", "GET **.png",
+"cdn.example.com/**" or "OPTIONS **".
+The requests with query args or a body are never bypassed
+*/
+type BypassRule struct {
+	Rule string
+	// empty for all the methods
+	Methods []string
+	// "" for all the hosts
+	Host string
+	path *regexp.Regexp
+}
+
+/*
+ParseBypassRules parses a comma separated list of bypass rules.
+The invalid rules are skipped, and the first one is reported
+*/
+func ParseBypassRules(list string) ([]BypassRule, error) {
+	var firstErr error
+	rules := []BypassRule{}
+	for _, r := range strings.Split(list, ",") {
+		if r = strings.TrimSpace(r); r == "" {
+			continue
+		}
+		rule, err := parseBypassRule(r)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		rules = append(rules, rule)
+	}
+	return rules, firstErr
+}
+
+func parseBypassRule(r string) (BypassRule, error) {
+	rule := BypassRule{Rule: r}
+	fields := strings.Fields(r)
+	target := fields[0]
+	switch len(fields) {
+	case 1:
+	case 2:
+		if !methodsRegexp.MatchString(fields[0]) {
+			return rule, fmt.Errorf("not able to parse bypass rule %s (bad methods %s)", r, fields[0])
+		}
+		rule.Methods = strings.Split(fields[0], "|")
+		target = fields[1]
+	default:
+		return rule, fmt.Errorf("not able to parse bypass rule %s (must be [METHOD[|METHOD...]] [host]/path)", r)
+	}
+
+	if !strings.HasPrefix(target, "/") && !strings.HasPrefix(target, "*") {
+		slash := strings.Index(target, "/")
+		if slash <= 0 {
+			return rule, fmt.Errorf("not able to parse bypass rule %s (must be [METHOD[|METHOD...]] [host]/path)", r)
+		}
+		rule.Host = strings.ToLower(target[:slash])
+		target = target[slash:]
+	}
+	if strings.HasPrefix(target, "*") {
+		target = "/" + target
+	}
+	pattern := strings.ReplaceAll(regexp.QuoteMeta(target), `\*\*`, ".*")
+	// (a single * does not cross the path segments)
+	pattern = strings.ReplaceAll(pattern, `\*`, "[^/]*")
+	rule.path = regexp.MustCompile("^" + pattern + "$")
+	return rule, nil
+}
+
+func (r *BypassRule) match(method string, host string, p string) bool {
+	if r.Host != "" && !strings.EqualFold(r.Host, host) {
+		return false
+	}
+	if len(r.Methods) > 0 {
+		found := false
+		for _, m := range r.Methods {
+			found = found || strings.EqualFold(m, method)
+		}
+		if !found {
+			return false
+		}
+	}
+	return r.path.MatchString(p)
+}
+
+/*
+bypassablePath refuses the paths that a server could resolve to another
+file than the one matched (dot segments, backslashes, path parameters)
+*/
+func bypassablePath(p string) bool {
+	if !strings.HasPrefix(p, "/") || strings.ContainsAny(p, "\\;\x00") {
+		return false
+	}
+	cleaned := path.Clean(p)
+	return cleaned == p || cleaned+"/" == p
+}
+
+/*
+matchBypassRule returns the first bypass rule that matches a request
+(nil if none). The query args and the body are not static content, a
+request that has some is scanned
+*/
+func matchBypassRule(rules []BypassRule, payload *com.TaxsiCom) *BypassRule {
+	if len(rules) == 0 || payload.Url == nil || !bypassablePath(payload.Url.Path) {
+		return nil
+	}
+	if payload.Url.RawQuery != "" || payload.Url.ForceQuery || len(payload.Body) > 0 {
+		return nil
+	}
+	host := requestHost(payload)
+	for i := range rules {
+		if rules[i].match(payload.Method, host, payload.Url.Path) {
+			return &rules[i]
+		}
+	}
+	return nil
+}
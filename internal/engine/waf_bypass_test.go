@@ -0,0 +1,135 @@
+package engine
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseBypassRules(t *testing.T) {
+	t.Run("happy path: methods, hosts and paths", func(t *testing.T) {
+		rules, err := ParseBypassRules("GET|HEAD /static/*.css, *.png,CDN.example.com/*, OPTIONS *")
+		assert.Nil(t, err)
+		assert.Equal(t, 4, len(rules))
+		assert.Equal(t, []string{"GET", "HEAD"}, rules[0].Methods)
+		assert.Equal(t, "", rules[0].Host)
+		assert.Equal(t, 0, len(rules[1].Methods))
+		assert.Equal(t, "cdn.example.com", rules[2].Host)
+		assert.Equal(t, "OPTIONS *", rules[3].Rule)
+	})
+
+	t.Run("not happy path: invalid rules are skipped", func(t *testing.T) {
+		rules, err := ParseBypassRules("GET /static/*, get /favicon.ico, OPTIONS, GET HEAD /x, *.js")
+		assert.NotNil(t, err)
+		assert.Contains(t, err.Error(), "get /favicon.ico")
+		assert.Equal(t, 2, len(rules))
+		assert.Equal(t, "GET /static/*", rules[0].Rule)
+		assert.Equal(t, "*.js", rules[1].Rule)
+	})
+}
+
+func TestMatchBypassRule(t *testing.T) {
+	rules, err := ParseBypassRules("GET|HEAD /static/*.css,GET *.png,GET /img/**.svg,cdn.example.com/**,OPTIONS **")
+	assert.Nil(t, err)
+
+	t.Run("happy path: the matching requests", func(t *testing.T) {
+		for _, c := range []struct {
+			method string
+			url    string
+			rule   string
+		}{
+			{"GET", "http://www.example.com/static/app.css", "GET|HEAD /static/*.css"},
+			{"HEAD", "http://www.example.com/static/app.css", "GET|HEAD /static/*.css"},
+			{"get", "http://www.example.com/logo.png", "GET *.png"},
+			{"GET", "http://www.example.com/img/icons/logo.svg", "GET /img/**.svg"},
+			{"POST", "http://CDN.example.com:8080/upload/file", "cdn.example.com/**"},
+			{"OPTIONS", "http://www.example.com/api/users", "OPTIONS **"},
+		} {
+			payload := cacheRequest(c.url, "", map[string][]string{}, "")
+			payload.Method = c.method
+			rule := matchBypassRule(rules, payload)
+			if assert.NotNil(t, rule, c.url) {
+				assert.Equal(t, c.rule, rule.Rule, c.url)
+			}
+		}
+	})
+
+	t.Run("not happy path: the other requests", func(t *testing.T) {
+		for _, c := range []struct {
+			method string
+			url    string
+		}{
+			{"POST", "http://www.example.com/static/app.css"},
+			{"GET", "http://www.example.com/static/app.js"},
+			{"GET", "http://www.example.com/api/app.css"},
+			{"GET", "http://www.example.com/static/app.css.php"},
+			// * does not cross the path segments
+			{"GET", "http://www.example.com/static/css/app.css"},
+			{"GET", "http://www.example.com/img/logo.png"},
+			{"GET", "http://www.example.com/static/admin/..%2fapp.css"},
+			// the query args are not static content
+			{"HEAD", "http://www.example.com/static/app.css?v=12"},
+			{"GET", "http://www.example.com/static/app.css?"},
+			// the paths that may not be the file they look like
+			{"GET", "http://www.example.com/static/../admin/app.css"},
+			{"GET", "http://www.example.com/static/%2e%2e/admin/app.css"},
+			{"GET", "http://www.example.com/static/..;/admin/app.css"},
+			{"GET", "http://www.example.com/static//app.css"},
+			{"GET", "http://www.example.com/static/..%5cadmin.png"},
+		} {
+			payload := cacheRequest(c.url, "", map[string][]string{}, "")
+			payload.Method = c.method
+			assert.Nil(t, matchBypassRule(rules, payload), c.url)
+		}
+
+		// nor the body
+		payload := cacheRequest("http://cdn.example.com/upload/file", "", map[string][]string{}, "id=1' or 1=1")
+		payload.Method = "POST"
+		assert.Nil(t, matchBypassRule(rules, payload))
+	})
+}
+
+func TestWafBypass(t *testing.T) {
+	newEngine := func(t *testing.T, config map[string]string) (*WafEngineImpl, *bytes.Buffer, *countingPlugin) {
+		output := &bytes.Buffer{}
+		we := newParallelEngine(t, config, output)
+		p := &countingPlugin{name: "a"}
+		we.RegisterPlugin(p)
+		return we, output, p
+	}
+
+	t.Run("happy path: the plugins are skipped", func(t *testing.T) {
+		we, output, p := newEngine(t, map[string]string{"plugin_a": "enabled", "bypass_rules": "GET /static/*"})
+		payload := cacheRequest("http://www.example.com/static/app.css", "", nil, "")
+		assert.Equal(t, VERDICT_PASS, we.Scan(context.Background(), payload))
+		assert.Equal(t, "pass bypass \n", output.String())
+		assert.Equal(t, "GET /static/*", payload.Variables[VARIABLE_BYPASS_RULE])
+		assert.Equal(t, int64(0), p.scans.Load())
+
+		assert.Equal(t, VERDICT_BLOCK, we.Scan(context.Background(), cacheRequest("http://www.example.com/api", "", nil, "")))
+		assert.Equal(t, int64(1), p.scans.Load())
+	})
+
+	t.Run("not happy path: the deny list still applies", func(t *testing.T) {
+		we, output, _ := newEngine(t, map[string]string{
+			"plugin_a":     "enabled",
+			"bypass_rules": "GET /static/*",
+			"denylist":     "1.2.3.0/24",
+		})
+		assert.Equal(t, VERDICT_BLOCK, we.Scan(context.Background(), cacheRequest("http://www.example.com/static/app.css", "1.2.3.4", nil, "")))
+		assert.Equal(t, "blocked denylist \n", output.String())
+	})
+
+	t.Run("happy path: the rules can be reset", func(t *testing.T) {
+		c := &DbServiceConfigMock{config: map[string]string{"bypass_rules": "GET /static/*"}}
+		wc, err := NewWafConfig(c)
+		assert.Nil(t, err)
+		assert.Equal(t, 1, len(wc.BypassRules))
+
+		delete(c.config, "bypass_rules")
+		wc.NotifyDbChange("bypass_rules")
+		assert.Equal(t, 0, len(wc.BypassRules))
+	})
+}
@@ -0,0 +1,340 @@
+package engine
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net"
+	"net/textproto"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nzin/taxsi2/internal/com"
+	"github.com/nzin/taxsi2/internal/db"
+)
+
+const (
+	// output variable (hit or miss, when the cache is enabled)
+	VARIABLE_VERDICT_CACHE = "verdict_cache"
+
+	DEFAULT_VERDICT_CACHE_TTL  = 5 * time.Second
+	DEFAULT_VERDICT_CACHE_SIZE = 10000
+)
+
+// the headers that change for identical requests (they are not in the key)
+var verdictCacheIgnoredHeaders = map[string]bool{
+	"Date":              true,
+	"If-Modified-Since": true,
+	"If-None-Match":     true,
+	"X-Request-Id":      true,
+	"X-Correlation-Id":  true,
+	"X-Amzn-Trace-Id":   true,
+	"Traceparent":       true,
+	"Tracestate":        true,
+	"X-B3-Traceid":      true,
+	"X-B3-Spanid":       true,
+	"X-B3-Parentspanid": true,
+	"X-B3-Sampled":      true,
+}
+
+/*
+WafEngineCacheablePlugin is implemented by the plugins whose verdict can
+change for identical requests (like the ratelimiter, that counts them):
+when Cacheable returns false, they run even when the verdict cache hits
+*/
+type WafEngineCacheablePlugin interface {
+	WafEnginePlugin
+	Cacheable() bool
+}
+
+/*
+WafEngineIPDependentPlugin is implemented by the plugins whose verdict
+depends on the exact client ip (like the botmanager, that verifies the
+crawlers by reverse DNS): when IPDependent returns true, the verdict
+cache key uses the client ip instead of its /24 or /64 network
+*/
+type WafEngineIPDependentPlugin interface {
+	WafEnginePlugin
+	IPDependent() bool
+}
+
+type verdictCacheEntry struct {
+	key string
+	// the verdicts of the cacheable plugins (the others pass)
+	verdicts  []Verdict
+	variables map[string]string
+	// the claims of the bearer token (used by the plugins that are not
+	// cacheable, like the ratelimiter)
+	claims map[string]string
+	// the expiration of the bearer token (zero if none)
+	notAfter time.Time
+	expires  time.Time
+}
+
+/*
+WafVerdictCache is a LRU cache of the plugin verdicts, for the requests
+that are repeated (health checks, static assets, polling clients...).
+It is flushed on any database change (config, rules, ...)
+*/
+type WafVerdictCache struct {
+	config  *WafConfig
+	now     func() time.Time
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	lru     *list.List
+	hits    int64
+	misses  int64
+}
+
+func NewWafVerdictCache(ds db.DbServiceSubscriber, config *WafConfig) *WafVerdictCache {
+	wc := &WafVerdictCache{
+		config:  config,
+		now:     time.Now,
+		entries: make(map[string]*list.Element),
+		lru:     list.New(),
+	}
+	for _, table := range db.CHANGELOG_TABLES {
+		ds.SubscribeChanges(table, wc)
+	}
+	return wc
+}
+
+// NotifyDbChange flushes the cache
+func (wc *WafVerdictCache) NotifyDbChange(key string) {
+	wc.Flush()
+}
+
+func (wc *WafVerdictCache) Flush() {
+	wc.mu.Lock()
+	defer wc.mu.Unlock()
+	wc.entries = make(map[string]*list.Element)
+	wc.lru.Init()
+}
+
+func (wc *WafVerdictCache) get(key string) (*verdictCacheEntry, bool) {
+	wc.mu.Lock()
+	defer wc.mu.Unlock()
+	if e, ok := wc.entries[key]; ok {
+		entry := e.Value.(*verdictCacheEntry)
+		if wc.now().Before(entry.expires) {
+			wc.lru.MoveToFront(e)
+			wc.hits++
+			return entry, true
+		}
+		wc.lru.Remove(e)
+		delete(wc.entries, key)
+	}
+	wc.misses++
+	return nil, false
+}
+
+func (wc *WafVerdictCache) put(entry *verdictCacheEntry) {
+	wc.mu.Lock()
+	defer wc.mu.Unlock()
+	config := wc.config.Settings()
+	entry.expires = wc.now().Add(config.VerdictCacheTTL)
+	if !entry.notAfter.IsZero() && entry.notAfter.Before(entry.expires) {
+		entry.expires = entry.notAfter
+	}
+	if e, ok := wc.entries[entry.key]; ok {
+		e.Value = entry
+		wc.lru.MoveToFront(e)
+		return
+	}
+	wc.entries[entry.key] = wc.lru.PushFront(entry)
+	for wc.lru.Len() > config.VerdictCacheSize {
+		oldest := wc.lru.Back()
+		wc.lru.Remove(oldest)
+		delete(wc.entries, oldest.Value.(*verdictCacheEntry).key)
+	}
+}
+
+/*
+Stats returns the hit and miss counters, and the number of entries
+*/
+func (wc *WafVerdictCache) Stats() (hits int64, misses int64, size int) {
+	wc.mu.Lock()
+	defer wc.mu.Unlock()
+	return wc.hits, wc.misses, wc.lru.Len()
+}
+
+/*
+verdictCacheKey hashes the normalized request: method, host, path, args,
+headers (but the volatile ones, see verdictCacheIgnoredHeaders), body
+and client IP class (its /24 or /64 network), or client IP with exactIP
+*/
+func verdictCacheKey(payload *com.TaxsiCom, exactIP bool) string {
+	h := sha256.New()
+	write := func(s ...string) {
+		for _, v := range s {
+			h.Write([]byte(v))
+			h.Write([]byte{0})
+		}
+	}
+
+	write(strings.ToUpper(payload.Method), strings.ToLower(payload.Url.Host), payload.Url.EscapedPath())
+	// (Encode sorts the args by name)
+	write(payload.Url.Query().Encode())
+
+	names := []string{}
+	for name := range payload.Headers {
+		if !verdictCacheIgnoredHeaders[textproto.CanonicalMIMEHeaderKey(name)] {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		write(textproto.CanonicalMIMEHeaderKey(name))
+		write(payload.Headers[name]...)
+	}
+	// (the header order is a fingerprint of the client)
+	write("order")
+	for _, name := range payload.HeaderOrder {
+		if !verdictCacheIgnoredHeaders[textproto.CanonicalMIMEHeaderKey(name)] {
+			write(name)
+		}
+	}
+
+	body := sha256.Sum256(payload.Body)
+	write(string(body[:]))
+	if exactIP {
+		write("ip", payload.RemoteAddr)
+	} else {
+		write("class", ipClass(payload.RemoteAddr))
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func ipClass(remoteAddr string) string {
+	ip := net.ParseIP(remoteAddr)
+	if ip == nil {
+		return ""
+	}
+	if v4 := ip.To4(); v4 != nil {
+		return v4.Mask(net.CIDRMask(24, 32)).String()
+	}
+	return ip.Mask(net.CIDRMask(64, 128)).String()
+}
+
+/*
+claimsExpiry returns the time of the exp claim (zero if there is none)
+*/
+func claimsExpiry(claims map[string]string) time.Time {
+	exp, ok := claims["exp"]
+	if !ok {
+		return time.Time{}
+	}
+	seconds, err := strconv.ParseFloat(exp, 64)
+	if err != nil {
+		// (not a valid token, don't cache it)
+		return time.Unix(0, 0)
+	}
+	return time.Unix(int64(seconds), 0)
+}
+
+/*
+exactIP is true when the verdicts depend on the exact client ip: an
+ip dependent plugin is enabled, or the request has a challenge clearance
+(that is bound to the client ip, see WafChallenge)
+*/
+func (we *WafEngineImpl) exactIP(payload *com.TaxsiCom, names []string) bool {
+	if _, ok := payload.GetCookie(CHALLENGE_COOKIE); ok {
+		return true
+	}
+	for _, name := range names {
+		if p, ok := we.plugins[name].(WafEngineIPDependentPlugin); ok && p.IPDependent() {
+			return true
+		}
+	}
+	return false
+}
+
+func (we *WafEngineImpl) cacheable(name string) bool {
+	if p, ok := we.plugins[name].(WafEngineCacheablePlugin); ok {
+		return p.Cacheable()
+	}
+	return true
+}
+
+/*
+scanCached is scanPlugins, with the verdict cache: on a hit, only the
+plugins that are not cacheable run (the variables and the claims of the
+cached scan are restored). A cached scan never outlives the exp claim of
+the bearer token (the token is part of the key). A scan is cached when it is complete (its first non pass
+verdict, with stopOnBlock, comes from a cacheable plugin) and when no
+plugin failed (timeout, panic)
+*/
+func (we *WafEngineImpl) scanCached(ctx context.Context, payload *com.TaxsiCom, names []string, stopOnBlock bool) []Verdict {
+	if we.cache == nil || !we.config.Settings().VerdictCache {
+		return we.scanPlugins(ctx, payload, names, stopOnBlock)
+	}
+
+	key := verdictCacheKey(payload, we.exactIP(payload, names))
+	if entry, ok := we.cache.get(key); ok && len(entry.verdicts) == len(names) {
+		for k, v := range entry.variables {
+			payload.SetVariable(k, v)
+		}
+		if entry.claims != nil {
+			payload.SetClaims(entry.claims)
+		}
+		payload.SetVariable(VARIABLE_VERDICT_CACHE, "hit")
+
+		verdicts := make([]Verdict, len(names))
+		copy(verdicts, entry.verdicts)
+		indexes := []int{}
+		uncacheable := []string{}
+		for i, name := range names {
+			if stopOnBlock && entry.verdicts[i] != VERDICT_PASS {
+				break
+			}
+			if !we.cacheable(name) {
+				indexes = append(indexes, i)
+				uncacheable = append(uncacheable, name)
+			}
+		}
+		for k, verdict := range we.scanPlugins(ctx, payload, uncacheable, stopOnBlock) {
+			verdicts[indexes[k]] = verdict
+		}
+		return verdicts
+	}
+
+	verdicts := we.scanPlugins(ctx, payload, names, stopOnBlock)
+	variables := payload.CopyVariables()
+	payload.SetVariable(VARIABLE_VERDICT_CACHE, "miss")
+	if variables[VARIABLE_SCAN_ERROR] != "" {
+		return verdicts
+	}
+	entry := &verdictCacheEntry{
+		key:       key,
+		verdicts:  make([]Verdict, len(names)),
+		variables: variables,
+		claims:    payload.Claims,
+		notAfter:  claimsExpiry(payload.Claims),
+	}
+	for i, name := range names {
+		if !we.cacheable(name) {
+			if stopOnBlock && verdicts[i] != VERDICT_PASS {
+				// (the next plugins did not run)
+				return verdicts
+			}
+			continue
+		}
+		entry.verdicts[i] = verdicts[i]
+		if stopOnBlock && verdicts[i] != VERDICT_PASS {
+			break
+		}
+	}
+	we.cache.put(entry)
+	return verdicts
+}
+
+func (we *WafEngineImpl) VerdictCacheStats() (hits int64, misses int64, size int) {
+	if we.cache == nil {
+		return 0, 0, 0
+	}
+	return we.cache.Stats()
+}
@@ -0,0 +1,382 @@
+package engine
+
+import (
+	"bytes"
+	"context"
+	"net/url"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/nzin/taxsi2/internal/com"
+	"github.com/nzin/taxsi2/internal/db"
+	"github.com/stretchr/testify/assert"
+)
+
+/*
+ * This is a mock implementation of the db.DbServiceSubscriber interface
+ */
+type DbServiceSubscriberMock struct {
+	listeners map[int][]db.DbChangeListener
+}
+
+func (m *DbServiceSubscriberMock) SubscribeChanges(table int, listener db.DbChangeListener) {
+	if m.listeners == nil {
+		m.listeners = make(map[int][]db.DbChangeListener)
+	}
+	m.listeners[table] = append(m.listeners[table], listener)
+}
+
+/*
+ * This is a mock implementation of a plugin that counts its scans
+ */
+type countingPlugin struct {
+	name        string
+	pass        bool
+	cacheable   bool
+	ipDependent bool
+	scans       atomic.Int64
+}
+
+func (p *countingPlugin) Name() string {
+	return p.name
+}
+func (p *countingPlugin) Cacheable() bool {
+	return p.cacheable
+}
+func (p *countingPlugin) IPDependent() bool {
+	return p.ipDependent
+}
+func (p *countingPlugin) Scan(ctx context.Context, payload *com.TaxsiCom) bool {
+	p.scans.Add(1)
+	payload.SetVariable(p.name, "scanned")
+	return p.pass
+}
+
+/*
+ * This is a mock implementation of the jwt plugin (cacheable), and of a
+ * plugin that uses its claims (not cacheable, like the ratelimiter)
+ */
+type tokenPlugin struct {
+	exp time.Time
+}
+
+func (p *tokenPlugin) Name() string {
+	return "token"
+}
+func (p *tokenPlugin) Cacheable() bool {
+	return true
+}
+func (p *tokenPlugin) Scan(ctx context.Context, payload *com.TaxsiCom) bool {
+	if time.Now().After(p.exp) {
+		return false
+	}
+	payload.SetClaims(map[string]string{
+		"sub": payload.GetHeader("Authorization")[0],
+		"exp": strconv.FormatInt(p.exp.Unix(), 10),
+	})
+	return true
+}
+
+type subjectPlugin struct {
+	subjects []string
+}
+
+func (p *subjectPlugin) Name() string {
+	return "subject"
+}
+func (p *subjectPlugin) Cacheable() bool {
+	return false
+}
+func (p *subjectPlugin) Scan(ctx context.Context, payload *com.TaxsiCom) bool {
+	p.subjects = append(p.subjects, payload.Claims["sub"])
+	return true
+}
+
+func cacheRequest(rawurl string, remoteAddr string, headers map[string][]string, body string) *com.TaxsiCom {
+	u, _ := url.Parse(rawurl)
+	if headers == nil {
+		headers = map[string][]string{"Host": {u.Host}}
+	}
+	return &com.TaxsiCom{Method: "GET", Url: u, RemoteAddr: remoteAddr, Headers: headers, Body: []byte(body)}
+}
+
+func TestVerdictCacheKey(t *testing.T) {
+	key := verdictCacheKey(cacheRequest("http://www.example.com/a?x=1&y=2", "1.2.3.4", nil, ""), false)
+
+	t.Run("happy path: the identical requests", func(t *testing.T) {
+		for _, payload := range []*com.TaxsiCom{
+			cacheRequest("http://www.example.com/a?x=1&y=2", "1.2.3.4", nil, ""),
+			cacheRequest("http://WWW.example.com/a?y=2&x=1", "1.2.3.4", map[string][]string{"Host": {"www.example.com"}}, ""),
+			// same /24
+			cacheRequest("http://www.example.com/a?x=1&y=2", "1.2.3.200", nil, ""),
+			// volatile headers
+			cacheRequest("http://www.example.com/a?x=1&y=2", "1.2.3.4", map[string][]string{
+				"Host":         {"www.example.com"},
+				"X-Request-Id": {"1234"},
+				"date":         {"Mon, 19 Oct 2026 10:00:00 GMT"},
+			}, ""),
+		} {
+			assert.Equal(t, key, verdictCacheKey(payload, false), payload.Url.String())
+		}
+	})
+
+	t.Run("not happy path: the different requests", func(t *testing.T) {
+		for _, payload := range []*com.TaxsiCom{
+			cacheRequest("http://www.example.com/b?x=1&y=2", "1.2.3.4", nil, ""),
+			cacheRequest("http://www.example.com/a?x=1&y=3", "1.2.3.4", nil, ""),
+			cacheRequest("http://www.example.com/a?x=1&y=2", "1.2.4.4", nil, ""),
+			cacheRequest("http://www.example.com/a?x=1&y=2", "1.2.3.4", nil, "body"),
+			cacheRequest("http://www.example.com/a?x=1&y=2", "1.2.3.4", map[string][]string{
+				"Host":   {"www.example.com"},
+				"Cookie": {"session=1"},
+			}, ""),
+		} {
+			assert.NotEqual(t, key, verdictCacheKey(payload, false), payload.Url.String())
+		}
+		payload := cacheRequest("http://www.example.com/a?x=1&y=2", "1.2.3.4", nil, "")
+		payload.Method = "POST"
+		assert.NotEqual(t, key, verdictCacheKey(payload, false))
+	})
+
+	t.Run("happy path: ip classes", func(t *testing.T) {
+		assert.Equal(t, "1.2.3.0", ipClass("1.2.3.4"))
+		assert.Equal(t, "2001:db8:1:2::", ipClass("2001:db8:1:2:3:4:5:6"))
+		assert.Equal(t, "", ipClass("unknown"))
+	})
+
+	t.Run("not happy path: the exact ip", func(t *testing.T) {
+		exact := verdictCacheKey(cacheRequest("http://www.example.com/a?x=1&y=2", "1.2.3.4", nil, ""), true)
+		assert.NotEqual(t, key, exact)
+		assert.Equal(t, exact, verdictCacheKey(cacheRequest("http://www.example.com/a?x=1&y=2", "1.2.3.4", nil, ""), true))
+		assert.NotEqual(t, exact, verdictCacheKey(cacheRequest("http://www.example.com/a?x=1&y=2", "1.2.3.200", nil, ""), true))
+		// (the address of the network is not its class)
+		assert.NotEqual(t, key, verdictCacheKey(cacheRequest("http://www.example.com/a?x=1&y=2", "1.2.3.0", nil, ""), true))
+	})
+}
+
+func TestWafVerdictCache(t *testing.T) {
+	newCache := func(t *testing.T, config map[string]string) (*WafVerdictCache, *DbServiceSubscriberMock) {
+		wc, err := NewWafConfig(&DbServiceConfigMock{config: config})
+		assert.Nil(t, err)
+		ds := &DbServiceSubscriberMock{}
+		return NewWafVerdictCache(ds, wc), ds
+	}
+
+	t.Run("happy path: least recently used entries are evicted", func(t *testing.T) {
+		cache, _ := newCache(t, map[string]string{"verdict_cache_size": "2"})
+		cache.put(&verdictCacheEntry{key: "a"})
+		cache.put(&verdictCacheEntry{key: "b"})
+		_, ok := cache.get("a")
+		assert.True(t, ok)
+		cache.put(&verdictCacheEntry{key: "c"})
+
+		_, ok = cache.get("b")
+		assert.False(t, ok)
+		_, ok = cache.get("a")
+		assert.True(t, ok)
+		_, ok = cache.get("c")
+		assert.True(t, ok)
+
+		hits, misses, size := cache.Stats()
+		assert.Equal(t, int64(3), hits)
+		assert.Equal(t, int64(1), misses)
+		assert.Equal(t, 2, size)
+	})
+
+	t.Run("not happy path: entries expire", func(t *testing.T) {
+		cache, _ := newCache(t, map[string]string{"verdict_cache_ttl": "10s"})
+		now := time.Now()
+		cache.now = func() time.Time { return now }
+		cache.put(&verdictCacheEntry{key: "a"})
+
+		now = now.Add(9 * time.Second)
+		_, ok := cache.get("a")
+		assert.True(t, ok)
+		now = now.Add(1 * time.Second)
+		_, ok = cache.get("a")
+		assert.False(t, ok)
+		_, _, size := cache.Stats()
+		assert.Equal(t, 0, size)
+	})
+
+	t.Run("happy path: any db change flushes the cache", func(t *testing.T) {
+		cache, ds := newCache(t, map[string]string{})
+		assert.Equal(t, len(db.CHANGELOG_TABLES), len(ds.listeners))
+		for _, table := range []int{db.CHANGELOG_TABLE_CONFIG, db.CHANGELOG_TABLE_RULE} {
+			cache.put(&verdictCacheEntry{key: "a"})
+			ds.listeners[table][0].NotifyDbChange("foo")
+			_, ok := cache.get("a")
+			assert.False(t, ok)
+		}
+	})
+}
+
+func TestWafEngineCache(t *testing.T) {
+	newEngine := func(t *testing.T, config map[string]string) (*WafEngineImpl, *bytes.Buffer) {
+		output := &bytes.Buffer{}
+		we := newParallelEngine(t, config, output)
+		we.cache = NewWafVerdictCache(&DbServiceSubscriberMock{}, we.config)
+		return we, output
+	}
+
+	t.Run("happy path: the cacheable plugins are not run again", func(t *testing.T) {
+		we, _ := newEngine(t, map[string]string{"verdict_cache": "enabled", "plugin_a": "enabled", "plugin_b": "enabled"})
+		a := &countingPlugin{name: "a", pass: true, cacheable: true}
+		b := &countingPlugin{name: "b", pass: true}
+		we.RegisterPlugin(a)
+		we.RegisterPlugin(b)
+
+		payload := cacheRequest("http://www.example.com/healthz", "", nil, "")
+		assert.Equal(t, VERDICT_PASS, we.Scan(context.Background(), payload))
+		assert.Equal(t, "miss", payload.Variables[VARIABLE_VERDICT_CACHE])
+		for i := 0; i < 3; i++ {
+			payload = cacheRequest("http://www.example.com/healthz", "", nil, "")
+			assert.Equal(t, VERDICT_PASS, we.Scan(context.Background(), payload))
+			assert.Equal(t, "hit", payload.Variables[VARIABLE_VERDICT_CACHE])
+			// the variables are restored
+			assert.Equal(t, "scanned", payload.Variables["a"])
+		}
+		assert.Equal(t, int64(1), a.scans.Load())
+		assert.Equal(t, int64(4), b.scans.Load())
+
+		hits, misses, size := we.VerdictCacheStats()
+		assert.Equal(t, int64(3), hits)
+		assert.Equal(t, int64(1), misses)
+		assert.Equal(t, 1, size)
+	})
+
+	t.Run("not happy path: a cached block", func(t *testing.T) {
+		we, output := newEngine(t, map[string]string{"verdict_cache": "enabled", "plugin_a": "enabled", "plugin_b": "enabled"})
+		a := &countingPlugin{name: "a", cacheable: true}
+		b := &countingPlugin{name: "b", pass: true}
+		we.RegisterPlugin(a)
+		we.RegisterPlugin(b)
+
+		for i := 0; i < 2; i++ {
+			assert.Equal(t, VERDICT_BLOCK, we.Scan(context.Background(), cacheRequest("http://www.example.com/?id=1'", "", nil, "")))
+		}
+		assert.Equal(t, "blocked a \nblocked a \n", output.String())
+		assert.Equal(t, int64(1), a.scans.Load())
+		// (b is after a)
+		assert.Equal(t, int64(0), b.scans.Load())
+	})
+
+	t.Run("not happy path: a block of a plugin that is not cacheable", func(t *testing.T) {
+		we, _ := newEngine(t, map[string]string{"verdict_cache": "enabled", "plugin_a": "enabled", "plugin_b": "enabled"})
+		a := &countingPlugin{name: "a"}
+		b := &countingPlugin{name: "b", pass: true, cacheable: true}
+		we.RegisterPlugin(a)
+		we.RegisterPlugin(b)
+
+		for i := 0; i < 2; i++ {
+			assert.Equal(t, VERDICT_BLOCK, we.Scan(context.Background(), cacheRequest("http://www.example.com/", "", nil, "")))
+		}
+		// b did not run, the scan is not cached
+		_, _, size := we.VerdictCacheStats()
+		assert.Equal(t, 0, size)
+	})
+
+	t.Run("not happy path: the failed scans are not cached", func(t *testing.T) {
+		we, _ := newEngine(t, map[string]string{"verdict_cache": "enabled", "plugin_panicking": "enabled"})
+		we.RegisterPlugin(&panickingPlugin{})
+		assert.Equal(t, VERDICT_PASS, we.Scan(context.Background(), cacheRequest("http://www.example.com/", "", nil, "")))
+		_, _, size := we.VerdictCacheStats()
+		assert.Equal(t, 0, size)
+	})
+
+	t.Run("happy path: the scores are cached", func(t *testing.T) {
+		we, output := newEngine(t, map[string]string{
+			"verdict_cache":     "enabled",
+			"scoring_mode":      "enabled",
+			"scoring_threshold": "20",
+			"plugin_a":          "enabled",
+			"plugin_b":          "enabled",
+		})
+		a := &countingPlugin{name: "a", cacheable: true}
+		b := &countingPlugin{name: "b"}
+		we.RegisterPlugin(a)
+		we.RegisterPlugin(b)
+		for i := 0; i < 2; i++ {
+			assert.Equal(t, VERDICT_BLOCK, we.Scan(context.Background(), cacheRequest("http://www.example.com/", "", nil, "")))
+		}
+		assert.Equal(t, "blocked score a:10,b:10\nblocked score a:10,b:10\n", output.String())
+		assert.Equal(t, int64(1), a.scans.Load())
+		assert.Equal(t, int64(2), b.scans.Load())
+	})
+
+	t.Run("happy path: the claims are cached", func(t *testing.T) {
+		we, _ := newEngine(t, map[string]string{"verdict_cache": "enabled", "plugin_token": "enabled", "plugin_subject": "enabled"})
+		subject := &subjectPlugin{}
+		we.RegisterPlugin(&tokenPlugin{exp: time.Now().Add(time.Hour)})
+		we.RegisterPlugin(subject)
+
+		for _, user := range []string{"alice", "bob", "alice", "bob"} {
+			payload := cacheRequest("http://www.example.com/api", "", map[string][]string{"Authorization": {user}}, "")
+			assert.Equal(t, VERDICT_PASS, we.Scan(context.Background(), payload))
+		}
+		assert.Equal(t, []string{"alice", "bob", "alice", "bob"}, subject.subjects)
+		hits, _, _ := we.VerdictCacheStats()
+		assert.Equal(t, int64(2), hits)
+	})
+
+	t.Run("not happy path: a cached scan does not outlive the token", func(t *testing.T) {
+		we, _ := newEngine(t, map[string]string{"verdict_cache": "enabled", "verdict_cache_ttl": "1m", "plugin_token": "enabled"})
+		token := &tokenPlugin{exp: time.Now().Add(time.Second)}
+		we.RegisterPlugin(token)
+
+		now := time.Now()
+		we.cache.now = func() time.Time { return now }
+		request := func() *com.TaxsiCom {
+			return cacheRequest("http://www.example.com/api", "", map[string][]string{"Authorization": {"alice"}}, "")
+		}
+		assert.Equal(t, VERDICT_PASS, we.Scan(context.Background(), request()))
+		assert.Equal(t, VERDICT_PASS, we.Scan(context.Background(), request()))
+
+		// the token expired
+		now = now.Add(2 * time.Second)
+		token.exp = time.Now().Add(-time.Second)
+		assert.Equal(t, VERDICT_BLOCK, we.Scan(context.Background(), request()))
+	})
+
+	t.Run("not happy path: an ip dependent plugin", func(t *testing.T) {
+		we, _ := newEngine(t, map[string]string{"verdict_cache": "enabled", "plugin_a": "enabled"})
+		a := &countingPlugin{name: "a", pass: true, cacheable: true, ipDependent: true}
+		we.RegisterPlugin(a)
+		for _, remoteAddr := range []string{"1.2.3.4", "1.2.3.200", "1.2.3.4"} {
+			assert.Equal(t, VERDICT_PASS, we.Scan(context.Background(), cacheRequest("http://www.example.com/", remoteAddr, nil, "")))
+		}
+		// (same /24, but not the same ip)
+		assert.Equal(t, int64(2), a.scans.Load())
+	})
+
+	t.Run("not happy path: a challenge clearance", func(t *testing.T) {
+		we, _ := newEngine(t, map[string]string{"verdict_cache": "enabled", "plugin_a": "enabled"})
+		a := &countingPlugin{name: "a", pass: true, cacheable: true}
+		we.RegisterPlugin(a)
+		headers := map[string][]string{"Host": {"www.example.com"}, "Cookie": {CHALLENGE_COOKIE + "=clearance"}}
+		for _, remoteAddr := range []string{"1.2.3.4", "1.2.3.200", "1.2.3.4"} {
+			assert.Equal(t, VERDICT_PASS, we.Scan(context.Background(), cacheRequest("http://www.example.com/", remoteAddr, headers, "")))
+		}
+		assert.Equal(t, int64(2), a.scans.Load())
+
+		// without clearance, the /24 shares the verdicts
+		for _, remoteAddr := range []string{"1.2.3.4", "1.2.3.200"} {
+			assert.Equal(t, VERDICT_PASS, we.Scan(context.Background(), cacheRequest("http://www.example.com/", remoteAddr, nil, "")))
+		}
+		assert.Equal(t, int64(3), a.scans.Load())
+	})
+
+	t.Run("happy path: the cache is disabled", func(t *testing.T) {
+		we, _ := newEngine(t, map[string]string{"plugin_a": "enabled"})
+		a := &countingPlugin{name: "a", pass: true, cacheable: true}
+		we.RegisterPlugin(a)
+		for i := 0; i < 2; i++ {
+			payload := cacheRequest("http://www.example.com/", "", nil, "")
+			assert.Equal(t, VERDICT_PASS, we.Scan(context.Background(), payload))
+			assert.Equal(t, "", payload.Variables[VARIABLE_VERDICT_CACHE])
+		}
+		assert.Equal(t, int64(2), a.scans.Load())
+	})
+}
@@ -0,0 +1,182 @@
+package engine
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"html/template"
+	"math/bits"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nzin/taxsi2/internal/com"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// name of the clearance cookie
+	CHALLENGE_COOKIE = "taxsi_clearance"
+)
+
+/*
+Challenge is what the proxy needs to serve the interstitial page.
+The token is "<expires>.<difficulty>.<signature>", the page looks for a
+nonce such that sha256("<token>.<nonce>") starts with difficulty zero
+bits, and sets the "<token>.<nonce>" clearance cookie
+*/
+type Challenge struct {
+	Cookie     string
+	Token      string
+	Difficulty int
+	Page       string
+}
+
+type WafChallenge struct {
+	config *WafConfig
+	now    func() time.Time
+	// used when no challenge_keys is configured (only valid on this node)
+	localKey []byte
+}
+
+var challengePage = template.Must(template.New("challenge").Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>Checking your browser</title></head>
+<body>
+<p>Checking your browser, please wait...</p>
+<noscript>Please enable JavaScript to continue.</noscript>
+<script>
+(async function() {
+  const token = {{.Token}};
+  const difficulty = {{.Difficulty}};
+  const encoder = new TextEncoder();
+  for (let nonce = 0; ; nonce++) {
+    const digest = new Uint8Array(await crypto.subtle.digest("SHA-256", encoder.encode(token + "." + nonce)));
+    let zeros = 0;
+    for (const b of digest) {
+      if (b === 0) { zeros += 8; continue; }
+      zeros += Math.clz32(b) - 24;
+      break;
+    }
+    if (zeros >= difficulty) {
+      document.cookie = {{.Cookie}} + "=" + token + "." + nonce + "; path=/; max-age=" + {{.MaxAge}} + "; SameSite=Lax";
+      window.location.reload();
+      return;
+    }
+  }
+})();
+</script>
+</body>
+</html>
+`))
+
+func NewWafChallenge(config *WafConfig) *WafChallenge {
+	localKey := make([]byte, 32)
+	if _, err := rand.Read(localKey); err != nil {
+		panic(err)
+	}
+	return &WafChallenge{
+		config:   config,
+		now:      time.Now,
+		localKey: localKey,
+	}
+}
+
+func (wc *WafChallenge) keys() [][]byte {
+	if keys := wc.config.Settings().ChallengeKeys; len(keys) > 0 {
+		return keys
+	}
+	return [][]byte{wc.localKey}
+}
+
+func sign(key []byte, remoteAddr string, expires int64, difficulty int) string {
+	mac := hmac.New(sha256.New, key)
+	fmt.Fprintf(mac, "%d.%d.%s", expires, difficulty, remoteAddr)
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+/*
+NewChallenge creates a challenge for the client of a request
+(the clearance is bound to its ip)
+*/
+func (wc *WafChallenge) NewChallenge(payload *com.TaxsiCom) *Challenge {
+	config := wc.config.Settings()
+	if len(config.ChallengeKeys) == 0 {
+		logrus.Warn("no challenge_keys configured, the clearance cookies are only valid on this node")
+	}
+
+	ttl := config.ChallengeTTL
+	expires := wc.now().Add(ttl).Unix()
+	difficulty := config.ChallengeDifficulty
+	token := fmt.Sprintf("%d.%d.%s", expires, difficulty, sign(wc.keys()[0], payload.RemoteAddr, expires, difficulty))
+
+	c := &Challenge{
+		Cookie:     CHALLENGE_COOKIE,
+		Token:      token,
+		Difficulty: difficulty,
+	}
+
+	var page bytes.Buffer
+	err := challengePage.Execute(&page, map[string]interface{}{
+		"Cookie":     c.Cookie,
+		"Token":      c.Token,
+		"Difficulty": c.Difficulty,
+		"MaxAge":     int(ttl.Seconds()),
+	})
+	if err != nil {
+		logrus.Errorf("unable to render the challenge page: %v", err)
+	}
+	c.Page = page.String()
+	return c
+}
+
+/*
+Verify checks the clearance cookie of a request: signature, expiration,
+client ip and proof-of-work
+*/
+func (wc *WafChallenge) Verify(payload *com.TaxsiCom) bool {
+	value, ok := payload.GetCookie(CHALLENGE_COOKIE)
+	if !ok {
+		return false
+	}
+	parts := strings.Split(value, ".")
+	if len(parts) != 4 {
+		return false
+	}
+	expires, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil || wc.now().Unix() >= expires {
+		return false
+	}
+	difficulty, err := strconv.Atoi(parts[1])
+	// the difficulty may have been raised since
+	if err != nil || difficulty < wc.config.Settings().ChallengeDifficulty {
+		return false
+	}
+
+	signed := false
+	for _, key := range wc.keys() {
+		if hmac.Equal([]byte(parts[2]), []byte(sign(key, payload.RemoteAddr, expires, difficulty))) {
+			signed = true
+			break
+		}
+	}
+	if !signed {
+		return false
+	}
+
+	return leadingZeroBits(sha256.Sum256([]byte(value))) >= difficulty
+}
+
+func leadingZeroBits(digest [sha256.Size]byte) int {
+	n := 0
+	for _, b := range digest {
+		if b != 0 {
+			return n + bits.LeadingZeros8(b)
+		}
+		n += 8
+	}
+	return n
+}
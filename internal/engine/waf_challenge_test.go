@@ -0,0 +1,142 @@
+package engine
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"html/template"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/nzin/taxsi2/internal/com"
+	"github.com/stretchr/testify/assert"
+)
+
+type challengingPlugin struct{}
+
+func (p *challengingPlugin) Name() string {
+	return "challenging"
+}
+func (p *challengingPlugin) Scan(ctx context.Context, payload *com.TaxsiCom) bool {
+	return false
+}
+func (p *challengingPlugin) ScanVerdict(ctx context.Context, payload *com.TaxsiCom) Verdict {
+	return VERDICT_CHALLENGE
+}
+
+/*
+solve does what the interstitial page does
+*/
+func solve(c *Challenge) string {
+	for nonce := 0; ; nonce++ {
+		value := fmt.Sprintf("%s.%d", c.Token, nonce)
+		if leadingZeroBits(sha256.Sum256([]byte(value))) >= c.Difficulty {
+			return value
+		}
+	}
+}
+
+func challengeRequest(remoteAddr string, cookie string) *com.TaxsiCom {
+	u, _ := url.Parse("http://www.example.com/")
+	payload := &com.TaxsiCom{
+		RemoteAddr: remoteAddr,
+		Url:        u,
+		Headers:    map[string][]string{},
+	}
+	if cookie != "" {
+		payload.Headers["Cookie"] = []string{"foo=bar; " + CHALLENGE_COOKIE + "=" + cookie}
+	}
+	return payload
+}
+
+// (MIN_CHALLENGE_KEY_SIZE bytes)
+const (
+	TEST_CHALLENGE_KEY1 = "0123456789abcdef0123456789abcdef"
+	TEST_CHALLENGE_KEY2 = "fedcba9876543210fedcba9876543210"
+)
+
+func newTestChallenge(t *testing.T, config map[string]string) *WafChallenge {
+	wc, err := NewWafConfig(&DbServiceConfigMock{config: config})
+	assert.Nil(t, err)
+	return NewWafChallenge(wc)
+}
+
+func TestWafChallenge(t *testing.T) {
+	t.Run("happy path: solve a challenge", func(t *testing.T) {
+		challenge := newTestChallenge(t, map[string]string{
+			"challenge_keys":       TEST_CHALLENGE_KEY1,
+			"challenge_difficulty": "8",
+		})
+
+		c := challenge.NewChallenge(challengeRequest("1.2.3.4", ""))
+		assert.Equal(t, CHALLENGE_COOKIE, c.Cookie)
+		assert.Equal(t, 8, c.Difficulty)
+		assert.Contains(t, c.Page, "crypto.subtle.digest")
+		cookie := solve(c)
+
+		assert.True(t, challenge.Verify(challengeRequest("1.2.3.4", cookie)))
+		// no cookie
+		assert.False(t, challenge.Verify(challengeRequest("1.2.3.4", "")))
+		// another ip
+		assert.False(t, challenge.Verify(challengeRequest("5.6.7.8", cookie)))
+		// not solved
+		assert.False(t, challenge.Verify(challengeRequest("1.2.3.4", c.Token+".x")))
+		assert.False(t, challenge.Verify(challengeRequest("1.2.3.4", c.Token)))
+
+		// expired
+		challenge.now = func() time.Time { return time.Now().Add(DEFAULT_CHALLENGE_TTL) }
+		assert.False(t, challenge.Verify(challengeRequest("1.2.3.4", cookie)))
+	})
+
+	t.Run("happy path: key rotation", func(t *testing.T) {
+		challenge := newTestChallenge(t, map[string]string{
+			"challenge_keys":       TEST_CHALLENGE_KEY1,
+			"challenge_difficulty": "4",
+		})
+		cookie := solve(challenge.NewChallenge(challengeRequest("1.2.3.4", "")))
+
+		challenge.config.parseKeyValue("challenge_keys", TEST_CHALLENGE_KEY2+","+TEST_CHALLENGE_KEY1)
+		assert.True(t, challenge.Verify(challengeRequest("1.2.3.4", cookie)))
+
+		challenge.config.parseKeyValue("challenge_keys", TEST_CHALLENGE_KEY2)
+		assert.False(t, challenge.Verify(challengeRequest("1.2.3.4", cookie)))
+	})
+
+	t.Run("not happy path: the difficulty has been raised", func(t *testing.T) {
+		challenge := newTestChallenge(t, map[string]string{
+			"challenge_keys":       TEST_CHALLENGE_KEY1,
+			"challenge_difficulty": "1",
+		})
+		cookie := solve(challenge.NewChallenge(challengeRequest("1.2.3.4", "")))
+
+		challenge.config.parseKeyValue("challenge_difficulty", "12")
+		assert.False(t, challenge.Verify(challengeRequest("1.2.3.4", cookie)))
+	})
+
+	t.Run("happy path: engine challenge verdict", func(t *testing.T) {
+		challenge := newTestChallenge(t, map[string]string{
+			"challenge_difficulty": "4",
+			"plugin_challenging":   "enabled",
+		})
+
+		tmpl, err := template.New("outputformat").Parse("")
+		assert.Nil(t, err)
+		we := WafEngineImpl{
+			analysisOutputTemplate: tmpl,
+			config:                 challenge.config,
+			bans:                   &WafBans{},
+			challenge:              challenge,
+			plugins:                map[string]WafEnginePlugin{},
+		}
+		we.RegisterPlugin(&challengingPlugin{})
+
+		assert.Equal(t, VERDICT_CHALLENGE, we.Scan(context.Background(), challengeRequest("1.2.3.4", "")))
+		cookie := solve(we.Challenge(challengeRequest("1.2.3.4", "")))
+		assert.Equal(t, VERDICT_PASS, we.Scan(context.Background(), challengeRequest("1.2.3.4", cookie)))
+
+		// dryrun
+		we.config.Mode = "dryrun"
+		assert.Equal(t, VERDICT_PASS, we.Scan(context.Background(), challengeRequest("1.2.3.4", "")))
+	})
+}
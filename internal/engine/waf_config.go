@@ -1,40 +1,157 @@
 package engine
 
 import (
+	"errors"
 	"fmt"
+	"maps"
 	"net"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/nzin/taxsi2/internal/db"
 	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
 )
 
 /*
-Taxsi2 main (global) configuration
+Taxsi2 main (global) configuration.
+The settings are reloaded (by the db watcher) while the requests are
+scanned: the scans must read them through Settings()
 */
 type WafConfig struct {
-	ds            db.DbServiceConfig
+	ds db.DbServiceConfig
+	// guards the settings, whose maps and slices are copied on write
+	// (so that a snapshot never changes)
+	mu sync.RWMutex
+	WafSettings
+}
+
+/*
+WafSettings is a snapshot of the configuration
+*/
+type WafSettings struct {
 	Mode          string // enabled, dryrun, disabled. (learning in the future?)
 	EnabledPlugin map[string]bool
 	AllowList     []*net.IPNet
 	DenyList      []*net.IPNet
+
+	// autoban: ban an IP for AutobanDuration when it triggers
+	// AutobanThreshold blocked verdicts within AutobanWindow (0 to disable)
+	AutobanThreshold int
+	AutobanWindow    time.Duration
+	AutobanDuration  time.Duration
+
+	// challenge: the clearance cookies are signed with the first key,
+	// and verified with all of them (to be able to rotate the keys)
+	ChallengeKeys       [][]byte
+	ChallengeTTL        time.Duration
+	ChallengeDifficulty int
+
+	// what to do with the bodies that can't be parsed (allow, block)
+	MalformedBodyAction string
+
+	// scoring: instead of the first blocking plugin, the weights of the
+	// blocking plugins are added, and the request is blocked when the
+	// total reaches the threshold (of the most specific policy)
+	ScoringMode      bool
+	ScoringThreshold int
+	ScoringWeights   map[string]int
+	ScoringPolicies  []ScoringPolicy
+
+	// isolation: the deadline of a plugin scan (0 for none), and the
+	// verdict when a plugin times out or panics (open: pass, closed: block)
+	ScanTimeout  time.Duration
+	ScanTimeouts map[string]time.Duration
+	ScanFailure  string
+	ScanFailures map[string]string
+
+	// run the plugins concurrently (see WafEngineImpl.scanParallel)
+	ScanParallel bool
+
+	// cache the verdicts of the identical requests (see WafVerdictCache)
+	VerdictCache     bool
+	VerdictCacheTTL  time.Duration
+	VerdictCacheSize int
+
+	// the requests that skip the plugins (see BypassRule)
+	BypassRules []BypassRule
 }
 
+const (
+	DEFAULT_AUTOBAN_WINDOW   = 1 * time.Minute
+	DEFAULT_AUTOBAN_DURATION = 1 * time.Hour
+
+	DEFAULT_CHALLENGE_TTL        = 1 * time.Hour
+	DEFAULT_CHALLENGE_DIFFICULTY = 16
+	// leading zero bits, more would take forever to solve in a browser
+	MAX_CHALLENGE_DIFFICULTY = 32
+	// the HMAC keys of the clearance cookies, in bytes
+	MIN_CHALLENGE_KEY_SIZE = 32
+
+	DEFAULT_MALFORMED_BODY_ACTION = "block"
+
+	DEFAULT_SCAN_TIMEOUT = 200 * time.Millisecond
+	DEFAULT_SCAN_FAILURE = "open"
+)
+
 func NewWafConfig(ds db.DbServiceConfig) (*WafConfig, error) {
+	wc := WafConfig{ds: ds, WafSettings: defaultSettings()}
+	if err := wc.loadConfigs(); err != nil {
+		return nil, err
+	}
+	ds.SubscribeChanges(db.CHANGELOG_TABLE_CONFIG, &wc)
+	return &wc, nil
+}
 
-	wc := WafConfig{
-		ds:            ds,
+/*
+defaultSettings returns the settings when nothing is configured
+*/
+func defaultSettings() WafSettings {
+	return WafSettings{
 		Mode:          "enabled",
 		EnabledPlugin: make(map[string]bool),
 		AllowList:     []*net.IPNet{},
 		DenyList:      []*net.IPNet{},
-	}
 
-	if err := wc.loadConfigs(); err != nil {
-		return nil, err
+		AutobanThreshold: 0,
+		AutobanWindow:    DEFAULT_AUTOBAN_WINDOW,
+		AutobanDuration:  DEFAULT_AUTOBAN_DURATION,
+
+		ChallengeKeys:       [][]byte{},
+		ChallengeTTL:        DEFAULT_CHALLENGE_TTL,
+		ChallengeDifficulty: DEFAULT_CHALLENGE_DIFFICULTY,
+
+		MalformedBodyAction: DEFAULT_MALFORMED_BODY_ACTION,
+
+		ScoringMode:      false,
+		ScoringThreshold: DEFAULT_SCORING_THRESHOLD,
+		ScoringWeights:   make(map[string]int),
+		ScoringPolicies:  []ScoringPolicy{},
+
+		ScanTimeout:  DEFAULT_SCAN_TIMEOUT,
+		ScanTimeouts: make(map[string]time.Duration),
+		ScanFailure:  DEFAULT_SCAN_FAILURE,
+		ScanFailures: make(map[string]string),
+
+		ScanParallel: false,
+
+		VerdictCache:     false,
+		VerdictCacheTTL:  DEFAULT_VERDICT_CACHE_TTL,
+		VerdictCacheSize: DEFAULT_VERDICT_CACHE_SIZE,
+
+		BypassRules: []BypassRule{},
 	}
-	ds.SubscribeChanges(db.CHANGELOG_TABLE_CONFIG, &wc)
-	return &wc, nil
+}
+
+/*
+Settings returns a snapshot of the configuration
+*/
+func (wc *WafConfig) Settings() WafSettings {
+	wc.mu.RLock()
+	defer wc.mu.RUnlock()
+	return wc.WafSettings
 }
 
 func (wc *WafConfig) loadConfigs() error {
@@ -42,85 +159,362 @@ func (wc *WafConfig) loadConfigs() error {
 	if err != nil {
 		return err
 	}
+	wc.mu.Lock()
+	defer wc.mu.Unlock()
 	for k, v := range configs {
-		wc.parseKeyValue(k, v)
+		if err := wc.parseKeyValue(k, v); err != nil {
+			logrus.Errorf("not able to parse config %s: %v", k, err)
+		}
 	}
 	return nil
 }
 
 func (wc *WafConfig) NotifyDbChange(key string) {
 	value, err := wc.ds.GetConfigValueForKey(key)
-	if err != nil {
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
 		logrus.Errorf("Error reading config %s: %v", key, err)
 		return
 	}
 
-	wc.parseKeyValue(key, value)
+	wc.mu.Lock()
+	defer wc.mu.Unlock()
+	if err != nil {
+		// the key has been removed (for example by a rollback)
+		wc.resetKey(key)
+		return
+	}
+	if err := wc.parseKeyValue(key, value); err != nil {
+		logrus.Errorf("not able to parse config %s: %v", key, err)
+	}
 }
 
-func (wc *WafConfig) parseKeyValue(k string, v string) {
+/*
+resetKey sets back a key to its default value
+(must be called with the lock held)
+*/
+func (wc *WafConfig) resetKey(k string) {
+	if k == "mode" {
+		wc.Mode = "enabled"
+	}
+	if strings.HasPrefix(k, "plugin_") {
+		wc.EnabledPlugin = maps.Clone(wc.EnabledPlugin)
+		delete(wc.EnabledPlugin, k[len("plugin_"):])
+	}
+	if k == "allowlist" {
+		wc.AllowList = []*net.IPNet{}
+	}
+	if k == "denylist" {
+		wc.DenyList = []*net.IPNet{}
+	}
+	if k == "autoban_threshold" {
+		wc.AutobanThreshold = 0
+	}
+	if k == "autoban_window" {
+		wc.AutobanWindow = DEFAULT_AUTOBAN_WINDOW
+	}
+	if k == "autoban_duration" {
+		wc.AutobanDuration = DEFAULT_AUTOBAN_DURATION
+	}
+	if k == "challenge_keys" {
+		wc.ChallengeKeys = [][]byte{}
+	}
+	if k == "challenge_ttl" {
+		wc.ChallengeTTL = DEFAULT_CHALLENGE_TTL
+	}
+	if k == "challenge_difficulty" {
+		wc.ChallengeDifficulty = DEFAULT_CHALLENGE_DIFFICULTY
+	}
+	if k == "malformed_body_action" {
+		wc.MalformedBodyAction = DEFAULT_MALFORMED_BODY_ACTION
+	}
+	if k == "scoring_mode" {
+		wc.ScoringMode = false
+	}
+	if k == "scoring_threshold" {
+		wc.ScoringThreshold = DEFAULT_SCORING_THRESHOLD
+	}
+	if strings.HasPrefix(k, "scoring_weight_") {
+		wc.ScoringWeights = maps.Clone(wc.ScoringWeights)
+		delete(wc.ScoringWeights, k[len("scoring_weight_"):])
+	}
+	if k == "scoring_policies" {
+		wc.ScoringPolicies = []ScoringPolicy{}
+	}
+	if k == "scan_timeout" {
+		wc.ScanTimeout = DEFAULT_SCAN_TIMEOUT
+	}
+	if strings.HasPrefix(k, "scan_timeout_") {
+		wc.ScanTimeouts = maps.Clone(wc.ScanTimeouts)
+		delete(wc.ScanTimeouts, k[len("scan_timeout_"):])
+	}
+	if k == "scan_failure" {
+		wc.ScanFailure = DEFAULT_SCAN_FAILURE
+	}
+	if strings.HasPrefix(k, "scan_failure_") {
+		wc.ScanFailures = maps.Clone(wc.ScanFailures)
+		delete(wc.ScanFailures, k[len("scan_failure_"):])
+	}
+	if k == "scan_parallel" {
+		wc.ScanParallel = false
+	}
+	if k == "verdict_cache" {
+		wc.VerdictCache = false
+	}
+	if k == "verdict_cache_ttl" {
+		wc.VerdictCacheTTL = DEFAULT_VERDICT_CACHE_TTL
+	}
+	if k == "verdict_cache_size" {
+		wc.VerdictCacheSize = DEFAULT_VERDICT_CACHE_SIZE
+	}
+	if k == "bypass_rules" {
+		wc.BypassRules = []BypassRule{}
+	}
+}
+
+/*
+ValidateConfig returns why a value can't be used for an engine key
+(nil if it can, or if the key is not an engine key).
+The value is checked by the same parser as the one loading the configuration
+*/
+func ValidateConfig(k string, v string) error {
+	wc := WafConfig{WafSettings: defaultSettings()}
+	return wc.parseKeyValue(k, v)
+}
+
+/*
+parseKeyValue sets a key (must be called with the lock held).
+Returns an error if the value is not valid: the key is then left
+unchanged (or, for the lists, set to their valid entries)
+*/
+func (wc *WafConfig) parseKeyValue(k string, v string) error {
 	// mode
 	if k == "mode" {
-		if v == "enabled" || v == "dryrun" || v == "disabled" {
-			wc.Mode = v
+		if v != "enabled" && v != "dryrun" && v != "disabled" {
+			return fmt.Errorf("bad mode %s (must be enabled,dryrun or disabled)", v)
 		}
+		wc.Mode = v
 	}
 	// plugin enable
-	if strings.HasPrefix(k, "plugin_") && (v == "enabled" || v == "disabled") {
+	if strings.HasPrefix(k, "plugin_") {
+		if v != "enabled" && v != "disabled" {
+			return fmt.Errorf("bad %s %s (must be enabled or disabled)", k, v)
+		}
+		wc.EnabledPlugin = maps.Clone(wc.EnabledPlugin)
 		wc.EnabledPlugin[k[len("plugin_"):]] = v == "enabled"
 	}
 
 	// allow list
 	if k == "allowlist" {
-		wc.AllowList = []*net.IPNet{}
-		nets := strings.Split(v, ",")
-		for _, n := range nets {
-			_, network, err := net.ParseCIDR(n)
-			if err != nil {
-				logrus.Errorf("not able to parse allow net %s: %v", n, err)
-			} else {
-				wc.AllowList = append(wc.AllowList, network)
-			}
+		networks, err := ParseNetworks(v)
+		wc.AllowList = networks
+		if err != nil {
+			return fmt.Errorf("allow list: %v", err)
 		}
 	}
 
 	// deny list
 	if k == "denylist" {
-		wc.DenyList = []*net.IPNet{}
-		nets := strings.Split(v, ",")
-		for _, n := range nets {
-			_, network, err := net.ParseCIDR(n)
-			if err != nil {
-				logrus.Errorf("not able to parse deny net %s: %v", n, err)
-			} else {
-				wc.DenyList = append(wc.DenyList, network)
+		networks, err := ParseNetworks(v)
+		wc.DenyList = networks
+		if err != nil {
+			return fmt.Errorf("deny list: %v", err)
+		}
+	}
+
+	// autoban
+	if k == "autoban_threshold" {
+		threshold, err := strconv.Atoi(v)
+		if err != nil || threshold < 0 {
+			return fmt.Errorf("bad autoban_threshold %s (must be a positive integer)", v)
+		}
+		wc.AutobanThreshold = threshold
+	}
+	if k == "autoban_window" || k == "autoban_duration" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 0 {
+			return fmt.Errorf("bad %s %s (must be a duration, like 10m)", k, v)
+		}
+		if k == "autoban_window" {
+			wc.AutobanWindow = d
+		} else {
+			wc.AutobanDuration = d
+		}
+	}
+
+	// challenge
+	if k == "challenge_keys" {
+		keys := [][]byte{}
+		for _, key := range strings.Split(v, ",") {
+			if key = strings.TrimSpace(key); key == "" {
+				continue
+			}
+			if len(key) < MIN_CHALLENGE_KEY_SIZE {
+				// (the keys are secrets, they are not in the message)
+				return fmt.Errorf("bad challenge_keys (the keys must be at least %d bytes)", MIN_CHALLENGE_KEY_SIZE)
 			}
+			keys = append(keys, []byte(key))
+		}
+		wc.ChallengeKeys = keys
+	}
+	if k == "challenge_ttl" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 0 {
+			return fmt.Errorf("bad challenge_ttl %s (must be a duration, like 10m)", v)
+		}
+		wc.ChallengeTTL = d
+	}
+	if k == "challenge_difficulty" {
+		difficulty, err := strconv.Atoi(v)
+		if err != nil || difficulty < 0 || difficulty > MAX_CHALLENGE_DIFFICULTY {
+			return fmt.Errorf("bad challenge_difficulty %s (must be between 0 and %d)", v, MAX_CHALLENGE_DIFFICULTY)
+		}
+		wc.ChallengeDifficulty = difficulty
+	}
+
+	// body parsing
+	if k == "malformed_body_action" {
+		if v != "allow" && v != "block" {
+			return fmt.Errorf("bad malformed_body_action %s (must be allow or block)", v)
+		}
+		wc.MalformedBodyAction = v
+	}
+
+	// scoring
+	if k == "scoring_mode" {
+		if v != "enabled" && v != "disabled" {
+			return fmt.Errorf("bad scoring_mode %s (must be enabled or disabled)", v)
+		}
+		wc.ScoringMode = v == "enabled"
+	}
+	if k == "scoring_threshold" {
+		threshold, err := strconv.Atoi(v)
+		if err != nil || threshold <= 0 {
+			return fmt.Errorf("bad scoring_threshold %s (must be a positive integer)", v)
+		}
+		wc.ScoringThreshold = threshold
+	}
+	if strings.HasPrefix(k, "scoring_weight_") {
+		weight, err := strconv.Atoi(v)
+		if err != nil || weight < 0 {
+			return fmt.Errorf("bad %s %s (must be a positive integer)", k, v)
+		}
+		wc.ScoringWeights = maps.Clone(wc.ScoringWeights)
+		wc.ScoringWeights[k[len("scoring_weight_"):]] = weight
+	}
+	if k == "scoring_policies" {
+		policies, err := ParseScoringPolicies(v)
+		wc.ScoringPolicies = policies
+		if err != nil {
+			return fmt.Errorf("scoring policies: %v", err)
+		}
+	}
+
+	// isolation
+	if k == "scan_timeout" || strings.HasPrefix(k, "scan_timeout_") {
+		timeout, err := time.ParseDuration(v)
+		if err != nil || timeout < 0 {
+			return fmt.Errorf("bad %s %s (must be a duration, like 200ms)", k, v)
+		}
+		if k == "scan_timeout" {
+			wc.ScanTimeout = timeout
+		} else {
+			wc.ScanTimeouts = maps.Clone(wc.ScanTimeouts)
+			wc.ScanTimeouts[k[len("scan_timeout_"):]] = timeout
+		}
+	}
+	if k == "scan_failure" || strings.HasPrefix(k, "scan_failure_") {
+		if v != "open" && v != "closed" {
+			return fmt.Errorf("bad %s %s (must be open or closed)", k, v)
+		}
+		if k == "scan_failure" {
+			wc.ScanFailure = v
+		} else {
+			wc.ScanFailures = maps.Clone(wc.ScanFailures)
+			wc.ScanFailures[k[len("scan_failure_"):]] = v
 		}
 	}
+	if k == "scan_parallel" {
+		if v != "enabled" && v != "disabled" {
+			return fmt.Errorf("bad scan_parallel %s (must be enabled or disabled)", v)
+		}
+		wc.ScanParallel = v == "enabled"
+	}
+
+	// verdict cache
+	if k == "verdict_cache" {
+		if v != "enabled" && v != "disabled" {
+			return fmt.Errorf("bad verdict_cache %s (must be enabled or disabled)", v)
+		}
+		wc.VerdictCache = v == "enabled"
+	}
+	if k == "verdict_cache_ttl" {
+		ttl, err := time.ParseDuration(v)
+		if err != nil || ttl <= 0 {
+			return fmt.Errorf("bad verdict_cache_ttl %s (must be a duration, like 10m)", v)
+		}
+		wc.VerdictCacheTTL = ttl
+	}
+	if k == "verdict_cache_size" {
+		size, err := strconv.Atoi(v)
+		if err != nil || size <= 0 {
+			return fmt.Errorf("bad verdict_cache_size %s (must be a positive integer)", v)
+		}
+		wc.VerdictCacheSize = size
+	}
+
+	// bypass
+	if k == "bypass_rules" {
+		rules, err := ParseBypassRules(v)
+		wc.BypassRules = rules
+		if err != nil {
+			return fmt.Errorf("bypass rules: %v", err)
+		}
+	}
+	return nil
 }
 
 /*
-IsIpAllowListed to know if an IP is allow/white listed
+ScanTimeoutFor returns the deadline of a plugin scan (0 for none)
 */
-func (wc *WafConfig) IsIpAllowListed(remoteAddr net.IP) bool {
-	for _, n := range wc.AllowList {
-		if n.Contains(remoteAddr) {
-			return true
-		}
+func (wc *WafSettings) ScanTimeoutFor(plugin string) time.Duration {
+	if timeout, ok := wc.ScanTimeouts[plugin]; ok {
+		return timeout
 	}
-	return false
+	return wc.ScanTimeout
 }
 
 /*
-IsIpAllowListed to know if an IP is deny/black listed
+FailClosed tells if a plugin that times out or panics blocks the request
 */
-func (wc *WafConfig) IsIpDenyListed(remoteAddr net.IP) bool {
-	for _, n := range wc.DenyList {
-		if n.Contains(remoteAddr) {
-			return true
-		}
+func (wc *WafSettings) FailClosed(plugin string) bool {
+	if failure, ok := wc.ScanFailures[plugin]; ok {
+		return failure == "closed"
 	}
-	return false
+	return wc.ScanFailure == "closed"
+}
+
+/*
+ScoringWeight returns the score of a plugin when it blocks a request
+*/
+func (wc *WafSettings) ScoringWeight(plugin string) int {
+	if weight, ok := wc.ScoringWeights[plugin]; ok {
+		return weight
+	}
+	return DEFAULT_SCORING_WEIGHT
+}
+
+/*
+IsIpAllowListed to know if an IP is allow/white listed
+*/
+func (wc *WafSettings) IsIpAllowListed(remoteAddr net.IP) bool {
+	return IsIpInNetworks(remoteAddr, wc.AllowList)
+}
+
+/*
+IsIpAllowListed to know if an IP is deny/black listed
+*/
+func (wc *WafSettings) IsIpDenyListed(remoteAddr net.IP) bool {
+	return IsIpInNetworks(remoteAddr, wc.DenyList)
 }
 
 func (wc *WafConfig) SetMode(mode string) error {
@@ -128,47 +522,44 @@ func (wc *WafConfig) SetMode(mode string) error {
 		return fmt.Errorf("bad mode (must be enabled,dryrun or disabled)")
 	}
 
+	wc.mu.Lock()
 	wc.Mode = mode
+	wc.mu.Unlock()
 	wc.ds.SetConfigValueForKey("mode", mode)
 	return nil
 }
 
 func (wc *WafConfig) SetAllowList(allowlist string) error {
 	// check values
-	nets := strings.Split(allowlist, ",")
-	al := []*net.IPNet{}
-	for _, n := range nets {
-		_, network, err := net.ParseCIDR(n)
-		if err != nil {
-			return fmt.Errorf("not able to parse allow net %s: %v", n, err)
-		} else {
-			al = append(al, network)
-		}
+	al, err := ParseNetworks(allowlist)
+	if err != nil {
+		return err
 	}
 
+	wc.mu.Lock()
 	wc.AllowList = al
+	wc.mu.Unlock()
 	return wc.ds.SetConfigValueForKey("allowlist", allowlist)
 }
 
 func (wc *WafConfig) SetDenyList(denylist string) error {
 	// check values
-	nets := strings.Split(denylist, ",")
-	dl := []*net.IPNet{}
-	for _, n := range nets {
-		_, network, err := net.ParseCIDR(n)
-		if err != nil {
-			return fmt.Errorf("not able to parse allow net %s: %v", n, err)
-		} else {
-			dl = append(dl, network)
-		}
+	dl, err := ParseNetworks(denylist)
+	if err != nil {
+		return err
 	}
 
+	wc.mu.Lock()
 	wc.DenyList = dl
+	wc.mu.Unlock()
 	return wc.ds.SetConfigValueForKey("denylist", denylist)
 }
 
 func (wc *WafConfig) EnablePlugin(pluginname string, enable bool) error {
+	wc.mu.Lock()
+	wc.EnabledPlugin = maps.Clone(wc.EnabledPlugin)
 	wc.EnabledPlugin[pluginname] = enable
+	wc.mu.Unlock()
 
 	e := "enabled"
 	if !enable {
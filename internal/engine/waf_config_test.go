@@ -1,11 +1,14 @@
 package engine
 
 import (
+	"context"
+	"io"
 	"net"
 	"testing"
 
 	"github.com/nzin/taxsi2/internal/db"
 	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
 )
 
 type DbServiceConfigMock struct {
@@ -21,7 +24,11 @@ func (c *DbServiceConfigMock) GetConfigs() (map[string]string, error) {
 	return c.config, nil
 }
 func (c *DbServiceConfigMock) GetConfigValueForKey(key string) (string, error) {
-	return c.config[key], nil
+	value, ok := c.config[key]
+	if !ok {
+		return "", gorm.ErrRecordNotFound
+	}
+	return value, nil
 }
 func (c *DbServiceConfigMock) SetConfigValueForKey(key string, value string) error {
 	c.lastSetKey = key
@@ -80,6 +87,30 @@ func TestWafConfig(t *testing.T) {
 		assert.Equal(t, 1, len(wc.DenyList))
 	})
 
+	t.Run("happy path: testing notification of a removed key", func(t *testing.T) {
+		c := DbServiceConfigMock{
+			config: make(map[string]string),
+		}
+		c.config["mode"] = "dryrun"
+		c.config["plugin_foo"] = "enabled"
+		c.config["allowlist"] = "8.8.8.8/32,1.1.1.0/24"
+
+		wc, err := NewWafConfig(&c)
+		assert.Nil(t, err)
+		assert.Equal(t, "dryrun", wc.Mode)
+
+		delete(c.config, "mode")
+		delete(c.config, "plugin_foo")
+		delete(c.config, "allowlist")
+		wc.NotifyDbChange("mode")
+		wc.NotifyDbChange("plugin_foo")
+		wc.NotifyDbChange("allowlist")
+
+		assert.Equal(t, "enabled", wc.Mode)
+		assert.Equal(t, 0, len(wc.EnabledPlugin))
+		assert.Equal(t, 0, len(wc.AllowList))
+	})
+
 	t.Run("happy path: testing ips", func(t *testing.T) {
 		c := DbServiceConfigMock{
 			config: make(map[string]string),
@@ -203,4 +234,46 @@ func TestWafConfig(t *testing.T) {
 		assert.Equal(t, "plugin_bar", c.lastSetKey)
 		assert.Equal(t, "enabled", c.lastSetValue)
 	})
+
+	t.Run("not happy path: validating the values", func(t *testing.T) {
+		assert.Nil(t, ValidateConfig("challenge_difficulty", "20"))
+		assert.NotNil(t, ValidateConfig("challenge_difficulty", "33"))
+		assert.Nil(t, ValidateConfig("challenge_keys", "0123456789abcdef0123456789abcdef"))
+		assert.NotNil(t, ValidateConfig("challenge_keys", "0123456789abcdef0123456789abcdef,k"))
+		assert.NotNil(t, ValidateConfig("mode", "learning"))
+		assert.NotNil(t, ValidateConfig("plugin_sqli", "on"))
+		assert.NotNil(t, ValidateConfig("scan_timeout_sqli", "10"))
+		assert.NotNil(t, ValidateConfig("bypass_rules", "get /favicon.ico"))
+		// not an engine key
+		assert.Nil(t, ValidateConfig("graphql_max_depth", "-1"))
+	})
+
+	t.Run("not happy path: the config changes while scanning", func(t *testing.T) {
+		we := newParallelEngine(t, map[string]string{
+			"plugin_a":         "enabled",
+			"scoring_weight_a": "5",
+			"scan_timeout_a":   "1s",
+			"denylist":         "1.2.3.0/24",
+		}, io.Discard)
+		we.RegisterPlugin(&countingPlugin{name: "a", pass: true})
+
+		// (the db watcher)
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			for i := 0; i < 1000; i++ {
+				for _, key := range []string{"plugin_a", "plugin_b", "scoring_weight_a", "scan_timeout_b", "denylist", "mode"} {
+					we.config.NotifyDbChange(key)
+				}
+			}
+		}()
+		for i := 0; i < 1000; i++ {
+			we.Scan(context.Background(), cacheRequest("http://www.example.com/", "5.6.7.8", nil, ""))
+		}
+		<-done
+
+		config := we.config.Settings()
+		assert.True(t, config.EnabledPlugin["a"])
+		assert.Equal(t, 5, config.ScoringWeight("a"))
+	})
 }
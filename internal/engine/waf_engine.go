@@ -2,6 +2,7 @@ package engine
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"html/template"
 	"io"
@@ -22,19 +23,58 @@ type WafEngine interface {
 	RegisterPlugin(plugin WafEnginePlugin)
 	/*
 	 main WAF scanning function
-	 Returns true if we dont block
 	*/
-	Scan(payload *com.TaxsiCom) bool
+	Scan(ctx context.Context, payload *com.TaxsiCom) Verdict
+	/*
+	 challenge to serve when Scan returns VERDICT_CHALLENGE
+	*/
+	Challenge(payload *com.TaxsiCom) *Challenge
+	/*
+	 failure (timeout, panic) counters of the plugins
+	*/
+	PluginStats() []PluginStats
+	/*
+	 hit and miss counters (and size) of the verdict cache
+	*/
+	VerdictCacheStats() (hits int64, misses int64, size int)
 }
 
+type Verdict int
+
+const (
+	VERDICT_PASS Verdict = iota
+	VERDICT_BLOCK
+	// the client must prove it is a browser (see WafChallenge)
+	VERDICT_CHALLENGE
+)
+
+const (
+	// reason of the verdict when the body can't be parsed
+	REASON_MALFORMED_BODY = "malformed_body"
+	// output variable (the body parsing error)
+	VARIABLE_BODY_ERROR = "body_error"
+)
+
 type WafEnginePlugin interface {
 	// WAF Plugin name
 	Name() string
 	/*
 	 WAF Plugin scanning function
 	 Returns true if we dont block
+	 The context is done when the scan deadline is reached: the plugin
+	 should then return as soon as possible (its verdict, variables and
+	 claims are discarded)
 	*/
-	Scan(payload *com.TaxsiCom) bool
+	Scan(ctx context.Context, payload *com.TaxsiCom) bool
+}
+
+/*
+WafEngineVerdictPlugin is implemented by the plugins that
+can ask for more than pass/block (i.e. a challenge)
+*/
+type WafEngineVerdictPlugin interface {
+	WafEnginePlugin
+	ScanVerdict(ctx context.Context, payload *com.TaxsiCom) Verdict
 }
 
 type WafEngineImpl struct {
@@ -52,11 +92,20 @@ type WafEngineImpl struct {
 	  - {{.UrlPath}}
 	  - {{.Method}}
 	  - {{.Remoteaddr}}
-	  - {{.Scanresult}} (blocked, dryrun, pass)
+	  - {{.Scanresult}} (blocked, challenged, dryrun, pass)
+	  - {{.Reason}} (the plugin name, allowlist, denylist, banned, malformed_body, score, bypass)
+	  - {{.Variables.<name>}} (set by the plugins, like {{.Variables.botclass}})
 	*/
 	analysisOutputTemplate *template.Template
-	config                 WafConfig
+	config                 *WafConfig
+	bans                   *WafBans
+	autoban                *WafAutoban
+	challenge              *WafChallenge
 	plugins                map[string]WafEnginePlugin
+	// the plugin names, in the order of registration (the scan order)
+	order []string
+	stats WafPluginStats
+	cache *WafVerdictCache
 }
 
 type WafOuput struct {
@@ -64,12 +113,24 @@ type WafOuput struct {
 	Writer     io.Writer
 }
 
-func NewWafEngineImpl(ds db.DbService, analysisOutput string, analysisOutputFormat string) (WafEngine, error) {
+/*
+NewWafEngineImpl creates the engine. Its background loops (bans purge,
+autoban counters sync) run until stopChannel is closed
+*/
+func NewWafEngineImpl(ds db.DbService, analysisOutput string, analysisOutputFormat string, stopChannel chan struct{}) (WafEngine, error) {
 	config, err := NewWafConfig(ds)
 	if err != nil {
 		return nil, err
 	}
 
+	bans, err := NewWafBans(ds)
+	if err != nil {
+		return nil, err
+	}
+	go bans.Watch(stopChannel)
+	autoban := NewWafAutoban(ds, config, bans)
+	go autoban.Watch(stopChannel)
+
 	tmpl, err := template.New("outputformat").Parse(analysisOutputFormat)
 	if err != nil {
 		return nil, fmt.Errorf("error analysis output format: %v", err)
@@ -114,59 +175,124 @@ func NewWafEngineImpl(ds db.DbService, analysisOutput string, analysisOutputForm
 	return &WafEngineImpl{
 		analysisOutput:         wafoutputs,
 		analysisOutputTemplate: tmpl,
-		config:                 *config,
+		config:                 config,
+		bans:                   bans,
+		autoban:                autoban,
+		challenge:              NewWafChallenge(config),
 		plugins:                make(map[string]WafEnginePlugin),
+		cache:                  NewWafVerdictCache(ds, config),
 	}, nil
 }
 
 func (we *WafEngineImpl) RegisterPlugin(plugin WafEnginePlugin) {
+	if _, ok := we.plugins[plugin.Name()]; !ok {
+		we.order = append(we.order, plugin.Name())
+	}
 	we.plugins[plugin.Name()] = plugin
 }
 
 /*
-main scanning function
+enabledPlugins returns the names of the enabled plugins, in the scan order
 */
-func (we *WafEngineImpl) Scan(payload *com.TaxsiCom) bool {
-	res := true
+func (we *WafEngineImpl) enabledPlugins() []string {
+	enabled := we.config.Settings().EnabledPlugin
+	names := []string{}
+	for _, name := range we.order {
+		if enabled[name] {
+			names = append(names, name)
+		}
+	}
+	return names
+}
 
-	if we.config.Mode == "disabled" {
-		we.output(payload, "pass")
-		return true
+/*
+main scanning function
+*/
+func (we *WafEngineImpl) Scan(ctx context.Context, payload *com.TaxsiCom) Verdict {
+	config := we.config.Settings()
+	if config.Mode == "disabled" {
+		we.output(payload, "pass", "")
+		return VERDICT_PASS
 	}
 
 	// deny/allow
 	remoteAddr := net.ParseIP(payload.RemoteAddr)
 	if remoteAddr != nil {
-		if we.config.IsIpAllowListed(remoteAddr) {
-			we.output(payload, "pass")
-			return true
+		if config.IsIpAllowListed(remoteAddr) {
+			we.output(payload, "pass", "allowlist")
+			return VERDICT_PASS
 		}
-		if we.config.IsIpDenyListed(remoteAddr) {
-			we.output(payload, "blocked")
-			return false
+		if config.IsIpDenyListed(remoteAddr) {
+			we.output(payload, "blocked", "denylist")
+			return VERDICT_BLOCK
 		}
+		if we.bans.IsIpBanned(remoteAddr) {
+			we.output(payload, "blocked", "banned")
+			return VERDICT_BLOCK
+		}
+	}
+
+	// fast path (static assets...)
+	if rule := matchBypassRule(config.BypassRules, payload); rule != nil {
+		payload.SetVariable(VARIABLE_BYPASS_RULE, rule.Rule)
+		we.output(payload, "pass", REASON_BYPASS)
+		return VERDICT_PASS
+	}
+
+	// shared pre-processing (decoding, normalization, body parsing), used by the plugins
+	parsed := payload.Parse()
+	if parsed.BodyError != nil && config.MalformedBodyAction == "block" {
+		payload.SetVariable(VARIABLE_BODY_ERROR, parsed.BodyError.Error())
+		return we.reject(payload, remoteAddr, VERDICT_BLOCK, REASON_MALFORMED_BODY)
 	}
 
 	// Engine scan
-	for name, plugin := range we.plugins {
-		if we.config.EnabledPlugin[name] {
-			res = plugin.Scan(payload)
-			// if the plugin attempt to block
-			if !res {
-				if we.config.Mode == "enabled" {
-					we.output(payload, "blocked")
-					return false
-				} else {
-					we.output(payload, "dryrun")
-					return true
-				}
-			}
+	if config.ScoringMode {
+		return we.scanScoring(ctx, payload, remoteAddr)
+	}
+	names := we.enabledPlugins()
+	for i, verdict := range we.scanCached(ctx, payload, names, true) {
+		if verdict != VERDICT_PASS {
+			return we.reject(payload, remoteAddr, verdict, names[i])
 		}
 	}
 
-	we.output(payload, "pass")
+	we.output(payload, "pass", "")
+
+	return VERDICT_PASS
+}
+
+/*
+reject applies a block (or challenge) verdict, according to the mode
+*/
+func (we *WafEngineImpl) reject(payload *com.TaxsiCom, remoteAddr net.IP, verdict Verdict, reason string) Verdict {
+	if we.config.Settings().Mode != "enabled" {
+		we.output(payload, "dryrun", reason)
+		return VERDICT_PASS
+	}
+	if verdict == VERDICT_CHALLENGE {
+		we.output(payload, "challenged", reason)
+		return VERDICT_CHALLENGE
+	}
+	if remoteAddr != nil {
+		we.autoban.RecordBlocked(remoteAddr)
+	}
+	we.output(payload, "blocked", reason)
+	return VERDICT_BLOCK
+}
+
+func scanPlugin(ctx context.Context, plugin WafEnginePlugin, payload *com.TaxsiCom) Verdict {
+	if p, ok := plugin.(WafEngineVerdictPlugin); ok {
+		return p.ScanVerdict(ctx, payload)
+	}
+	if !plugin.Scan(ctx, payload) {
+		return VERDICT_BLOCK
+	}
+	return VERDICT_PASS
+}
 
-	return res
+func (we *WafEngineImpl) Challenge(payload *com.TaxsiCom) *Challenge {
+	return we.challenge.NewChallenge(payload)
 }
 
 type OutputVariables struct {
@@ -178,9 +304,11 @@ type OutputVariables struct {
 	Method      string
 	Remoteaddr  string
 	Scanresult  string
+	Reason      string
+	Variables   map[string]string
 }
 
-func (we *WafEngineImpl) output(payload *com.TaxsiCom, scanresult string) {
+func (we *WafEngineImpl) output(payload *com.TaxsiCom, scanresult string, reason string) {
 	now := time.Now()
 	v := OutputVariables{
 		Date:        now.Format(time.RFC3339),
@@ -191,6 +319,8 @@ func (we *WafEngineImpl) output(payload *com.TaxsiCom, scanresult string) {
 		Method:      payload.Method,
 		Remoteaddr:  payload.RemoteAddr,
 		Scanresult:  scanresult,
+		Reason:      reason,
+		Variables:   payload.CopyVariables(),
 	}
 
 	for _, o := range we.analysisOutput {
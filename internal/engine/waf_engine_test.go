@@ -0,0 +1,70 @@
+package engine
+
+import (
+	"bytes"
+	"context"
+	"html/template"
+	"net/url"
+	"testing"
+
+	"github.com/nzin/taxsi2/internal/com"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWafEngine(t *testing.T) {
+	newEngine := func(t *testing.T, config map[string]string) (*WafEngineImpl, *bytes.Buffer) {
+		wc, err := NewWafConfig(&DbServiceConfigMock{config: config})
+		assert.Nil(t, err)
+		tmpl, err := template.New("outputformat").Parse("{{.Scanresult}} {{.Reason}} {{.Variables.body_error}}\n")
+		assert.Nil(t, err)
+		output := &bytes.Buffer{}
+		return &WafEngineImpl{
+			analysisOutput:         []WafOuput{{OutputType: "all", Writer: output}},
+			analysisOutputTemplate: tmpl,
+			config:                 wc,
+			bans:                   &WafBans{},
+			challenge:              NewWafChallenge(wc),
+			plugins:                map[string]WafEnginePlugin{},
+		}, output
+	}
+
+	request := func(contentType string, body string) *com.TaxsiCom {
+		u, _ := url.Parse("http://www.example.com/api")
+		return &com.TaxsiCom{
+			Method:  "POST",
+			Url:     u,
+			Headers: map[string][]string{"Content-Type": {contentType}},
+			Body:    []byte(body),
+		}
+	}
+
+	t.Run("happy path: well formed bodies pass", func(t *testing.T) {
+		we, output := newEngine(t, map[string]string{})
+		assert.Equal(t, VERDICT_PASS, we.Scan(context.Background(), request("application/json", `{"user": {"name": "bob"}}`)))
+		assert.Equal(t, "pass  \n", output.String())
+	})
+
+	t.Run("not happy path: malformed bodies are blocked", func(t *testing.T) {
+		we, output := newEngine(t, map[string]string{})
+		assert.Equal(t, VERDICT_BLOCK, we.Scan(context.Background(), request("application/json", `{"user": `)))
+		assert.Contains(t, output.String(), "blocked malformed_body invalid JSON body")
+	})
+
+	t.Run("happy path: malformed bodies can be allowed", func(t *testing.T) {
+		we, _ := newEngine(t, map[string]string{"malformed_body_action": "allow"})
+		assert.Equal(t, VERDICT_PASS, we.Scan(context.Background(), request("application/json", `{"user": `)))
+	})
+
+	t.Run("happy path: dryrun", func(t *testing.T) {
+		we, output := newEngine(t, map[string]string{"mode": "dryrun"})
+		assert.Equal(t, VERDICT_PASS, we.Scan(context.Background(), request("application/xml", `<user><name>bob</user>`)))
+		assert.Contains(t, output.String(), "dryrun malformed_body invalid XML body")
+	})
+
+	t.Run("happy path: the reason is the plugin name", func(t *testing.T) {
+		we, output := newEngine(t, map[string]string{"plugin_challenging": "enabled"})
+		we.RegisterPlugin(&challengingPlugin{})
+		assert.Equal(t, VERDICT_CHALLENGE, we.Scan(context.Background(), request("text/plain", "hello")))
+		assert.Equal(t, "challenged challenging \n", output.String())
+	})
+}
@@ -0,0 +1,165 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/nzin/taxsi2/internal/com"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// output variable (the plugin that timed out or panicked)
+	VARIABLE_SCAN_ERROR = "scan_error"
+)
+
+/*
+WafEngineInterruptiblePlugin is implemented by the plugins that return
+soon once their context is done (they check it, or never block): when
+Interruptible returns true, they run inline, on the payload itself,
+without a goroutine nor a fork of the payload
+*/
+type WafEngineInterruptiblePlugin interface {
+	WafEnginePlugin
+	Interruptible() bool
+}
+
+/*
+PluginStats counts the failures of a plugin
+*/
+type PluginStats struct {
+	Plugin   string
+	Timeouts int64
+	Panics   int64
+}
+
+/*
+WafPluginStats are the failure counters of all the plugins
+(the zero value is ready to use)
+*/
+type WafPluginStats struct {
+	mu       sync.Mutex
+	counters map[string]*PluginStats
+}
+
+func (ws *WafPluginStats) record(plugin string, timeout bool) {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+	if ws.counters == nil {
+		ws.counters = make(map[string]*PluginStats)
+	}
+	c, ok := ws.counters[plugin]
+	if !ok {
+		c = &PluginStats{Plugin: plugin}
+		ws.counters[plugin] = c
+	}
+	if timeout {
+		c.Timeouts++
+	} else {
+		c.Panics++
+	}
+}
+
+/*
+Snapshot returns the counters, sorted by plugin name
+*/
+func (ws *WafPluginStats) Snapshot() []PluginStats {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+	stats := make([]PluginStats, 0, len(ws.counters))
+	for _, c := range ws.counters {
+		stats = append(stats, *c)
+	}
+	sort.Slice(stats, func(a, b int) bool { return stats[a].Plugin < stats[b].Plugin })
+	return stats
+}
+
+/*
+scanPlugin runs a plugin with its deadline, and recovers from its panics.
+A plugin that times out keeps running in the background (it should stop
+when its context is done), but its verdict is ignored. As it runs on a
+fork of the payload, whose variables and claims are only merged back if
+it is done in time, it can't change the request of the next plugins.
+The interruptible plugins run inline, and their verdict is ignored as
+well when they return after the deadline.
+A cancelled scan (the request is gone, or the verdict is already known,
+see scanParallel) passes
+*/
+func (we *WafEngineImpl) scanPlugin(ctx context.Context, name string, plugin WafEnginePlugin, payload *com.TaxsiCom) Verdict {
+	config := we.config.Settings()
+	timeout := config.ScanTimeoutFor(name)
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	if ctx.Done() == nil {
+		// (nothing can interrupt the scan)
+		return we.scanPluginSafe(ctx, name, plugin, payload)
+	}
+	if p, ok := plugin.(WafEngineInterruptiblePlugin); ok && p.Interruptible() {
+		verdict := we.scanPluginSafe(ctx, name, plugin, payload)
+		if ctx.Err() != nil {
+			return we.scanInterrupted(ctx, name, timeout, payload)
+		}
+		return verdict
+	}
+
+	fork := payload.Fork()
+	done := make(chan Verdict, 1)
+	go func() {
+		done <- we.scanPluginSafe(ctx, name, plugin, fork)
+	}()
+	select {
+	case verdict := <-done:
+		payload.Merge(fork)
+		return verdict
+	case <-ctx.Done():
+		return we.scanInterrupted(ctx, name, timeout, payload)
+	}
+}
+
+/*
+scanInterrupted returns the verdict of a plugin whose context is done:
+the failure verdict when it timed out, pass when the scan is cancelled
+*/
+func (we *WafEngineImpl) scanInterrupted(ctx context.Context, name string, timeout time.Duration, payload *com.TaxsiCom) Verdict {
+	if !errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return VERDICT_PASS
+	}
+	we.stats.record(name, true)
+	logrus.Warnf("plugin %s timed out after %v", name, timeout)
+	return we.scanFailure(name, payload, fmt.Sprintf("%s: %v", name, ctx.Err()))
+}
+
+func (we *WafEngineImpl) scanPluginSafe(ctx context.Context, name string, plugin WafEnginePlugin, payload *com.TaxsiCom) (verdict Verdict) {
+	defer func() {
+		if r := recover(); r != nil {
+			we.stats.record(name, false)
+			logrus.Errorf("plugin %s panicked: %v\n%s", name, r, debug.Stack())
+			verdict = we.scanFailure(name, payload, fmt.Sprintf("%s: panic: %v", name, r))
+		}
+	}()
+	return scanPlugin(ctx, plugin, payload)
+}
+
+/*
+scanFailure returns the verdict of a plugin that timed out or panicked
+*/
+func (we *WafEngineImpl) scanFailure(name string, payload *com.TaxsiCom, reason string) Verdict {
+	payload.SetVariable(VARIABLE_SCAN_ERROR, reason)
+	config := we.config.Settings()
+	if config.FailClosed(name) {
+		return VERDICT_BLOCK
+	}
+	return VERDICT_PASS
+}
+
+func (we *WafEngineImpl) PluginStats() []PluginStats {
+	return we.stats.Snapshot()
+}
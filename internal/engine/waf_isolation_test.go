@@ -0,0 +1,213 @@
+package engine
+
+import (
+	"bytes"
+	"context"
+	"html/template"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/nzin/taxsi2/internal/com"
+	"github.com/stretchr/testify/assert"
+)
+
+/*
+ * This is a mock implementation of a plugin that blocks after a delay
+ * (or when its context is done)
+ */
+type slowPlugin struct {
+	delay         time.Duration
+	interruptible bool
+	// the payload given to the plugin
+	payload *com.TaxsiCom
+}
+
+func (p *slowPlugin) Name() string {
+	return "slow"
+}
+func (p *slowPlugin) Interruptible() bool {
+	return p.interruptible
+}
+func (p *slowPlugin) Scan(ctx context.Context, payload *com.TaxsiCom) bool {
+	p.payload = payload
+	select {
+	case <-time.After(p.delay):
+	case <-ctx.Done():
+	}
+	return false
+}
+
+/*
+ * This is a mock implementation of a buggy plugin
+ */
+type panickingPlugin struct{}
+
+func (p *panickingPlugin) Name() string {
+	return "panicking"
+}
+func (p *panickingPlugin) Scan(ctx context.Context, payload *com.TaxsiCom) bool {
+	var m map[string]string
+	m["boom"] = "boom"
+	return true
+}
+
+/*
+ * This is a mock implementation of a plugin that ignores its context,
+ * and sets the claims once done
+ */
+type stubbornPlugin struct {
+	delay time.Duration
+}
+
+func (p *stubbornPlugin) Name() string {
+	return "stubborn"
+}
+func (p *stubbornPlugin) Scan(ctx context.Context, payload *com.TaxsiCom) bool {
+	time.Sleep(p.delay)
+	payload.SetClaims(map[string]string{"sub": "mallory"})
+	payload.SetVariable("stubborn", "done")
+	return true
+}
+
+func TestWafIsolation(t *testing.T) {
+	newEngine := func(t *testing.T, config map[string]string) (*WafEngineImpl, *bytes.Buffer) {
+		wc, err := NewWafConfig(&DbServiceConfigMock{config: config})
+		assert.Nil(t, err)
+		tmpl, err := template.New("outputformat").Parse("{{.Scanresult}} {{.Reason}} {{.Variables.scan_error}}\n")
+		assert.Nil(t, err)
+		output := &bytes.Buffer{}
+		we := &WafEngineImpl{
+			analysisOutput:         []WafOuput{{OutputType: "all", Writer: output}},
+			analysisOutputTemplate: tmpl,
+			config:                 wc,
+			bans:                   &WafBans{},
+			challenge:              NewWafChallenge(wc),
+			plugins:                map[string]WafEnginePlugin{},
+		}
+		return we, output
+	}
+
+	request := func() *com.TaxsiCom {
+		u, _ := url.Parse("http://www.example.com/")
+		return &com.TaxsiCom{Method: "GET", Url: u, Headers: map[string][]string{}}
+	}
+
+	t.Run("happy path: the config", func(t *testing.T) {
+		c := &DbServiceConfigMock{config: map[string]string{
+			"scan_timeout":         "50ms",
+			"scan_timeout_modsec":  "1s",
+			"scan_failure":         "closed",
+			"scan_failure_geoip":   "open",
+			"scan_timeout_ratelim": "foo",
+		}}
+		wc, err := NewWafConfig(c)
+		assert.Nil(t, err)
+		assert.Equal(t, 50*time.Millisecond, wc.ScanTimeoutFor("sqli"))
+		assert.Equal(t, 1*time.Second, wc.ScanTimeoutFor("modsec"))
+		assert.Equal(t, 50*time.Millisecond, wc.ScanTimeoutFor("ratelim"))
+		assert.True(t, wc.FailClosed("sqli"))
+		assert.False(t, wc.FailClosed("geoip"))
+
+		delete(c.config, "scan_failure")
+		wc.NotifyDbChange("scan_failure")
+		assert.False(t, wc.FailClosed("sqli"))
+	})
+
+	t.Run("happy path: a slow plugin fails open", func(t *testing.T) {
+		we, output := newEngine(t, map[string]string{"plugin_slow": "enabled", "scan_timeout": "10ms"})
+		we.RegisterPlugin(&slowPlugin{delay: 10 * time.Second})
+		assert.Equal(t, VERDICT_PASS, we.Scan(context.Background(), request()))
+		assert.Equal(t, "pass  slow: context deadline exceeded\n", output.String())
+		assert.Equal(t, []PluginStats{{Plugin: "slow", Timeouts: 1}}, we.PluginStats())
+	})
+
+	t.Run("not happy path: a slow plugin fails closed", func(t *testing.T) {
+		we, output := newEngine(t, map[string]string{
+			"plugin_slow":       "enabled",
+			"scan_timeout_slow": "10ms",
+			"scan_failure_slow": "closed",
+		})
+		we.RegisterPlugin(&slowPlugin{delay: 10 * time.Second})
+		assert.Equal(t, VERDICT_BLOCK, we.Scan(context.Background(), request()))
+		assert.Equal(t, "blocked slow slow: context deadline exceeded\n", output.String())
+	})
+
+	t.Run("happy path: a plugin within its deadline", func(t *testing.T) {
+		we, output := newEngine(t, map[string]string{"plugin_slow": "enabled", "scan_timeout": "5s"})
+		we.RegisterPlugin(&slowPlugin{delay: 1 * time.Millisecond})
+		assert.Equal(t, VERDICT_BLOCK, we.Scan(context.Background(), request()))
+		assert.Equal(t, "blocked slow \n", output.String())
+		assert.Equal(t, 0, len(we.PluginStats()))
+	})
+
+	t.Run("happy path: a panic is recovered", func(t *testing.T) {
+		for _, timeout := range []string{"0s", "1s"} {
+			we, output := newEngine(t, map[string]string{"plugin_panicking": "enabled", "scan_timeout": timeout})
+			we.RegisterPlugin(&panickingPlugin{})
+			assert.Equal(t, VERDICT_PASS, we.Scan(context.Background(), request()))
+			assert.Equal(t, "pass  panicking: panic: assignment to entry in nil map\n", output.String())
+			assert.Equal(t, []PluginStats{{Plugin: "panicking", Panics: 1}}, we.PluginStats())
+
+			we.config.ScanFailure = "closed"
+			assert.Equal(t, VERDICT_BLOCK, we.Scan(context.Background(), request()))
+			assert.Equal(t, []PluginStats{{Plugin: "panicking", Panics: 2}}, we.PluginStats())
+		}
+	})
+
+	t.Run("not happy path: the request is cancelled", func(t *testing.T) {
+		we, _ := newEngine(t, map[string]string{"plugin_slow": "enabled"})
+		we.RegisterPlugin(&slowPlugin{delay: 10 * time.Second})
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		assert.Equal(t, VERDICT_PASS, we.Scan(ctx, request()))
+		// it is not a timeout of the plugin
+		assert.Equal(t, 0, len(we.PluginStats()))
+	})
+	t.Run("not happy path: a plugin that times out can't change the request", func(t *testing.T) {
+		we, _ := newEngine(t, map[string]string{"plugin_stubborn": "enabled", "scan_timeout": "10ms"})
+		we.RegisterPlugin(&stubbornPlugin{delay: 50 * time.Millisecond})
+		payload := request()
+		assert.Equal(t, VERDICT_PASS, we.Scan(context.Background(), payload))
+
+		// the plugin is done by now
+		time.Sleep(100 * time.Millisecond)
+		assert.Nil(t, payload.Claims)
+		assert.Equal(t, "", payload.CopyVariables()["stubborn"])
+	})
+
+	t.Run("happy path: the changes of a plugin within its deadline are kept", func(t *testing.T) {
+		we, _ := newEngine(t, map[string]string{"plugin_stubborn": "enabled", "scan_timeout": "5s"})
+		we.RegisterPlugin(&stubbornPlugin{delay: 1 * time.Millisecond})
+		payload := request()
+		assert.Equal(t, VERDICT_PASS, we.Scan(context.Background(), payload))
+		assert.Equal(t, "mallory", payload.Claims["sub"])
+		assert.Equal(t, "done", payload.CopyVariables()["stubborn"])
+	})
+	t.Run("happy path: an interruptible plugin runs inline", func(t *testing.T) {
+		we, output := newEngine(t, map[string]string{"plugin_slow": "enabled", "scan_timeout": "5s"})
+		plugin := &slowPlugin{delay: 1 * time.Millisecond, interruptible: true}
+		we.RegisterPlugin(plugin)
+		payload := request()
+		assert.Equal(t, VERDICT_BLOCK, we.Scan(context.Background(), payload))
+		assert.Equal(t, "blocked slow \n", output.String())
+		// (not a fork)
+		assert.True(t, plugin.payload == payload)
+	})
+
+	t.Run("not happy path: an interruptible plugin times out", func(t *testing.T) {
+		we, output := newEngine(t, map[string]string{"plugin_slow": "enabled", "scan_timeout": "10ms"})
+		we.RegisterPlugin(&slowPlugin{delay: 10 * time.Second, interruptible: true})
+		assert.Equal(t, VERDICT_PASS, we.Scan(context.Background(), request()))
+		assert.Equal(t, "pass  slow: context deadline exceeded\n", output.String())
+		assert.Equal(t, []PluginStats{{Plugin: "slow", Timeouts: 1}}, we.PluginStats())
+
+		// the request is cancelled
+		we, _ = newEngine(t, map[string]string{"plugin_slow": "enabled"})
+		we.RegisterPlugin(&slowPlugin{delay: 10 * time.Second, interruptible: true})
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		assert.Equal(t, VERDICT_PASS, we.Scan(ctx, request()))
+		assert.Equal(t, 0, len(we.PluginStats()))
+	})
+}
@@ -0,0 +1,39 @@
+package engine
+
+import (
+	"fmt"
+	"net"
+	"strings"
+)
+
+/*
+ParseNetworks parses a comma separated list of CIDR networks.
+The invalid networks are skipped, and the first one is reported
+*/
+func ParseNetworks(list string) ([]*net.IPNet, error) {
+	var firstErr error
+	networks := []*net.IPNet{}
+	for _, n := range strings.Split(list, ",") {
+		_, network, err := net.ParseCIDR(strings.TrimSpace(n))
+		if err != nil {
+			if firstErr == nil {
+				firstErr = fmt.Errorf("not able to parse net %s: %v", n, err)
+			}
+			continue
+		}
+		networks = append(networks, network)
+	}
+	return networks, firstErr
+}
+
+/*
+IsIpInNetworks tells if an IP belongs to one of the networks
+*/
+func IsIpInNetworks(ip net.IP, networks []*net.IPNet) bool {
+	for _, n := range networks {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,25 @@
+package engine
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNetworks(t *testing.T) {
+	t.Run("happy path: parsing networks", func(t *testing.T) {
+		networks, err := ParseNetworks("10.0.0.0/8, fc00::/7")
+		assert.Nil(t, err)
+		assert.Equal(t, 2, len(networks))
+		assert.True(t, IsIpInNetworks(net.ParseIP("10.1.2.3"), networks))
+		assert.True(t, IsIpInNetworks(net.ParseIP("fd00::1"), networks))
+		assert.False(t, IsIpInNetworks(net.ParseIP("8.8.8.8"), networks))
+	})
+
+	t.Run("not happy path: invalid networks are skipped", func(t *testing.T) {
+		networks, err := ParseNetworks("10.0.0.0/8,10.0.0.1,192.168.0.0/16")
+		assert.NotNil(t, err)
+		assert.Equal(t, 2, len(networks))
+	})
+}
@@ -0,0 +1,132 @@
+package engine
+
+import (
+	"context"
+	"sync"
+
+	"github.com/nzin/taxsi2/internal/com"
+)
+
+/*
+WafEngineDependentPlugin is implemented by the plugins that use the
+results of other plugins (like the claims set by the jwt plugin): in
+parallel mode, they run once these plugins are done.
+Only the plugins registered before are taken into account (like in the
+serial mode)
+*/
+type WafEngineDependentPlugin interface {
+	WafEnginePlugin
+	DependsOn() []string
+}
+
+/*
+scanPlugins returns the verdicts of the plugins, in the order of the names
+(a challenge that is already solved passes). With stopOnBlock, the
+plugins after the first one that does not pass are not run
+*/
+func (we *WafEngineImpl) scanPlugins(ctx context.Context, payload *com.TaxsiCom, names []string, stopOnBlock bool) []Verdict {
+	if we.config.Settings().ScanParallel && len(names) > 1 {
+		return we.scanParallel(ctx, payload, names, stopOnBlock)
+	}
+	verdicts := make([]Verdict, len(names))
+	for i, name := range names {
+		verdicts[i] = we.pluginVerdict(ctx, name, payload)
+		if stopOnBlock && verdicts[i] != VERDICT_PASS {
+			break
+		}
+	}
+	return verdicts
+}
+
+func (we *WafEngineImpl) pluginVerdict(ctx context.Context, name string, payload *com.TaxsiCom) Verdict {
+	verdict := we.scanPlugin(ctx, name, we.plugins[name], payload)
+	if verdict == VERDICT_CHALLENGE && we.challenge.Verify(payload) {
+		// already solved
+		return VERDICT_PASS
+	}
+	return verdict
+}
+
+/*
+scanParallel runs the plugins concurrently, wave by wave (see waves).
+The verdicts are the same as the serial ones: with stopOnBlock, a plugin
+that does not pass cancels the plugins after it (in the scan order), but
+the plugins before it still run, and may take over
+*/
+func (we *WafEngineImpl) scanParallel(ctx context.Context, payload *com.TaxsiCom, names []string, stopOnBlock bool) []Verdict {
+	ctxs := make([]context.Context, len(names))
+	cancels := make([]context.CancelFunc, len(names))
+	for i := range names {
+		ctxs[i], cancels[i] = context.WithCancel(ctx)
+	}
+	defer func() {
+		for _, cancel := range cancels {
+			cancel()
+		}
+	}()
+
+	var mu sync.Mutex
+	verdicts := make([]Verdict, len(names))
+	// the first plugin (in the scan order) that does not pass
+	first := len(names)
+	for _, wave := range we.waves(names) {
+		var wg sync.WaitGroup
+		for _, i := range wave {
+			mu.Lock()
+			skip := i > first
+			mu.Unlock()
+			if skip {
+				continue
+			}
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				verdict := we.pluginVerdict(ctxs[i], names[i], payload)
+
+				mu.Lock()
+				defer mu.Unlock()
+				verdicts[i] = verdict
+				if stopOnBlock && verdict != VERDICT_PASS && i < first {
+					for j := i + 1; j < first; j++ {
+						cancels[j]()
+					}
+					first = i
+				}
+			}(i)
+		}
+		wg.Wait()
+	}
+
+	// (the plugins after the first one are cancelled)
+	for i := first + 1; i < len(names); i++ {
+		verdicts[i] = VERDICT_PASS
+	}
+	return verdicts
+}
+
+/*
+waves groups the plugins (by index) so that the plugins of a wave only
+depend on the plugins of the previous waves
+*/
+func (we *WafEngineImpl) waves(names []string) [][]int {
+	index := make(map[string]int, len(names))
+	for i, name := range names {
+		index[name] = i
+	}
+	levels := make([]int, len(names))
+	waves := [][]int{}
+	for i, name := range names {
+		if p, ok := we.plugins[name].(WafEngineDependentPlugin); ok {
+			for _, dependency := range p.DependsOn() {
+				if j, ok := index[dependency]; ok && j < i && levels[j]+1 > levels[i] {
+					levels[i] = levels[j] + 1
+				}
+			}
+		}
+		if levels[i] == len(waves) {
+			waves = append(waves, []int{})
+		}
+		waves[levels[i]] = append(waves[levels[i]], i)
+	}
+	return waves
+}
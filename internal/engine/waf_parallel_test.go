@@ -0,0 +1,248 @@
+package engine
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"html/template"
+	"io"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/nzin/taxsi2/internal/com"
+	"github.com/stretchr/testify/assert"
+)
+
+/*
+ * This is a mock implementation of a plugin that answers after a delay
+ * (it stops when its context is done)
+ */
+type delayedPlugin struct {
+	name      string
+	delay     time.Duration
+	pass      bool
+	dependsOn []string
+	cancelled atomic.Bool
+}
+
+func (p *delayedPlugin) Name() string {
+	return p.name
+}
+func (p *delayedPlugin) DependsOn() []string {
+	return p.dependsOn
+}
+func (p *delayedPlugin) Scan(ctx context.Context, payload *com.TaxsiCom) bool {
+	select {
+	case <-time.After(p.delay):
+	case <-ctx.Done():
+		p.cancelled.Store(true)
+	}
+	return p.pass
+}
+
+/*
+ * This is a mock implementation of plugins that share the claims
+ * (the second one passes only if it runs after the first one)
+ */
+type claimingPlugin struct {
+	name string
+}
+
+func (p *claimingPlugin) Name() string {
+	return p.name
+}
+func (p *claimingPlugin) Scan(ctx context.Context, payload *com.TaxsiCom) bool {
+	time.Sleep(5 * time.Millisecond)
+	payload.SetClaims(map[string]string{"sub": "bob"})
+	return true
+}
+
+type claimsPlugin struct{}
+
+func (p *claimsPlugin) Name() string {
+	return "claims"
+}
+func (p *claimsPlugin) DependsOn() []string {
+	return []string{"claiming"}
+}
+func (p *claimsPlugin) Scan(ctx context.Context, payload *com.TaxsiCom) bool {
+	return payload.Claims["sub"] == "bob"
+}
+
+/*
+ * This is a mock implementation of a (cpu bound) plugin for the benchmarks
+ */
+type hashingPlugin struct {
+	name          string
+	interruptible bool
+}
+
+func (p *hashingPlugin) Name() string {
+	return p.name
+}
+func (p *hashingPlugin) Interruptible() bool {
+	return p.interruptible
+}
+func (p *hashingPlugin) Scan(ctx context.Context, payload *com.TaxsiCom) bool {
+	sum := sha256.Sum256(payload.Body)
+	for i := 0; i < 200; i++ {
+		sum = sha256.Sum256(sum[:])
+	}
+	return sum[0] != 0 || sum[1] != 0 || sum[2] != 0 || sum[3] != 0
+}
+
+func newParallelEngine(t testing.TB, config map[string]string, output io.Writer) *WafEngineImpl {
+	wc, err := NewWafConfig(&DbServiceConfigMock{config: config})
+	assert.Nil(t, err)
+	tmpl, err := template.New("outputformat").Parse("{{.Scanresult}} {{.Reason}} {{.Variables.score_breakdown}}\n")
+	assert.Nil(t, err)
+	return &WafEngineImpl{
+		analysisOutput:         []WafOuput{{OutputType: "all", Writer: output}},
+		analysisOutputTemplate: tmpl,
+		config:                 wc,
+		bans:                   &WafBans{},
+		challenge:              NewWafChallenge(wc),
+		plugins:                map[string]WafEnginePlugin{},
+	}
+}
+
+func TestWafParallel(t *testing.T) {
+	request := func() *com.TaxsiCom {
+		u, _ := url.Parse("http://www.example.com/")
+		return &com.TaxsiCom{Method: "GET", Url: u, Headers: map[string][]string{}}
+	}
+	enabled := func(config map[string]string, names ...string) map[string]string {
+		for _, name := range names {
+			config["plugin_"+name] = "enabled"
+		}
+		return config
+	}
+
+	t.Run("happy path: the plugins run in the order of registration", func(t *testing.T) {
+		for i := 0; i < 10; i++ {
+			output := &bytes.Buffer{}
+			we := newParallelEngine(t, enabled(map[string]string{}, "a", "b", "c"), output)
+			we.RegisterPlugin(&delayedPlugin{name: "a", pass: true})
+			we.RegisterPlugin(&delayedPlugin{name: "b"})
+			we.RegisterPlugin(&delayedPlugin{name: "c"})
+			assert.Equal(t, VERDICT_BLOCK, we.Scan(context.Background(), request()))
+			assert.Equal(t, "blocked b \n", output.String())
+		}
+	})
+
+	t.Run("not happy path: a block cancels the next plugins", func(t *testing.T) {
+		output := &bytes.Buffer{}
+		we := newParallelEngine(t, enabled(map[string]string{"scan_parallel": "enabled"}, "a", "b", "c"), output)
+		a := &delayedPlugin{name: "a", delay: 20 * time.Millisecond, pass: true}
+		b := &delayedPlugin{name: "b", delay: 1 * time.Millisecond}
+		c := &delayedPlugin{name: "c", delay: 10 * time.Second}
+		we.RegisterPlugin(a)
+		we.RegisterPlugin(b)
+		we.RegisterPlugin(c)
+
+		start := time.Now()
+		assert.Equal(t, VERDICT_BLOCK, we.Scan(context.Background(), request()))
+		assert.Less(t, time.Since(start), 5*time.Second)
+		assert.Equal(t, "blocked b \n", output.String())
+		assert.False(t, a.cancelled.Load())
+		assert.True(t, c.cancelled.Load())
+		// (a cancellation is not a timeout)
+		assert.Equal(t, 0, len(we.PluginStats()))
+	})
+
+	t.Run("not happy path: the verdict is the serial one", func(t *testing.T) {
+		for _, parallel := range []string{"disabled", "enabled"} {
+			output := &bytes.Buffer{}
+			we := newParallelEngine(t, enabled(map[string]string{"scan_parallel": parallel}, "a", "b"), output)
+			// b blocks first, but a is before it
+			we.RegisterPlugin(&delayedPlugin{name: "a", delay: 20 * time.Millisecond})
+			we.RegisterPlugin(&delayedPlugin{name: "b", delay: 1 * time.Millisecond})
+			assert.Equal(t, VERDICT_BLOCK, we.Scan(context.Background(), request()))
+			assert.Equal(t, "blocked a \n", output.String(), parallel)
+		}
+	})
+
+	t.Run("happy path: the scores are combined in parallel", func(t *testing.T) {
+		for _, parallel := range []string{"disabled", "enabled"} {
+			output := &bytes.Buffer{}
+			we := newParallelEngine(t, enabled(map[string]string{
+				"scan_parallel":     parallel,
+				"scoring_mode":      "enabled",
+				"scoring_threshold": "30",
+			}, "a", "b", "c", "d"), output)
+			we.RegisterPlugin(&delayedPlugin{name: "a", delay: 5 * time.Millisecond})
+			we.RegisterPlugin(&delayedPlugin{name: "b", delay: 1 * time.Millisecond, pass: true})
+			we.RegisterPlugin(&delayedPlugin{name: "c", delay: 1 * time.Millisecond})
+			we.RegisterPlugin(&delayedPlugin{name: "d"})
+			assert.Equal(t, VERDICT_BLOCK, we.Scan(context.Background(), request()))
+			assert.Equal(t, "blocked score a:10,c:10,d:10\n", output.String(), parallel)
+		}
+	})
+
+	t.Run("happy path: the dependent plugins run after their dependencies", func(t *testing.T) {
+		for i := 0; i < 10; i++ {
+			output := &bytes.Buffer{}
+			we := newParallelEngine(t, enabled(map[string]string{"scan_parallel": "enabled"}, "claiming", "claims", "a"), output)
+			we.RegisterPlugin(&claimingPlugin{name: "claiming"})
+			we.RegisterPlugin(&claimsPlugin{})
+			we.RegisterPlugin(&delayedPlugin{name: "a", pass: true})
+			assert.Equal(t, VERDICT_PASS, we.Scan(context.Background(), request()))
+		}
+	})
+
+	t.Run("happy path: waves", func(t *testing.T) {
+		we := newParallelEngine(t, map[string]string{}, io.Discard)
+		we.RegisterPlugin(&delayedPlugin{name: "a"})
+		we.RegisterPlugin(&delayedPlugin{name: "b", dependsOn: []string{"a"}})
+		we.RegisterPlugin(&delayedPlugin{name: "c"})
+		we.RegisterPlugin(&delayedPlugin{name: "d", dependsOn: []string{"b", "c"}})
+		// e depends on a plugin registered after it (ignored)
+		we.RegisterPlugin(&delayedPlugin{name: "e", dependsOn: []string{"f"}})
+		we.RegisterPlugin(&delayedPlugin{name: "f"})
+		assert.Equal(t, [][]int{{0, 2, 4, 5}, {1}, {3}}, we.waves([]string{"a", "b", "c", "d", "e", "f"}))
+		// b is disabled
+		assert.Equal(t, [][]int{{0, 1}, {2}}, we.waves([]string{"a", "c", "d"}))
+	})
+}
+
+/*
+BenchmarkWafScan compares the serial and the parallel scans, with plugins
+that all pass: cpu bound ones (the parallel scan needs several cores),
+the same ones run inline (see WafEngineInterruptiblePlugin),
+and io bound ones (like the ssrf resolutions, or the cluster ratelimits)
+*/
+func BenchmarkWafScan(b *testing.B) {
+	u, _ := url.Parse("http://www.example.com/")
+	plugins := map[string]func(name string) WafEnginePlugin{
+		"cpu": func(name string) WafEnginePlugin { return &hashingPlugin{name: name} },
+		"cpu-inline": func(name string) WafEnginePlugin {
+			return &hashingPlugin{name: name, interruptible: true}
+		},
+		"io": func(name string) WafEnginePlugin {
+			return &delayedPlugin{name: name, delay: 100 * time.Microsecond, pass: true}
+		},
+	}
+	for _, kind := range []string{"cpu", "cpu-inline", "io"} {
+		for _, parallel := range []string{"disabled", "enabled"} {
+			for _, count := range []int{1, 4, 8, 16} {
+				config := map[string]string{"scan_parallel": parallel}
+				for i := 0; i < count; i++ {
+					config[fmt.Sprintf("plugin_p%d", i)] = "enabled"
+				}
+				we := newParallelEngine(b, config, io.Discard)
+				for i := 0; i < count; i++ {
+					we.RegisterPlugin(plugins[kind](fmt.Sprintf("p%d", i)))
+				}
+
+				b.Run(fmt.Sprintf("%s/parallel=%s/plugins=%d", kind, parallel, count), func(b *testing.B) {
+					for i := 0; i < b.N; i++ {
+						we.Scan(context.Background(), &com.TaxsiCom{Method: "GET", Url: u, Body: []byte("hello")})
+					}
+				})
+			}
+		}
+	}
+}
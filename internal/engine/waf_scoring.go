@@ -0,0 +1,165 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/nzin/taxsi2/internal/com"
+)
+
+const (
+	// reason of the verdict when the score reaches the threshold
+	REASON_SCORE = "score"
+
+	// output variables
+	// the total score of the plugins
+	VARIABLE_SCORE = "score"
+	// the threshold applied to the request
+	VARIABLE_SCORE_THRESHOLD = "score_threshold"
+	// the score of each (blocking) plugin, like "sqli:10,xss:5"
+	VARIABLE_SCORE_BREAKDOWN = "score_breakdown"
+
+	// with these defaults, the scoring mode blocks like the first-match mode
+	DEFAULT_SCORING_THRESHOLD = 10
+	DEFAULT_SCORING_WEIGHT    = 10
+)
+
+/*
+ScoringPolicy is a threshold for the requests of a host and/or of a path
+prefix, like "api.example.com/admin=5" or "/public=20"
+*/
+type ScoringPolicy struct {
+	// "" for all the hosts
+	Host       string
+	PathPrefix string
+	Threshold  int
+}
+
+/*
+ParseScoringPolicies parses a comma separated list of [host]/path=threshold.
+The invalid policies are skipped, and the first one is reported
+*/
+func ParseScoringPolicies(list string) ([]ScoringPolicy, error) {
+	var firstErr error
+	policies := []ScoringPolicy{}
+	for _, p := range strings.Split(list, ",") {
+		if p = strings.TrimSpace(p); p == "" {
+			continue
+		}
+		prefix, value, _ := strings.Cut(p, "=")
+		threshold, err := strconv.Atoi(strings.TrimSpace(value))
+		slash := strings.Index(prefix, "/")
+		if err != nil || threshold <= 0 || slash < 0 {
+			if firstErr == nil {
+				firstErr = fmt.Errorf("not able to parse scoring policy %s (must be [host]/path=threshold)", p)
+			}
+			continue
+		}
+		policies = append(policies, ScoringPolicy{
+			Host:       strings.ToLower(prefix[:slash]),
+			PathPrefix: prefix[slash:],
+			Threshold:  threshold,
+		})
+	}
+	return policies, firstErr
+}
+
+/*
+scoringThreshold returns the threshold of the most specific policy
+(a host match wins, then the longest path prefix)
+*/
+func scoringThreshold(policies []ScoringPolicy, defaultThreshold int, host string, path string) int {
+	threshold := defaultThreshold
+	best := -1
+	for _, p := range policies {
+		if (p.Host != "" && !strings.EqualFold(p.Host, host)) || !strings.HasPrefix(path, p.PathPrefix) {
+			continue
+		}
+		specificity := len(p.PathPrefix)
+		if p.Host != "" {
+			specificity += 1 << 16
+		}
+		if specificity > best {
+			best = specificity
+			threshold = p.Threshold
+		}
+	}
+	return threshold
+}
+
+/*
+formatBreakdown formats the scores of the plugins, sorted by name
+*/
+func formatBreakdown(scores map[string]int) string {
+	names := make([]string, 0, len(scores))
+	for name := range scores {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	parts := make([]string, 0, len(names))
+	for _, name := range names {
+		parts = append(parts, fmt.Sprintf("%s:%d", name, scores[name]))
+	}
+	return strings.Join(parts, ",")
+}
+
+/*
+requestHost returns the host of a request (without the port)
+*/
+func requestHost(payload *com.TaxsiCom) string {
+	host := payload.Url.Host
+	if host == "" {
+		if h := payload.GetHeader("Host"); len(h) > 0 {
+			host = h[0]
+		}
+	}
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	return strings.ToLower(host)
+}
+
+/*
+scanScoring runs all the enabled plugins and adds the weights of the
+ones that block. The request is blocked when the total reaches the
+threshold, else it is challenged if a plugin asked for it (the first
+one, in the scan order)
+*/
+func (we *WafEngineImpl) scanScoring(ctx context.Context, payload *com.TaxsiCom, remoteAddr net.IP) Verdict {
+	config := we.config.Settings()
+	scores := map[string]int{}
+	total := 0
+	challenger := ""
+	names := we.enabledPlugins()
+	for i, verdict := range we.scanCached(ctx, payload, names, false) {
+		switch verdict {
+		case VERDICT_BLOCK:
+			weight := config.ScoringWeight(names[i])
+			scores[names[i]] = weight
+			total += weight
+		case VERDICT_CHALLENGE:
+			if challenger == "" {
+				challenger = names[i]
+			}
+		}
+	}
+
+	threshold := scoringThreshold(config.ScoringPolicies, config.ScoringThreshold,
+		requestHost(payload), payload.Parse().Path)
+	payload.SetVariable(VARIABLE_SCORE, strconv.Itoa(total))
+	payload.SetVariable(VARIABLE_SCORE_THRESHOLD, strconv.Itoa(threshold))
+	payload.SetVariable(VARIABLE_SCORE_BREAKDOWN, formatBreakdown(scores))
+
+	if total >= threshold {
+		return we.reject(payload, remoteAddr, VERDICT_BLOCK, REASON_SCORE)
+	}
+	if challenger != "" {
+		return we.reject(payload, remoteAddr, VERDICT_CHALLENGE, challenger)
+	}
+	we.output(payload, "pass", "")
+	return VERDICT_PASS
+}
@@ -0,0 +1,174 @@
+package engine
+
+import (
+	"bytes"
+	"context"
+	"html/template"
+	"net/url"
+	"testing"
+
+	"github.com/nzin/taxsi2/internal/com"
+	"github.com/stretchr/testify/assert"
+)
+
+/*
+ * This is a mock implementation of a named plugin that always blocks
+ */
+type namedBlockingPlugin struct {
+	name string
+}
+
+func (p *namedBlockingPlugin) Name() string {
+	return p.name
+}
+func (p *namedBlockingPlugin) Scan(ctx context.Context, payload *com.TaxsiCom) bool {
+	return false
+}
+
+func TestParseScoringPolicies(t *testing.T) {
+	t.Run("happy path: hosts and paths", func(t *testing.T) {
+		policies, err := ParseScoringPolicies("/admin=5, API.example.com/=20,api.example.com/public=30")
+		assert.Nil(t, err)
+		assert.Equal(t, []ScoringPolicy{
+			{Host: "", PathPrefix: "/admin", Threshold: 5},
+			{Host: "api.example.com", PathPrefix: "/", Threshold: 20},
+			{Host: "api.example.com", PathPrefix: "/public", Threshold: 30},
+		}, policies)
+	})
+
+	t.Run("not happy path: invalid policies are skipped", func(t *testing.T) {
+		policies, err := ParseScoringPolicies("/admin=5,api.example.com=20,/public=0,/api=foo")
+		assert.NotNil(t, err)
+		assert.Contains(t, err.Error(), "api.example.com=20")
+		assert.Equal(t, []ScoringPolicy{{Host: "", PathPrefix: "/admin", Threshold: 5}}, policies)
+	})
+}
+
+func TestScoringThreshold(t *testing.T) {
+	policies, err := ParseScoringPolicies("/admin=5,/admin/public=15,api.example.com/=20,api.example.com/admin=25")
+	assert.Nil(t, err)
+
+	assert.Equal(t, 10, scoringThreshold(policies, 10, "www.example.com", "/"))
+	assert.Equal(t, 5, scoringThreshold(policies, 10, "www.example.com", "/admin/users"))
+	assert.Equal(t, 15, scoringThreshold(policies, 10, "www.example.com", "/admin/public/logo.png"))
+	assert.Equal(t, 20, scoringThreshold(policies, 10, "api.example.com", "/users"))
+	// a host match wins over a longer path prefix
+	assert.Equal(t, 25, scoringThreshold(policies, 10, "api.example.com", "/admin/public/logo.png"))
+}
+
+func TestFormatBreakdown(t *testing.T) {
+	assert.Equal(t, "", formatBreakdown(map[string]int{}))
+	assert.Equal(t, "sqli:10,xss:5", formatBreakdown(map[string]int{"xss": 5, "sqli": 10}))
+}
+
+func TestWafScoring(t *testing.T) {
+	newEngine := func(t *testing.T, config map[string]string) (*WafEngineImpl, *bytes.Buffer) {
+		wc, err := NewWafConfig(&DbServiceConfigMock{config: config})
+		assert.Nil(t, err)
+		tmpl, err := template.New("outputformat").Parse("{{.Scanresult}} {{.Reason}} {{.Variables.score}}/{{.Variables.score_threshold}} {{.Variables.score_breakdown}}\n")
+		assert.Nil(t, err)
+		output := &bytes.Buffer{}
+		we := &WafEngineImpl{
+			analysisOutput:         []WafOuput{{OutputType: "all", Writer: output}},
+			analysisOutputTemplate: tmpl,
+			config:                 wc,
+			bans:                   &WafBans{},
+			challenge:              NewWafChallenge(wc),
+			plugins:                map[string]WafEnginePlugin{},
+		}
+		we.RegisterPlugin(&namedBlockingPlugin{name: "sqli"})
+		we.RegisterPlugin(&namedBlockingPlugin{name: "xss"})
+		return we, output
+	}
+
+	request := func(rawurl string) *com.TaxsiCom {
+		u, _ := url.Parse(rawurl)
+		return &com.TaxsiCom{
+			Method:  "GET",
+			Url:     u,
+			Headers: map[string][]string{},
+		}
+	}
+
+	t.Run("happy path: the config", func(t *testing.T) {
+		c := &DbServiceConfigMock{config: map[string]string{
+			"scoring_mode":        "enabled",
+			"scoring_threshold":   "15",
+			"scoring_weight_sqli": "0",
+			"scoring_policies":    "/admin=5",
+		}}
+		wc, err := NewWafConfig(c)
+		assert.Nil(t, err)
+		assert.True(t, wc.ScoringMode)
+		assert.Equal(t, 15, wc.ScoringThreshold)
+		assert.Equal(t, 0, wc.ScoringWeight("sqli"))
+		assert.Equal(t, DEFAULT_SCORING_WEIGHT, wc.ScoringWeight("xss"))
+		assert.Equal(t, 1, len(wc.ScoringPolicies))
+
+		delete(c.config, "scoring_weight_sqli")
+		wc.NotifyDbChange("scoring_weight_sqli")
+		assert.Equal(t, DEFAULT_SCORING_WEIGHT, wc.ScoringWeight("sqli"))
+	})
+
+	t.Run("not happy path: bad config values are ignored", func(t *testing.T) {
+		wc, err := NewWafConfig(&DbServiceConfigMock{config: map[string]string{
+			"scoring_mode":        "maybe",
+			"scoring_threshold":   "-1",
+			"scoring_weight_sqli": "foo",
+		}})
+		assert.Nil(t, err)
+		assert.False(t, wc.ScoringMode)
+		assert.Equal(t, DEFAULT_SCORING_THRESHOLD, wc.ScoringThreshold)
+		assert.Equal(t, DEFAULT_SCORING_WEIGHT, wc.ScoringWeight("sqli"))
+	})
+
+	t.Run("not happy path: the total reaches the threshold", func(t *testing.T) {
+		we, output := newEngine(t, map[string]string{
+			"plugin_sqli":         "enabled",
+			"plugin_xss":          "enabled",
+			"scoring_mode":        "enabled",
+			"scoring_threshold":   "12",
+			"scoring_weight_sqli": "7",
+			"scoring_weight_xss":  "5",
+		})
+		assert.Equal(t, VERDICT_BLOCK, we.Scan(context.Background(), request("http://www.example.com/")))
+		assert.Equal(t, "blocked score 12/12 sqli:7,xss:5\n", output.String())
+	})
+
+	t.Run("happy path: the total is under the threshold", func(t *testing.T) {
+		we, output := newEngine(t, map[string]string{
+			"plugin_sqli":         "enabled",
+			"plugin_xss":          "enabled",
+			"scoring_mode":        "enabled",
+			"scoring_threshold":   "20",
+			"scoring_weight_sqli": "7",
+		})
+		assert.Equal(t, VERDICT_PASS, we.Scan(context.Background(), request("http://www.example.com/")))
+		assert.Equal(t, "pass  17/20 sqli:7,xss:10\n", output.String())
+	})
+
+	t.Run("happy path: the threshold of a policy", func(t *testing.T) {
+		we, output := newEngine(t, map[string]string{
+			"plugin_sqli":         "enabled",
+			"scoring_mode":        "enabled",
+			"scoring_threshold":   "20",
+			"scoring_weight_sqli": "7",
+			"scoring_policies":    "/admin=5,www.example.com/admin/public=30",
+		})
+		assert.Equal(t, VERDICT_BLOCK, we.Scan(context.Background(), request("http://www.example.com/admin/users")))
+		assert.Equal(t, VERDICT_PASS, we.Scan(context.Background(), request("http://WWW.example.com:8080/admin/public/logo.png")))
+		assert.Equal(t, "blocked score 7/5 sqli:7\npass  7/30 sqli:7\n", output.String())
+	})
+
+	t.Run("happy path: a challenge under the threshold", func(t *testing.T) {
+		we, output := newEngine(t, map[string]string{
+			"plugin_sqli":         "enabled",
+			"plugin_challenging":  "enabled",
+			"scoring_mode":        "enabled",
+			"scoring_weight_sqli": "5",
+		})
+		we.RegisterPlugin(&challengingPlugin{})
+		assert.Equal(t, VERDICT_CHALLENGE, we.Scan(context.Background(), request("http://www.example.com/")))
+		assert.Equal(t, "challenged challenging 5/10 sqli:5\n", output.String())
+	})
+}
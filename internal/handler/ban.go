@@ -0,0 +1,107 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/go-openapi/runtime/middleware"
+	"github.com/go-openapi/strfmt"
+	"github.com/nzin/taxsi2/internal/db"
+	"github.com/nzin/taxsi2/swagger_gen/models"
+	"github.com/nzin/taxsi2/swagger_gen/restapi/operations/admin"
+	"gorm.io/gorm"
+)
+
+func (c *crud) GetBans(params admin.GetBansParams) middleware.Responder {
+	bans, err := c.ds.GetActiveBans()
+	if err != nil {
+		return admin.NewGetBansDefault(500).WithPayload(
+			ErrorMessage("unable to read the bans: %v", err),
+		)
+	}
+
+	payload := []*models.Ban{}
+	for _, b := range bans {
+		payload = append(payload, banToModel(b))
+	}
+	return admin.NewGetBansOK().WithPayload(payload)
+}
+
+func (c *crud) PostBan(params admin.PostBanParams) middleware.Responder {
+	cidr, err := normalizeCidr(*params.Body.Cidr)
+	if err != nil {
+		return admin.NewPostBanDefault(400).WithPayload(
+			ErrorMessage("%v", err),
+		)
+	}
+	if *params.Body.Duration <= 0 {
+		return admin.NewPostBanDefault(400).WithPayload(
+			ErrorMessage("the duration must be a positive number of seconds"),
+		)
+	}
+
+	ban, err := c.ds.AddBan(
+		cidr,
+		params.Body.Reason,
+		*params.Body.Creator,
+		time.Now().Add(time.Duration(*params.Body.Duration)*time.Second),
+	)
+	if err != nil {
+		return admin.NewPostBanDefault(500).WithPayload(
+			ErrorMessage("unable to add the ban: %v", err),
+		)
+	}
+	return admin.NewPostBanCreated().WithPayload(banToModel(*ban))
+}
+
+func (c *crud) DeleteBan(params admin.DeleteBanParams) middleware.Responder {
+	err := c.ds.RemoveBan(uint(params.ID))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return admin.NewDeleteBanDefault(404).WithPayload(
+			ErrorMessage("ban %d not found", params.ID),
+		)
+	}
+	if err != nil {
+		return admin.NewDeleteBanDefault(500).WithPayload(
+			ErrorMessage("unable to remove the ban: %v", err),
+		)
+	}
+	return admin.NewDeleteBanOK()
+}
+
+func banToModel(b db.Ban) *models.Ban {
+	return &models.Ban{
+		ID:        int64(b.ID),
+		Cidr:      b.Cidr,
+		Reason:    b.Reason,
+		Creator:   b.Creator,
+		CreatedAt: strfmt.DateTime(b.CreatedAt),
+		ExpiresAt: strfmt.DateTime(b.ExpiresAt),
+	}
+}
+
+/*
+normalizeCidr accepts an IP (turned into a /32 or /128) or a CIDR
+*/
+func normalizeCidr(s string) (string, error) {
+	s = strings.TrimSpace(s)
+	if strings.Contains(s, "/") {
+		_, network, err := net.ParseCIDR(s)
+		if err != nil {
+			return "", fmt.Errorf("not able to parse %s: %v", s, err)
+		}
+		return network.String(), nil
+	}
+
+	ip := net.ParseIP(s)
+	if ip == nil {
+		return "", fmt.Errorf("not able to parse %s: not an IP or a CIDR", s)
+	}
+	if ip.To4() != nil {
+		return ip.String() + "/32", nil
+	}
+	return ip.String() + "/128", nil
+}
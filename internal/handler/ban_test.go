@@ -0,0 +1,112 @@
+package handler
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/nzin/taxsi2/internal/db"
+	"github.com/nzin/taxsi2/internal/util"
+	"github.com/nzin/taxsi2/swagger_gen/models"
+	"github.com/nzin/taxsi2/swagger_gen/restapi/operations/admin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBans(t *testing.T) {
+	t.Run("happy path: add, list and remove a ban", func(t *testing.T) {
+		tmpFile, err := os.CreateTemp("", "taxsi.temp*")
+		assert.Nil(t, err)
+		defer os.Remove(tmpFile.Name())
+
+		ds, err := db.NewDbService("sqlite3", tmpFile.Name(), 1, 1*time.Second)
+		assert.Nil(t, err)
+
+		c := crud{
+			ds:        ds,
+			wafEngine: nil,
+		}
+
+		duration := int64(7200)
+		res := c.PostBan(admin.PostBanParams{
+			Body: &models.BanCreate{
+				Cidr:     util.StringPtr("1.2.3.4"),
+				Reason:   "credential stuffing",
+				Creator:  util.StringPtr("alice"),
+				Duration: &duration,
+			},
+		})
+		created, ok := res.(*admin.PostBanCreated)
+		assert.True(t, ok)
+		assert.Equal(t, "1.2.3.4/32", created.Payload.Cidr)
+		assert.WithinDuration(t, time.Now().Add(2*time.Hour), time.Time(created.Payload.ExpiresAt), time.Minute)
+
+		res = c.GetBans(admin.GetBansParams{})
+		bans, ok := res.(*admin.GetBansOK)
+		assert.True(t, ok)
+		assert.Equal(t, 1, len(bans.Payload))
+		assert.Equal(t, "credential stuffing", bans.Payload[0].Reason)
+
+		res = c.DeleteBan(admin.DeleteBanParams{ID: created.Payload.ID})
+		_, ok = res.(*admin.DeleteBanOK)
+		assert.True(t, ok)
+
+		res = c.GetBans(admin.GetBansParams{})
+		bans, ok = res.(*admin.GetBansOK)
+		assert.True(t, ok)
+		assert.Equal(t, 0, len(bans.Payload))
+	})
+
+	t.Run("not happy path: bad inputs", func(t *testing.T) {
+		tmpFile, err := os.CreateTemp("", "taxsi.temp*")
+		assert.Nil(t, err)
+		defer os.Remove(tmpFile.Name())
+
+		ds, err := db.NewDbService("sqlite3", tmpFile.Name(), 1, 1*time.Second)
+		assert.Nil(t, err)
+
+		c := crud{
+			ds:        ds,
+			wafEngine: nil,
+		}
+
+		duration := int64(60)
+		res := c.PostBan(admin.PostBanParams{
+			Body: &models.BanCreate{
+				Cidr:     util.StringPtr("1.2.3.400"),
+				Creator:  util.StringPtr("alice"),
+				Duration: &duration,
+			},
+		})
+		_, ok := res.(*admin.PostBanDefault)
+		assert.True(t, ok)
+
+		duration = 0
+		res = c.PostBan(admin.PostBanParams{
+			Body: &models.BanCreate{
+				Cidr:     util.StringPtr("1.2.3.0/24"),
+				Creator:  util.StringPtr("alice"),
+				Duration: &duration,
+			},
+		})
+		_, ok = res.(*admin.PostBanDefault)
+		assert.True(t, ok)
+
+		res = c.DeleteBan(admin.DeleteBanParams{ID: 42})
+		notfound, ok := res.(*admin.DeleteBanDefault)
+		assert.True(t, ok)
+		assert.Equal(t, "ban 42 not found", *notfound.Payload.Message)
+	})
+}
+
+func TestNormalizeCidr(t *testing.T) {
+	cidr, err := normalizeCidr("10.1.2.3/8")
+	assert.Nil(t, err)
+	assert.Equal(t, "10.0.0.0/8", cidr)
+
+	cidr, err = normalizeCidr("::1")
+	assert.Nil(t, err)
+	assert.Equal(t, "::1/128", cidr)
+
+	_, err = normalizeCidr("foo")
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,70 @@
+package handler
+
+import (
+	"time"
+
+	"github.com/go-openapi/runtime/middleware"
+	"github.com/go-openapi/strfmt"
+	"github.com/nzin/taxsi2/internal/policy"
+	"github.com/nzin/taxsi2/swagger_gen/models"
+	"github.com/nzin/taxsi2/swagger_gen/restapi/operations/admin"
+)
+
+func (c *crud) GetConfigHistory(params admin.GetConfigHistoryParams) middleware.Responder {
+	key := ""
+	if params.Key != nil {
+		key = *params.Key
+	}
+	since := time.Time{}
+	if params.Since != nil {
+		since = time.Time(*params.Since)
+	}
+
+	history, err := c.ds.GetConfigHistory(key, since)
+	if err != nil {
+		return admin.NewGetConfigHistoryDefault(500).WithPayload(
+			ErrorMessage("unable to read the config history: %v", err),
+		)
+	}
+
+	payload := []*models.ConfigHistory{}
+	for _, h := range history {
+		payload = append(payload, &models.ConfigHistory{
+			ID:        int64(h.ID),
+			Key:       h.Key,
+			OldValue:  h.OldValue,
+			NewValue:  h.NewValue,
+			Existed:   h.Existed,
+			Removed:   h.Removed,
+			Author:    h.Author,
+			Comment:   h.Comment,
+			CreatedAt: strfmt.DateTime(h.CreatedAt),
+		})
+	}
+	return admin.NewGetConfigHistoryOK().WithPayload(payload)
+}
+
+func (c *crud) PostConfigRollback(params admin.PostConfigRollbackParams) middleware.Responder {
+	err := c.ds.RollbackConfig(
+		params.Body.Key,
+		time.Time(*params.Body.Timestamp),
+		*params.Body.Author,
+		params.Body.Comment,
+	)
+	if err != nil {
+		return admin.NewPostConfigRollbackDefault(500).WithPayload(
+			ErrorMessage("unable to rollback the config: %v", err),
+		)
+	}
+	return admin.NewPostConfigRollbackOK()
+}
+
+func (c *crud) GetConfigExport(params admin.GetConfigExportParams) middleware.Responder {
+	doc, err := policy.Export(c.ds)
+	if err != nil {
+		return admin.NewGetConfigExportDefault(500).WithPayload(
+			ErrorMessage("unable to export the config: %v", err),
+		)
+	}
+	return admin.NewGetConfigExportOK().WithPayload(doc)
+}
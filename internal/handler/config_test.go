@@ -0,0 +1,101 @@
+package handler
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/go-openapi/strfmt"
+	"github.com/nzin/taxsi2/internal/db"
+	"github.com/nzin/taxsi2/internal/policy"
+	"github.com/nzin/taxsi2/internal/util"
+	"github.com/nzin/taxsi2/swagger_gen/models"
+	"github.com/nzin/taxsi2/swagger_gen/restapi/operations/admin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigHistory(t *testing.T) {
+	t.Run("happy path: list and rollback", func(t *testing.T) {
+		tmpFile, err := os.CreateTemp("", "taxsi.temp*")
+		assert.Nil(t, err)
+		defer os.Remove(tmpFile.Name())
+
+		ds, err := db.NewDbService("sqlite3", tmpFile.Name(), 1, 1*time.Second)
+		assert.Nil(t, err)
+
+		c := crud{
+			ds:        ds,
+			wafEngine: nil,
+		}
+
+		err = ds.SetConfigValueForKey("mode", "enabled")
+		assert.Nil(t, err)
+		time.Sleep(10 * time.Millisecond)
+		checkpoint := time.Now()
+		time.Sleep(10 * time.Millisecond)
+		err = ds.SetConfigValueForKeyWithAuthor("mode", "disabled", "bob", "maintenance")
+		assert.Nil(t, err)
+
+		res := c.GetConfigHistory(admin.GetConfigHistoryParams{
+			Key: util.StringPtr("mode"),
+		})
+		history, ok := res.(*admin.GetConfigHistoryOK)
+		assert.True(t, ok)
+		assert.Equal(t, 2, len(history.Payload))
+		assert.Equal(t, "bob", history.Payload[1].Author)
+		assert.Equal(t, "disabled", history.Payload[1].NewValue)
+
+		timestamp := strfmt.DateTime(checkpoint)
+		res = c.PostConfigRollback(admin.PostConfigRollbackParams{
+			Body: &models.ConfigRollback{
+				Timestamp: &timestamp,
+				Author:    util.StringPtr("alice"),
+			},
+		})
+		_, ok = res.(*admin.PostConfigRollbackOK)
+		assert.True(t, ok)
+
+		value, err := ds.GetConfigValueForKey("mode")
+		assert.Nil(t, err)
+		assert.Equal(t, "enabled", value)
+
+		since := strfmt.DateTime(checkpoint)
+		res = c.GetConfigHistory(admin.GetConfigHistoryParams{
+			Since: &since,
+		})
+		history, ok = res.(*admin.GetConfigHistoryOK)
+		assert.True(t, ok)
+		assert.Equal(t, 2, len(history.Payload))
+		assert.Equal(t, "alice", history.Payload[1].Author)
+	})
+}
+
+func TestConfigExport(t *testing.T) {
+	t.Run("happy path: export the policy", func(t *testing.T) {
+		tmpFile, err := os.CreateTemp("", "taxsi.temp*")
+		assert.Nil(t, err)
+		defer os.Remove(tmpFile.Name())
+
+		ds, err := db.NewDbService("sqlite3", tmpFile.Name(), 1, 1*time.Second)
+		assert.Nil(t, err)
+
+		c := crud{
+			ds:        ds,
+			wafEngine: nil,
+		}
+
+		err = ds.SetConfigValueForKey("mode", "dryrun")
+		assert.Nil(t, err)
+		err = ds.SetConfigValueForKey("allowlist", "10.0.0.0/8")
+		assert.Nil(t, err)
+
+		res := c.GetConfigExport(admin.GetConfigExportParams{})
+		export, ok := res.(*admin.GetConfigExportOK)
+		assert.True(t, ok)
+
+		doc, ok := export.Payload.(*policy.Document)
+		assert.True(t, ok)
+		assert.Equal(t, "dryrun", doc.Config["mode"])
+		assert.Equal(t, []string{"10.0.0.0/8"}, doc.Allowlist)
+	})
+}
@@ -4,14 +4,29 @@ import (
 	"bufio"
 	"bytes"
 	"io"
+	"net"
 
 	"github.com/nzin/taxsi2/internal/com"
 	"github.com/nzin/taxsi2/internal/config"
 	"github.com/nzin/taxsi2/internal/db"
 	"github.com/nzin/taxsi2/internal/engine"
 	"github.com/nzin/taxsi2/internal/engine/plugins/axsi"
+	"github.com/nzin/taxsi2/internal/engine/plugins/botmanager"
 	"github.com/nzin/taxsi2/internal/engine/plugins/geoip"
+	"github.com/nzin/taxsi2/internal/engine/plugins/graphql"
+	"github.com/nzin/taxsi2/internal/engine/plugins/jwt"
+	"github.com/nzin/taxsi2/internal/engine/plugins/modsec"
+	"github.com/nzin/taxsi2/internal/engine/plugins/openapi"
+	"github.com/nzin/taxsi2/internal/engine/plugins/osattack"
+	"github.com/nzin/taxsi2/internal/engine/plugins/protocol"
+	"github.com/nzin/taxsi2/internal/engine/plugins/ratelimiter"
+	"github.com/nzin/taxsi2/internal/engine/plugins/rules"
+	"github.com/nzin/taxsi2/internal/engine/plugins/sqli"
+	"github.com/nzin/taxsi2/internal/engine/plugins/ssrf"
+	"github.com/nzin/taxsi2/internal/engine/plugins/upload"
+	"github.com/nzin/taxsi2/internal/engine/plugins/xss"
 	"github.com/nzin/taxsi2/swagger_gen/models"
+	"github.com/nzin/taxsi2/swagger_gen/restapi/operations/admin"
 	"github.com/nzin/taxsi2/swagger_gen/restapi/operations/health"
 	"github.com/nzin/taxsi2/swagger_gen/restapi/operations/waf"
 	"github.com/sirupsen/logrus"
@@ -24,6 +39,46 @@ type CRUD interface {
 	// healthcheck
 	GetHealthcheck(health.GetHealthParams) middleware.Responder
 	PostSubmit(waf.PostSubmitParams) middleware.Responder
+	// config audit trail
+	GetConfigHistory(admin.GetConfigHistoryParams) middleware.Responder
+	PostConfigRollback(admin.PostConfigRollbackParams) middleware.Responder
+	GetConfigExport(admin.GetConfigExportParams) middleware.Responder
+	// ip bans
+	GetBans(admin.GetBansParams) middleware.Responder
+	PostBan(admin.PostBanParams) middleware.Responder
+	DeleteBan(admin.DeleteBanParams) middleware.Responder
+	// rate limiter
+	GetRatelimits(admin.GetRatelimitsParams) middleware.Responder
+	PostRatelimit(admin.PostRatelimitParams) middleware.Responder
+	PutRatelimit(admin.PutRatelimitParams) middleware.Responder
+	DeleteRatelimit(admin.DeleteRatelimitParams) middleware.Responder
+	// upload inspection
+	GetUploadpolicies(admin.GetUploadpoliciesParams) middleware.Responder
+	PostUploadpolicy(admin.PostUploadpolicyParams) middleware.Responder
+	PutUploadpolicy(admin.PutUploadpolicyParams) middleware.Responder
+	DeleteUploadpolicy(admin.DeleteUploadpolicyParams) middleware.Responder
+	// openapi validation
+	GetOpenapiSpecs(admin.GetOpenapiSpecsParams) middleware.Responder
+	PutOpenapiSpec(admin.PutOpenapiSpecParams) middleware.Responder
+	DeleteOpenapiSpec(admin.DeleteOpenapiSpecParams) middleware.Responder
+	// jwt validation
+	GetJwtPolicies(admin.GetJwtPoliciesParams) middleware.Responder
+	PostJwtPolicy(admin.PostJwtPolicyParams) middleware.Responder
+	PutJwtPolicy(admin.PutJwtPolicyParams) middleware.Responder
+	DeleteJwtPolicy(admin.DeleteJwtPolicyParams) middleware.Responder
+	GetJwtKeys(admin.GetJwtKeysParams) middleware.Responder
+	PutJwtKey(admin.PutJwtKeyParams) middleware.Responder
+	DeleteJwtKey(admin.DeleteJwtKeyParams) middleware.Responder
+	// custom rules
+	GetRules(admin.GetRulesParams) middleware.Responder
+	PutRule(admin.PutRuleParams) middleware.Responder
+	DeleteRule(admin.DeleteRuleParams) middleware.Responder
+	// modsec rulesets
+	GetModsecRulesets(admin.GetModsecRulesetsParams) middleware.Responder
+	PutModsecRuleset(admin.PutModsecRulesetParams) middleware.Responder
+	DeleteModsecRuleset(admin.DeleteModsecRulesetParams) middleware.Responder
+	GetPluginStats(admin.GetPluginStatsParams) middleware.Responder
+	GetCacheStats(admin.GetCacheStatsParams) middleware.Responder
 }
 
 // NewCRUD creates a new CRUD instance
@@ -38,16 +93,23 @@ func NewCRUD() CRUD {
 		panic(err)
 	}
 
+	// the background loops run as long as the server
+	stopChannel := make(chan struct{})
+
 	e, err := engine.NewWafEngineImpl(
 		ds,
 		config.Config.WafOutput,
 		config.Config.WafOutputFormat,
+		stopChannel,
 	)
 	if err != nil {
 		panic(err)
 	}
 
 	// register scan plugins
+	protocolPlugin := protocol.NewProtocolWafPlugin(ds)
+	e.RegisterPlugin(protocolPlugin)
+
 	geoipPlugin, err := geoip.NewGeoipWafPlugin(config.Config.DefaultGeoipDbPath, config.Config.DefaultRemoteGeoipDbPath, ds)
 	if err != nil {
 		logrus.Errorf("unable to create geoip plugin: %v", err)
@@ -58,9 +120,70 @@ func NewCRUD() CRUD {
 	axsi := axsi.NewAxiWafPlugin(ds)
 	e.RegisterPlugin(axsi)
 
-	// for later
-	// - botmanager?
-	// - ratelimiter?
+	// (before the ratelimiter, that can use the claims as keys)
+	jwtPlugin, err := jwt.NewJwtWafPlugin(ds)
+	if err != nil {
+		logrus.Errorf("unable to create jwt plugin: %v", err)
+	} else {
+		e.RegisterPlugin(jwtPlugin)
+	}
+
+	ratelimiterPlugin, err := ratelimiter.NewRatelimiterWafPlugin(ds, stopChannel)
+	if err != nil {
+		logrus.Errorf("unable to create ratelimiter plugin: %v", err)
+	} else {
+		e.RegisterPlugin(ratelimiterPlugin)
+	}
+
+	botmanagerPlugin := botmanager.NewBotmanagerWafPlugin(ds, &net.Resolver{})
+	e.RegisterPlugin(botmanagerPlugin)
+
+	sqliPlugin := sqli.NewSqliWafPlugin()
+	e.RegisterPlugin(sqliPlugin)
+
+	xssPlugin := xss.NewXssWafPlugin()
+	e.RegisterPlugin(xssPlugin)
+
+	uploadPlugin, err := upload.NewUploadWafPlugin(ds)
+	if err != nil {
+		logrus.Errorf("unable to create upload plugin: %v", err)
+	} else {
+		e.RegisterPlugin(uploadPlugin)
+	}
+
+	openapiPlugin, err := openapi.NewOpenapiWafPlugin(ds)
+	if err != nil {
+		logrus.Errorf("unable to create openapi plugin: %v", err)
+	} else {
+		e.RegisterPlugin(openapiPlugin)
+	}
+
+	graphqlPlugin := graphql.NewGraphqlWafPlugin(ds)
+	e.RegisterPlugin(graphqlPlugin)
+	osattackPlugin := osattack.NewOsattackWafPlugin(ds)
+	e.RegisterPlugin(osattackPlugin)
+	ssrfPlugin := ssrf.NewSsrfWafPlugin(ds)
+	e.RegisterPlugin(ssrfPlugin)
+
+	// the country of the rules comes from the geoip database (if any)
+	countries, _ := geoipPlugin.(rules.CountryLookup)
+	rulesPlugin, err := rules.NewRulesWafPlugin(ds, countries)
+	if err != nil {
+		logrus.Errorf("unable to create rules plugin: %v", err)
+	} else {
+		e.RegisterPlugin(rulesPlugin)
+	}
+
+	modsecPlugin, err := modsec.NewModsecWafPlugin(ds)
+	if err != nil {
+		logrus.Errorf("unable to create modsec plugin: %v", err)
+	} else {
+		e.RegisterPlugin(modsecPlugin)
+	}
+
+	// propagate the changelog notifications (config, bans expiration...)
+	// once all the subscribers are registered
+	go ds.Watch(stopChannel)
 
 	return &crud{
 		ds:        ds,
@@ -94,9 +217,17 @@ func (c *crud) PostSubmit(params waf.PostSubmitParams) middleware.Responder {
 		)
 	}
 
-	res := c.wafEngine.Scan(t)
-	if !res {
+	switch c.wafEngine.Scan(params.HTTPRequest.Context(), t) {
+	case engine.VERDICT_BLOCK:
 		return &waf.PostSubmitForbidden{}
+	case engine.VERDICT_CHALLENGE:
+		challenge := c.wafEngine.Challenge(t)
+		return waf.NewPostSubmitUnauthorized().WithPayload(&models.Challenge{
+			Cookie:     challenge.Cookie,
+			Token:      challenge.Token,
+			Difficulty: int64(challenge.Difficulty),
+			Page:       challenge.Page,
+		})
 	}
 
 	return &waf.PostSubmitOK{}
@@ -2,6 +2,7 @@ package handler
 
 import (
 	"bytes"
+	"context"
 	"io"
 	"net/http"
 	"testing"
@@ -26,15 +27,33 @@ func TestHealth(t *testing.T) {
 }
 
 type WafEngineMock struct {
-	result bool
+	result engine.Verdict
+	stats  []engine.PluginStats
+	hits   int64
+	misses int64
+	size   int
 }
 
 func (we *WafEngineMock) RegisterPlugin(plugin engine.WafEnginePlugin) {
 
 }
-func (we *WafEngineMock) Scan(payload *com.TaxsiCom) bool {
+func (we *WafEngineMock) Scan(ctx context.Context, payload *com.TaxsiCom) engine.Verdict {
 	return we.result
 }
+func (we *WafEngineMock) PluginStats() []engine.PluginStats {
+	return we.stats
+}
+func (we *WafEngineMock) VerdictCacheStats() (int64, int64, int) {
+	return we.hits, we.misses, we.size
+}
+func (we *WafEngineMock) Challenge(payload *com.TaxsiCom) *engine.Challenge {
+	return &engine.Challenge{
+		Cookie:     engine.CHALLENGE_COOKIE,
+		Token:      "token",
+		Difficulty: 1,
+		Page:       "<html></html>",
+	}
+}
 
 func TestHGetHealth(t *testing.T) {
 	t.Run("happy path", func(t *testing.T) {
@@ -51,7 +70,7 @@ func TestHGetHealth(t *testing.T) {
 func TestHPostSubmit(t *testing.T) {
 	t.Run("happy path", func(t *testing.T) {
 		we := WafEngineMock{
-			result: true,
+			result: engine.VERDICT_PASS,
 		}
 		c := crud{
 			ds:        nil,
@@ -79,4 +98,37 @@ func TestHPostSubmit(t *testing.T) {
 		_, ok := res.(*waf.PostSubmitOK)
 		assert.Equal(t, true, ok)
 	})
+
+	t.Run("happy path: challenge", func(t *testing.T) {
+		we := WafEngineMock{
+			result: engine.VERDICT_CHALLENGE,
+		}
+		c := crud{
+			ds:        nil,
+			wafEngine: &we,
+		}
+
+		r, err := http.NewRequest("GET", "https://foo/bar", nil)
+		assert.Nil(t, err)
+
+		payload, err := com.NewTaxsiCom(r)
+		assert.Nil(t, err)
+
+		var buf bytes.Buffer
+		err = payload.Marshall(&buf)
+		assert.Nil(t, err)
+
+		call, err := http.NewRequest("POST", "https://taxsi2", &buf)
+		assert.Nil(t, err)
+		res := c.PostSubmit(waf.PostSubmitParams{
+			HTTPRequest: call,
+			Request:     io.NopCloser(&buf),
+		})
+
+		// returning 401 with the challenge
+		challenge, ok := res.(*waf.PostSubmitUnauthorized)
+		assert.Equal(t, true, ok)
+		assert.Equal(t, engine.CHALLENGE_COOKIE, challenge.Payload.Cookie)
+		assert.Equal(t, "token", challenge.Payload.Token)
+	})
 }
@@ -2,6 +2,7 @@ package handler
 
 import (
 	"github.com/nzin/taxsi2/swagger_gen/restapi/operations"
+	"github.com/nzin/taxsi2/swagger_gen/restapi/operations/admin"
 	"github.com/nzin/taxsi2/swagger_gen/restapi/operations/health"
 	"github.com/nzin/taxsi2/swagger_gen/restapi/operations/waf"
 )
@@ -13,4 +14,38 @@ func Setup(api *operations.Taxsi2API) {
 	// healthcheck
 	api.HealthGetHealthHandler = health.GetHealthHandlerFunc(c.GetHealthcheck)
 	api.WafPostSubmitHandler = waf.PostSubmitHandlerFunc(c.PostSubmit)
+
+	// admin
+	api.AdminGetConfigHistoryHandler = admin.GetConfigHistoryHandlerFunc(c.GetConfigHistory)
+	api.AdminPostConfigRollbackHandler = admin.PostConfigRollbackHandlerFunc(c.PostConfigRollback)
+	api.AdminGetConfigExportHandler = admin.GetConfigExportHandlerFunc(c.GetConfigExport)
+	api.AdminGetBansHandler = admin.GetBansHandlerFunc(c.GetBans)
+	api.AdminPostBanHandler = admin.PostBanHandlerFunc(c.PostBan)
+	api.AdminDeleteBanHandler = admin.DeleteBanHandlerFunc(c.DeleteBan)
+	api.AdminGetRatelimitsHandler = admin.GetRatelimitsHandlerFunc(c.GetRatelimits)
+	api.AdminPostRatelimitHandler = admin.PostRatelimitHandlerFunc(c.PostRatelimit)
+	api.AdminPutRatelimitHandler = admin.PutRatelimitHandlerFunc(c.PutRatelimit)
+	api.AdminDeleteRatelimitHandler = admin.DeleteRatelimitHandlerFunc(c.DeleteRatelimit)
+	api.AdminGetUploadpoliciesHandler = admin.GetUploadpoliciesHandlerFunc(c.GetUploadpolicies)
+	api.AdminPostUploadpolicyHandler = admin.PostUploadpolicyHandlerFunc(c.PostUploadpolicy)
+	api.AdminPutUploadpolicyHandler = admin.PutUploadpolicyHandlerFunc(c.PutUploadpolicy)
+	api.AdminDeleteUploadpolicyHandler = admin.DeleteUploadpolicyHandlerFunc(c.DeleteUploadpolicy)
+	api.AdminGetOpenapiSpecsHandler = admin.GetOpenapiSpecsHandlerFunc(c.GetOpenapiSpecs)
+	api.AdminPutOpenapiSpecHandler = admin.PutOpenapiSpecHandlerFunc(c.PutOpenapiSpec)
+	api.AdminDeleteOpenapiSpecHandler = admin.DeleteOpenapiSpecHandlerFunc(c.DeleteOpenapiSpec)
+	api.AdminGetJwtPoliciesHandler = admin.GetJwtPoliciesHandlerFunc(c.GetJwtPolicies)
+	api.AdminPostJwtPolicyHandler = admin.PostJwtPolicyHandlerFunc(c.PostJwtPolicy)
+	api.AdminPutJwtPolicyHandler = admin.PutJwtPolicyHandlerFunc(c.PutJwtPolicy)
+	api.AdminDeleteJwtPolicyHandler = admin.DeleteJwtPolicyHandlerFunc(c.DeleteJwtPolicy)
+	api.AdminGetJwtKeysHandler = admin.GetJwtKeysHandlerFunc(c.GetJwtKeys)
+	api.AdminPutJwtKeyHandler = admin.PutJwtKeyHandlerFunc(c.PutJwtKey)
+	api.AdminDeleteJwtKeyHandler = admin.DeleteJwtKeyHandlerFunc(c.DeleteJwtKey)
+	api.AdminGetRulesHandler = admin.GetRulesHandlerFunc(c.GetRules)
+	api.AdminPutRuleHandler = admin.PutRuleHandlerFunc(c.PutRule)
+	api.AdminDeleteRuleHandler = admin.DeleteRuleHandlerFunc(c.DeleteRule)
+	api.AdminGetModsecRulesetsHandler = admin.GetModsecRulesetsHandlerFunc(c.GetModsecRulesets)
+	api.AdminPutModsecRulesetHandler = admin.PutModsecRulesetHandlerFunc(c.PutModsecRuleset)
+	api.AdminDeleteModsecRulesetHandler = admin.DeleteModsecRulesetHandlerFunc(c.DeleteModsecRuleset)
+	api.AdminGetPluginStatsHandler = admin.GetPluginStatsHandlerFunc(c.GetPluginStats)
+	api.AdminGetCacheStatsHandler = admin.GetCacheStatsHandlerFunc(c.GetCacheStats)
 }
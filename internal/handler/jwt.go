@@ -0,0 +1,193 @@
+package handler
+
+import (
+	"errors"
+
+	"github.com/go-openapi/runtime/middleware"
+	"github.com/go-openapi/strfmt"
+	"github.com/nzin/taxsi2/internal/db"
+	"github.com/nzin/taxsi2/internal/engine/plugins/jwt"
+	"github.com/nzin/taxsi2/swagger_gen/models"
+	"github.com/nzin/taxsi2/swagger_gen/restapi/operations/admin"
+	"gorm.io/gorm"
+)
+
+func (c *crud) GetJwtPolicies(params admin.GetJwtPoliciesParams) middleware.Responder {
+	policies, err := c.ds.GetJwtPolicies()
+	if err != nil {
+		return admin.NewGetJwtPoliciesDefault(500).WithPayload(
+			ErrorMessage("unable to read the JWT policies: %v", err),
+		)
+	}
+
+	payload := []*models.JwtPolicy{}
+	for _, p := range policies {
+		payload = append(payload, jwtPolicyToModel(p))
+	}
+	return admin.NewGetJwtPoliciesOK().WithPayload(payload)
+}
+
+func (c *crud) PostJwtPolicy(params admin.PostJwtPolicyParams) middleware.Responder {
+	policy, err := jwtPolicyFromModel(params.Body)
+	if err != nil {
+		return admin.NewPostJwtPolicyDefault(400).WithPayload(
+			ErrorMessage("%v", err),
+		)
+	}
+
+	if err := c.ds.SetJwtPolicy(policy); err != nil {
+		return admin.NewPostJwtPolicyDefault(500).WithPayload(
+			ErrorMessage("unable to add the JWT policy: %v", err),
+		)
+	}
+	return admin.NewPostJwtPolicyCreated().WithPayload(jwtPolicyToModel(*policy))
+}
+
+func (c *crud) PutJwtPolicy(params admin.PutJwtPolicyParams) middleware.Responder {
+	policy, err := jwtPolicyFromModel(params.Body)
+	if err != nil {
+		return admin.NewPutJwtPolicyDefault(400).WithPayload(
+			ErrorMessage("%v", err),
+		)
+	}
+
+	found := false
+	policies, err := c.ds.GetJwtPolicies()
+	if err != nil {
+		return admin.NewPutJwtPolicyDefault(500).WithPayload(
+			ErrorMessage("unable to read the JWT policies: %v", err),
+		)
+	}
+	for _, p := range policies {
+		if p.ID == uint(params.ID) {
+			policy.Model = p.Model
+			found = true
+		}
+	}
+	if !found {
+		return admin.NewPutJwtPolicyDefault(404).WithPayload(
+			ErrorMessage("JWT policy %d not found", params.ID),
+		)
+	}
+
+	if err := c.ds.SetJwtPolicy(policy); err != nil {
+		return admin.NewPutJwtPolicyDefault(500).WithPayload(
+			ErrorMessage("unable to update the JWT policy: %v", err),
+		)
+	}
+	return admin.NewPutJwtPolicyOK().WithPayload(jwtPolicyToModel(*policy))
+}
+
+func (c *crud) DeleteJwtPolicy(params admin.DeleteJwtPolicyParams) middleware.Responder {
+	err := c.ds.DeleteJwtPolicy(uint(params.ID))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return admin.NewDeleteJwtPolicyDefault(404).WithPayload(
+			ErrorMessage("JWT policy %d not found", params.ID),
+		)
+	}
+	if err != nil {
+		return admin.NewDeleteJwtPolicyDefault(500).WithPayload(
+			ErrorMessage("unable to remove the JWT policy: %v", err),
+		)
+	}
+	return admin.NewDeleteJwtPolicyOK()
+}
+
+func (c *crud) GetJwtKeys(params admin.GetJwtKeysParams) middleware.Responder {
+	keys, err := c.ds.GetJwtKeys()
+	if err != nil {
+		return admin.NewGetJwtKeysDefault(500).WithPayload(
+			ErrorMessage("unable to read the JWT keys: %v", err),
+		)
+	}
+
+	payload := []*models.JwtKey{}
+	for _, k := range keys {
+		payload = append(payload, jwtKeyToModel(k))
+	}
+	return admin.NewGetJwtKeysOK().WithPayload(payload)
+}
+
+func (c *crud) PutJwtKey(params admin.PutJwtKeyParams) middleware.Responder {
+	// check that the key can be used before saving it
+	k, err := jwt.ParseJWK([]byte(*params.Body.Jwk))
+	if err != nil {
+		return admin.NewPutJwtKeyDefault(400).WithPayload(
+			ErrorMessage("%v", err),
+		)
+	}
+	if k == nil {
+		return admin.NewPutJwtKeyDefault(400).WithPayload(
+			ErrorMessage("not a signature key"),
+		)
+	}
+
+	if err := c.ds.SetJwtKey(params.Kid, []byte(*params.Body.Jwk)); err != nil {
+		return admin.NewPutJwtKeyDefault(500).WithPayload(
+			ErrorMessage("unable to save the JWT key: %v", err),
+		)
+	}
+	keys, err := c.ds.GetJwtKeys()
+	if err != nil {
+		return admin.NewPutJwtKeyDefault(500).WithPayload(
+			ErrorMessage("unable to read the JWT keys: %v", err),
+		)
+	}
+	for _, k := range keys {
+		if k.Kid == params.Kid {
+			return admin.NewPutJwtKeyOK().WithPayload(jwtKeyToModel(k))
+		}
+	}
+	return admin.NewPutJwtKeyDefault(500).WithPayload(
+		ErrorMessage("JWT key %s not found after saving it", params.Kid),
+	)
+}
+
+func (c *crud) DeleteJwtKey(params admin.DeleteJwtKeyParams) middleware.Responder {
+	err := c.ds.DeleteJwtKey(params.Kid)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return admin.NewDeleteJwtKeyDefault(404).WithPayload(
+			ErrorMessage("JWT key %s not found", params.Kid),
+		)
+	}
+	if err != nil {
+		return admin.NewDeleteJwtKeyDefault(500).WithPayload(
+			ErrorMessage("unable to remove the JWT key: %v", err),
+		)
+	}
+	return admin.NewDeleteJwtKeyOK()
+}
+
+func jwtPolicyToModel(p db.JwtPolicy) *models.JwtPolicy {
+	return &models.JwtPolicy{
+		ID:             int64(p.ID),
+		PathPrefix:     p.PathPrefix,
+		Required:       p.Required,
+		Issuer:         p.Issuer,
+		Audience:       p.Audience,
+		RequiredClaims: p.RequiredClaims,
+	}
+}
+
+func jwtPolicyFromModel(m *models.JwtPolicy) (*db.JwtPolicy, error) {
+	policy := &db.JwtPolicy{
+		PathPrefix:     m.PathPrefix,
+		Required:       m.Required,
+		Issuer:         m.Issuer,
+		Audience:       m.Audience,
+		RequiredClaims: m.RequiredClaims,
+	}
+	if err := jwt.ValidatePolicy(*policy); err != nil {
+		return nil, err
+	}
+	return policy, nil
+}
+
+func jwtKeyToModel(k db.JwtKey) *models.JwtKey {
+	jwk := string(k.Jwk)
+	return &models.JwtKey{
+		Kid:       k.Kid,
+		Jwk:       &jwk,
+		UpdatedAt: strfmt.DateTime(k.UpdatedAt),
+	}
+}
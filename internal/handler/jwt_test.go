@@ -0,0 +1,123 @@
+package handler
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/nzin/taxsi2/internal/db"
+	"github.com/nzin/taxsi2/internal/util"
+	"github.com/nzin/taxsi2/swagger_gen/models"
+	"github.com/nzin/taxsi2/swagger_gen/restapi/operations/admin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJwtPolicies(t *testing.T) {
+	t.Run("happy path: add, update, list and remove a policy", func(t *testing.T) {
+		tmpFile, err := os.CreateTemp("", "taxsi.temp*")
+		assert.Nil(t, err)
+		defer os.Remove(tmpFile.Name())
+
+		ds, err := db.NewDbService("sqlite3", tmpFile.Name(), 1, 1*time.Second)
+		assert.Nil(t, err)
+
+		c := crud{
+			ds:        ds,
+			wafEngine: nil,
+		}
+
+		policy := models.JwtPolicy{
+			PathPrefix: "/api",
+			Required:   true,
+			Issuer:     "https://auth.example.com",
+		}
+		res := c.PostJwtPolicy(admin.PostJwtPolicyParams{Body: &policy})
+		created, ok := res.(*admin.PostJwtPolicyCreated)
+		assert.True(t, ok)
+		assert.NotEqual(t, int64(0), created.Payload.ID)
+
+		policy.RequiredClaims = "sub,scope=admin"
+		res = c.PutJwtPolicy(admin.PutJwtPolicyParams{ID: created.Payload.ID, Body: &policy})
+		_, ok = res.(*admin.PutJwtPolicyOK)
+		assert.True(t, ok)
+
+		res = c.GetJwtPolicies(admin.GetJwtPoliciesParams{})
+		policies, ok := res.(*admin.GetJwtPoliciesOK)
+		assert.True(t, ok)
+		assert.Equal(t, 1, len(policies.Payload))
+		assert.Equal(t, "sub,scope=admin", policies.Payload[0].RequiredClaims)
+
+		res = c.DeleteJwtPolicy(admin.DeleteJwtPolicyParams{ID: created.Payload.ID})
+		_, ok = res.(*admin.DeleteJwtPolicyOK)
+		assert.True(t, ok)
+
+		res = c.DeleteJwtPolicy(admin.DeleteJwtPolicyParams{ID: created.Payload.ID})
+		_, ok = res.(*admin.DeleteJwtPolicyDefault)
+		assert.True(t, ok)
+
+		res = c.PostJwtPolicy(admin.PostJwtPolicyParams{Body: &models.JwtPolicy{RequiredClaims: "sub,=admin"}})
+		_, ok = res.(*admin.PostJwtPolicyDefault)
+		assert.True(t, ok)
+	})
+}
+
+func TestJwtKeys(t *testing.T) {
+	t.Run("happy path: add, list and remove a key", func(t *testing.T) {
+		tmpFile, err := os.CreateTemp("", "taxsi.temp*")
+		assert.Nil(t, err)
+		defer os.Remove(tmpFile.Name())
+
+		ds, err := db.NewDbService("sqlite3", tmpFile.Name(), 1, 1*time.Second)
+		assert.Nil(t, err)
+
+		c := crud{
+			ds:        ds,
+			wafEngine: nil,
+		}
+
+		key := models.JwtKey{
+			Jwk: util.StringPtr(`{"kty": "oct", "k": "YSB2ZXJ5IGxvbmcgc2VjcmV0IG9mIDMyIGJ5dGVzISE"}`),
+		}
+		res := c.PutJwtKey(admin.PutJwtKeyParams{Kid: "hmac", Body: &key})
+		saved, ok := res.(*admin.PutJwtKeyOK)
+		assert.True(t, ok)
+		assert.Equal(t, "hmac", saved.Payload.Kid)
+
+		res = c.GetJwtKeys(admin.GetJwtKeysParams{})
+		keys, ok := res.(*admin.GetJwtKeysOK)
+		assert.True(t, ok)
+		assert.Equal(t, 1, len(keys.Payload))
+
+		res = c.DeleteJwtKey(admin.DeleteJwtKeyParams{Kid: "hmac"})
+		_, ok = res.(*admin.DeleteJwtKeyOK)
+		assert.True(t, ok)
+
+		res = c.DeleteJwtKey(admin.DeleteJwtKeyParams{Kid: "hmac"})
+		_, ok = res.(*admin.DeleteJwtKeyDefault)
+		assert.True(t, ok)
+	})
+
+	t.Run("not happy path: bad keys", func(t *testing.T) {
+		tmpFile, err := os.CreateTemp("", "taxsi.temp*")
+		assert.Nil(t, err)
+		defer os.Remove(tmpFile.Name())
+
+		ds, err := db.NewDbService("sqlite3", tmpFile.Name(), 1, 1*time.Second)
+		assert.Nil(t, err)
+
+		c := crud{
+			ds:        ds,
+			wafEngine: nil,
+		}
+
+		for _, jwk := range []string{
+			"{",
+			`{"kty": "oct", "k": "c2hvcnQ"}`,
+			`{"kty": "oct", "use": "enc", "k": "YSB2ZXJ5IGxvbmcgc2VjcmV0IG9mIDMyIGJ5dGVzISE"}`,
+		} {
+			res := c.PutJwtKey(admin.PutJwtKeyParams{Kid: "k", Body: &models.JwtKey{Jwk: util.StringPtr(jwk)}})
+			_, ok := res.(*admin.PutJwtKeyDefault)
+			assert.True(t, ok, jwk)
+		}
+	})
+}
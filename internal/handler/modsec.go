@@ -0,0 +1,75 @@
+package handler
+
+import (
+	"errors"
+
+	"github.com/go-openapi/runtime/middleware"
+	"github.com/go-openapi/strfmt"
+	"github.com/nzin/taxsi2/internal/db"
+	"github.com/nzin/taxsi2/internal/engine/plugins/modsec"
+	"github.com/nzin/taxsi2/swagger_gen/models"
+	"github.com/nzin/taxsi2/swagger_gen/restapi/operations/admin"
+	"gorm.io/gorm"
+)
+
+func (c *crud) GetModsecRulesets(params admin.GetModsecRulesetsParams) middleware.Responder {
+	list, err := c.ds.GetModsecRulesets()
+	if err != nil {
+		return admin.NewGetModsecRulesetsDefault(500).WithPayload(
+			ErrorMessage("unable to read the modsec rulesets: %v", err),
+		)
+	}
+
+	payload := []*models.ModsecRuleset{}
+	for _, r := range list {
+		payload = append(payload, modsecRulesetToModel(r, modsec.Parse(r.Name, r.Content)))
+	}
+	return admin.NewGetModsecRulesetsOK().WithPayload(payload)
+}
+
+func (c *crud) PutModsecRuleset(params admin.PutModsecRulesetParams) middleware.Responder {
+	ruleset := db.ModsecRuleset{
+		Name:    params.Name,
+		Content: *params.Body.Content,
+	}
+	rs, err := modsec.SaveRuleset(c.ds, &ruleset)
+	if err != nil {
+		return admin.NewPutModsecRulesetDefault(500).WithPayload(
+			ErrorMessage("unable to save the modsec ruleset: %v", err),
+		)
+	}
+	return admin.NewPutModsecRulesetOK().WithPayload(modsecRulesetToModel(ruleset, rs))
+}
+
+func (c *crud) DeleteModsecRuleset(params admin.DeleteModsecRulesetParams) middleware.Responder {
+	err := c.ds.DeleteModsecRuleset(params.Name)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return admin.NewDeleteModsecRulesetDefault(404).WithPayload(
+			ErrorMessage("modsec ruleset %s not found", params.Name),
+		)
+	}
+	if err != nil {
+		return admin.NewDeleteModsecRulesetDefault(500).WithPayload(
+			ErrorMessage("unable to remove the modsec ruleset: %v", err),
+		)
+	}
+	return admin.NewDeleteModsecRulesetOK()
+}
+
+func modsecRulesetToModel(r db.ModsecRuleset, rs *modsec.Ruleset) *models.ModsecRuleset {
+	content := r.Content
+	rules := 0
+	for _, rule := range rs.Rules {
+		if rule.Marker == "" {
+			rules++
+		}
+	}
+	return &models.ModsecRuleset{
+		Name:          r.Name,
+		Content:       &content,
+		Rules:         int64(rules),
+		ResponseRules: int64(rs.ResponseRules),
+		Warnings:      append([]string{}, rs.Warnings...),
+		UpdatedAt:     strfmt.DateTime(r.UpdatedAt),
+	}
+}
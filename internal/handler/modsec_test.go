@@ -0,0 +1,80 @@
+package handler
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/nzin/taxsi2/internal/db"
+	"github.com/nzin/taxsi2/swagger_gen/models"
+	"github.com/nzin/taxsi2/swagger_gen/restapi/operations/admin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestModsecRulesets(t *testing.T) {
+	t.Run("happy path: add, replace, list and remove rulesets", func(t *testing.T) {
+		tmpFile, err := os.CreateTemp("", "taxsi.temp*")
+		assert.Nil(t, err)
+		defer os.Remove(tmpFile.Name())
+
+		ds, err := db.NewDbService("sqlite3", tmpFile.Name(), 1, 1*time.Second)
+		assert.Nil(t, err)
+
+		c := crud{
+			ds:        ds,
+			wafEngine: nil,
+		}
+
+		content := `
+SecRule ARGS "@detectSQLi" "id:942100,phase:2,block,severity:'CRITICAL'"
+SecRule ARGS "@pmFromFile sqli.data" "id:942101,phase:2,block"
+SecMarker END
+SecRule RESPONSE_STATUS "@rx ^5" "id:950100,phase:3,block"
+`
+		res := c.PutModsecRuleset(admin.PutModsecRulesetParams{Name: "sqli.conf", Body: &models.ModsecRuleset{Content: &content}})
+		saved, ok := res.(*admin.PutModsecRulesetOK)
+		assert.True(t, ok)
+		assert.Equal(t, "sqli.conf", saved.Payload.Name)
+		assert.Equal(t, int64(1), saved.Payload.Rules)
+		assert.Equal(t, int64(1), saved.Payload.ResponseRules)
+		assert.Equal(t, []string{"sqli.conf:3: rule 942101 skipped: @pmf is not supported"}, saved.Payload.Warnings)
+
+		content = `SecRule REQUEST_METHOD "@streq TRACE" "id:1,deny"`
+		res = c.PutModsecRuleset(admin.PutModsecRulesetParams{Name: "method.conf", Body: &models.ModsecRuleset{Content: &content}})
+		_, ok = res.(*admin.PutModsecRulesetOK)
+		assert.True(t, ok)
+		res = c.PutModsecRuleset(admin.PutModsecRulesetParams{Name: "method.conf", Body: &models.ModsecRuleset{Content: &content}})
+		_, ok = res.(*admin.PutModsecRulesetOK)
+		assert.True(t, ok)
+
+		res = c.GetModsecRulesets(admin.GetModsecRulesetsParams{})
+		list, ok := res.(*admin.GetModsecRulesetsOK)
+		assert.True(t, ok)
+		assert.Equal(t, 2, len(list.Payload))
+		assert.Equal(t, "method.conf", list.Payload[0].Name)
+		assert.Equal(t, 0, len(list.Payload[0].Warnings))
+		assert.Equal(t, 1, len(list.Payload[1].Warnings))
+
+		res = c.DeleteModsecRuleset(admin.DeleteModsecRulesetParams{Name: "method.conf"})
+		_, ok = res.(*admin.DeleteModsecRulesetOK)
+		assert.True(t, ok)
+	})
+
+	t.Run("not happy path: unknown ruleset", func(t *testing.T) {
+		tmpFile, err := os.CreateTemp("", "taxsi.temp*")
+		assert.Nil(t, err)
+		defer os.Remove(tmpFile.Name())
+
+		ds, err := db.NewDbService("sqlite3", tmpFile.Name(), 1, 1*time.Second)
+		assert.Nil(t, err)
+
+		c := crud{
+			ds:        ds,
+			wafEngine: nil,
+		}
+
+		res := c.DeleteModsecRuleset(admin.DeleteModsecRulesetParams{Name: "foo.conf"})
+		_, ok := res.(*admin.DeleteModsecRulesetDefault)
+		assert.True(t, ok)
+	})
+}
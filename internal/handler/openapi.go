@@ -0,0 +1,83 @@
+package handler
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/go-openapi/runtime/middleware"
+	"github.com/go-openapi/strfmt"
+	"github.com/nzin/taxsi2/internal/db"
+	"github.com/nzin/taxsi2/internal/engine/plugins/openapi"
+	"github.com/nzin/taxsi2/swagger_gen/models"
+	"github.com/nzin/taxsi2/swagger_gen/restapi/operations/admin"
+	"gorm.io/gorm"
+)
+
+func (c *crud) GetOpenapiSpecs(params admin.GetOpenapiSpecsParams) middleware.Responder {
+	specs, err := c.ds.GetOpenapiSpecs()
+	if err != nil {
+		return admin.NewGetOpenapiSpecsDefault(500).WithPayload(
+			ErrorMessage("unable to read the openapi specs: %v", err),
+		)
+	}
+
+	payload := []*models.OpenapiSpec{}
+	for _, s := range specs {
+		payload = append(payload, openapiSpecToModel(s))
+	}
+	return admin.NewGetOpenapiSpecsOK().WithPayload(payload)
+}
+
+func (c *crud) PutOpenapiSpec(params admin.PutOpenapiSpecParams) middleware.Responder {
+	host := strings.ToLower(params.Host)
+	// check that the spec can be used before saving it
+	if _, err := openapi.LoadSpec([]byte(*params.Body.Spec)); err != nil {
+		return admin.NewPutOpenapiSpecDefault(400).WithPayload(
+			ErrorMessage("%v", err),
+		)
+	}
+
+	if err := c.ds.SetOpenapiSpec(host, []byte(*params.Body.Spec)); err != nil {
+		return admin.NewPutOpenapiSpecDefault(500).WithPayload(
+			ErrorMessage("unable to save the openapi spec: %v", err),
+		)
+	}
+	specs, err := c.ds.GetOpenapiSpecs()
+	if err != nil {
+		return admin.NewPutOpenapiSpecDefault(500).WithPayload(
+			ErrorMessage("unable to read the openapi specs: %v", err),
+		)
+	}
+	for _, s := range specs {
+		if s.Host == host {
+			return admin.NewPutOpenapiSpecOK().WithPayload(openapiSpecToModel(s))
+		}
+	}
+	return admin.NewPutOpenapiSpecDefault(500).WithPayload(
+		ErrorMessage("openapi spec of %s not found after saving it", host),
+	)
+}
+
+func (c *crud) DeleteOpenapiSpec(params admin.DeleteOpenapiSpecParams) middleware.Responder {
+	err := c.ds.DeleteOpenapiSpec(strings.ToLower(params.Host))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return admin.NewDeleteOpenapiSpecDefault(404).WithPayload(
+			ErrorMessage("no openapi spec for %s", params.Host),
+		)
+	}
+	if err != nil {
+		return admin.NewDeleteOpenapiSpecDefault(500).WithPayload(
+			ErrorMessage("unable to remove the openapi spec: %v", err),
+		)
+	}
+	return admin.NewDeleteOpenapiSpecOK()
+}
+
+func openapiSpecToModel(s db.OpenapiSpec) *models.OpenapiSpec {
+	spec := string(s.Spec)
+	return &models.OpenapiSpec{
+		Host:      s.Host,
+		Spec:      &spec,
+		UpdatedAt: strfmt.DateTime(s.UpdatedAt),
+	}
+}
@@ -0,0 +1,71 @@
+package handler
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/nzin/taxsi2/internal/db"
+	"github.com/nzin/taxsi2/internal/util"
+	"github.com/nzin/taxsi2/swagger_gen/models"
+	"github.com/nzin/taxsi2/swagger_gen/restapi/operations/admin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOpenapiSpecs(t *testing.T) {
+	t.Run("happy path: add, list and remove a spec", func(t *testing.T) {
+		tmpFile, err := os.CreateTemp("", "taxsi.temp*")
+		assert.Nil(t, err)
+		defer os.Remove(tmpFile.Name())
+
+		ds, err := db.NewDbService("sqlite3", tmpFile.Name(), 1, 1*time.Second)
+		assert.Nil(t, err)
+
+		c := crud{
+			ds:        ds,
+			wafEngine: nil,
+		}
+
+		spec := models.OpenapiSpec{
+			Spec: util.StringPtr("swagger: \"2.0\"\ninfo:\n  title: t\n  version: \"1\"\npaths:\n  /a:\n    get: {}\n"),
+		}
+		res := c.PutOpenapiSpec(admin.PutOpenapiSpecParams{Host: "API.example.com", Body: &spec})
+		saved, ok := res.(*admin.PutOpenapiSpecOK)
+		assert.True(t, ok)
+		assert.Equal(t, "api.example.com", saved.Payload.Host)
+
+		res = c.GetOpenapiSpecs(admin.GetOpenapiSpecsParams{})
+		specs, ok := res.(*admin.GetOpenapiSpecsOK)
+		assert.True(t, ok)
+		assert.Equal(t, 1, len(specs.Payload))
+		assert.Equal(t, *spec.Spec, *specs.Payload[0].Spec)
+
+		res = c.DeleteOpenapiSpec(admin.DeleteOpenapiSpecParams{Host: "api.example.com"})
+		_, ok = res.(*admin.DeleteOpenapiSpecOK)
+		assert.True(t, ok)
+
+		res = c.DeleteOpenapiSpec(admin.DeleteOpenapiSpecParams{Host: "api.example.com"})
+		_, ok = res.(*admin.DeleteOpenapiSpecDefault)
+		assert.True(t, ok)
+	})
+
+	t.Run("not happy path: bad specs", func(t *testing.T) {
+		tmpFile, err := os.CreateTemp("", "taxsi.temp*")
+		assert.Nil(t, err)
+		defer os.Remove(tmpFile.Name())
+
+		ds, err := db.NewDbService("sqlite3", tmpFile.Name(), 1, 1*time.Second)
+		assert.Nil(t, err)
+
+		c := crud{
+			ds:        ds,
+			wafEngine: nil,
+		}
+
+		for _, spec := range []string{"{", `{"openapi": "3.0.0"}`} {
+			res := c.PutOpenapiSpec(admin.PutOpenapiSpecParams{Host: "api.example.com", Body: &models.OpenapiSpec{Spec: util.StringPtr(spec)}})
+			_, ok := res.(*admin.PutOpenapiSpecDefault)
+			assert.True(t, ok)
+		}
+	})
+}
@@ -0,0 +1,124 @@
+package handler
+
+import (
+	"errors"
+
+	"github.com/go-openapi/runtime/middleware"
+	"github.com/nzin/taxsi2/internal/db"
+	"github.com/nzin/taxsi2/internal/engine/plugins/ratelimiter"
+	"github.com/nzin/taxsi2/swagger_gen/models"
+	"github.com/nzin/taxsi2/swagger_gen/restapi/operations/admin"
+	"gorm.io/gorm"
+)
+
+func (c *crud) GetRatelimits(params admin.GetRatelimitsParams) middleware.Responder {
+	rules, err := c.ds.GetRatelimitRules()
+	if err != nil {
+		return admin.NewGetRatelimitsDefault(500).WithPayload(
+			ErrorMessage("unable to read the rate limit rules: %v", err),
+		)
+	}
+
+	payload := []*models.RatelimitRule{}
+	for _, r := range rules {
+		payload = append(payload, ratelimitRuleToModel(r))
+	}
+	return admin.NewGetRatelimitsOK().WithPayload(payload)
+}
+
+func (c *crud) PostRatelimit(params admin.PostRatelimitParams) middleware.Responder {
+	rule, err := ratelimitRuleFromModel(params.Body)
+	if err != nil {
+		return admin.NewPostRatelimitDefault(400).WithPayload(
+			ErrorMessage("%v", err),
+		)
+	}
+
+	if err := c.ds.SetRatelimitRule(rule); err != nil {
+		return admin.NewPostRatelimitDefault(500).WithPayload(
+			ErrorMessage("unable to add the rate limit rule: %v", err),
+		)
+	}
+	return admin.NewPostRatelimitCreated().WithPayload(ratelimitRuleToModel(*rule))
+}
+
+func (c *crud) PutRatelimit(params admin.PutRatelimitParams) middleware.Responder {
+	rule, err := ratelimitRuleFromModel(params.Body)
+	if err != nil {
+		return admin.NewPutRatelimitDefault(400).WithPayload(
+			ErrorMessage("%v", err),
+		)
+	}
+
+	found := false
+	rules, err := c.ds.GetRatelimitRules()
+	if err != nil {
+		return admin.NewPutRatelimitDefault(500).WithPayload(
+			ErrorMessage("unable to read the rate limit rules: %v", err),
+		)
+	}
+	for _, r := range rules {
+		if r.ID == uint(params.ID) {
+			rule.Model = r.Model
+			found = true
+		}
+	}
+	if !found {
+		return admin.NewPutRatelimitDefault(404).WithPayload(
+			ErrorMessage("rate limit rule %d not found", params.ID),
+		)
+	}
+
+	if err := c.ds.SetRatelimitRule(rule); err != nil {
+		return admin.NewPutRatelimitDefault(500).WithPayload(
+			ErrorMessage("unable to update the rate limit rule: %v", err),
+		)
+	}
+	return admin.NewPutRatelimitOK().WithPayload(ratelimitRuleToModel(*rule))
+}
+
+func (c *crud) DeleteRatelimit(params admin.DeleteRatelimitParams) middleware.Responder {
+	err := c.ds.DeleteRatelimitRule(uint(params.ID))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return admin.NewDeleteRatelimitDefault(404).WithPayload(
+			ErrorMessage("rate limit rule %d not found", params.ID),
+		)
+	}
+	if err != nil {
+		return admin.NewDeleteRatelimitDefault(500).WithPayload(
+			ErrorMessage("unable to remove the rate limit rule: %v", err),
+		)
+	}
+	return admin.NewDeleteRatelimitOK()
+}
+
+func ratelimitRuleToModel(r db.RatelimitRule) *models.RatelimitRule {
+	key := r.Key
+	algorithm := r.Algorithm
+	limit := int64(r.Limit)
+	period := int64(r.Period)
+	return &models.RatelimitRule{
+		ID:         int64(r.ID),
+		PathPrefix: r.PathPrefix,
+		Key:        &key,
+		Algorithm:  &algorithm,
+		Limit:      &limit,
+		Period:     &period,
+		Burst:      int64(r.Burst),
+	}
+}
+
+func ratelimitRuleFromModel(m *models.RatelimitRule) (*db.RatelimitRule, error) {
+	rule := &db.RatelimitRule{
+		PathPrefix: m.PathPrefix,
+		Key:        *m.Key,
+		Algorithm:  *m.Algorithm,
+		Limit:      int(*m.Limit),
+		Period:     int(*m.Period),
+		Burst:      int(m.Burst),
+	}
+	if err := ratelimiter.ValidateRule(*rule); err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
@@ -0,0 +1,95 @@
+package handler
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/nzin/taxsi2/internal/db"
+	"github.com/nzin/taxsi2/internal/util"
+	"github.com/nzin/taxsi2/swagger_gen/models"
+	"github.com/nzin/taxsi2/swagger_gen/restapi/operations/admin"
+	"github.com/stretchr/testify/assert"
+)
+
+func int64Ptr(i int64) *int64 {
+	return &i
+}
+
+func TestRatelimits(t *testing.T) {
+	t.Run("happy path: add, update, list and remove a rule", func(t *testing.T) {
+		tmpFile, err := os.CreateTemp("", "taxsi.temp*")
+		assert.Nil(t, err)
+		defer os.Remove(tmpFile.Name())
+
+		ds, err := db.NewDbService("sqlite3", tmpFile.Name(), 1, 1*time.Second)
+		assert.Nil(t, err)
+
+		c := crud{
+			ds:        ds,
+			wafEngine: nil,
+		}
+
+		rule := models.RatelimitRule{
+			PathPrefix: "/login",
+			Key:        util.StringPtr("ip,header:X-Api-Key"),
+			Algorithm:  util.StringPtr(db.RATELIMIT_SLIDING_WINDOW),
+			Limit:      int64Ptr(10),
+			Period:     int64Ptr(60),
+		}
+		res := c.PostRatelimit(admin.PostRatelimitParams{Body: &rule})
+		created, ok := res.(*admin.PostRatelimitCreated)
+		assert.True(t, ok)
+		assert.NotEqual(t, int64(0), created.Payload.ID)
+
+		rule.Limit = int64Ptr(20)
+		res = c.PutRatelimit(admin.PutRatelimitParams{ID: created.Payload.ID, Body: &rule})
+		_, ok = res.(*admin.PutRatelimitOK)
+		assert.True(t, ok)
+
+		res = c.GetRatelimits(admin.GetRatelimitsParams{})
+		rules, ok := res.(*admin.GetRatelimitsOK)
+		assert.True(t, ok)
+		assert.Equal(t, 1, len(rules.Payload))
+		assert.Equal(t, int64(20), *rules.Payload[0].Limit)
+
+		res = c.DeleteRatelimit(admin.DeleteRatelimitParams{ID: created.Payload.ID})
+		_, ok = res.(*admin.DeleteRatelimitOK)
+		assert.True(t, ok)
+
+		res = c.DeleteRatelimit(admin.DeleteRatelimitParams{ID: created.Payload.ID})
+		_, ok = res.(*admin.DeleteRatelimitDefault)
+		assert.True(t, ok)
+	})
+
+	t.Run("not happy path: bad rules", func(t *testing.T) {
+		tmpFile, err := os.CreateTemp("", "taxsi.temp*")
+		assert.Nil(t, err)
+		defer os.Remove(tmpFile.Name())
+
+		ds, err := db.NewDbService("sqlite3", tmpFile.Name(), 1, 1*time.Second)
+		assert.Nil(t, err)
+
+		c := crud{
+			ds:        ds,
+			wafEngine: nil,
+		}
+
+		for _, rule := range []models.RatelimitRule{
+			{Key: util.StringPtr("cookie"), Algorithm: util.StringPtr(db.RATELIMIT_TOKEN_BUCKET), Limit: int64Ptr(1), Period: int64Ptr(1)},
+			{Key: util.StringPtr("header:"), Algorithm: util.StringPtr(db.RATELIMIT_TOKEN_BUCKET), Limit: int64Ptr(1), Period: int64Ptr(1)},
+			{Key: util.StringPtr("ip"), Algorithm: util.StringPtr("leaky-bucket"), Limit: int64Ptr(1), Period: int64Ptr(1)},
+			{Key: util.StringPtr("ip"), Algorithm: util.StringPtr(db.RATELIMIT_TOKEN_BUCKET), Limit: int64Ptr(0), Period: int64Ptr(1)},
+		} {
+			res := c.PostRatelimit(admin.PostRatelimitParams{Body: &rule})
+			_, ok := res.(*admin.PostRatelimitDefault)
+			assert.True(t, ok)
+		}
+
+		res := c.PutRatelimit(admin.PutRatelimitParams{ID: 42, Body: &models.RatelimitRule{
+			Key: util.StringPtr("ip"), Algorithm: util.StringPtr(db.RATELIMIT_TOKEN_BUCKET), Limit: int64Ptr(1), Period: int64Ptr(1),
+		}})
+		_, ok := res.(*admin.PutRatelimitDefault)
+		assert.True(t, ok)
+	})
+}
@@ -0,0 +1,78 @@
+package handler
+
+import (
+	"errors"
+
+	"github.com/go-openapi/runtime/middleware"
+	"github.com/go-openapi/strfmt"
+	"github.com/nzin/taxsi2/internal/db"
+	"github.com/nzin/taxsi2/internal/engine/plugins/rules"
+	"github.com/nzin/taxsi2/swagger_gen/models"
+	"github.com/nzin/taxsi2/swagger_gen/restapi/operations/admin"
+	"gorm.io/gorm"
+)
+
+func (c *crud) GetRules(params admin.GetRulesParams) middleware.Responder {
+	list, err := c.ds.GetRules()
+	if err != nil {
+		return admin.NewGetRulesDefault(500).WithPayload(
+			ErrorMessage("unable to read the rules: %v", err),
+		)
+	}
+	list = rules.Sort(list)
+
+	payload := []*models.Rule{}
+	for _, r := range list {
+		payload = append(payload, ruleToModel(r))
+	}
+	return admin.NewGetRulesOK().WithPayload(payload)
+}
+
+func (c *crud) PutRule(params admin.PutRuleParams) middleware.Responder {
+	rule := db.Rule{
+		ID:          params.ID,
+		Expression:  *params.Body.Expression,
+		Action:      *params.Body.Action,
+		Description: params.Body.Description,
+	}
+	// check that the rule compiles before saving it
+	if err := rules.ValidateRule(rule); err != nil {
+		return admin.NewPutRuleDefault(400).WithPayload(
+			ErrorMessage("%v", err),
+		)
+	}
+
+	if err := c.ds.SetRule(&rule); err != nil {
+		return admin.NewPutRuleDefault(500).WithPayload(
+			ErrorMessage("unable to save the rule: %v", err),
+		)
+	}
+	return admin.NewPutRuleOK().WithPayload(ruleToModel(rule))
+}
+
+func (c *crud) DeleteRule(params admin.DeleteRuleParams) middleware.Responder {
+	err := c.ds.DeleteRule(params.ID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return admin.NewDeleteRuleDefault(404).WithPayload(
+			ErrorMessage("rule %s not found", params.ID),
+		)
+	}
+	if err != nil {
+		return admin.NewDeleteRuleDefault(500).WithPayload(
+			ErrorMessage("unable to remove the rule: %v", err),
+		)
+	}
+	return admin.NewDeleteRuleOK()
+}
+
+func ruleToModel(r db.Rule) *models.Rule {
+	expression := r.Expression
+	action := r.Action
+	return &models.Rule{
+		ID:          r.ID,
+		Expression:  &expression,
+		Action:      &action,
+		Description: r.Description,
+		UpdatedAt:   strfmt.DateTime(r.UpdatedAt),
+	}
+}
@@ -0,0 +1,86 @@
+package handler
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/nzin/taxsi2/internal/db"
+	"github.com/nzin/taxsi2/swagger_gen/models"
+	"github.com/nzin/taxsi2/swagger_gen/restapi/operations/admin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRules(t *testing.T) {
+	t.Run("happy path: add, replace, list and remove rules", func(t *testing.T) {
+		tmpFile, err := os.CreateTemp("", "taxsi.temp*")
+		assert.Nil(t, err)
+		defer os.Remove(tmpFile.Name())
+
+		ds, err := db.NewDbService("sqlite3", tmpFile.Name(), 1, 1*time.Second)
+		assert.Nil(t, err)
+
+		c := crud{
+			ds:        ds,
+			wafEngine: nil,
+		}
+
+		expression := `method == "POST" && path.startsWith("/admin") && !ip.in("10.0.0.0/8")`
+		action := "block"
+		res := c.PutRule(admin.PutRuleParams{ID: "10", Body: &models.Rule{Expression: &expression, Action: &action}})
+		saved, ok := res.(*admin.PutRuleOK)
+		assert.True(t, ok)
+		assert.Equal(t, "10", saved.Payload.ID)
+
+		action = "log"
+		res = c.PutRule(admin.PutRuleParams{ID: "9", Body: &models.Rule{Expression: &expression, Action: &action, Description: "admin posts"}})
+		_, ok = res.(*admin.PutRuleOK)
+		assert.True(t, ok)
+		res = c.PutRule(admin.PutRuleParams{ID: "10", Body: &models.Rule{Expression: &expression, Action: &action}})
+		_, ok = res.(*admin.PutRuleOK)
+		assert.True(t, ok)
+
+		res = c.GetRules(admin.GetRulesParams{})
+		list, ok := res.(*admin.GetRulesOK)
+		assert.True(t, ok)
+		assert.Equal(t, 2, len(list.Payload))
+		// numerically sorted
+		assert.Equal(t, "9", list.Payload[0].ID)
+		assert.Equal(t, "admin posts", list.Payload[0].Description)
+		assert.Equal(t, "log", *list.Payload[1].Action)
+
+		res = c.DeleteRule(admin.DeleteRuleParams{ID: "10"})
+		_, ok = res.(*admin.DeleteRuleOK)
+		assert.True(t, ok)
+	})
+
+	t.Run("not happy path: invalid rules and unknown rules", func(t *testing.T) {
+		tmpFile, err := os.CreateTemp("", "taxsi.temp*")
+		assert.Nil(t, err)
+		defer os.Remove(tmpFile.Name())
+
+		ds, err := db.NewDbService("sqlite3", tmpFile.Name(), 1, 1*time.Second)
+		assert.Nil(t, err)
+
+		c := crud{
+			ds:        ds,
+			wafEngine: nil,
+		}
+
+		expression := `method == `
+		action := "block"
+		res := c.PutRule(admin.PutRuleParams{ID: "1", Body: &models.Rule{Expression: &expression, Action: &action}})
+		_, ok := res.(*admin.PutRuleDefault)
+		assert.True(t, ok)
+
+		expression = `method == "TRACE"`
+		action = "drop"
+		res = c.PutRule(admin.PutRuleParams{ID: "1", Body: &models.Rule{Expression: &expression, Action: &action}})
+		_, ok = res.(*admin.PutRuleDefault)
+		assert.True(t, ok)
+
+		res = c.DeleteRule(admin.DeleteRuleParams{ID: "1"})
+		_, ok = res.(*admin.DeleteRuleDefault)
+		assert.True(t, ok)
+	})
+}
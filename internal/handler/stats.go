@@ -0,0 +1,28 @@
+package handler
+
+import (
+	"github.com/go-openapi/runtime/middleware"
+	"github.com/nzin/taxsi2/swagger_gen/models"
+	"github.com/nzin/taxsi2/swagger_gen/restapi/operations/admin"
+)
+
+func (c *crud) GetPluginStats(params admin.GetPluginStatsParams) middleware.Responder {
+	payload := []*models.PluginStats{}
+	for _, s := range c.wafEngine.PluginStats() {
+		payload = append(payload, &models.PluginStats{
+			Plugin:   s.Plugin,
+			Timeouts: s.Timeouts,
+			Panics:   s.Panics,
+		})
+	}
+	return admin.NewGetPluginStatsOK().WithPayload(payload)
+}
+
+func (c *crud) GetCacheStats(params admin.GetCacheStatsParams) middleware.Responder {
+	hits, misses, size := c.wafEngine.VerdictCacheStats()
+	return admin.NewGetCacheStatsOK().WithPayload(&models.CacheStats{
+		Hits:   hits,
+		Misses: misses,
+		Size:   int64(size),
+	})
+}
@@ -0,0 +1,47 @@
+package handler
+
+import (
+	"testing"
+
+	"github.com/nzin/taxsi2/internal/engine"
+	"github.com/nzin/taxsi2/swagger_gen/restapi/operations/admin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPluginStats(t *testing.T) {
+	t.Run("happy path: list the counters", func(t *testing.T) {
+		c := crud{
+			ds: nil,
+			wafEngine: &WafEngineMock{
+				stats: []engine.PluginStats{{Plugin: "modsec", Timeouts: 3}, {Plugin: "sqli", Panics: 1}},
+			},
+		}
+		res := c.GetPluginStats(admin.GetPluginStatsParams{})
+		list, ok := res.(*admin.GetPluginStatsOK)
+		assert.True(t, ok)
+		assert.Equal(t, 2, len(list.Payload))
+		assert.Equal(t, "modsec", list.Payload[0].Plugin)
+		assert.Equal(t, int64(3), list.Payload[0].Timeouts)
+		assert.Equal(t, int64(1), list.Payload[1].Panics)
+	})
+
+	t.Run("happy path: no failure", func(t *testing.T) {
+		c := crud{ds: nil, wafEngine: &WafEngineMock{}}
+		res := c.GetPluginStats(admin.GetPluginStatsParams{})
+		list, ok := res.(*admin.GetPluginStatsOK)
+		assert.True(t, ok)
+		assert.Equal(t, 0, len(list.Payload))
+	})
+}
+
+func TestCacheStats(t *testing.T) {
+	t.Run("happy path: get the counters", func(t *testing.T) {
+		c := crud{ds: nil, wafEngine: &WafEngineMock{hits: 12, misses: 3, size: 2}}
+		res := c.GetCacheStats(admin.GetCacheStatsParams{})
+		stats, ok := res.(*admin.GetCacheStatsOK)
+		assert.True(t, ok)
+		assert.Equal(t, int64(12), stats.Payload.Hits)
+		assert.Equal(t, int64(3), stats.Payload.Misses)
+		assert.Equal(t, int64(2), stats.Payload.Size)
+	})
+}
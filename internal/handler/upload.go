@@ -0,0 +1,126 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/go-openapi/runtime/middleware"
+	"github.com/nzin/taxsi2/internal/db"
+	"github.com/nzin/taxsi2/swagger_gen/models"
+	"github.com/nzin/taxsi2/swagger_gen/restapi/operations/admin"
+	"gorm.io/gorm"
+)
+
+func (c *crud) GetUploadpolicies(params admin.GetUploadpoliciesParams) middleware.Responder {
+	policies, err := c.ds.GetUploadPolicies()
+	if err != nil {
+		return admin.NewGetUploadpoliciesDefault(500).WithPayload(
+			ErrorMessage("unable to read the upload policies: %v", err),
+		)
+	}
+
+	payload := []*models.UploadPolicy{}
+	for _, p := range policies {
+		payload = append(payload, uploadPolicyToModel(p))
+	}
+	return admin.NewGetUploadpoliciesOK().WithPayload(payload)
+}
+
+func (c *crud) PostUploadpolicy(params admin.PostUploadpolicyParams) middleware.Responder {
+	policy, err := uploadPolicyFromModel(params.Body)
+	if err != nil {
+		return admin.NewPostUploadpolicyDefault(400).WithPayload(
+			ErrorMessage("%v", err),
+		)
+	}
+
+	if err := c.ds.SetUploadPolicy(policy); err != nil {
+		return admin.NewPostUploadpolicyDefault(500).WithPayload(
+			ErrorMessage("unable to add the upload policy: %v", err),
+		)
+	}
+	return admin.NewPostUploadpolicyCreated().WithPayload(uploadPolicyToModel(*policy))
+}
+
+func (c *crud) PutUploadpolicy(params admin.PutUploadpolicyParams) middleware.Responder {
+	policy, err := uploadPolicyFromModel(params.Body)
+	if err != nil {
+		return admin.NewPutUploadpolicyDefault(400).WithPayload(
+			ErrorMessage("%v", err),
+		)
+	}
+
+	found := false
+	policies, err := c.ds.GetUploadPolicies()
+	if err != nil {
+		return admin.NewPutUploadpolicyDefault(500).WithPayload(
+			ErrorMessage("unable to read the upload policies: %v", err),
+		)
+	}
+	for _, p := range policies {
+		if p.ID == uint(params.ID) {
+			policy.Model = p.Model
+			found = true
+		}
+	}
+	if !found {
+		return admin.NewPutUploadpolicyDefault(404).WithPayload(
+			ErrorMessage("upload policy %d not found", params.ID),
+		)
+	}
+
+	if err := c.ds.SetUploadPolicy(policy); err != nil {
+		return admin.NewPutUploadpolicyDefault(500).WithPayload(
+			ErrorMessage("unable to update the upload policy: %v", err),
+		)
+	}
+	return admin.NewPutUploadpolicyOK().WithPayload(uploadPolicyToModel(*policy))
+}
+
+func (c *crud) DeleteUploadpolicy(params admin.DeleteUploadpolicyParams) middleware.Responder {
+	err := c.ds.DeleteUploadPolicy(uint(params.ID))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return admin.NewDeleteUploadpolicyDefault(404).WithPayload(
+			ErrorMessage("upload policy %d not found", params.ID),
+		)
+	}
+	if err != nil {
+		return admin.NewDeleteUploadpolicyDefault(500).WithPayload(
+			ErrorMessage("unable to remove the upload policy: %v", err),
+		)
+	}
+	return admin.NewDeleteUploadpolicyOK()
+}
+
+func uploadPolicyToModel(p db.UploadPolicy) *models.UploadPolicy {
+	return &models.UploadPolicy{
+		ID:                int64(p.ID),
+		PathPrefix:        p.PathPrefix,
+		Deny:              p.Deny,
+		AllowedExtensions: p.AllowedExtensions,
+		DeniedExtensions:  p.DeniedExtensions,
+		AllowedTypes:      p.AllowedTypes,
+		MaxFileSize:       int64(p.MaxFileSize),
+	}
+}
+
+func uploadPolicyFromModel(m *models.UploadPolicy) (*db.UploadPolicy, error) {
+	if m.MaxFileSize < 0 {
+		return nil, fmt.Errorf("the max file size must be a positive number")
+	}
+	for _, t := range strings.Split(m.AllowedTypes, ",") {
+		if t = strings.TrimSpace(t); t != "" && !strings.Contains(t, "/") {
+			return nil, fmt.Errorf("bad content type %s (must be like image/png)", t)
+		}
+	}
+
+	return &db.UploadPolicy{
+		PathPrefix:        m.PathPrefix,
+		Deny:              m.Deny,
+		AllowedExtensions: m.AllowedExtensions,
+		DeniedExtensions:  m.DeniedExtensions,
+		AllowedTypes:      m.AllowedTypes,
+		MaxFileSize:       int(m.MaxFileSize),
+	}, nil
+}
@@ -0,0 +1,85 @@
+package handler
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/nzin/taxsi2/internal/db"
+	"github.com/nzin/taxsi2/swagger_gen/models"
+	"github.com/nzin/taxsi2/swagger_gen/restapi/operations/admin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUploadpolicies(t *testing.T) {
+	t.Run("happy path: add, update, list and remove a policy", func(t *testing.T) {
+		tmpFile, err := os.CreateTemp("", "taxsi.temp*")
+		assert.Nil(t, err)
+		defer os.Remove(tmpFile.Name())
+
+		ds, err := db.NewDbService("sqlite3", tmpFile.Name(), 1, 1*time.Second)
+		assert.Nil(t, err)
+
+		c := crud{
+			ds:        ds,
+			wafEngine: nil,
+		}
+
+		policy := models.UploadPolicy{
+			PathPrefix:        "/cms/media",
+			AllowedExtensions: "jpg,png",
+			AllowedTypes:      "image/jpeg,image/png",
+			MaxFileSize:       1024,
+		}
+		res := c.PostUploadpolicy(admin.PostUploadpolicyParams{Body: &policy})
+		created, ok := res.(*admin.PostUploadpolicyCreated)
+		assert.True(t, ok)
+		assert.NotEqual(t, int64(0), created.Payload.ID)
+
+		policy.MaxFileSize = 2048
+		res = c.PutUploadpolicy(admin.PutUploadpolicyParams{ID: created.Payload.ID, Body: &policy})
+		_, ok = res.(*admin.PutUploadpolicyOK)
+		assert.True(t, ok)
+
+		res = c.GetUploadpolicies(admin.GetUploadpoliciesParams{})
+		policies, ok := res.(*admin.GetUploadpoliciesOK)
+		assert.True(t, ok)
+		assert.Equal(t, 1, len(policies.Payload))
+		assert.Equal(t, int64(2048), policies.Payload[0].MaxFileSize)
+
+		res = c.DeleteUploadpolicy(admin.DeleteUploadpolicyParams{ID: created.Payload.ID})
+		_, ok = res.(*admin.DeleteUploadpolicyOK)
+		assert.True(t, ok)
+
+		res = c.DeleteUploadpolicy(admin.DeleteUploadpolicyParams{ID: created.Payload.ID})
+		_, ok = res.(*admin.DeleteUploadpolicyDefault)
+		assert.True(t, ok)
+	})
+
+	t.Run("not happy path: bad policies", func(t *testing.T) {
+		tmpFile, err := os.CreateTemp("", "taxsi.temp*")
+		assert.Nil(t, err)
+		defer os.Remove(tmpFile.Name())
+
+		ds, err := db.NewDbService("sqlite3", tmpFile.Name(), 1, 1*time.Second)
+		assert.Nil(t, err)
+
+		c := crud{
+			ds:        ds,
+			wafEngine: nil,
+		}
+
+		for _, policy := range []models.UploadPolicy{
+			{MaxFileSize: -1},
+			{AllowedTypes: "image/png,jpeg"},
+		} {
+			res := c.PostUploadpolicy(admin.PostUploadpolicyParams{Body: &policy})
+			_, ok := res.(*admin.PostUploadpolicyDefault)
+			assert.True(t, ok)
+		}
+
+		res := c.PutUploadpolicy(admin.PutUploadpolicyParams{ID: 42, Body: &models.UploadPolicy{}})
+		_, ok := res.(*admin.PutUploadpolicyDefault)
+		assert.True(t, ok)
+	})
+}
@@ -0,0 +1,351 @@
+package policy
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+
+	"github.com/nzin/taxsi2/internal/db"
+	"github.com/nzin/taxsi2/internal/engine"
+	"github.com/nzin/taxsi2/internal/engine/plugins/botmanager"
+	"github.com/nzin/taxsi2/internal/engine/plugins/graphql"
+	"github.com/nzin/taxsi2/internal/engine/plugins/jwt"
+	"github.com/nzin/taxsi2/internal/engine/plugins/modsec"
+	"github.com/nzin/taxsi2/internal/engine/plugins/osattack"
+	"github.com/nzin/taxsi2/internal/engine/plugins/protocol"
+	"github.com/nzin/taxsi2/internal/engine/plugins/ratelimiter"
+	"github.com/nzin/taxsi2/internal/engine/plugins/ssrf"
+	"gopkg.in/yaml.v2"
+)
+
+/*
+Document is the declarative representation of the taxsi2 policy,
+to be managed from git (see 'taxsi2 config export/apply').
+The upload policies, OpenAPI specs, JWT keys and secret config values
+(like the challenge keys, see db.SECRET_CONFIG_KEYS) are not part of it, nor the bans (that are runtime state: the autobans,
+or the bans added during an incident)
+*/
+type Document struct {
+	// GlobalConfig key/values (except the allow/deny lists)
+	Config         map[string]string     `yaml:"config" json:"config"`
+	Allowlist      []string              `yaml:"allowlist" json:"allowlist"`
+	Denylist       []string              `yaml:"denylist" json:"denylist"`
+	Geoip          GeoipPolicy           `yaml:"geoip" json:"geoip"`
+	Ratelimits     []RatelimitPolicy     `yaml:"ratelimits" json:"ratelimits"`
+	Rules          []RulePolicy          `yaml:"rules" json:"rules"`
+	JwtPolicies    []JwtPolicy           `yaml:"jwt_policies" json:"jwt_policies"`
+	ModsecRulesets []ModsecRulesetPolicy `yaml:"modsec_rulesets" json:"modsec_rulesets"`
+}
+
+type GeoipPolicy struct {
+	// allow or deny
+	Mode      string   `yaml:"mode" json:"mode"`
+	Countries []string `yaml:"countries" json:"countries"`
+}
+
+const (
+	CHANGE_ADD    = "add"
+	CHANGE_UPDATE = "update"
+	CHANGE_REMOVE = "remove"
+)
+
+/*
+Change is a difference between a Document and the database
+*/
+type Change struct {
+	Section string // config, allowlist, denylist, geoip, ratelimits, rules, jwt_policies, modsec_rulesets
+	Key     string
+	Action  string // add, update, remove
+	Old     string
+	New     string
+}
+
+func (c Change) String() string {
+	name := c.Section
+	if c.Key != "" {
+		name = c.Section + "." + c.Key
+	}
+	switch c.Action {
+	case CHANGE_ADD:
+		return fmt.Sprintf("+ %s: %s", name, c.New)
+	case CHANGE_REMOVE:
+		return fmt.Sprintf("- %s: %s", name, c.Old)
+	default:
+		return fmt.Sprintf("~ %s: %s -> %s", name, c.Old, c.New)
+	}
+}
+
+/*
+Export reads the policy from the database
+*/
+func Export(ds db.DbService) (*Document, error) {
+	configs, err := ds.GetConfigs()
+	if err != nil {
+		return nil, err
+	}
+
+	doc := Document{
+		Config:    make(map[string]string),
+		Allowlist: []string{},
+		Denylist:  []string{},
+		Geoip: GeoipPolicy{
+			Mode:      "allow",
+			Countries: []string{},
+		},
+		Ratelimits:     []RatelimitPolicy{},
+		Rules:          []RulePolicy{},
+		JwtPolicies:    []JwtPolicy{},
+		ModsecRulesets: []ModsecRulesetPolicy{},
+	}
+	for k, v := range configs {
+		switch k {
+		case "allowlist":
+			doc.Allowlist = splitList(v)
+		case "denylist":
+			doc.Denylist = splitList(v)
+		default:
+			if db.SECRET_CONFIG_KEYS[k] {
+				continue
+			}
+			doc.Config[k] = v
+		}
+	}
+
+	countries, err := ds.GetGeoipCountries()
+	if err != nil {
+		return nil, err
+	}
+	for cc, allow := range countries {
+		doc.Geoip.Countries = append(doc.Geoip.Countries, cc)
+		if !allow {
+			doc.Geoip.Mode = "deny"
+		}
+	}
+	sort.Strings(doc.Geoip.Countries)
+
+	if err := exportTables(ds, &doc); err != nil {
+		return nil, err
+	}
+	return &doc, nil
+}
+
+/*
+Marshal returns the YAML representation of the document
+*/
+func (d *Document) Marshal() ([]byte, error) {
+	return yaml.Marshal(d)
+}
+
+/*
+Unmarshal parses (and validates) a YAML policy document
+*/
+func Unmarshal(data []byte) (*Document, error) {
+	var doc Document
+	if err := yaml.UnmarshalStrict(data, &doc); err != nil {
+		return nil, fmt.Errorf("not able to parse the policy: %v", err)
+	}
+	if doc.Config == nil {
+		doc.Config = make(map[string]string)
+	}
+	if err := doc.Validate(); err != nil {
+		return nil, err
+	}
+	return &doc, nil
+}
+
+// the ValidateConfig functions of the engine and of the plugins
+var configValidators = []func(key string, value string) error{
+	engine.ValidateConfig,
+	botmanager.ValidateConfig,
+	graphql.ValidateConfig,
+	jwt.ValidateConfig,
+	modsec.ValidateConfig,
+	osattack.ValidateConfig,
+	protocol.ValidateConfig,
+	ratelimiter.ValidateConfig,
+	ssrf.ValidateConfig,
+}
+
+/*
+Validate checks the document before it is applied
+*/
+func (d *Document) Validate() error {
+	keys := []string{}
+	for k := range d.Config {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if k == "allowlist" || k == "denylist" {
+			return fmt.Errorf("%s must be defined as a top level list, not in config", k)
+		}
+		if db.SECRET_CONFIG_KEYS[k] {
+			return fmt.Errorf("%s is a secret, it can't be part of the policy", k)
+		}
+		// the values are checked by the parsers of the engine and the plugins
+		for _, validate := range configValidators {
+			if err := validate(k, d.Config[k]); err != nil {
+				return err
+			}
+		}
+	}
+	for _, n := range d.Allowlist {
+		if _, _, err := net.ParseCIDR(n); err != nil {
+			return fmt.Errorf("not able to parse allow net %s: %v", n, err)
+		}
+	}
+	for _, n := range d.Denylist {
+		if _, _, err := net.ParseCIDR(n); err != nil {
+			return fmt.Errorf("not able to parse deny net %s: %v", n, err)
+		}
+	}
+	if len(d.Geoip.Countries) > 0 && d.Geoip.Mode != "allow" && d.Geoip.Mode != "deny" {
+		return fmt.Errorf("bad geoip mode %s (must be allow or deny)", d.Geoip.Mode)
+	}
+	return d.validateTables()
+}
+
+/*
+Diff returns the changes needed to go from the database state to the document
+*/
+func Diff(ds db.DbService, d *Document) ([]Change, error) {
+	current, err := Export(ds)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := diffMap("config", current.Config, d.Config)
+	changes = append(changes, diffList("allowlist", current.Allowlist, d.Allowlist)...)
+	changes = append(changes, diffList("denylist", current.Denylist, d.Denylist)...)
+	changes = append(changes, diffGeoip(current.Geoip, d.Geoip)...)
+	changes = append(changes, diffTables(current, d)...)
+
+	return changes, nil
+}
+
+/*
+Apply updates the database (in a single transaction) to match the document.
+It goes through the regular DbService setters, so the other nodes are
+notified via the changelog.
+It returns the applied changes
+*/
+func Apply(ds db.DbService, d *Document, author string, comment string) ([]Change, error) {
+	var changes []Change
+
+	err := ds.Transaction(func(tx db.DbService) error {
+		var err error
+		changes, err = Diff(tx, d)
+		if err != nil {
+			return err
+		}
+
+		for _, c := range changes {
+			if err := applyChange(tx, d, c, author, comment); err != nil {
+				return fmt.Errorf("not able to apply '%s': %v", c, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return changes, nil
+}
+
+func applyChange(ds db.DbService, d *Document, c Change, author string, comment string) error {
+	switch c.Section {
+	case "config":
+		if c.Action == CHANGE_REMOVE {
+			return ds.DeleteConfigKeyWithAuthor(c.Key, author, comment)
+		}
+		return ds.SetConfigValueForKeyWithAuthor(c.Key, c.New, author, comment)
+	case "allowlist", "denylist":
+		if c.Action == CHANGE_REMOVE {
+			return ds.DeleteConfigKeyWithAuthor(c.Section, author, comment)
+		}
+		return ds.SetConfigValueForKeyWithAuthor(c.Section, c.New, author, comment)
+	case "geoip":
+		return ds.SetGeoipCountries(d.Geoip.Countries, d.Geoip.Mode != "deny")
+	case "ratelimits", "rules", "jwt_policies", "modsec_rulesets":
+		return applyTableChange(ds, d, c, author)
+	}
+	return fmt.Errorf("unknown section %s", c.Section)
+}
+
+func diffMap(section string, current map[string]string, wanted map[string]string) []Change {
+	keys := []string{}
+	for k := range current {
+		keys = append(keys, k)
+	}
+	for k := range wanted {
+		if _, ok := current[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	changes := []Change{}
+	for _, k := range keys {
+		oldValue, inCurrent := current[k]
+		newValue, inWanted := wanted[k]
+		switch {
+		case !inCurrent:
+			changes = append(changes, Change{Section: section, Key: k, Action: CHANGE_ADD, New: newValue})
+		case !inWanted:
+			changes = append(changes, Change{Section: section, Key: k, Action: CHANGE_REMOVE, Old: oldValue})
+		case oldValue != newValue:
+			changes = append(changes, Change{Section: section, Key: k, Action: CHANGE_UPDATE, Old: oldValue, New: newValue})
+		}
+	}
+	return changes
+}
+
+func diffList(section string, current []string, wanted []string) []Change {
+	oldValue := strings.Join(current, ",")
+	newValue := strings.Join(wanted, ",")
+	switch {
+	case oldValue == newValue:
+		return []Change{}
+	case oldValue == "":
+		return []Change{{Section: section, Action: CHANGE_ADD, New: newValue}}
+	case newValue == "":
+		return []Change{{Section: section, Action: CHANGE_REMOVE, Old: oldValue}}
+	}
+	return []Change{{Section: section, Action: CHANGE_UPDATE, Old: oldValue, New: newValue}}
+}
+
+func diffGeoip(current GeoipPolicy, wanted GeoipPolicy) []Change {
+	wantedCountries := append([]string{}, wanted.Countries...)
+	sort.Strings(wantedCountries)
+
+	oldValue := geoipString(current.Mode, current.Countries)
+	newValue := geoipString(wanted.Mode, wantedCountries)
+	switch {
+	case oldValue == newValue:
+		return []Change{}
+	case oldValue == "":
+		return []Change{{Section: "geoip", Action: CHANGE_ADD, New: newValue}}
+	case newValue == "":
+		return []Change{{Section: "geoip", Action: CHANGE_REMOVE, Old: oldValue}}
+	}
+	return []Change{{Section: "geoip", Action: CHANGE_UPDATE, Old: oldValue, New: newValue}}
+}
+
+func geoipString(mode string, countries []string) string {
+	if len(countries) == 0 {
+		return ""
+	}
+	return mode + " " + strings.Join(countries, ",")
+}
+
+func splitList(v string) []string {
+	res := []string{}
+	for _, n := range strings.Split(v, ",") {
+		n = strings.TrimSpace(n)
+		if n != "" {
+			res = append(res, n)
+		}
+	}
+	return res
+}
@@ -0,0 +1,399 @@
+package policy
+
+import (
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/nzin/taxsi2/internal/db"
+	"github.com/nzin/taxsi2/internal/engine"
+	"github.com/stretchr/testify/assert"
+)
+
+const policyYaml = `
+config:
+  mode: dryrun
+  plugin_geoip: enabled
+allowlist:
+  - 10.0.0.0/8
+denylist:
+  - 1.2.3.4/32
+  - 5.6.0.0/16
+geoip:
+  mode: deny
+  countries:
+    - RU
+    - CN
+`
+
+func newDbService(t *testing.T) (db.DbService, func()) {
+	tmpFile, err := os.CreateTemp("", "taxsi.temp*")
+	assert.Nil(t, err)
+
+	dbs, err := db.NewDbService("sqlite3", tmpFile.Name(), 1, 1*time.Second)
+	assert.Nil(t, err)
+	assert.NotNil(t, dbs)
+
+	return dbs, func() { os.Remove(tmpFile.Name()) }
+}
+
+func TestUnmarshal(t *testing.T) {
+	t.Run("happy path: parse a policy", func(t *testing.T) {
+		doc, err := Unmarshal([]byte(policyYaml))
+		assert.Nil(t, err)
+		assert.Equal(t, "dryrun", doc.Config["mode"])
+		assert.Equal(t, []string{"1.2.3.4/32", "5.6.0.0/16"}, doc.Denylist)
+		assert.Equal(t, "deny", doc.Geoip.Mode)
+	})
+
+	t.Run("not happy path: bad values", func(t *testing.T) {
+		_, err := Unmarshal([]byte("config:\n  mode: foobar\n"))
+		assert.NotNil(t, err)
+
+		_, err = Unmarshal([]byte("allowlist:\n  - 1.2.3.4/\n"))
+		assert.NotNil(t, err)
+
+		_, err = Unmarshal([]byte("config:\n  denylist: 1.2.3.4/32\n"))
+		assert.NotNil(t, err)
+
+		_, err = Unmarshal([]byte("geoip:\n  mode: foo\n  countries: [FR]\n"))
+		assert.NotNil(t, err)
+
+		_, err = Unmarshal([]byte("config:\n  autoban_threshold: -1\n"))
+		assert.NotNil(t, err)
+
+		_, err = Unmarshal([]byte("config:\n  autoban_window: 10\n"))
+		assert.NotNil(t, err)
+
+		_, err = Unmarshal([]byte("config:\n  botmanager_action_badbot: captcha\n"))
+		assert.NotNil(t, err)
+
+		_, err = Unmarshal([]byte("config:\n  challenge_difficulty: 64\n"))
+		assert.NotNil(t, err)
+
+		// the limit of the engine
+		_, err = Unmarshal([]byte(fmt.Sprintf("config:\n  challenge_difficulty: %d\n", engine.MAX_CHALLENGE_DIFFICULTY+1)))
+		assert.NotNil(t, err)
+		_, err = Unmarshal([]byte(fmt.Sprintf("config:\n  challenge_difficulty: %d\n", engine.MAX_CHALLENGE_DIFFICULTY)))
+		assert.Nil(t, err)
+
+		_, err = Unmarshal([]byte("config:\n  plugin_sqli: on\n"))
+		assert.NotNil(t, err)
+
+		_, err = Unmarshal([]byte("config:\n  botmanager_action_robot: block\n"))
+		assert.NotNil(t, err)
+
+		_, err = Unmarshal([]byte("config:\n  ratelimit_mode: redis\n"))
+		assert.NotNil(t, err)
+
+		_, err = Unmarshal([]byte("config:\n  malformed_body_action: drop\n"))
+		assert.NotNil(t, err)
+
+		_, err = Unmarshal([]byte("config:\n  protocol_max_headers: 0\n"))
+		assert.NotNil(t, err)
+
+		_, err = Unmarshal([]byte("config:\n  graphql_max_depth: -1\n"))
+		assert.NotNil(t, err)
+
+		_, err = Unmarshal([]byte("config:\n  graphql_introspection: maybe\n"))
+		assert.NotNil(t, err)
+
+		_, err = Unmarshal([]byte("config:\n  jwt_clock_skew: 1m\n"))
+		assert.NotNil(t, err)
+
+		_, err = Unmarshal([]byte("config:\n  osattack_os: unix,macos\n"))
+		assert.NotNil(t, err)
+
+		_, err = Unmarshal([]byte("config:\n  ssrf_blocked_networks: 10.0.0.0/8,127.0.0.1\n"))
+		assert.NotNil(t, err)
+
+		_, err = Unmarshal([]byte("config:\n  ssrf_resolve: maybe\n"))
+		assert.NotNil(t, err)
+
+		_, err = Unmarshal([]byte("config:\n  ssrf_unresolved_action: ignore\n"))
+		assert.NotNil(t, err)
+
+		_, err = Unmarshal([]byte("config:\n  modsec_anomaly_threshold: 0\n"))
+		assert.NotNil(t, err)
+
+		_, err = Unmarshal([]byte("config:\n  scoring_mode: maybe\n"))
+		assert.NotNil(t, err)
+
+		_, err = Unmarshal([]byte("config:\n  scoring_threshold: 0\n"))
+		assert.NotNil(t, err)
+
+		_, err = Unmarshal([]byte("config:\n  scoring_weight_sqli: -5\n"))
+		assert.NotNil(t, err)
+
+		_, err = Unmarshal([]byte("config:\n  scoring_policies: /admin=5,api.example.com=20\n"))
+		assert.NotNil(t, err)
+
+		_, err = Unmarshal([]byte("config:\n  scan_timeout_modsec: 10\n"))
+		assert.NotNil(t, err)
+
+		_, err = Unmarshal([]byte("config:\n  scan_failure: ignore\n"))
+		assert.NotNil(t, err)
+
+		_, err = Unmarshal([]byte("config:\n  scan_parallel: yes\n"))
+		assert.NotNil(t, err)
+
+		_, err = Unmarshal([]byte("config:\n  verdict_cache: on\n"))
+		assert.NotNil(t, err)
+
+		_, err = Unmarshal([]byte("config:\n  verdict_cache_ttl: 5\n"))
+		assert.NotNil(t, err)
+
+		_, err = Unmarshal([]byte("config:\n  verdict_cache_size: 0\n"))
+		assert.NotNil(t, err)
+
+		_, err = Unmarshal([]byte("config:\n  bypass_rules: GET /static/*.css,get /favicon.ico\n"))
+		assert.NotNil(t, err)
+
+		_, err = Unmarshal([]byte("ratelimits:\n  - path_prefix: /api\n    key: cookie:session\n    algorithm: token-bucket\n    limit: 10\n    period: 60\n"))
+		assert.NotNil(t, err)
+
+		_, err = Unmarshal([]byte("ratelimits:\n  - {path_prefix: /api, key: ip, algorithm: token-bucket, limit: 10, period: 60}\n  - {path_prefix: /api, key: path, algorithm: token-bucket, limit: 10, period: 60}\n"))
+		assert.NotNil(t, err)
+
+		_, err = Unmarshal([]byte("rules:\n  - id: \"1\"\n    expression: path ==\n    action: block\n"))
+		assert.NotNil(t, err)
+
+		_, err = Unmarshal([]byte("jwt_policies:\n  - path_prefix: /api\n    required_claims: =admin\n"))
+		assert.NotNil(t, err)
+
+		_, err = Unmarshal([]byte("modsec_rulesets:\n  - content: SecRuleEngine On\n"))
+		assert.NotNil(t, err)
+
+		_, err = Unmarshal([]byte("config:\n  challenge_keys: 0123456789abcdef0123456789abcdef\n"))
+		assert.NotNil(t, err)
+
+		// unknown section
+		_, err = Unmarshal([]byte("foo: bar\n"))
+		assert.NotNil(t, err)
+	})
+}
+
+func TestExport(t *testing.T) {
+	t.Run("happy path: export an empty db", func(t *testing.T) {
+		dbs, cleanup := newDbService(t)
+		defer cleanup()
+
+		doc, err := Export(dbs)
+		assert.Nil(t, err)
+		assert.Equal(t, 0, len(doc.Config))
+		assert.Equal(t, 0, len(doc.Allowlist))
+		assert.Equal(t, 0, len(doc.Geoip.Countries))
+		assert.Equal(t, 0, len(doc.Rules))
+	})
+
+	t.Run("happy path: export/apply roundtrip", func(t *testing.T) {
+		dbs, cleanup := newDbService(t)
+		defer cleanup()
+
+		err := dbs.SetConfigValueForKey("mode", "enabled")
+		assert.Nil(t, err)
+		err = dbs.SetConfigValueForKey("denylist", "1.2.3.4/32,5.6.0.0/16")
+		assert.Nil(t, err)
+		err = dbs.SetGeoipCountries([]string{"FR", "CA"}, true)
+		assert.Nil(t, err)
+		err = dbs.SetConfigValueForKey("challenge_keys", "0123456789abcdef0123456789abcdef")
+		assert.Nil(t, err)
+
+		doc, err := Export(dbs)
+		assert.Nil(t, err)
+		assert.Equal(t, "enabled", doc.Config["mode"])
+		// (a secret)
+		_, ok := doc.Config["challenge_keys"]
+		assert.False(t, ok)
+		assert.Equal(t, []string{"1.2.3.4/32", "5.6.0.0/16"}, doc.Denylist)
+		assert.Equal(t, "allow", doc.Geoip.Mode)
+		assert.Equal(t, []string{"CA", "FR"}, doc.Geoip.Countries)
+
+		data, err := doc.Marshal()
+		assert.Nil(t, err)
+
+		doc2, err := Unmarshal(data)
+		assert.Nil(t, err)
+
+		// nothing to change
+		changes, err := Diff(dbs, doc2)
+		assert.Nil(t, err)
+		assert.Equal(t, 0, len(changes))
+	})
+}
+
+func TestApply(t *testing.T) {
+	t.Run("happy path: diff and apply", func(t *testing.T) {
+		dbs, cleanup := newDbService(t)
+		defer cleanup()
+
+		err := dbs.SetConfigValueForKey("mode", "enabled")
+		assert.Nil(t, err)
+		err = dbs.SetConfigValueForKey("plugin_axi", "enabled")
+		assert.Nil(t, err)
+		err = dbs.SetConfigValueForKey("denylist", "1.2.3.4/32")
+		assert.Nil(t, err)
+
+		doc, err := Unmarshal([]byte(policyYaml))
+		assert.Nil(t, err)
+
+		changes, err := Diff(dbs, doc)
+		assert.Nil(t, err)
+		assert.Equal(t, []string{
+			"~ config.mode: enabled -> dryrun",
+			"- config.plugin_axi: enabled",
+			"+ config.plugin_geoip: enabled",
+			"+ allowlist: 10.0.0.0/8",
+			"~ denylist: 1.2.3.4/32 -> 1.2.3.4/32,5.6.0.0/16",
+			"+ geoip: deny CN,RU",
+		}, changesToStrings(changes))
+
+		// the diff didn't change anything
+		value, err := dbs.GetConfigValueForKey("mode")
+		assert.Nil(t, err)
+		assert.Equal(t, "enabled", value)
+
+		applied, err := Apply(dbs, doc, "alice", "from git")
+		assert.Nil(t, err)
+		assert.Equal(t, 6, len(applied))
+
+		value, err = dbs.GetConfigValueForKey("mode")
+		assert.Nil(t, err)
+		assert.Equal(t, "dryrun", value)
+		_, err = dbs.GetConfigValueForKey("plugin_axi")
+		assert.NotNil(t, err)
+		countries, err := dbs.GetGeoipCountries()
+		assert.Nil(t, err)
+		assert.Equal(t, map[string]bool{"RU": false, "CN": false}, countries)
+
+		// the changes are in the audit trail
+		history, err := dbs.GetConfigHistory("mode", time.Time{})
+		assert.Nil(t, err)
+		assert.Equal(t, "alice", history[len(history)-1].Author)
+		assert.Equal(t, "from git", history[len(history)-1].Comment)
+
+		// applying twice is a noop
+		changes, err = Diff(dbs, doc)
+		assert.Nil(t, err)
+		assert.Equal(t, 0, len(changes))
+	})
+}
+
+func TestApplyTables(t *testing.T) {
+	t.Run("happy path: export/apply roundtrip", func(t *testing.T) {
+		dbs, cleanup := newDbService(t)
+		defer cleanup()
+
+		_, err := dbs.AddBan("1.2.3.4/32", "scanner", "alice", time.Now().Add(1*time.Hour))
+		assert.Nil(t, err)
+		err = dbs.SetRatelimitRule(&db.RatelimitRule{PathPrefix: "/api", Key: "ip", Algorithm: db.RATELIMIT_TOKEN_BUCKET, Limit: 10, Period: 60})
+		assert.Nil(t, err)
+		err = dbs.SetRule(&db.Rule{ID: "1", Expression: `path.startsWith("/admin")`, Action: "block"})
+		assert.Nil(t, err)
+		err = dbs.SetJwtPolicy(&db.JwtPolicy{PathPrefix: "/api", Required: true, RequiredClaims: "sub"})
+		assert.Nil(t, err)
+		err = dbs.SetModsecRuleset(&db.ModsecRuleset{Name: "crs.conf", Content: "SecRuleEngine On\n"})
+		assert.Nil(t, err)
+
+		doc, err := Export(dbs)
+		assert.Nil(t, err)
+		assert.Equal(t, 1, len(doc.Ratelimits))
+		assert.Equal(t, 1, len(doc.Rules))
+		assert.Equal(t, 1, len(doc.JwtPolicies))
+		assert.Equal(t, "SecRuleEngine On\n", doc.ModsecRulesets[0].Content)
+
+		data, err := doc.Marshal()
+		assert.Nil(t, err)
+
+		// in another deployment
+		dbs2, cleanup2 := newDbService(t)
+		defer cleanup2()
+		doc2, err := Unmarshal(data)
+		assert.Nil(t, err)
+		applied, err := Apply(dbs2, doc2, "bob", "")
+		assert.Nil(t, err)
+		assert.Equal(t, 4, len(applied))
+
+		changes, err := Diff(dbs2, doc)
+		assert.Nil(t, err)
+		assert.Equal(t, 0, len(changes))
+		// the bans are not exported
+		bans, err := dbs2.GetActiveBans()
+		assert.Nil(t, err)
+		assert.Equal(t, 0, len(bans))
+	})
+
+	t.Run("happy path: update and remove rows", func(t *testing.T) {
+		dbs, cleanup := newDbService(t)
+		defer cleanup()
+
+		err := dbs.SetRatelimitRule(&db.RatelimitRule{PathPrefix: "/api", Key: "ip", Algorithm: db.RATELIMIT_TOKEN_BUCKET, Limit: 10, Period: 60})
+		assert.Nil(t, err)
+		err = dbs.SetRule(&db.Rule{ID: "1", Expression: `path.startsWith("/admin")`, Action: "block"})
+		assert.Nil(t, err)
+
+		doc, err := Unmarshal([]byte(`
+ratelimits:
+  - path_prefix: /api
+    key: ip
+    algorithm: sliding-window
+    limit: 100
+    period: 60
+rules:
+  - id: "2"
+    expression: method == "TRACE"
+    action: block
+`))
+		assert.Nil(t, err)
+
+		changes, err := Diff(dbs, doc)
+		assert.Nil(t, err)
+		assert.Equal(t, []string{
+			"~ ratelimits./api: key=ip algorithm=token-bucket limit=10 period=60 burst=0 -> key=ip algorithm=sliding-window limit=100 period=60 burst=0",
+			`- rules.1: action=block expression="path.startsWith(\"/admin\")" description=""`,
+			`+ rules.2: action=block expression="method == \"TRACE\"" description=""`,
+		}, changesToStrings(changes))
+
+		_, err = Apply(dbs, doc, "alice", "")
+		assert.Nil(t, err)
+
+		ratelimits, err := dbs.GetRatelimitRules()
+		assert.Nil(t, err)
+		assert.Equal(t, 1, len(ratelimits))
+		assert.Equal(t, 100, ratelimits[0].Limit)
+		list, err := dbs.GetRules()
+		assert.Nil(t, err)
+		assert.Equal(t, 1, len(list))
+		assert.Equal(t, "2", list[0].ID)
+	})
+
+	t.Run("not happy path: the bans are not removed", func(t *testing.T) {
+		dbs, cleanup := newDbService(t)
+		defer cleanup()
+
+		_, err := dbs.AddBan("1.2.3.4/32", "too many blocked requests", db.AUTOBAN_CREATOR, time.Now().Add(1*time.Hour))
+		assert.Nil(t, err)
+
+		doc, err := Unmarshal([]byte("config:\n  mode: enabled\n"))
+		assert.Nil(t, err)
+		_, err = Apply(dbs, doc, "alice", "")
+		assert.Nil(t, err)
+
+		bans, err := dbs.GetActiveBans()
+		assert.Nil(t, err)
+		assert.Equal(t, 1, len(bans))
+
+		// not a section of the document
+		_, err = Unmarshal([]byte("bans:\n  - cidr: 1.2.3.4/32\n"))
+		assert.NotNil(t, err)
+	})
+}
+
+func changesToStrings(changes []Change) []string {
+	res := []string{}
+	for _, c := range changes {
+		res = append(res, c.String())
+	}
+	return res
+}
@@ -0,0 +1,328 @@
+package policy
+
+import (
+	"crypto/sha256"
+	"fmt"
+
+	"github.com/nzin/taxsi2/internal/db"
+	"github.com/nzin/taxsi2/internal/engine/plugins/jwt"
+	"github.com/nzin/taxsi2/internal/engine/plugins/ratelimiter"
+	"github.com/nzin/taxsi2/internal/engine/plugins/rules"
+)
+
+/*
+RatelimitPolicy is a db.RatelimitRule (there is one rule per path prefix)
+*/
+type RatelimitPolicy struct {
+	PathPrefix string `yaml:"path_prefix" json:"path_prefix"`
+	Key        string `yaml:"key" json:"key"`
+	Algorithm  string `yaml:"algorithm" json:"algorithm"`
+	Limit      int    `yaml:"limit" json:"limit"`
+	Period     int    `yaml:"period" json:"period"`
+	Burst      int    `yaml:"burst,omitempty" json:"burst,omitempty"`
+}
+
+/*
+RulePolicy is a custom rule (see the rules plugin)
+*/
+type RulePolicy struct {
+	ID          string `yaml:"id" json:"id"`
+	Expression  string `yaml:"expression" json:"expression"`
+	Action      string `yaml:"action" json:"action"`
+	Description string `yaml:"description,omitempty" json:"description,omitempty"`
+}
+
+/*
+JwtPolicy is a db.JwtPolicy (there is one policy per path prefix)
+*/
+type JwtPolicy struct {
+	PathPrefix     string `yaml:"path_prefix" json:"path_prefix"`
+	Required       bool   `yaml:"required" json:"required"`
+	Issuer         string `yaml:"issuer,omitempty" json:"issuer,omitempty"`
+	Audience       string `yaml:"audience,omitempty" json:"audience,omitempty"`
+	RequiredClaims string `yaml:"required_claims,omitempty" json:"required_claims,omitempty"`
+}
+
+/*
+ModsecRulesetPolicy is a SecLang file, run by the modsec plugin
+*/
+type ModsecRulesetPolicy struct {
+	Name    string `yaml:"name" json:"name"`
+	Content string `yaml:"content" json:"content"`
+}
+
+func (r RatelimitPolicy) String() string {
+	return fmt.Sprintf("key=%s algorithm=%s limit=%d period=%d burst=%d", r.Key, r.Algorithm, r.Limit, r.Period, r.Burst)
+}
+
+func (r RulePolicy) String() string {
+	return fmt.Sprintf("action=%s expression=%q description=%q", r.Action, r.Expression, r.Description)
+}
+
+func (p JwtPolicy) String() string {
+	return fmt.Sprintf("required=%t issuer=%q audience=%q required_claims=%q", p.Required, p.Issuer, p.Audience, p.RequiredClaims)
+}
+
+func (r ModsecRulesetPolicy) String() string {
+	// (the content is too long to be displayed)
+	sum := sha256.Sum256([]byte(r.Content))
+	return fmt.Sprintf("%d bytes, sha256 %x", len(r.Content), sum[:8])
+}
+
+func (r RatelimitPolicy) toDb() db.RatelimitRule {
+	return db.RatelimitRule{
+		PathPrefix: r.PathPrefix,
+		Key:        r.Key,
+		Algorithm:  r.Algorithm,
+		Limit:      r.Limit,
+		Period:     r.Period,
+		Burst:      r.Burst,
+	}
+}
+
+func (r RulePolicy) toDb() db.Rule {
+	return db.Rule{
+		ID:          r.ID,
+		Expression:  r.Expression,
+		Action:      r.Action,
+		Description: r.Description,
+	}
+}
+
+func (p JwtPolicy) toDb() db.JwtPolicy {
+	return db.JwtPolicy{
+		PathPrefix:     p.PathPrefix,
+		Required:       p.Required,
+		Issuer:         p.Issuer,
+		Audience:       p.Audience,
+		RequiredClaims: p.RequiredClaims,
+	}
+}
+
+/*
+exportTables reads the tables (rate limits, rules, JWT policies, modsec
+rulesets) into the document
+*/
+func exportTables(ds db.DbService, doc *Document) error {
+	ratelimits, err := ds.GetRatelimitRules()
+	if err != nil {
+		return err
+	}
+	for _, r := range ratelimits {
+		doc.Ratelimits = append(doc.Ratelimits, RatelimitPolicy{
+			PathPrefix: r.PathPrefix,
+			Key:        r.Key,
+			Algorithm:  r.Algorithm,
+			Limit:      r.Limit,
+			Period:     r.Period,
+			Burst:      r.Burst,
+		})
+	}
+
+	list, err := ds.GetRules()
+	if err != nil {
+		return err
+	}
+	for _, r := range rules.Sort(list) {
+		doc.Rules = append(doc.Rules, RulePolicy{
+			ID:          r.ID,
+			Expression:  r.Expression,
+			Action:      r.Action,
+			Description: r.Description,
+		})
+	}
+
+	policies, err := ds.GetJwtPolicies()
+	if err != nil {
+		return err
+	}
+	for _, p := range policies {
+		doc.JwtPolicies = append(doc.JwtPolicies, JwtPolicy{
+			PathPrefix:     p.PathPrefix,
+			Required:       p.Required,
+			Issuer:         p.Issuer,
+			Audience:       p.Audience,
+			RequiredClaims: p.RequiredClaims,
+		})
+	}
+
+	rulesets, err := ds.GetModsecRulesets()
+	if err != nil {
+		return err
+	}
+	for _, r := range rulesets {
+		doc.ModsecRulesets = append(doc.ModsecRulesets, ModsecRulesetPolicy{
+			Name:    r.Name,
+			Content: r.Content,
+		})
+	}
+	return nil
+}
+
+/*
+validateTables checks the tables of the document, with the validations
+of the plugins that use them
+*/
+func (d *Document) validateTables() error {
+	seen := make(map[string]bool)
+	unique := func(section string, key string) error {
+		if seen[section+" "+key] {
+			return fmt.Errorf("duplicate %s %s", section, key)
+		}
+		seen[section+" "+key] = true
+		return nil
+	}
+
+	for _, r := range d.Ratelimits {
+		if err := ratelimiter.ValidateRule(r.toDb()); err != nil {
+			return fmt.Errorf("bad ratelimit %s: %v", r.PathPrefix, err)
+		}
+		if err := unique("ratelimit", r.PathPrefix); err != nil {
+			return err
+		}
+	}
+	for _, r := range d.Rules {
+		if r.ID == "" {
+			return fmt.Errorf("a rule has no id")
+		}
+		if err := rules.ValidateRule(r.toDb()); err != nil {
+			return fmt.Errorf("bad rule %s: %v", r.ID, err)
+		}
+		if err := unique("rule", r.ID); err != nil {
+			return err
+		}
+	}
+	for _, p := range d.JwtPolicies {
+		if err := jwt.ValidatePolicy(p.toDb()); err != nil {
+			return fmt.Errorf("bad jwt policy %s: %v", p.PathPrefix, err)
+		}
+		if err := unique("jwt policy", p.PathPrefix); err != nil {
+			return err
+		}
+	}
+	for _, r := range d.ModsecRulesets {
+		if r.Name == "" {
+			return fmt.Errorf("a modsec ruleset has no name")
+		}
+		if err := unique("modsec ruleset", r.Name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+/*
+diffTables returns the changes of the tables, keyed by path prefix
+(rate limits, JWT policies), ID (rules) or name (rulesets)
+*/
+func diffTables(current *Document, d *Document) []Change {
+	ratelimits := func(doc *Document) map[string]string {
+		m := make(map[string]string)
+		for _, r := range doc.Ratelimits {
+			m[r.PathPrefix] = r.String()
+		}
+		return m
+	}
+	changes := diffMap("ratelimits", ratelimits(current), ratelimits(d))
+
+	list := func(doc *Document) map[string]string {
+		m := make(map[string]string)
+		for _, r := range doc.Rules {
+			m[r.ID] = r.String()
+		}
+		return m
+	}
+	changes = append(changes, diffMap("rules", list(current), list(d))...)
+
+	policies := func(doc *Document) map[string]string {
+		m := make(map[string]string)
+		for _, p := range doc.JwtPolicies {
+			m[p.PathPrefix] = p.String()
+		}
+		return m
+	}
+	changes = append(changes, diffMap("jwt_policies", policies(current), policies(d))...)
+
+	rulesets := func(doc *Document) map[string]string {
+		m := make(map[string]string)
+		for _, r := range doc.ModsecRulesets {
+			m[r.Name] = r.String()
+		}
+		return m
+	}
+	return append(changes, diffMap("modsec_rulesets", rulesets(current), rulesets(d))...)
+}
+
+/*
+applyTableChange applies a change of a table. The current rows are
+read again, to get their IDs
+*/
+func applyTableChange(ds db.DbService, d *Document, c Change, author string) error {
+	switch c.Section {
+	case "ratelimits":
+		current, err := ds.GetRatelimitRules()
+		if err != nil {
+			return err
+		}
+		rule := db.RatelimitRule{}
+		for _, r := range current {
+			if r.PathPrefix == c.Key {
+				rule = r
+			}
+		}
+		if c.Action == CHANGE_REMOVE {
+			return ds.DeleteRatelimitRule(rule.ID)
+		}
+		for _, r := range d.Ratelimits {
+			if r.PathPrefix == c.Key {
+				wanted := r.toDb()
+				wanted.Model = rule.Model
+				return ds.SetRatelimitRule(&wanted)
+			}
+		}
+
+	case "rules":
+		if c.Action == CHANGE_REMOVE {
+			return ds.DeleteRule(c.Key)
+		}
+		for _, r := range d.Rules {
+			if r.ID == c.Key {
+				wanted := r.toDb()
+				return ds.SetRule(&wanted)
+			}
+		}
+
+	case "jwt_policies":
+		current, err := ds.GetJwtPolicies()
+		if err != nil {
+			return err
+		}
+		policy := db.JwtPolicy{}
+		for _, p := range current {
+			if p.PathPrefix == c.Key {
+				policy = p
+			}
+		}
+		if c.Action == CHANGE_REMOVE {
+			return ds.DeleteJwtPolicy(policy.ID)
+		}
+		for _, p := range d.JwtPolicies {
+			if p.PathPrefix == c.Key {
+				wanted := p.toDb()
+				wanted.Model = policy.Model
+				return ds.SetJwtPolicy(&wanted)
+			}
+		}
+
+	case "modsec_rulesets":
+		if c.Action == CHANGE_REMOVE {
+			return ds.DeleteModsecRuleset(c.Key)
+		}
+		for _, r := range d.ModsecRulesets {
+			if r.Name == c.Key {
+				return ds.SetModsecRuleset(&db.ModsecRuleset{Name: r.Name, Content: r.Content})
+			}
+		}
+	}
+	return fmt.Errorf("%s %s not found in the document", c.Section, c.Key)
+}
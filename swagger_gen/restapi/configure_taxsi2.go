@@ -5,7 +5,9 @@ package restapi
 import (
 	"crypto/tls"
 	"net/http"
+	"os"
 
+	"github.com/nzin/taxsi2/internal/cli"
 	"github.com/nzin/taxsi2/internal/config"
 	"github.com/nzin/taxsi2/internal/handler"
 	"github.com/nzin/taxsi2/swagger_gen/restapi/operations"
@@ -20,6 +22,13 @@ import (
 //go:generate swagger generate server --target ../../swagger_gen --name golang-skeleton --spec ../../docs/api_docs/bundle.yaml
 
 func configureFlags(api *operations.Taxsi2API) {
+	// 'taxsi2 config ...' and 'taxsi2 modsec ...' sub commands don't start the server
+	if len(os.Args) > 1 && os.Args[1] == "config" {
+		os.Exit(cli.RunConfig(os.Args[2:]))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "modsec" {
+		os.Exit(cli.RunModsec(os.Args[2:]))
+	}
 	// api.CommandLineOptionsGroups = []swag.CommandLineOptionsGroup{ ... }
 }
 
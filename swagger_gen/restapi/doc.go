@@ -16,6 +16,7 @@
 //
 //	Produces:
 //	  - application/json
+//	  - application/x-yaml
 //
 // swagger:meta
 package restapi
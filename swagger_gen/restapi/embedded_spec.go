@@ -35,18 +35,21 @@ func init() {
   },
   "basePath": "/api/v1",
   "paths": {
-    "/health": {
+    "/bans": {
       "get": {
-        "description": "Check if taxsi2 is healthy",
+        "description": "List the active (not expired) IP bans",
         "tags": [
-          "health"
+          "admin"
         ],
-        "operationId": "getHealth",
+        "operationId": "getBans",
         "responses": {
           "200": {
-            "description": "status of health check",
+            "description": "the active bans",
             "schema": {
-              "$ref": "#/definitions/health"
+              "type": "array",
+              "items": {
+                "$ref": "#/definitions/ban"
+              }
             }
           },
           "default": {
@@ -56,36 +59,83 @@ func init() {
             }
           }
         }
-      }
-    },
-    "/submit": {
+      },
       "post": {
-        "description": "Submit a request payload to analyze",
-        "consumes": [
-          "application/octet-stream"
-        ],
+        "description": "Ban an IP (or a CIDR) for a given duration",
         "tags": [
-          "waf"
+          "admin"
         ],
-        "operationId": "postSubmit",
+        "operationId": "postBan",
         "parameters": [
           {
-            "description": "The request payload in binary format",
-            "name": "request",
+            "description": "the ban to add",
+            "name": "body",
             "in": "body",
             "required": true,
             "schema": {
-              "type": "string",
-              "format": "binary"
+              "$ref": "#/definitions/banCreate"
+            }
+          }
+        ],
+        "responses": {
+          "201": {
+            "description": "the ban has been added",
+            "schema": {
+              "$ref": "#/definitions/ban"
             }
+          },
+          "default": {
+            "description": "generic error response",
+            "schema": {
+              "$ref": "#/definitions/error"
+            }
+          }
+        }
+      }
+    },
+    "/bans/{id}": {
+      "delete": {
+        "description": "Lift a ban before its expiration",
+        "tags": [
+          "admin"
+        ],
+        "operationId": "deleteBan",
+        "parameters": [
+          {
+            "type": "integer",
+            "format": "int64",
+            "description": "the ban id",
+            "name": "id",
+            "in": "path",
+            "required": true
           }
         ],
         "responses": {
           "200": {
-            "description": "the request is legit"
+            "description": "the ban has been removed"
           },
-          "403": {
-            "description": "the request must be blocked"
+          "default": {
+            "description": "generic error response",
+            "schema": {
+              "$ref": "#/definitions/error"
+            }
+          }
+        }
+      }
+    },
+    "/cache/stats": {
+      "get": {
+        "description": "Get the counters of the verdict cache (since the start of the node)",
+        "tags": [
+          "admin"
+        ],
+        "operationId": "getCacheStats",
+        "responses": {
+          "200": {
+            "description": "the counters of the verdict cache",
+            "schema": {
+              "$ref": "#/definitions/cacheStats"
+            }
           },
           "default": {
             "description": "generic error response",
@@ -95,75 +145,105 @@ func init() {
           }
         }
       }
-    }
-  },
-  "definitions": {
-    "error": {
-      "type": "object",
-      "required": [
-        "message"
-      ],
-      "properties": {
-        "message": {
-          "type": "string",
-          "minLength": 1
+    },
+    "/config/export": {
+      "get": {
+        "description": "Export the policy (config, allow/deny lists, geoip countries, rate limits, rules, JWT policies, modsec rulesets) as a YAML document, to be applied with 'taxsi2 config apply'",
+        "produces": [
+          "application/x-yaml"
+        ],
+        "tags": [
+          "admin"
+        ],
+        "operationId": "getConfigExport",
+        "responses": {
+          "200": {
+            "description": "the policy document",
+            "schema": {
+              "type": "object"
+            }
+          },
+          "default": {
+            "description": "generic error response",
+            "schema": {
+              "$ref": "#/definitions/error"
+            }
+          }
         }
       }
     },
-    "health": {
-      "type": "object",
-      "properties": {
-        "status": {
-          "type": "string"
+    "/config/history": {
+      "get": {
+        "description": "List the configuration changes (oldest first)",
+        "tags": [
+          "admin"
+        ],
+        "operationId": "getConfigHistory",
+        "parameters": [
+          {
+            "type": "string",
+            "description": "only return the changes of this config key",
+            "name": "key",
+            "in": "query"
+          },
+          {
+            "type": "string",
+            "format": "date-time",
+            "description": "only return the changes made after this date",
+            "name": "since",
+            "in": "query"
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "the configuration changes",
+            "schema": {
+              "type": "array",
+              "items": {
+                "$ref": "#/definitions/configHistory"
+              }
+            }
+          },
+          "default": {
+            "description": "generic error response",
+            "schema": {
+              "$ref": "#/definitions/error"
+            }
+          }
         }
       }
-    }
-  },
-  "tags": [
-    {
-      "description": "Check if taxsi2 is healthy",
-      "name": "health"
-    }
-  ],
-  "x-tagGroups": [
-    {
-      "name": "taxsi2 Management",
-      "tags": [
-        "app"
-      ]
     },
-    {
-      "name": "Health Check",
-      "tags": [
-        "health"
-      ]
+    "/config/rollback": {
+      "post": {
+        "description": "Rollback a config key (or the whole config) to a point in time",
+        "tags": [
+          "admin"
+        ],
+        "operationId": "postConfigRollback",
+        "parameters": [
+          {
+            "description": "the rollback to apply",
+            "name": "body",
+            "in": "body",
+            "required": true,
+            "schema": {
+              "$ref": "#/definitions/configRollback"
+            }
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "the config has been rolled back"
+          },
+          "default": {
+            "description": "generic error response",
+            "schema": {
+              "$ref": "#/definitions/error"
+            }
+          }
+        }
+      }
     },
-    {
-      "name": "WAF",
-      "tags": [
-        "waf"
-      ]
-    }
-  ]
-}`))
-	FlatSwaggerJSON = json.RawMessage([]byte(`{
-  "consumes": [
-    "application/json"
-  ],
-  "produces": [
-    "application/json"
-  ],
-  "schemes": [
-    "http"
-  ],
-  "swagger": "2.0",
-  "info": {
-    "description": "taxsi2 is a WAF server application The base path for all the APIs is \"/api/v1\".\n",
-    "title": "taxsi2",
-    "version": "1.0.0"
-  },
-  "basePath": "/api/v1",
-  "paths": {
     "/health": {
       "get": {
         "description": "Check if taxsi2 is healthy",
@@ -187,34 +267,63 @@ func init() {
         }
       }
     },
-    "/submit": {
-      "post": {
-        "description": "Submit a request payload to analyze",
-        "consumes": [
-          "application/octet-stream"
+    "/jwt/keys": {
+      "get": {
+        "description": "List the keys the bearer tokens are checked with (the keys of the JWKS files are not listed)",
+        "tags": [
+          "admin"
         ],
+        "operationId": "getJwtKeys",
+        "responses": {
+          "200": {
+            "description": "the keys",
+            "schema": {
+              "type": "array",
+              "items": {
+                "$ref": "#/definitions/jwtKey"
+              }
+            }
+          },
+          "default": {
+            "description": "generic error response",
+            "schema": {
+              "$ref": "#/definitions/error"
+            }
+          }
+        }
+      }
+    },
+    "/jwt/keys/{kid}": {
+      "put": {
+        "description": "Add or replace a key",
         "tags": [
-          "waf"
+          "admin"
         ],
-        "operationId": "postSubmit",
+        "operationId": "putJwtKey",
         "parameters": [
           {
-            "description": "The request payload in binary format",
-            "name": "request",
+            "type": "string",
+            "description": "the key id",
+            "name": "kid",
+            "in": "path",
+            "required": true
+          },
+          {
+            "description": "the key",
+            "name": "body",
             "in": "body",
             "required": true,
             "schema": {
-              "type": "string",
-              "format": "binary"
+              "$ref": "#/definitions/jwtKey"
             }
           }
         ],
         "responses": {
           "200": {
-            "description": "the request is legit"
-          },
-          "403": {
-            "description": "the request must be blocked"
+            "description": "the key has been saved",
+            "schema": {
+              "$ref": "#/definitions/jwtKey"
+            }
           },
           "default": {
             "description": "generic error response",
@@ -223,42 +332,2715 @@ func init() {
             }
           }
         }
-      }
-    }
-  },
-  "definitions": {
-    "error": {
-      "type": "object",
-      "required": [
-        "message"
-      ],
-      "properties": {
-        "message": {
-          "type": "string",
-          "minLength": 1
+      },
+      "delete": {
+        "description": "Remove a key",
+        "tags": [
+          "admin"
+        ],
+        "operationId": "deleteJwtKey",
+        "parameters": [
+          {
+            "type": "string",
+            "description": "the key id",
+            "name": "kid",
+            "in": "path",
+            "required": true
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "the key has been removed"
+          },
+          "default": {
+            "description": "generic error response",
+            "schema": {
+              "$ref": "#/definitions/error"
+            }
+          }
         }
       }
     },
-    "health": {
-      "type": "object",
-      "properties": {
-        "status": {
-          "type": "string"
-        }
-      }
-    }
-  },
-  "tags": [
-    {
-      "description": "Check if taxsi2 is healthy",
-      "name": "health"
+    "/jwt/policies": {
+      "get": {
+        "description": "List the JWT policies",
+        "tags": [
+          "admin"
+        ],
+        "operationId": "getJwtPolicies",
+        "responses": {
+          "200": {
+            "description": "the JWT policies",
+            "schema": {
+              "type": "array",
+              "items": {
+                "$ref": "#/definitions/jwtPolicy"
+              }
+            }
+          },
+          "default": {
+            "description": "generic error response",
+            "schema": {
+              "$ref": "#/definitions/error"
+            }
+          }
+        }
+      },
+      "post": {
+        "description": "Add a JWT policy",
+        "tags": [
+          "admin"
+        ],
+        "operationId": "postJwtPolicy",
+        "parameters": [
+          {
+            "description": "the policy to add",
+            "name": "body",
+            "in": "body",
+            "required": true,
+            "schema": {
+              "$ref": "#/definitions/jwtPolicy"
+            }
+          }
+        ],
+        "responses": {
+          "201": {
+            "description": "the policy has been added",
+            "schema": {
+              "$ref": "#/definitions/jwtPolicy"
+            }
+          },
+          "default": {
+            "description": "generic error response",
+            "schema": {
+              "$ref": "#/definitions/error"
+            }
+          }
+        }
+      }
+    },
+    "/jwt/policies/{id}": {
+      "put": {
+        "description": "Update a JWT policy",
+        "tags": [
+          "admin"
+        ],
+        "operationId": "putJwtPolicy",
+        "parameters": [
+          {
+            "type": "integer",
+            "format": "int64",
+            "description": "the policy id",
+            "name": "id",
+            "in": "path",
+            "required": true
+          },
+          {
+            "description": "the new policy",
+            "name": "body",
+            "in": "body",
+            "required": true,
+            "schema": {
+              "$ref": "#/definitions/jwtPolicy"
+            }
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "the policy has been updated",
+            "schema": {
+              "$ref": "#/definitions/jwtPolicy"
+            }
+          },
+          "default": {
+            "description": "generic error response",
+            "schema": {
+              "$ref": "#/definitions/error"
+            }
+          }
+        }
+      },
+      "delete": {
+        "description": "Remove a JWT policy",
+        "tags": [
+          "admin"
+        ],
+        "operationId": "deleteJwtPolicy",
+        "parameters": [
+          {
+            "type": "integer",
+            "format": "int64",
+            "description": "the policy id",
+            "name": "id",
+            "in": "path",
+            "required": true
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "the policy has been removed"
+          },
+          "default": {
+            "description": "generic error response",
+            "schema": {
+              "$ref": "#/definitions/error"
+            }
+          }
+        }
+      }
+    },
+    "/modsec/rulesets": {
+      "get": {
+        "description": "List the SecLang rulesets run by the modsec plugin, in the order they are loaded",
+        "tags": [
+          "admin"
+        ],
+        "operationId": "getModsecRulesets",
+        "responses": {
+          "200": {
+            "description": "the rulesets",
+            "schema": {
+              "type": "array",
+              "items": {
+                "$ref": "#/definitions/modsecRuleset"
+              }
+            }
+          },
+          "default": {
+            "description": "generic error response",
+            "schema": {
+              "$ref": "#/definitions/error"
+            }
+          }
+        }
+      }
+    },
+    "/modsec/rulesets/{name}": {
+      "put": {
+        "description": "Add or replace a SecLang ruleset (the rules that are not supported are skipped, see the warnings)",
+        "tags": [
+          "admin"
+        ],
+        "operationId": "putModsecRuleset",
+        "parameters": [
+          {
+            "type": "string",
+            "description": "the ruleset name, like REQUEST-942-APPLICATION-ATTACK-SQLI.conf",
+            "name": "name",
+            "in": "path",
+            "required": true
+          },
+          {
+            "description": "the ruleset",
+            "name": "body",
+            "in": "body",
+            "required": true,
+            "schema": {
+              "$ref": "#/definitions/modsecRuleset"
+            }
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "the ruleset has been saved",
+            "schema": {
+              "$ref": "#/definitions/modsecRuleset"
+            }
+          },
+          "default": {
+            "description": "generic error response",
+            "schema": {
+              "$ref": "#/definitions/error"
+            }
+          }
+        }
+      },
+      "delete": {
+        "description": "Remove a SecLang ruleset",
+        "tags": [
+          "admin"
+        ],
+        "operationId": "deleteModsecRuleset",
+        "parameters": [
+          {
+            "type": "string",
+            "description": "the ruleset name",
+            "name": "name",
+            "in": "path",
+            "required": true
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "the ruleset has been removed"
+          },
+          "default": {
+            "description": "generic error response",
+            "schema": {
+              "$ref": "#/definitions/error"
+            }
+          }
+        }
+      }
+    },
+    "/openapi/specs": {
+      "get": {
+        "description": "List the OpenAPI specs the requests are validated against (one per host)",
+        "tags": [
+          "admin"
+        ],
+        "operationId": "getOpenapiSpecs",
+        "responses": {
+          "200": {
+            "description": "the specs",
+            "schema": {
+              "type": "array",
+              "items": {
+                "$ref": "#/definitions/openapiSpec"
+              }
+            }
+          },
+          "default": {
+            "description": "generic error response",
+            "schema": {
+              "$ref": "#/definitions/error"
+            }
+          }
+        }
+      }
+    },
+    "/openapi/specs/{host}": {
+      "put": {
+        "description": "Add or replace the OpenAPI spec of a host",
+        "tags": [
+          "admin"
+        ],
+        "operationId": "putOpenapiSpec",
+        "parameters": [
+          {
+            "type": "string",
+            "description": "the host (with its port if it is not the default one)",
+            "name": "host",
+            "in": "path",
+            "required": true
+          },
+          {
+            "description": "the spec",
+            "name": "body",
+            "in": "body",
+            "required": true,
+            "schema": {
+              "$ref": "#/definitions/openapiSpec"
+            }
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "the spec has been saved",
+            "schema": {
+              "$ref": "#/definitions/openapiSpec"
+            }
+          },
+          "default": {
+            "description": "generic error response",
+            "schema": {
+              "$ref": "#/definitions/error"
+            }
+          }
+        }
+      },
+      "delete": {
+        "description": "Remove the OpenAPI spec of a host (its requests are not validated anymore)",
+        "tags": [
+          "admin"
+        ],
+        "operationId": "deleteOpenapiSpec",
+        "parameters": [
+          {
+            "type": "string",
+            "description": "the host",
+            "name": "host",
+            "in": "path",
+            "required": true
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "the spec has been removed"
+          },
+          "default": {
+            "description": "generic error response",
+            "schema": {
+              "$ref": "#/definitions/error"
+            }
+          }
+        }
+      }
+    },
+    "/plugins/stats": {
+      "get": {
+        "description": "List the failure counters of the plugins (scan timeouts and panics)",
+        "tags": [
+          "admin"
+        ],
+        "operationId": "getPluginStats",
+        "responses": {
+          "200": {
+            "description": "the counters of the plugins that failed at least once",
+            "schema": {
+              "type": "array",
+              "items": {
+                "$ref": "#/definitions/pluginStats"
+              }
+            }
+          },
+          "default": {
+            "description": "generic error response",
+            "schema": {
+              "$ref": "#/definitions/error"
+            }
+          }
+        }
+      }
+    },
+    "/ratelimits": {
+      "get": {
+        "description": "List the rate limit rules",
+        "tags": [
+          "admin"
+        ],
+        "operationId": "getRatelimits",
+        "responses": {
+          "200": {
+            "description": "the rate limit rules",
+            "schema": {
+              "type": "array",
+              "items": {
+                "$ref": "#/definitions/ratelimitRule"
+              }
+            }
+          },
+          "default": {
+            "description": "generic error response",
+            "schema": {
+              "$ref": "#/definitions/error"
+            }
+          }
+        }
+      },
+      "post": {
+        "description": "Add a rate limit rule",
+        "tags": [
+          "admin"
+        ],
+        "operationId": "postRatelimit",
+        "parameters": [
+          {
+            "description": "the rule to add",
+            "name": "body",
+            "in": "body",
+            "required": true,
+            "schema": {
+              "$ref": "#/definitions/ratelimitRule"
+            }
+          }
+        ],
+        "responses": {
+          "201": {
+            "description": "the rule has been added",
+            "schema": {
+              "$ref": "#/definitions/ratelimitRule"
+            }
+          },
+          "default": {
+            "description": "generic error response",
+            "schema": {
+              "$ref": "#/definitions/error"
+            }
+          }
+        }
+      }
+    },
+    "/ratelimits/{id}": {
+      "put": {
+        "description": "Update a rate limit rule",
+        "tags": [
+          "admin"
+        ],
+        "operationId": "putRatelimit",
+        "parameters": [
+          {
+            "type": "integer",
+            "format": "int64",
+            "description": "the rule id",
+            "name": "id",
+            "in": "path",
+            "required": true
+          },
+          {
+            "description": "the new rule",
+            "name": "body",
+            "in": "body",
+            "required": true,
+            "schema": {
+              "$ref": "#/definitions/ratelimitRule"
+            }
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "the rule has been updated",
+            "schema": {
+              "$ref": "#/definitions/ratelimitRule"
+            }
+          },
+          "default": {
+            "description": "generic error response",
+            "schema": {
+              "$ref": "#/definitions/error"
+            }
+          }
+        }
+      },
+      "delete": {
+        "description": "Remove a rate limit rule",
+        "tags": [
+          "admin"
+        ],
+        "operationId": "deleteRatelimit",
+        "parameters": [
+          {
+            "type": "integer",
+            "format": "int64",
+            "description": "the rule id",
+            "name": "id",
+            "in": "path",
+            "required": true
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "the rule has been removed"
+          },
+          "default": {
+            "description": "generic error response",
+            "schema": {
+              "$ref": "#/definitions/error"
+            }
+          }
+        }
+      }
+    },
+    "/rules": {
+      "get": {
+        "description": "List the custom rules, in the order they are evaluated",
+        "tags": [
+          "admin"
+        ],
+        "operationId": "getRules",
+        "responses": {
+          "200": {
+            "description": "the rules",
+            "schema": {
+              "type": "array",
+              "items": {
+                "$ref": "#/definitions/rule"
+              }
+            }
+          },
+          "default": {
+            "description": "generic error response",
+            "schema": {
+              "$ref": "#/definitions/error"
+            }
+          }
+        }
+      }
+    },
+    "/rules/{id}": {
+      "put": {
+        "description": "Add or replace a custom rule (the expression is checked before it is saved)",
+        "tags": [
+          "admin"
+        ],
+        "operationId": "putRule",
+        "parameters": [
+          {
+            "type": "string",
+            "description": "the rule id",
+            "name": "id",
+            "in": "path",
+            "required": true
+          },
+          {
+            "description": "the rule",
+            "name": "body",
+            "in": "body",
+            "required": true,
+            "schema": {
+              "$ref": "#/definitions/rule"
+            }
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "the rule has been saved",
+            "schema": {
+              "$ref": "#/definitions/rule"
+            }
+          },
+          "default": {
+            "description": "generic error response",
+            "schema": {
+              "$ref": "#/definitions/error"
+            }
+          }
+        }
+      },
+      "delete": {
+        "description": "Remove a custom rule",
+        "tags": [
+          "admin"
+        ],
+        "operationId": "deleteRule",
+        "parameters": [
+          {
+            "type": "string",
+            "description": "the rule id",
+            "name": "id",
+            "in": "path",
+            "required": true
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "the rule has been removed"
+          },
+          "default": {
+            "description": "generic error response",
+            "schema": {
+              "$ref": "#/definitions/error"
+            }
+          }
+        }
+      }
+    },
+    "/submit": {
+      "post": {
+        "description": "Submit a request payload to analyze",
+        "consumes": [
+          "application/octet-stream"
+        ],
+        "tags": [
+          "waf"
+        ],
+        "operationId": "postSubmit",
+        "parameters": [
+          {
+            "description": "The request payload in binary format",
+            "name": "request",
+            "in": "body",
+            "required": true,
+            "schema": {
+              "type": "string",
+              "format": "binary"
+            }
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "the request is legit"
+          },
+          "401": {
+            "description": "the client must solve a challenge (the proxy serves the page)",
+            "schema": {
+              "$ref": "#/definitions/challenge"
+            }
+          },
+          "403": {
+            "description": "the request must be blocked"
+          },
+          "default": {
+            "description": "generic error response",
+            "schema": {
+              "$ref": "#/definitions/error"
+            }
+          }
+        }
+      }
+    },
+    "/uploadpolicies": {
+      "get": {
+        "description": "List the upload policies",
+        "tags": [
+          "admin"
+        ],
+        "operationId": "getUploadpolicies",
+        "responses": {
+          "200": {
+            "description": "the upload policies",
+            "schema": {
+              "type": "array",
+              "items": {
+                "$ref": "#/definitions/uploadPolicy"
+              }
+            }
+          },
+          "default": {
+            "description": "generic error response",
+            "schema": {
+              "$ref": "#/definitions/error"
+            }
+          }
+        }
+      },
+      "post": {
+        "description": "Add a upload policy",
+        "tags": [
+          "admin"
+        ],
+        "operationId": "postUploadpolicy",
+        "parameters": [
+          {
+            "description": "the policy to add",
+            "name": "body",
+            "in": "body",
+            "required": true,
+            "schema": {
+              "$ref": "#/definitions/uploadPolicy"
+            }
+          }
+        ],
+        "responses": {
+          "201": {
+            "description": "the policy has been added",
+            "schema": {
+              "$ref": "#/definitions/uploadPolicy"
+            }
+          },
+          "default": {
+            "description": "generic error response",
+            "schema": {
+              "$ref": "#/definitions/error"
+            }
+          }
+        }
+      }
+    },
+    "/uploadpolicies/{id}": {
+      "put": {
+        "description": "Update a upload policy",
+        "tags": [
+          "admin"
+        ],
+        "operationId": "putUploadpolicy",
+        "parameters": [
+          {
+            "type": "integer",
+            "format": "int64",
+            "description": "the policy id",
+            "name": "id",
+            "in": "path",
+            "required": true
+          },
+          {
+            "description": "the new policy",
+            "name": "body",
+            "in": "body",
+            "required": true,
+            "schema": {
+              "$ref": "#/definitions/uploadPolicy"
+            }
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "the policy has been updated",
+            "schema": {
+              "$ref": "#/definitions/uploadPolicy"
+            }
+          },
+          "default": {
+            "description": "generic error response",
+            "schema": {
+              "$ref": "#/definitions/error"
+            }
+          }
+        }
+      },
+      "delete": {
+        "description": "Remove a upload policy",
+        "tags": [
+          "admin"
+        ],
+        "operationId": "deleteUploadpolicy",
+        "parameters": [
+          {
+            "type": "integer",
+            "format": "int64",
+            "description": "the policy id",
+            "name": "id",
+            "in": "path",
+            "required": true
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "the policy has been removed"
+          },
+          "default": {
+            "description": "generic error response",
+            "schema": {
+              "$ref": "#/definitions/error"
+            }
+          }
+        }
+      }
+    }
+  },
+  "definitions": {
+    "ban": {
+      "type": "object",
+      "properties": {
+        "cidr": {
+          "type": "string"
+        },
+        "created_at": {
+          "type": "string",
+          "format": "date-time"
+        },
+        "creator": {
+          "type": "string"
+        },
+        "expires_at": {
+          "type": "string",
+          "format": "date-time"
+        },
+        "id": {
+          "type": "integer"
+        },
+        "reason": {
+          "type": "string"
+        }
+      }
+    },
+    "banCreate": {
+      "type": "object",
+      "required": [
+        "cidr",
+        "creator",
+        "duration"
+      ],
+      "properties": {
+        "cidr": {
+          "description": "an IP (1.2.3.4) or a CIDR (1.2.3.0/24)",
+          "type": "string",
+          "minLength": 1
+        },
+        "creator": {
+          "type": "string",
+          "minLength": 1
+        },
+        "duration": {
+          "description": "the ban duration, in seconds",
+          "type": "integer"
+        },
+        "reason": {
+          "type": "string"
+        }
+      }
+    },
+    "cacheStats": {
+      "type": "object",
+      "properties": {
+        "hits": {
+          "type": "integer"
+        },
+        "misses": {
+          "type": "integer"
+        },
+        "size": {
+          "description": "the number of cached verdicts",
+          "type": "integer"
+        }
+      }
+    },
+    "challenge": {
+      "type": "object",
+      "properties": {
+        "cookie": {
+          "description": "the name of the clearance cookie",
+          "type": "string"
+        },
+        "difficulty": {
+          "description": "the number of leading zero bits of sha256(token.nonce)",
+          "type": "integer"
+        },
+        "page": {
+          "description": "the interstitial html page that solves the challenge and sets the cookie",
+          "type": "string"
+        },
+        "token": {
+          "description": "the signed (and time-limited) challenge",
+          "type": "string"
+        }
+      }
+    },
+    "configHistory": {
+      "type": "object",
+      "properties": {
+        "author": {
+          "type": "string"
+        },
+        "comment": {
+          "type": "string"
+        },
+        "created_at": {
+          "type": "string",
+          "format": "date-time"
+        },
+        "existed": {
+          "description": "false if the key was created by this change",
+          "type": "boolean"
+        },
+        "id": {
+          "type": "integer"
+        },
+        "key": {
+          "type": "string"
+        },
+        "new_value": {
+          "type": "string"
+        },
+        "old_value": {
+          "type": "string"
+        },
+        "removed": {
+          "description": "true if the key was removed by this change",
+          "type": "boolean"
+        }
+      }
+    },
+    "configRollback": {
+      "type": "object",
+      "required": [
+        "timestamp",
+        "author"
+      ],
+      "properties": {
+        "author": {
+          "type": "string",
+          "minLength": 1
+        },
+        "comment": {
+          "type": "string"
+        },
+        "key": {
+          "description": "the config key to rollback (the whole config if empty)",
+          "type": "string"
+        },
+        "timestamp": {
+          "description": "the point in time to rollback to",
+          "type": "string",
+          "format": "date-time"
+        }
+      }
+    },
+    "error": {
+      "type": "object",
+      "required": [
+        "message"
+      ],
+      "properties": {
+        "message": {
+          "type": "string",
+          "minLength": 1
+        }
+      }
+    },
+    "health": {
+      "type": "object",
+      "properties": {
+        "status": {
+          "type": "string"
+        }
+      }
+    },
+    "jwtKey": {
+      "type": "object",
+      "required": [
+        "jwk"
+      ],
+      "properties": {
+        "jwk": {
+          "description": "the public key (or the HMAC secret) as a JWK, in JSON",
+          "type": "string",
+          "minLength": 1
+        },
+        "kid": {
+          "type": "string",
+          "readOnly": true
+        },
+        "updated_at": {
+          "type": "string",
+          "format": "date-time",
+          "readOnly": true
+        }
+      }
+    },
+    "jwtPolicy": {
+      "type": "object",
+      "properties": {
+        "audience": {
+          "description": "the expected aud claim (any if empty)",
+          "type": "string"
+        },
+        "id": {
+          "type": "integer",
+          "readOnly": true
+        },
+        "issuer": {
+          "description": "the expected iss claim (any if empty)",
+          "type": "string"
+        },
+        "path_prefix": {
+          "description": "the policy applies to the paths starting with this prefix (the longest prefix wins)",
+          "type": "string"
+        },
+        "required": {
+          "description": "the requests without bearer token are rejected",
+          "type": "boolean"
+        },
+        "required_claims": {
+          "description": "comma separated list of claim or claim=value, for example \"sub,scope=admin\"",
+          "type": "string"
+        }
+      }
+    },
+    "modsecRuleset": {
+      "type": "object",
+      "required": [
+        "content"
+      ],
+      "properties": {
+        "content": {
+          "description": "the SecLang file (SecRule, SecAction and SecMarker directives)",
+          "type": "string"
+        },
+        "name": {
+          "description": "the rulesets are loaded in the order of their name",
+          "type": "string",
+          "readOnly": true
+        },
+        "response_rules": {
+          "description": "the number of rules of the response phases (they are not run)",
+          "type": "integer",
+          "readOnly": true
+        },
+        "rules": {
+          "description": "the number of rules that are run",
+          "type": "integer",
+          "readOnly": true
+        },
+        "updated_at": {
+          "type": "string",
+          "format": "date-time",
+          "readOnly": true
+        },
+        "warnings": {
+          "description": "the rules that are skipped, and why",
+          "type": "array",
+          "items": {
+            "type": "string"
+          },
+          "readOnly": true
+        }
+      }
+    },
+    "openapiSpec": {
+      "type": "object",
+      "required": [
+        "spec"
+      ],
+      "properties": {
+        "host": {
+          "type": "string",
+          "readOnly": true
+        },
+        "spec": {
+          "description": "the swagger 2.0 spec, in JSON or in YAML",
+          "type": "string",
+          "minLength": 1
+        },
+        "updated_at": {
+          "type": "string",
+          "format": "date-time",
+          "readOnly": true
+        }
+      }
+    },
+    "pluginStats": {
+      "type": "object",
+      "properties": {
+        "panics": {
+          "description": "the number of scans that panicked",
+          "type": "integer"
+        },
+        "plugin": {
+          "type": "string"
+        },
+        "timeouts": {
+          "description": "the number of scans that reached their deadline",
+          "type": "integer"
+        }
+      }
+    },
+    "ratelimitRule": {
+      "type": "object",
+      "required": [
+        "key",
+        "algorithm",
+        "limit",
+        "period"
+      ],
+      "properties": {
+        "algorithm": {
+          "description": "token-bucket or sliding-window",
+          "type": "string",
+          "minLength": 1
+        },
+        "burst": {
+          "description": "the token-bucket capacity (limit by default)",
+          "type": "integer"
+        },
+        "id": {
+          "type": "integer",
+          "readOnly": true
+        },
+        "key": {
+          "description": "comma separated list of ip, path, header:\u003cname\u003e, claim:\u003cname\u003e (a claim of the bearer token checked by the jwt plugin), for example \"ip,header:X-Api-Key\" or \"claim:sub\"",
+          "type": "string",
+          "minLength": 1
+        },
+        "limit": {
+          "description": "number of requests allowed per period",
+          "type": "integer"
+        },
+        "path_prefix": {
+          "description": "the rule applies to the paths starting with this prefix (the longest prefix wins)",
+          "type": "string"
+        },
+        "period": {
+          "description": "the period, in seconds",
+          "type": "integer"
+        }
+      }
+    },
+    "rule": {
+      "type": "object",
+      "required": [
+        "expression",
+        "action"
+      ],
+      "properties": {
+        "action": {
+          "type": "string",
+          "enum": [
+            "block",
+            "challenge",
+            "log"
+          ]
+        },
+        "description": {
+          "type": "string"
+        },
+        "expression": {
+          "description": "a boolean expression over the request fields, for example method == \"POST\" \u0026\u0026 path.startsWith(\"/admin\") \u0026\u0026 !ip.in(\"10.0.0.0/8\")",
+          "type": "string",
+          "minLength": 1
+        },
+        "id": {
+          "description": "the rules are evaluated in the order of their id (numerically if they are numbers)",
+          "type": "string",
+          "readOnly": true
+        },
+        "updated_at": {
+          "type": "string",
+          "format": "date-time",
+          "readOnly": true
+        }
+      }
+    },
+    "uploadPolicy": {
+      "type": "object",
+      "properties": {
+        "allowed_extensions": {
+          "description": "comma separated list of the allowed file extensions (any if empty), for example \"jpg,png\"",
+          "type": "string"
+        },
+        "allowed_types": {
+          "description": "comma separated list of the allowed (declared and sniffed) content types (any if empty), for example \"image/jpeg,image/png\"",
+          "type": "string"
+        },
+        "denied_extensions": {
+          "description": "comma separated list of the denied file extensions, for example \"php,exe\"",
+          "type": "string"
+        },
+        "deny": {
+          "description": "no file can be uploaded",
+          "type": "boolean"
+        },
+        "id": {
+          "type": "integer",
+          "readOnly": true
+        },
+        "max_file_size": {
+          "description": "the maximum size of a file, in bytes (no limit if 0)",
+          "type": "integer"
+        },
+        "path_prefix": {
+          "description": "the policy applies to the paths starting with this prefix (the longest prefix wins)",
+          "type": "string"
+        }
+      }
+    }
+  },
+  "tags": [
+    {
+      "description": "Check if taxsi2 is healthy",
+      "name": "health"
+    },
+    {
+      "description": "Manage taxsi2 configuration",
+      "name": "admin"
+    }
+  ],
+  "x-tagGroups": [
+    {
+      "name": "taxsi2 Management",
+      "tags": [
+        "app",
+        "admin"
+      ]
+    },
+    {
+      "name": "Health Check",
+      "tags": [
+        "health"
+      ]
+    },
+    {
+      "name": "WAF",
+      "tags": [
+        "waf"
+      ]
+    }
+  ]
+}`))
+	FlatSwaggerJSON = json.RawMessage([]byte(`{
+  "consumes": [
+    "application/json"
+  ],
+  "produces": [
+    "application/json"
+  ],
+  "schemes": [
+    "http"
+  ],
+  "swagger": "2.0",
+  "info": {
+    "description": "taxsi2 is a WAF server application The base path for all the APIs is \"/api/v1\".\n",
+    "title": "taxsi2",
+    "version": "1.0.0"
+  },
+  "basePath": "/api/v1",
+  "paths": {
+    "/bans": {
+      "get": {
+        "description": "List the active (not expired) IP bans",
+        "tags": [
+          "admin"
+        ],
+        "operationId": "getBans",
+        "responses": {
+          "200": {
+            "description": "the active bans",
+            "schema": {
+              "type": "array",
+              "items": {
+                "$ref": "#/definitions/ban"
+              }
+            }
+          },
+          "default": {
+            "description": "generic error response",
+            "schema": {
+              "$ref": "#/definitions/error"
+            }
+          }
+        }
+      },
+      "post": {
+        "description": "Ban an IP (or a CIDR) for a given duration",
+        "tags": [
+          "admin"
+        ],
+        "operationId": "postBan",
+        "parameters": [
+          {
+            "description": "the ban to add",
+            "name": "body",
+            "in": "body",
+            "required": true,
+            "schema": {
+              "$ref": "#/definitions/banCreate"
+            }
+          }
+        ],
+        "responses": {
+          "201": {
+            "description": "the ban has been added",
+            "schema": {
+              "$ref": "#/definitions/ban"
+            }
+          },
+          "default": {
+            "description": "generic error response",
+            "schema": {
+              "$ref": "#/definitions/error"
+            }
+          }
+        }
+      }
+    },
+    "/bans/{id}": {
+      "delete": {
+        "description": "Lift a ban before its expiration",
+        "tags": [
+          "admin"
+        ],
+        "operationId": "deleteBan",
+        "parameters": [
+          {
+            "type": "integer",
+            "format": "int64",
+            "description": "the ban id",
+            "name": "id",
+            "in": "path",
+            "required": true
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "the ban has been removed"
+          },
+          "default": {
+            "description": "generic error response",
+            "schema": {
+              "$ref": "#/definitions/error"
+            }
+          }
+        }
+      }
+    },
+    "/cache/stats": {
+      "get": {
+        "description": "Get the counters of the verdict cache (since the start of the node)",
+        "tags": [
+          "admin"
+        ],
+        "operationId": "getCacheStats",
+        "responses": {
+          "200": {
+            "description": "the counters of the verdict cache",
+            "schema": {
+              "$ref": "#/definitions/cacheStats"
+            }
+          },
+          "default": {
+            "description": "generic error response",
+            "schema": {
+              "$ref": "#/definitions/error"
+            }
+          }
+        }
+      }
+    },
+    "/config/export": {
+      "get": {
+        "description": "Export the policy (config, allow/deny lists, geoip countries, rate limits, rules, JWT policies, modsec rulesets) as a YAML document, to be applied with 'taxsi2 config apply'",
+        "produces": [
+          "application/x-yaml"
+        ],
+        "tags": [
+          "admin"
+        ],
+        "operationId": "getConfigExport",
+        "responses": {
+          "200": {
+            "description": "the policy document",
+            "schema": {
+              "type": "object"
+            }
+          },
+          "default": {
+            "description": "generic error response",
+            "schema": {
+              "$ref": "#/definitions/error"
+            }
+          }
+        }
+      }
+    },
+    "/config/history": {
+      "get": {
+        "description": "List the configuration changes (oldest first)",
+        "tags": [
+          "admin"
+        ],
+        "operationId": "getConfigHistory",
+        "parameters": [
+          {
+            "type": "string",
+            "description": "only return the changes of this config key",
+            "name": "key",
+            "in": "query"
+          },
+          {
+            "type": "string",
+            "format": "date-time",
+            "description": "only return the changes made after this date",
+            "name": "since",
+            "in": "query"
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "the configuration changes",
+            "schema": {
+              "type": "array",
+              "items": {
+                "$ref": "#/definitions/configHistory"
+              }
+            }
+          },
+          "default": {
+            "description": "generic error response",
+            "schema": {
+              "$ref": "#/definitions/error"
+            }
+          }
+        }
+      }
+    },
+    "/config/rollback": {
+      "post": {
+        "description": "Rollback a config key (or the whole config) to a point in time",
+        "tags": [
+          "admin"
+        ],
+        "operationId": "postConfigRollback",
+        "parameters": [
+          {
+            "description": "the rollback to apply",
+            "name": "body",
+            "in": "body",
+            "required": true,
+            "schema": {
+              "$ref": "#/definitions/configRollback"
+            }
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "the config has been rolled back"
+          },
+          "default": {
+            "description": "generic error response",
+            "schema": {
+              "$ref": "#/definitions/error"
+            }
+          }
+        }
+      }
+    },
+    "/health": {
+      "get": {
+        "description": "Check if taxsi2 is healthy",
+        "tags": [
+          "health"
+        ],
+        "operationId": "getHealth",
+        "responses": {
+          "200": {
+            "description": "status of health check",
+            "schema": {
+              "$ref": "#/definitions/health"
+            }
+          },
+          "default": {
+            "description": "generic error response",
+            "schema": {
+              "$ref": "#/definitions/error"
+            }
+          }
+        }
+      }
+    },
+    "/jwt/keys": {
+      "get": {
+        "description": "List the keys the bearer tokens are checked with (the keys of the JWKS files are not listed)",
+        "tags": [
+          "admin"
+        ],
+        "operationId": "getJwtKeys",
+        "responses": {
+          "200": {
+            "description": "the keys",
+            "schema": {
+              "type": "array",
+              "items": {
+                "$ref": "#/definitions/jwtKey"
+              }
+            }
+          },
+          "default": {
+            "description": "generic error response",
+            "schema": {
+              "$ref": "#/definitions/error"
+            }
+          }
+        }
+      }
+    },
+    "/jwt/keys/{kid}": {
+      "put": {
+        "description": "Add or replace a key",
+        "tags": [
+          "admin"
+        ],
+        "operationId": "putJwtKey",
+        "parameters": [
+          {
+            "type": "string",
+            "description": "the key id",
+            "name": "kid",
+            "in": "path",
+            "required": true
+          },
+          {
+            "description": "the key",
+            "name": "body",
+            "in": "body",
+            "required": true,
+            "schema": {
+              "$ref": "#/definitions/jwtKey"
+            }
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "the key has been saved",
+            "schema": {
+              "$ref": "#/definitions/jwtKey"
+            }
+          },
+          "default": {
+            "description": "generic error response",
+            "schema": {
+              "$ref": "#/definitions/error"
+            }
+          }
+        }
+      },
+      "delete": {
+        "description": "Remove a key",
+        "tags": [
+          "admin"
+        ],
+        "operationId": "deleteJwtKey",
+        "parameters": [
+          {
+            "type": "string",
+            "description": "the key id",
+            "name": "kid",
+            "in": "path",
+            "required": true
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "the key has been removed"
+          },
+          "default": {
+            "description": "generic error response",
+            "schema": {
+              "$ref": "#/definitions/error"
+            }
+          }
+        }
+      }
+    },
+    "/jwt/policies": {
+      "get": {
+        "description": "List the JWT policies",
+        "tags": [
+          "admin"
+        ],
+        "operationId": "getJwtPolicies",
+        "responses": {
+          "200": {
+            "description": "the JWT policies",
+            "schema": {
+              "type": "array",
+              "items": {
+                "$ref": "#/definitions/jwtPolicy"
+              }
+            }
+          },
+          "default": {
+            "description": "generic error response",
+            "schema": {
+              "$ref": "#/definitions/error"
+            }
+          }
+        }
+      },
+      "post": {
+        "description": "Add a JWT policy",
+        "tags": [
+          "admin"
+        ],
+        "operationId": "postJwtPolicy",
+        "parameters": [
+          {
+            "description": "the policy to add",
+            "name": "body",
+            "in": "body",
+            "required": true,
+            "schema": {
+              "$ref": "#/definitions/jwtPolicy"
+            }
+          }
+        ],
+        "responses": {
+          "201": {
+            "description": "the policy has been added",
+            "schema": {
+              "$ref": "#/definitions/jwtPolicy"
+            }
+          },
+          "default": {
+            "description": "generic error response",
+            "schema": {
+              "$ref": "#/definitions/error"
+            }
+          }
+        }
+      }
+    },
+    "/jwt/policies/{id}": {
+      "put": {
+        "description": "Update a JWT policy",
+        "tags": [
+          "admin"
+        ],
+        "operationId": "putJwtPolicy",
+        "parameters": [
+          {
+            "type": "integer",
+            "format": "int64",
+            "description": "the policy id",
+            "name": "id",
+            "in": "path",
+            "required": true
+          },
+          {
+            "description": "the new policy",
+            "name": "body",
+            "in": "body",
+            "required": true,
+            "schema": {
+              "$ref": "#/definitions/jwtPolicy"
+            }
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "the policy has been updated",
+            "schema": {
+              "$ref": "#/definitions/jwtPolicy"
+            }
+          },
+          "default": {
+            "description": "generic error response",
+            "schema": {
+              "$ref": "#/definitions/error"
+            }
+          }
+        }
+      },
+      "delete": {
+        "description": "Remove a JWT policy",
+        "tags": [
+          "admin"
+        ],
+        "operationId": "deleteJwtPolicy",
+        "parameters": [
+          {
+            "type": "integer",
+            "format": "int64",
+            "description": "the policy id",
+            "name": "id",
+            "in": "path",
+            "required": true
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "the policy has been removed"
+          },
+          "default": {
+            "description": "generic error response",
+            "schema": {
+              "$ref": "#/definitions/error"
+            }
+          }
+        }
+      }
+    },
+    "/modsec/rulesets": {
+      "get": {
+        "description": "List the SecLang rulesets run by the modsec plugin, in the order they are loaded",
+        "tags": [
+          "admin"
+        ],
+        "operationId": "getModsecRulesets",
+        "responses": {
+          "200": {
+            "description": "the rulesets",
+            "schema": {
+              "type": "array",
+              "items": {
+                "$ref": "#/definitions/modsecRuleset"
+              }
+            }
+          },
+          "default": {
+            "description": "generic error response",
+            "schema": {
+              "$ref": "#/definitions/error"
+            }
+          }
+        }
+      }
+    },
+    "/modsec/rulesets/{name}": {
+      "put": {
+        "description": "Add or replace a SecLang ruleset (the rules that are not supported are skipped, see the warnings)",
+        "tags": [
+          "admin"
+        ],
+        "operationId": "putModsecRuleset",
+        "parameters": [
+          {
+            "type": "string",
+            "description": "the ruleset name, like REQUEST-942-APPLICATION-ATTACK-SQLI.conf",
+            "name": "name",
+            "in": "path",
+            "required": true
+          },
+          {
+            "description": "the ruleset",
+            "name": "body",
+            "in": "body",
+            "required": true,
+            "schema": {
+              "$ref": "#/definitions/modsecRuleset"
+            }
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "the ruleset has been saved",
+            "schema": {
+              "$ref": "#/definitions/modsecRuleset"
+            }
+          },
+          "default": {
+            "description": "generic error response",
+            "schema": {
+              "$ref": "#/definitions/error"
+            }
+          }
+        }
+      },
+      "delete": {
+        "description": "Remove a SecLang ruleset",
+        "tags": [
+          "admin"
+        ],
+        "operationId": "deleteModsecRuleset",
+        "parameters": [
+          {
+            "type": "string",
+            "description": "the ruleset name",
+            "name": "name",
+            "in": "path",
+            "required": true
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "the ruleset has been removed"
+          },
+          "default": {
+            "description": "generic error response",
+            "schema": {
+              "$ref": "#/definitions/error"
+            }
+          }
+        }
+      }
+    },
+    "/openapi/specs": {
+      "get": {
+        "description": "List the OpenAPI specs the requests are validated against (one per host)",
+        "tags": [
+          "admin"
+        ],
+        "operationId": "getOpenapiSpecs",
+        "responses": {
+          "200": {
+            "description": "the specs",
+            "schema": {
+              "type": "array",
+              "items": {
+                "$ref": "#/definitions/openapiSpec"
+              }
+            }
+          },
+          "default": {
+            "description": "generic error response",
+            "schema": {
+              "$ref": "#/definitions/error"
+            }
+          }
+        }
+      }
+    },
+    "/openapi/specs/{host}": {
+      "put": {
+        "description": "Add or replace the OpenAPI spec of a host",
+        "tags": [
+          "admin"
+        ],
+        "operationId": "putOpenapiSpec",
+        "parameters": [
+          {
+            "type": "string",
+            "description": "the host (with its port if it is not the default one)",
+            "name": "host",
+            "in": "path",
+            "required": true
+          },
+          {
+            "description": "the spec",
+            "name": "body",
+            "in": "body",
+            "required": true,
+            "schema": {
+              "$ref": "#/definitions/openapiSpec"
+            }
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "the spec has been saved",
+            "schema": {
+              "$ref": "#/definitions/openapiSpec"
+            }
+          },
+          "default": {
+            "description": "generic error response",
+            "schema": {
+              "$ref": "#/definitions/error"
+            }
+          }
+        }
+      },
+      "delete": {
+        "description": "Remove the OpenAPI spec of a host (its requests are not validated anymore)",
+        "tags": [
+          "admin"
+        ],
+        "operationId": "deleteOpenapiSpec",
+        "parameters": [
+          {
+            "type": "string",
+            "description": "the host",
+            "name": "host",
+            "in": "path",
+            "required": true
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "the spec has been removed"
+          },
+          "default": {
+            "description": "generic error response",
+            "schema": {
+              "$ref": "#/definitions/error"
+            }
+          }
+        }
+      }
+    },
+    "/plugins/stats": {
+      "get": {
+        "description": "List the failure counters of the plugins (scan timeouts and panics)",
+        "tags": [
+          "admin"
+        ],
+        "operationId": "getPluginStats",
+        "responses": {
+          "200": {
+            "description": "the counters of the plugins that failed at least once",
+            "schema": {
+              "type": "array",
+              "items": {
+                "$ref": "#/definitions/pluginStats"
+              }
+            }
+          },
+          "default": {
+            "description": "generic error response",
+            "schema": {
+              "$ref": "#/definitions/error"
+            }
+          }
+        }
+      }
+    },
+    "/ratelimits": {
+      "get": {
+        "description": "List the rate limit rules",
+        "tags": [
+          "admin"
+        ],
+        "operationId": "getRatelimits",
+        "responses": {
+          "200": {
+            "description": "the rate limit rules",
+            "schema": {
+              "type": "array",
+              "items": {
+                "$ref": "#/definitions/ratelimitRule"
+              }
+            }
+          },
+          "default": {
+            "description": "generic error response",
+            "schema": {
+              "$ref": "#/definitions/error"
+            }
+          }
+        }
+      },
+      "post": {
+        "description": "Add a rate limit rule",
+        "tags": [
+          "admin"
+        ],
+        "operationId": "postRatelimit",
+        "parameters": [
+          {
+            "description": "the rule to add",
+            "name": "body",
+            "in": "body",
+            "required": true,
+            "schema": {
+              "$ref": "#/definitions/ratelimitRule"
+            }
+          }
+        ],
+        "responses": {
+          "201": {
+            "description": "the rule has been added",
+            "schema": {
+              "$ref": "#/definitions/ratelimitRule"
+            }
+          },
+          "default": {
+            "description": "generic error response",
+            "schema": {
+              "$ref": "#/definitions/error"
+            }
+          }
+        }
+      }
+    },
+    "/ratelimits/{id}": {
+      "put": {
+        "description": "Update a rate limit rule",
+        "tags": [
+          "admin"
+        ],
+        "operationId": "putRatelimit",
+        "parameters": [
+          {
+            "type": "integer",
+            "format": "int64",
+            "description": "the rule id",
+            "name": "id",
+            "in": "path",
+            "required": true
+          },
+          {
+            "description": "the new rule",
+            "name": "body",
+            "in": "body",
+            "required": true,
+            "schema": {
+              "$ref": "#/definitions/ratelimitRule"
+            }
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "the rule has been updated",
+            "schema": {
+              "$ref": "#/definitions/ratelimitRule"
+            }
+          },
+          "default": {
+            "description": "generic error response",
+            "schema": {
+              "$ref": "#/definitions/error"
+            }
+          }
+        }
+      },
+      "delete": {
+        "description": "Remove a rate limit rule",
+        "tags": [
+          "admin"
+        ],
+        "operationId": "deleteRatelimit",
+        "parameters": [
+          {
+            "type": "integer",
+            "format": "int64",
+            "description": "the rule id",
+            "name": "id",
+            "in": "path",
+            "required": true
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "the rule has been removed"
+          },
+          "default": {
+            "description": "generic error response",
+            "schema": {
+              "$ref": "#/definitions/error"
+            }
+          }
+        }
+      }
+    },
+    "/rules": {
+      "get": {
+        "description": "List the custom rules, in the order they are evaluated",
+        "tags": [
+          "admin"
+        ],
+        "operationId": "getRules",
+        "responses": {
+          "200": {
+            "description": "the rules",
+            "schema": {
+              "type": "array",
+              "items": {
+                "$ref": "#/definitions/rule"
+              }
+            }
+          },
+          "default": {
+            "description": "generic error response",
+            "schema": {
+              "$ref": "#/definitions/error"
+            }
+          }
+        }
+      }
+    },
+    "/rules/{id}": {
+      "put": {
+        "description": "Add or replace a custom rule (the expression is checked before it is saved)",
+        "tags": [
+          "admin"
+        ],
+        "operationId": "putRule",
+        "parameters": [
+          {
+            "type": "string",
+            "description": "the rule id",
+            "name": "id",
+            "in": "path",
+            "required": true
+          },
+          {
+            "description": "the rule",
+            "name": "body",
+            "in": "body",
+            "required": true,
+            "schema": {
+              "$ref": "#/definitions/rule"
+            }
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "the rule has been saved",
+            "schema": {
+              "$ref": "#/definitions/rule"
+            }
+          },
+          "default": {
+            "description": "generic error response",
+            "schema": {
+              "$ref": "#/definitions/error"
+            }
+          }
+        }
+      },
+      "delete": {
+        "description": "Remove a custom rule",
+        "tags": [
+          "admin"
+        ],
+        "operationId": "deleteRule",
+        "parameters": [
+          {
+            "type": "string",
+            "description": "the rule id",
+            "name": "id",
+            "in": "path",
+            "required": true
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "the rule has been removed"
+          },
+          "default": {
+            "description": "generic error response",
+            "schema": {
+              "$ref": "#/definitions/error"
+            }
+          }
+        }
+      }
+    },
+    "/submit": {
+      "post": {
+        "description": "Submit a request payload to analyze",
+        "consumes": [
+          "application/octet-stream"
+        ],
+        "tags": [
+          "waf"
+        ],
+        "operationId": "postSubmit",
+        "parameters": [
+          {
+            "description": "The request payload in binary format",
+            "name": "request",
+            "in": "body",
+            "required": true,
+            "schema": {
+              "type": "string",
+              "format": "binary"
+            }
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "the request is legit"
+          },
+          "401": {
+            "description": "the client must solve a challenge (the proxy serves the page)",
+            "schema": {
+              "$ref": "#/definitions/challenge"
+            }
+          },
+          "403": {
+            "description": "the request must be blocked"
+          },
+          "default": {
+            "description": "generic error response",
+            "schema": {
+              "$ref": "#/definitions/error"
+            }
+          }
+        }
+      }
+    },
+    "/uploadpolicies": {
+      "get": {
+        "description": "List the upload policies",
+        "tags": [
+          "admin"
+        ],
+        "operationId": "getUploadpolicies",
+        "responses": {
+          "200": {
+            "description": "the upload policies",
+            "schema": {
+              "type": "array",
+              "items": {
+                "$ref": "#/definitions/uploadPolicy"
+              }
+            }
+          },
+          "default": {
+            "description": "generic error response",
+            "schema": {
+              "$ref": "#/definitions/error"
+            }
+          }
+        }
+      },
+      "post": {
+        "description": "Add a upload policy",
+        "tags": [
+          "admin"
+        ],
+        "operationId": "postUploadpolicy",
+        "parameters": [
+          {
+            "description": "the policy to add",
+            "name": "body",
+            "in": "body",
+            "required": true,
+            "schema": {
+              "$ref": "#/definitions/uploadPolicy"
+            }
+          }
+        ],
+        "responses": {
+          "201": {
+            "description": "the policy has been added",
+            "schema": {
+              "$ref": "#/definitions/uploadPolicy"
+            }
+          },
+          "default": {
+            "description": "generic error response",
+            "schema": {
+              "$ref": "#/definitions/error"
+            }
+          }
+        }
+      }
+    },
+    "/uploadpolicies/{id}": {
+      "put": {
+        "description": "Update a upload policy",
+        "tags": [
+          "admin"
+        ],
+        "operationId": "putUploadpolicy",
+        "parameters": [
+          {
+            "type": "integer",
+            "format": "int64",
+            "description": "the policy id",
+            "name": "id",
+            "in": "path",
+            "required": true
+          },
+          {
+            "description": "the new policy",
+            "name": "body",
+            "in": "body",
+            "required": true,
+            "schema": {
+              "$ref": "#/definitions/uploadPolicy"
+            }
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "the policy has been updated",
+            "schema": {
+              "$ref": "#/definitions/uploadPolicy"
+            }
+          },
+          "default": {
+            "description": "generic error response",
+            "schema": {
+              "$ref": "#/definitions/error"
+            }
+          }
+        }
+      },
+      "delete": {
+        "description": "Remove a upload policy",
+        "tags": [
+          "admin"
+        ],
+        "operationId": "deleteUploadpolicy",
+        "parameters": [
+          {
+            "type": "integer",
+            "format": "int64",
+            "description": "the policy id",
+            "name": "id",
+            "in": "path",
+            "required": true
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "the policy has been removed"
+          },
+          "default": {
+            "description": "generic error response",
+            "schema": {
+              "$ref": "#/definitions/error"
+            }
+          }
+        }
+      }
+    }
+  },
+  "definitions": {
+    "ban": {
+      "type": "object",
+      "properties": {
+        "cidr": {
+          "type": "string"
+        },
+        "created_at": {
+          "type": "string",
+          "format": "date-time"
+        },
+        "creator": {
+          "type": "string"
+        },
+        "expires_at": {
+          "type": "string",
+          "format": "date-time"
+        },
+        "id": {
+          "type": "integer"
+        },
+        "reason": {
+          "type": "string"
+        }
+      }
+    },
+    "banCreate": {
+      "type": "object",
+      "required": [
+        "cidr",
+        "creator",
+        "duration"
+      ],
+      "properties": {
+        "cidr": {
+          "description": "an IP (1.2.3.4) or a CIDR (1.2.3.0/24)",
+          "type": "string",
+          "minLength": 1
+        },
+        "creator": {
+          "type": "string",
+          "minLength": 1
+        },
+        "duration": {
+          "description": "the ban duration, in seconds",
+          "type": "integer"
+        },
+        "reason": {
+          "type": "string"
+        }
+      }
+    },
+    "cacheStats": {
+      "type": "object",
+      "properties": {
+        "hits": {
+          "type": "integer"
+        },
+        "misses": {
+          "type": "integer"
+        },
+        "size": {
+          "description": "the number of cached verdicts",
+          "type": "integer"
+        }
+      }
+    },
+    "challenge": {
+      "type": "object",
+      "properties": {
+        "cookie": {
+          "description": "the name of the clearance cookie",
+          "type": "string"
+        },
+        "difficulty": {
+          "description": "the number of leading zero bits of sha256(token.nonce)",
+          "type": "integer"
+        },
+        "page": {
+          "description": "the interstitial html page that solves the challenge and sets the cookie",
+          "type": "string"
+        },
+        "token": {
+          "description": "the signed (and time-limited) challenge",
+          "type": "string"
+        }
+      }
+    },
+    "configHistory": {
+      "type": "object",
+      "properties": {
+        "author": {
+          "type": "string"
+        },
+        "comment": {
+          "type": "string"
+        },
+        "created_at": {
+          "type": "string",
+          "format": "date-time"
+        },
+        "existed": {
+          "description": "false if the key was created by this change",
+          "type": "boolean"
+        },
+        "id": {
+          "type": "integer"
+        },
+        "key": {
+          "type": "string"
+        },
+        "new_value": {
+          "type": "string"
+        },
+        "old_value": {
+          "type": "string"
+        },
+        "removed": {
+          "description": "true if the key was removed by this change",
+          "type": "boolean"
+        }
+      }
+    },
+    "configRollback": {
+      "type": "object",
+      "required": [
+        "timestamp",
+        "author"
+      ],
+      "properties": {
+        "author": {
+          "type": "string",
+          "minLength": 1
+        },
+        "comment": {
+          "type": "string"
+        },
+        "key": {
+          "description": "the config key to rollback (the whole config if empty)",
+          "type": "string"
+        },
+        "timestamp": {
+          "description": "the point in time to rollback to",
+          "type": "string",
+          "format": "date-time"
+        }
+      }
+    },
+    "error": {
+      "type": "object",
+      "required": [
+        "message"
+      ],
+      "properties": {
+        "message": {
+          "type": "string",
+          "minLength": 1
+        }
+      }
+    },
+    "health": {
+      "type": "object",
+      "properties": {
+        "status": {
+          "type": "string"
+        }
+      }
+    },
+    "jwtKey": {
+      "type": "object",
+      "required": [
+        "jwk"
+      ],
+      "properties": {
+        "jwk": {
+          "description": "the public key (or the HMAC secret) as a JWK, in JSON",
+          "type": "string",
+          "minLength": 1
+        },
+        "kid": {
+          "type": "string",
+          "readOnly": true
+        },
+        "updated_at": {
+          "type": "string",
+          "format": "date-time",
+          "readOnly": true
+        }
+      }
+    },
+    "jwtPolicy": {
+      "type": "object",
+      "properties": {
+        "audience": {
+          "description": "the expected aud claim (any if empty)",
+          "type": "string"
+        },
+        "id": {
+          "type": "integer",
+          "readOnly": true
+        },
+        "issuer": {
+          "description": "the expected iss claim (any if empty)",
+          "type": "string"
+        },
+        "path_prefix": {
+          "description": "the policy applies to the paths starting with this prefix (the longest prefix wins)",
+          "type": "string"
+        },
+        "required": {
+          "description": "the requests without bearer token are rejected",
+          "type": "boolean"
+        },
+        "required_claims": {
+          "description": "comma separated list of claim or claim=value, for example \"sub,scope=admin\"",
+          "type": "string"
+        }
+      }
+    },
+    "modsecRuleset": {
+      "type": "object",
+      "required": [
+        "content"
+      ],
+      "properties": {
+        "content": {
+          "description": "the SecLang file (SecRule, SecAction and SecMarker directives)",
+          "type": "string"
+        },
+        "name": {
+          "description": "the rulesets are loaded in the order of their name",
+          "type": "string",
+          "readOnly": true
+        },
+        "response_rules": {
+          "description": "the number of rules of the response phases (they are not run)",
+          "type": "integer",
+          "readOnly": true
+        },
+        "rules": {
+          "description": "the number of rules that are run",
+          "type": "integer",
+          "readOnly": true
+        },
+        "updated_at": {
+          "type": "string",
+          "format": "date-time",
+          "readOnly": true
+        },
+        "warnings": {
+          "description": "the rules that are skipped, and why",
+          "type": "array",
+          "items": {
+            "type": "string"
+          },
+          "readOnly": true
+        }
+      }
+    },
+    "openapiSpec": {
+      "type": "object",
+      "required": [
+        "spec"
+      ],
+      "properties": {
+        "host": {
+          "type": "string",
+          "readOnly": true
+        },
+        "spec": {
+          "description": "the swagger 2.0 spec, in JSON or in YAML",
+          "type": "string",
+          "minLength": 1
+        },
+        "updated_at": {
+          "type": "string",
+          "format": "date-time",
+          "readOnly": true
+        }
+      }
+    },
+    "pluginStats": {
+      "type": "object",
+      "properties": {
+        "panics": {
+          "description": "the number of scans that panicked",
+          "type": "integer"
+        },
+        "plugin": {
+          "type": "string"
+        },
+        "timeouts": {
+          "description": "the number of scans that reached their deadline",
+          "type": "integer"
+        }
+      }
+    },
+    "ratelimitRule": {
+      "type": "object",
+      "required": [
+        "key",
+        "algorithm",
+        "limit",
+        "period"
+      ],
+      "properties": {
+        "algorithm": {
+          "description": "token-bucket or sliding-window",
+          "type": "string",
+          "minLength": 1
+        },
+        "burst": {
+          "description": "the token-bucket capacity (limit by default)",
+          "type": "integer"
+        },
+        "id": {
+          "type": "integer",
+          "readOnly": true
+        },
+        "key": {
+          "description": "comma separated list of ip, path, header:\u003cname\u003e, claim:\u003cname\u003e (a claim of the bearer token checked by the jwt plugin), for example \"ip,header:X-Api-Key\" or \"claim:sub\"",
+          "type": "string",
+          "minLength": 1
+        },
+        "limit": {
+          "description": "number of requests allowed per period",
+          "type": "integer"
+        },
+        "path_prefix": {
+          "description": "the rule applies to the paths starting with this prefix (the longest prefix wins)",
+          "type": "string"
+        },
+        "period": {
+          "description": "the period, in seconds",
+          "type": "integer"
+        }
+      }
+    },
+    "rule": {
+      "type": "object",
+      "required": [
+        "expression",
+        "action"
+      ],
+      "properties": {
+        "action": {
+          "type": "string",
+          "enum": [
+            "block",
+            "challenge",
+            "log"
+          ]
+        },
+        "description": {
+          "type": "string"
+        },
+        "expression": {
+          "description": "a boolean expression over the request fields, for example method == \"POST\" \u0026\u0026 path.startsWith(\"/admin\") \u0026\u0026 !ip.in(\"10.0.0.0/8\")",
+          "type": "string",
+          "minLength": 1
+        },
+        "id": {
+          "description": "the rules are evaluated in the order of their id (numerically if they are numbers)",
+          "type": "string",
+          "readOnly": true
+        },
+        "updated_at": {
+          "type": "string",
+          "format": "date-time",
+          "readOnly": true
+        }
+      }
+    },
+    "uploadPolicy": {
+      "type": "object",
+      "properties": {
+        "allowed_extensions": {
+          "description": "comma separated list of the allowed file extensions (any if empty), for example \"jpg,png\"",
+          "type": "string"
+        },
+        "allowed_types": {
+          "description": "comma separated list of the allowed (declared and sniffed) content types (any if empty), for example \"image/jpeg,image/png\"",
+          "type": "string"
+        },
+        "denied_extensions": {
+          "description": "comma separated list of the denied file extensions, for example \"php,exe\"",
+          "type": "string"
+        },
+        "deny": {
+          "description": "no file can be uploaded",
+          "type": "boolean"
+        },
+        "id": {
+          "type": "integer",
+          "readOnly": true
+        },
+        "max_file_size": {
+          "description": "the maximum size of a file, in bytes (no limit if 0)",
+          "type": "integer"
+        },
+        "path_prefix": {
+          "description": "the policy applies to the paths starting with this prefix (the longest prefix wins)",
+          "type": "string"
+        }
+      }
+    }
+  },
+  "tags": [
+    {
+      "description": "Check if taxsi2 is healthy",
+      "name": "health"
+    },
+    {
+      "description": "Manage taxsi2 configuration",
+      "name": "admin"
     }
   ],
   "x-tagGroups": [
     {
       "name": "taxsi2 Management",
       "tags": [
-        "app"
+        "app",
+        "admin"
       ]
     },
     {
@@ -15,10 +15,12 @@ import (
 	"github.com/go-openapi/runtime"
 	"github.com/go-openapi/runtime/middleware"
 	"github.com/go-openapi/runtime/security"
+	"github.com/go-openapi/runtime/yamlpc"
 	"github.com/go-openapi/spec"
 	"github.com/go-openapi/strfmt"
 	"github.com/go-openapi/swag"
 
+	"github.com/nzin/taxsi2/swagger_gen/restapi/operations/admin"
 	"github.com/nzin/taxsi2/swagger_gen/restapi/operations/health"
 	"github.com/nzin/taxsi2/swagger_gen/restapi/operations/waf"
 )
@@ -45,7 +47,104 @@ func NewTaxsi2API(spec *loads.Document) *Taxsi2API {
 		JSONConsumer: runtime.JSONConsumer(),
 
 		JSONProducer: runtime.JSONProducer(),
+		YamlProducer: yamlpc.YAMLProducer(),
 
+		AdminDeleteBanHandler: admin.DeleteBanHandlerFunc(func(params admin.DeleteBanParams) middleware.Responder {
+			return middleware.NotImplemented("operation admin.DeleteBan has not yet been implemented")
+		}),
+		AdminDeleteJwtKeyHandler: admin.DeleteJwtKeyHandlerFunc(func(params admin.DeleteJwtKeyParams) middleware.Responder {
+			return middleware.NotImplemented("operation admin.DeleteJwtKey has not yet been implemented")
+		}),
+		AdminDeleteJwtPolicyHandler: admin.DeleteJwtPolicyHandlerFunc(func(params admin.DeleteJwtPolicyParams) middleware.Responder {
+			return middleware.NotImplemented("operation admin.DeleteJwtPolicy has not yet been implemented")
+		}),
+		AdminDeleteModsecRulesetHandler: admin.DeleteModsecRulesetHandlerFunc(func(params admin.DeleteModsecRulesetParams) middleware.Responder {
+			return middleware.NotImplemented("operation admin.DeleteModsecRuleset has not yet been implemented")
+		}),
+		AdminDeleteOpenapiSpecHandler: admin.DeleteOpenapiSpecHandlerFunc(func(params admin.DeleteOpenapiSpecParams) middleware.Responder {
+			return middleware.NotImplemented("operation admin.DeleteOpenapiSpec has not yet been implemented")
+		}),
+		AdminDeleteRatelimitHandler: admin.DeleteRatelimitHandlerFunc(func(params admin.DeleteRatelimitParams) middleware.Responder {
+			return middleware.NotImplemented("operation admin.DeleteRatelimit has not yet been implemented")
+		}),
+		AdminDeleteRuleHandler: admin.DeleteRuleHandlerFunc(func(params admin.DeleteRuleParams) middleware.Responder {
+			return middleware.NotImplemented("operation admin.DeleteRule has not yet been implemented")
+		}),
+		AdminDeleteUploadpolicyHandler: admin.DeleteUploadpolicyHandlerFunc(func(params admin.DeleteUploadpolicyParams) middleware.Responder {
+			return middleware.NotImplemented("operation admin.DeleteUploadpolicy has not yet been implemented")
+		}),
+		AdminGetBansHandler: admin.GetBansHandlerFunc(func(params admin.GetBansParams) middleware.Responder {
+			return middleware.NotImplemented("operation admin.GetBans has not yet been implemented")
+		}),
+		AdminGetCacheStatsHandler: admin.GetCacheStatsHandlerFunc(func(params admin.GetCacheStatsParams) middleware.Responder {
+			return middleware.NotImplemented("operation admin.GetCacheStats has not yet been implemented")
+		}),
+		AdminGetConfigExportHandler: admin.GetConfigExportHandlerFunc(func(params admin.GetConfigExportParams) middleware.Responder {
+			return middleware.NotImplemented("operation admin.GetConfigExport has not yet been implemented")
+		}),
+		AdminGetConfigHistoryHandler: admin.GetConfigHistoryHandlerFunc(func(params admin.GetConfigHistoryParams) middleware.Responder {
+			return middleware.NotImplemented("operation admin.GetConfigHistory has not yet been implemented")
+		}),
+		AdminGetJwtKeysHandler: admin.GetJwtKeysHandlerFunc(func(params admin.GetJwtKeysParams) middleware.Responder {
+			return middleware.NotImplemented("operation admin.GetJwtKeys has not yet been implemented")
+		}),
+		AdminGetJwtPoliciesHandler: admin.GetJwtPoliciesHandlerFunc(func(params admin.GetJwtPoliciesParams) middleware.Responder {
+			return middleware.NotImplemented("operation admin.GetJwtPolicies has not yet been implemented")
+		}),
+		AdminGetModsecRulesetsHandler: admin.GetModsecRulesetsHandlerFunc(func(params admin.GetModsecRulesetsParams) middleware.Responder {
+			return middleware.NotImplemented("operation admin.GetModsecRulesets has not yet been implemented")
+		}),
+		AdminGetOpenapiSpecsHandler: admin.GetOpenapiSpecsHandlerFunc(func(params admin.GetOpenapiSpecsParams) middleware.Responder {
+			return middleware.NotImplemented("operation admin.GetOpenapiSpecs has not yet been implemented")
+		}),
+		AdminGetPluginStatsHandler: admin.GetPluginStatsHandlerFunc(func(params admin.GetPluginStatsParams) middleware.Responder {
+			return middleware.NotImplemented("operation admin.GetPluginStats has not yet been implemented")
+		}),
+		AdminGetRatelimitsHandler: admin.GetRatelimitsHandlerFunc(func(params admin.GetRatelimitsParams) middleware.Responder {
+			return middleware.NotImplemented("operation admin.GetRatelimits has not yet been implemented")
+		}),
+		AdminGetRulesHandler: admin.GetRulesHandlerFunc(func(params admin.GetRulesParams) middleware.Responder {
+			return middleware.NotImplemented("operation admin.GetRules has not yet been implemented")
+		}),
+		AdminGetUploadpoliciesHandler: admin.GetUploadpoliciesHandlerFunc(func(params admin.GetUploadpoliciesParams) middleware.Responder {
+			return middleware.NotImplemented("operation admin.GetUploadpolicies has not yet been implemented")
+		}),
+		AdminPostBanHandler: admin.PostBanHandlerFunc(func(params admin.PostBanParams) middleware.Responder {
+			return middleware.NotImplemented("operation admin.PostBan has not yet been implemented")
+		}),
+		AdminPostConfigRollbackHandler: admin.PostConfigRollbackHandlerFunc(func(params admin.PostConfigRollbackParams) middleware.Responder {
+			return middleware.NotImplemented("operation admin.PostConfigRollback has not yet been implemented")
+		}),
+		AdminPostJwtPolicyHandler: admin.PostJwtPolicyHandlerFunc(func(params admin.PostJwtPolicyParams) middleware.Responder {
+			return middleware.NotImplemented("operation admin.PostJwtPolicy has not yet been implemented")
+		}),
+		AdminPostRatelimitHandler: admin.PostRatelimitHandlerFunc(func(params admin.PostRatelimitParams) middleware.Responder {
+			return middleware.NotImplemented("operation admin.PostRatelimit has not yet been implemented")
+		}),
+		AdminPostUploadpolicyHandler: admin.PostUploadpolicyHandlerFunc(func(params admin.PostUploadpolicyParams) middleware.Responder {
+			return middleware.NotImplemented("operation admin.PostUploadpolicy has not yet been implemented")
+		}),
+		AdminPutJwtKeyHandler: admin.PutJwtKeyHandlerFunc(func(params admin.PutJwtKeyParams) middleware.Responder {
+			return middleware.NotImplemented("operation admin.PutJwtKey has not yet been implemented")
+		}),
+		AdminPutJwtPolicyHandler: admin.PutJwtPolicyHandlerFunc(func(params admin.PutJwtPolicyParams) middleware.Responder {
+			return middleware.NotImplemented("operation admin.PutJwtPolicy has not yet been implemented")
+		}),
+		AdminPutModsecRulesetHandler: admin.PutModsecRulesetHandlerFunc(func(params admin.PutModsecRulesetParams) middleware.Responder {
+			return middleware.NotImplemented("operation admin.PutModsecRuleset has not yet been implemented")
+		}),
+		AdminPutOpenapiSpecHandler: admin.PutOpenapiSpecHandlerFunc(func(params admin.PutOpenapiSpecParams) middleware.Responder {
+			return middleware.NotImplemented("operation admin.PutOpenapiSpec has not yet been implemented")
+		}),
+		AdminPutRatelimitHandler: admin.PutRatelimitHandlerFunc(func(params admin.PutRatelimitParams) middleware.Responder {
+			return middleware.NotImplemented("operation admin.PutRatelimit has not yet been implemented")
+		}),
+		AdminPutRuleHandler: admin.PutRuleHandlerFunc(func(params admin.PutRuleParams) middleware.Responder {
+			return middleware.NotImplemented("operation admin.PutRule has not yet been implemented")
+		}),
+		AdminPutUploadpolicyHandler: admin.PutUploadpolicyHandlerFunc(func(params admin.PutUploadpolicyParams) middleware.Responder {
+			return middleware.NotImplemented("operation admin.PutUploadpolicy has not yet been implemented")
+		}),
 		HealthGetHealthHandler: health.GetHealthHandlerFunc(func(params health.GetHealthParams) middleware.Responder {
 			return middleware.NotImplemented("operation health.GetHealth has not yet been implemented")
 		}),
@@ -91,7 +190,74 @@ type Taxsi2API struct {
 	// JSONProducer registers a producer for the following mime types:
 	//   - application/json
 	JSONProducer runtime.Producer
-
+	// YamlProducer registers a producer for the following mime types:
+	//   - application/x-yaml
+	YamlProducer runtime.Producer
+
+	// AdminDeleteBanHandler sets the operation handler for the delete ban operation
+	AdminDeleteBanHandler admin.DeleteBanHandler
+	// AdminDeleteJwtKeyHandler sets the operation handler for the delete jwt key operation
+	AdminDeleteJwtKeyHandler admin.DeleteJwtKeyHandler
+	// AdminDeleteJwtPolicyHandler sets the operation handler for the delete jwt policy operation
+	AdminDeleteJwtPolicyHandler admin.DeleteJwtPolicyHandler
+	// AdminDeleteModsecRulesetHandler sets the operation handler for the delete modsec ruleset operation
+	AdminDeleteModsecRulesetHandler admin.DeleteModsecRulesetHandler
+	// AdminDeleteOpenapiSpecHandler sets the operation handler for the delete openapi spec operation
+	AdminDeleteOpenapiSpecHandler admin.DeleteOpenapiSpecHandler
+	// AdminDeleteRatelimitHandler sets the operation handler for the delete ratelimit operation
+	AdminDeleteRatelimitHandler admin.DeleteRatelimitHandler
+	// AdminDeleteRuleHandler sets the operation handler for the delete rule operation
+	AdminDeleteRuleHandler admin.DeleteRuleHandler
+	// AdminDeleteUploadpolicyHandler sets the operation handler for the delete uploadpolicy operation
+	AdminDeleteUploadpolicyHandler admin.DeleteUploadpolicyHandler
+	// AdminGetBansHandler sets the operation handler for the get bans operation
+	AdminGetBansHandler admin.GetBansHandler
+	// AdminGetCacheStatsHandler sets the operation handler for the get cache stats operation
+	AdminGetCacheStatsHandler admin.GetCacheStatsHandler
+	// AdminGetConfigExportHandler sets the operation handler for the get config export operation
+	AdminGetConfigExportHandler admin.GetConfigExportHandler
+	// AdminGetConfigHistoryHandler sets the operation handler for the get config history operation
+	AdminGetConfigHistoryHandler admin.GetConfigHistoryHandler
+	// AdminGetJwtKeysHandler sets the operation handler for the get jwt keys operation
+	AdminGetJwtKeysHandler admin.GetJwtKeysHandler
+	// AdminGetJwtPoliciesHandler sets the operation handler for the get jwt policies operation
+	AdminGetJwtPoliciesHandler admin.GetJwtPoliciesHandler
+	// AdminGetModsecRulesetsHandler sets the operation handler for the get modsec rulesets operation
+	AdminGetModsecRulesetsHandler admin.GetModsecRulesetsHandler
+	// AdminGetOpenapiSpecsHandler sets the operation handler for the get openapi specs operation
+	AdminGetOpenapiSpecsHandler admin.GetOpenapiSpecsHandler
+	// AdminGetPluginStatsHandler sets the operation handler for the get plugin stats operation
+	AdminGetPluginStatsHandler admin.GetPluginStatsHandler
+	// AdminGetRatelimitsHandler sets the operation handler for the get ratelimits operation
+	AdminGetRatelimitsHandler admin.GetRatelimitsHandler
+	// AdminGetRulesHandler sets the operation handler for the get rules operation
+	AdminGetRulesHandler admin.GetRulesHandler
+	// AdminGetUploadpoliciesHandler sets the operation handler for the get uploadpolicies operation
+	AdminGetUploadpoliciesHandler admin.GetUploadpoliciesHandler
+	// AdminPostBanHandler sets the operation handler for the post ban operation
+	AdminPostBanHandler admin.PostBanHandler
+	// AdminPostConfigRollbackHandler sets the operation handler for the post config rollback operation
+	AdminPostConfigRollbackHandler admin.PostConfigRollbackHandler
+	// AdminPostJwtPolicyHandler sets the operation handler for the post jwt policy operation
+	AdminPostJwtPolicyHandler admin.PostJwtPolicyHandler
+	// AdminPostRatelimitHandler sets the operation handler for the post ratelimit operation
+	AdminPostRatelimitHandler admin.PostRatelimitHandler
+	// AdminPostUploadpolicyHandler sets the operation handler for the post uploadpolicy operation
+	AdminPostUploadpolicyHandler admin.PostUploadpolicyHandler
+	// AdminPutJwtKeyHandler sets the operation handler for the put jwt key operation
+	AdminPutJwtKeyHandler admin.PutJwtKeyHandler
+	// AdminPutJwtPolicyHandler sets the operation handler for the put jwt policy operation
+	AdminPutJwtPolicyHandler admin.PutJwtPolicyHandler
+	// AdminPutModsecRulesetHandler sets the operation handler for the put modsec ruleset operation
+	AdminPutModsecRulesetHandler admin.PutModsecRulesetHandler
+	// AdminPutOpenapiSpecHandler sets the operation handler for the put openapi spec operation
+	AdminPutOpenapiSpecHandler admin.PutOpenapiSpecHandler
+	// AdminPutRatelimitHandler sets the operation handler for the put ratelimit operation
+	AdminPutRatelimitHandler admin.PutRatelimitHandler
+	// AdminPutRuleHandler sets the operation handler for the put rule operation
+	AdminPutRuleHandler admin.PutRuleHandler
+	// AdminPutUploadpolicyHandler sets the operation handler for the put uploadpolicy operation
+	AdminPutUploadpolicyHandler admin.PutUploadpolicyHandler
 	// HealthGetHealthHandler sets the operation handler for the get health operation
 	HealthGetHealthHandler health.GetHealthHandler
 	// WafPostSubmitHandler sets the operation handler for the post submit operation
@@ -175,7 +341,106 @@ func (o *Taxsi2API) Validate() error {
 	if o.JSONProducer == nil {
 		unregistered = append(unregistered, "JSONProducer")
 	}
+	if o.YamlProducer == nil {
+		unregistered = append(unregistered, "YamlProducer")
+	}
 
+	if o.AdminDeleteBanHandler == nil {
+		unregistered = append(unregistered, "admin.DeleteBanHandler")
+	}
+	if o.AdminDeleteJwtKeyHandler == nil {
+		unregistered = append(unregistered, "admin.DeleteJwtKeyHandler")
+	}
+	if o.AdminDeleteJwtPolicyHandler == nil {
+		unregistered = append(unregistered, "admin.DeleteJwtPolicyHandler")
+	}
+	if o.AdminDeleteModsecRulesetHandler == nil {
+		unregistered = append(unregistered, "admin.DeleteModsecRulesetHandler")
+	}
+	if o.AdminDeleteOpenapiSpecHandler == nil {
+		unregistered = append(unregistered, "admin.DeleteOpenapiSpecHandler")
+	}
+	if o.AdminDeleteRatelimitHandler == nil {
+		unregistered = append(unregistered, "admin.DeleteRatelimitHandler")
+	}
+	if o.AdminDeleteRuleHandler == nil {
+		unregistered = append(unregistered, "admin.DeleteRuleHandler")
+	}
+	if o.AdminDeleteUploadpolicyHandler == nil {
+		unregistered = append(unregistered, "admin.DeleteUploadpolicyHandler")
+	}
+	if o.AdminGetBansHandler == nil {
+		unregistered = append(unregistered, "admin.GetBansHandler")
+	}
+	if o.AdminGetCacheStatsHandler == nil {
+		unregistered = append(unregistered, "admin.GetCacheStatsHandler")
+	}
+	if o.AdminGetConfigExportHandler == nil {
+		unregistered = append(unregistered, "admin.GetConfigExportHandler")
+	}
+	if o.AdminGetConfigHistoryHandler == nil {
+		unregistered = append(unregistered, "admin.GetConfigHistoryHandler")
+	}
+	if o.AdminGetJwtKeysHandler == nil {
+		unregistered = append(unregistered, "admin.GetJwtKeysHandler")
+	}
+	if o.AdminGetJwtPoliciesHandler == nil {
+		unregistered = append(unregistered, "admin.GetJwtPoliciesHandler")
+	}
+	if o.AdminGetModsecRulesetsHandler == nil {
+		unregistered = append(unregistered, "admin.GetModsecRulesetsHandler")
+	}
+	if o.AdminGetOpenapiSpecsHandler == nil {
+		unregistered = append(unregistered, "admin.GetOpenapiSpecsHandler")
+	}
+	if o.AdminGetPluginStatsHandler == nil {
+		unregistered = append(unregistered, "admin.GetPluginStatsHandler")
+	}
+	if o.AdminGetRatelimitsHandler == nil {
+		unregistered = append(unregistered, "admin.GetRatelimitsHandler")
+	}
+	if o.AdminGetRulesHandler == nil {
+		unregistered = append(unregistered, "admin.GetRulesHandler")
+	}
+	if o.AdminGetUploadpoliciesHandler == nil {
+		unregistered = append(unregistered, "admin.GetUploadpoliciesHandler")
+	}
+	if o.AdminPostBanHandler == nil {
+		unregistered = append(unregistered, "admin.PostBanHandler")
+	}
+	if o.AdminPostConfigRollbackHandler == nil {
+		unregistered = append(unregistered, "admin.PostConfigRollbackHandler")
+	}
+	if o.AdminPostJwtPolicyHandler == nil {
+		unregistered = append(unregistered, "admin.PostJwtPolicyHandler")
+	}
+	if o.AdminPostRatelimitHandler == nil {
+		unregistered = append(unregistered, "admin.PostRatelimitHandler")
+	}
+	if o.AdminPostUploadpolicyHandler == nil {
+		unregistered = append(unregistered, "admin.PostUploadpolicyHandler")
+	}
+	if o.AdminPutJwtKeyHandler == nil {
+		unregistered = append(unregistered, "admin.PutJwtKeyHandler")
+	}
+	if o.AdminPutJwtPolicyHandler == nil {
+		unregistered = append(unregistered, "admin.PutJwtPolicyHandler")
+	}
+	if o.AdminPutModsecRulesetHandler == nil {
+		unregistered = append(unregistered, "admin.PutModsecRulesetHandler")
+	}
+	if o.AdminPutOpenapiSpecHandler == nil {
+		unregistered = append(unregistered, "admin.PutOpenapiSpecHandler")
+	}
+	if o.AdminPutRatelimitHandler == nil {
+		unregistered = append(unregistered, "admin.PutRatelimitHandler")
+	}
+	if o.AdminPutRuleHandler == nil {
+		unregistered = append(unregistered, "admin.PutRuleHandler")
+	}
+	if o.AdminPutUploadpolicyHandler == nil {
+		unregistered = append(unregistered, "admin.PutUploadpolicyHandler")
+	}
 	if o.HealthGetHealthHandler == nil {
 		unregistered = append(unregistered, "health.GetHealthHandler")
 	}
@@ -232,6 +497,8 @@ func (o *Taxsi2API) ProducersFor(mediaTypes []string) map[string]runtime.Produce
 		switch mt {
 		case "application/json":
 			result["application/json"] = o.JSONProducer
+		case "application/x-yaml":
+			result["application/x-yaml"] = o.YamlProducer
 		}
 
 		if p, ok := o.customProducers[mt]; ok {
@@ -272,6 +539,134 @@ func (o *Taxsi2API) initHandlerCache() {
 		o.handlers = make(map[string]map[string]http.Handler)
 	}
 
+	if o.handlers["DELETE"] == nil {
+		o.handlers["DELETE"] = make(map[string]http.Handler)
+	}
+	o.handlers["DELETE"]["/bans/{id}"] = admin.NewDeleteBan(o.context, o.AdminDeleteBanHandler)
+	if o.handlers["DELETE"] == nil {
+		o.handlers["DELETE"] = make(map[string]http.Handler)
+	}
+	o.handlers["DELETE"]["/jwt/keys/{kid}"] = admin.NewDeleteJwtKey(o.context, o.AdminDeleteJwtKeyHandler)
+	if o.handlers["DELETE"] == nil {
+		o.handlers["DELETE"] = make(map[string]http.Handler)
+	}
+	o.handlers["DELETE"]["/jwt/policies/{id}"] = admin.NewDeleteJwtPolicy(o.context, o.AdminDeleteJwtPolicyHandler)
+	if o.handlers["DELETE"] == nil {
+		o.handlers["DELETE"] = make(map[string]http.Handler)
+	}
+	o.handlers["DELETE"]["/modsec/rulesets/{name}"] = admin.NewDeleteModsecRuleset(o.context, o.AdminDeleteModsecRulesetHandler)
+	if o.handlers["DELETE"] == nil {
+		o.handlers["DELETE"] = make(map[string]http.Handler)
+	}
+	o.handlers["DELETE"]["/openapi/specs/{host}"] = admin.NewDeleteOpenapiSpec(o.context, o.AdminDeleteOpenapiSpecHandler)
+	if o.handlers["DELETE"] == nil {
+		o.handlers["DELETE"] = make(map[string]http.Handler)
+	}
+	o.handlers["DELETE"]["/ratelimits/{id}"] = admin.NewDeleteRatelimit(o.context, o.AdminDeleteRatelimitHandler)
+	if o.handlers["DELETE"] == nil {
+		o.handlers["DELETE"] = make(map[string]http.Handler)
+	}
+	o.handlers["DELETE"]["/rules/{id}"] = admin.NewDeleteRule(o.context, o.AdminDeleteRuleHandler)
+	if o.handlers["DELETE"] == nil {
+		o.handlers["DELETE"] = make(map[string]http.Handler)
+	}
+	o.handlers["DELETE"]["/uploadpolicies/{id}"] = admin.NewDeleteUploadpolicy(o.context, o.AdminDeleteUploadpolicyHandler)
+	if o.handlers["GET"] == nil {
+		o.handlers["GET"] = make(map[string]http.Handler)
+	}
+	o.handlers["GET"]["/bans"] = admin.NewGetBans(o.context, o.AdminGetBansHandler)
+	if o.handlers["GET"] == nil {
+		o.handlers["GET"] = make(map[string]http.Handler)
+	}
+	o.handlers["GET"]["/cache/stats"] = admin.NewGetCacheStats(o.context, o.AdminGetCacheStatsHandler)
+	if o.handlers["GET"] == nil {
+		o.handlers["GET"] = make(map[string]http.Handler)
+	}
+	o.handlers["GET"]["/config/export"] = admin.NewGetConfigExport(o.context, o.AdminGetConfigExportHandler)
+	if o.handlers["GET"] == nil {
+		o.handlers["GET"] = make(map[string]http.Handler)
+	}
+	o.handlers["GET"]["/config/history"] = admin.NewGetConfigHistory(o.context, o.AdminGetConfigHistoryHandler)
+	if o.handlers["GET"] == nil {
+		o.handlers["GET"] = make(map[string]http.Handler)
+	}
+	o.handlers["GET"]["/jwt/keys"] = admin.NewGetJwtKeys(o.context, o.AdminGetJwtKeysHandler)
+	if o.handlers["GET"] == nil {
+		o.handlers["GET"] = make(map[string]http.Handler)
+	}
+	o.handlers["GET"]["/jwt/policies"] = admin.NewGetJwtPolicies(o.context, o.AdminGetJwtPoliciesHandler)
+	if o.handlers["GET"] == nil {
+		o.handlers["GET"] = make(map[string]http.Handler)
+	}
+	o.handlers["GET"]["/modsec/rulesets"] = admin.NewGetModsecRulesets(o.context, o.AdminGetModsecRulesetsHandler)
+	if o.handlers["GET"] == nil {
+		o.handlers["GET"] = make(map[string]http.Handler)
+	}
+	o.handlers["GET"]["/openapi/specs"] = admin.NewGetOpenapiSpecs(o.context, o.AdminGetOpenapiSpecsHandler)
+	if o.handlers["GET"] == nil {
+		o.handlers["GET"] = make(map[string]http.Handler)
+	}
+	o.handlers["GET"]["/plugins/stats"] = admin.NewGetPluginStats(o.context, o.AdminGetPluginStatsHandler)
+	if o.handlers["GET"] == nil {
+		o.handlers["GET"] = make(map[string]http.Handler)
+	}
+	o.handlers["GET"]["/ratelimits"] = admin.NewGetRatelimits(o.context, o.AdminGetRatelimitsHandler)
+	if o.handlers["GET"] == nil {
+		o.handlers["GET"] = make(map[string]http.Handler)
+	}
+	o.handlers["GET"]["/rules"] = admin.NewGetRules(o.context, o.AdminGetRulesHandler)
+	if o.handlers["GET"] == nil {
+		o.handlers["GET"] = make(map[string]http.Handler)
+	}
+	o.handlers["GET"]["/uploadpolicies"] = admin.NewGetUploadpolicies(o.context, o.AdminGetUploadpoliciesHandler)
+	if o.handlers["POST"] == nil {
+		o.handlers["POST"] = make(map[string]http.Handler)
+	}
+	o.handlers["POST"]["/bans"] = admin.NewPostBan(o.context, o.AdminPostBanHandler)
+	if o.handlers["POST"] == nil {
+		o.handlers["POST"] = make(map[string]http.Handler)
+	}
+	o.handlers["POST"]["/config/rollback"] = admin.NewPostConfigRollback(o.context, o.AdminPostConfigRollbackHandler)
+	if o.handlers["POST"] == nil {
+		o.handlers["POST"] = make(map[string]http.Handler)
+	}
+	o.handlers["POST"]["/jwt/policies"] = admin.NewPostJwtPolicy(o.context, o.AdminPostJwtPolicyHandler)
+	if o.handlers["POST"] == nil {
+		o.handlers["POST"] = make(map[string]http.Handler)
+	}
+	o.handlers["POST"]["/ratelimits"] = admin.NewPostRatelimit(o.context, o.AdminPostRatelimitHandler)
+	if o.handlers["POST"] == nil {
+		o.handlers["POST"] = make(map[string]http.Handler)
+	}
+	o.handlers["POST"]["/uploadpolicies"] = admin.NewPostUploadpolicy(o.context, o.AdminPostUploadpolicyHandler)
+	if o.handlers["PUT"] == nil {
+		o.handlers["PUT"] = make(map[string]http.Handler)
+	}
+	o.handlers["PUT"]["/jwt/keys/{kid}"] = admin.NewPutJwtKey(o.context, o.AdminPutJwtKeyHandler)
+	if o.handlers["PUT"] == nil {
+		o.handlers["PUT"] = make(map[string]http.Handler)
+	}
+	o.handlers["PUT"]["/jwt/policies/{id}"] = admin.NewPutJwtPolicy(o.context, o.AdminPutJwtPolicyHandler)
+	if o.handlers["PUT"] == nil {
+		o.handlers["PUT"] = make(map[string]http.Handler)
+	}
+	o.handlers["PUT"]["/modsec/rulesets/{name}"] = admin.NewPutModsecRuleset(o.context, o.AdminPutModsecRulesetHandler)
+	if o.handlers["PUT"] == nil {
+		o.handlers["PUT"] = make(map[string]http.Handler)
+	}
+	o.handlers["PUT"]["/openapi/specs/{host}"] = admin.NewPutOpenapiSpec(o.context, o.AdminPutOpenapiSpecHandler)
+	if o.handlers["PUT"] == nil {
+		o.handlers["PUT"] = make(map[string]http.Handler)
+	}
+	o.handlers["PUT"]["/ratelimits/{id}"] = admin.NewPutRatelimit(o.context, o.AdminPutRatelimitHandler)
+	if o.handlers["PUT"] == nil {
+		o.handlers["PUT"] = make(map[string]http.Handler)
+	}
+	o.handlers["PUT"]["/rules/{id}"] = admin.NewPutRule(o.context, o.AdminPutRuleHandler)
+	if o.handlers["PUT"] == nil {
+		o.handlers["PUT"] = make(map[string]http.Handler)
+	}
+	o.handlers["PUT"]["/uploadpolicies/{id}"] = admin.NewPutUploadpolicy(o.context, o.AdminPutUploadpolicyHandler)
 	if o.handlers["GET"] == nil {
 		o.handlers["GET"] = make(map[string]http.Handler)
 	}
@@ -38,6 +38,51 @@ func (o *PostSubmitOK) WriteResponse(rw http.ResponseWriter, producer runtime.Pr
 	rw.WriteHeader(200)
 }
 
+// PostSubmitUnauthorizedCode is the HTTP code returned for type PostSubmitUnauthorized
+const PostSubmitUnauthorizedCode int = 401
+
+/*
+PostSubmitUnauthorized the client must solve a challenge (the proxy serves the page)
+
+swagger:response postSubmitUnauthorized
+*/
+type PostSubmitUnauthorized struct {
+
+	/*
+	  In: Body
+	*/
+	Payload *models.Challenge `json:"body,omitempty"`
+}
+
+// NewPostSubmitUnauthorized creates PostSubmitUnauthorized with default headers values
+func NewPostSubmitUnauthorized() *PostSubmitUnauthorized {
+
+	return &PostSubmitUnauthorized{}
+}
+
+// WithPayload adds the payload to the post submit unauthorized response
+func (o *PostSubmitUnauthorized) WithPayload(payload *models.Challenge) *PostSubmitUnauthorized {
+	o.Payload = payload
+	return o
+}
+
+// SetPayload sets the payload to the post submit unauthorized response
+func (o *PostSubmitUnauthorized) SetPayload(payload *models.Challenge) {
+	o.Payload = payload
+}
+
+// WriteResponse to the client
+func (o *PostSubmitUnauthorized) WriteResponse(rw http.ResponseWriter, producer runtime.Producer) {
+
+	rw.WriteHeader(401)
+	if o.Payload != nil {
+		payload := o.Payload
+		if err := producer.Produce(rw, payload); err != nil {
+			panic(err) // let the recovery middleware deal with this
+		}
+	}
+}
+
 // PostSubmitForbiddenCode is the HTTP code returned for type PostSubmitForbidden
 const PostSubmitForbiddenCode int = 403
 